@@ -36,10 +36,34 @@ CREATE TABLE sessions (
         ON DELETE CASCADE
 );
 
+-- Outcome of mutating requests sent with an Idempotency-Key header, a retry
+-- with the same key replays the stored response instead of running again
+CREATE TABLE idempotency_keys (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL, -- with the query params
+    request_hash CHAR(64) NOT NULL, -- sha256 of method, path, query and body
+    token UUID NOT NULL, -- new for every reservation, only its holder may store or free it
+    status_code INTEGER, -- NULL while the first request is still running
+    response_headers JSONB,
+    response_body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    CONSTRAINT uq_idempotency_keys_user_key UNIQUE (user_id, key),
+    CONSTRAINT fk_idempotency_keys_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+        ON DELETE CASCADE
+);
+
 CREATE TABLE categories (
     id SERIAL PRIMARY KEY,
     name VARCHAR(100) NOT NULL UNIQUE,
     description TEXT,
+    tax_rate NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (tax_rate >= 0 AND tax_rate <= 100), -- percent
     created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
@@ -75,9 +99,15 @@ CREATE TABLE items (
     name VARCHAR(150) NOT NULL,
     category_id INTEGER NOT NULL,
     rack_id INTEGER NOT NULL,
-    stock INTEGER NOT NULL DEFAULT 0,
+    stock INTEGER NOT NULL DEFAULT 0, -- in the racks plus in transit between them
+    in_transit INTEGER NOT NULL DEFAULT 0 CHECK (in_transit >= 0), -- dispatched by a transfer, not yet received
     minimum_stock INTEGER NOT NULL DEFAULT 5,
     price NUMERIC(15,2) NOT NULL CHECK (price >= 0),
+    tax_rate NUMERIC(5,2) CHECK (tax_rate >= 0 AND tax_rate <= 100), -- NULL uses the category rate
+    track_lots BOOLEAN NOT NULL DEFAULT FALSE, -- stock is received and sold per lot
+    serialized BOOLEAN NOT NULL DEFAULT FALSE, -- every unit has a serial, stock counts the serials in stock or in transit
+    average_cost NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (average_cost >= 0), -- weighted average cost of the stock on hand
+    abc_class CHAR(1) CHECK (abc_class IN ('A', 'B', 'C')), -- from the last ABC classification, NULL until classified
     created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
 
@@ -90,26 +120,171 @@ CREATE TABLE items (
         REFERENCES racks(id)
 );
 
+-- Stock per rack, items.stock is kept as the sum of all locations of the item
+CREATE TABLE item_locations (
+    id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL,
+    rack_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_item_locations_item
+        FOREIGN KEY (item_id)
+        REFERENCES items(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_item_locations_rack
+        FOREIGN KEY (rack_id)
+        REFERENCES racks(id),
+
+    CONSTRAINT uq_item_location
+        UNIQUE (item_id, rack_id)
+);
+
+-- Stock per lot and rack of items with track_lots, receipts create lots and
+-- sales consume them first-expiry-first-out. A lot spread over racks has a row
+-- per rack with the same expiry date; the lots of an item in a rack sum to its
+-- item_locations quantity. Units of a lot in transit are in no rack, they are
+-- booked into the destination rack when the transfer is received
+CREATE TABLE item_lots (
+    id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL,
+    rack_id INTEGER NOT NULL,
+    lot_number VARCHAR(50) NOT NULL,
+    expiry_date DATE, -- NULL for lots that don't expire
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_item_lots_item
+        FOREIGN KEY (item_id)
+        REFERENCES items(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_item_lots_rack
+        FOREIGN KEY (rack_id)
+        REFERENCES racks(id),
+
+    CONSTRAINT uq_item_lot
+        UNIQUE (item_id, rack_id, lot_number)
+);
+
+-- Units of serialized items, every stock movement of such an item names the
+-- serials it moves so items.stock equals the serials in_stock or in_transit
+CREATE TABLE item_serials (
+    id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL,
+    serial_number VARCHAR(100) NOT NULL UNIQUE,
+    rack_id INTEGER NOT NULL, -- current rack, or the last one while not in stock
+    status VARCHAR(20) NOT NULL DEFAULT 'in_stock'
+        CHECK (status IN ('in_stock', 'in_transit', 'sold', 'damaged', 'removed')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_item_serials_item
+        FOREIGN KEY (item_id)
+        REFERENCES items(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_item_serials_rack
+        FOREIGN KEY (rack_id)
+        REFERENCES racks(id)
+);
+
+-- Lifecycle of a serial: one row per stock movement that moved it, plus
+-- damaged returns which don't move stock
+CREATE TABLE serial_events (
+    id SERIAL PRIMARY KEY,
+    serial_id INTEGER NOT NULL,
+    event_type VARCHAR(30) NOT NULL,
+    rack_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    reference_type VARCHAR(30),
+    reference_id INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_serial_events_serial
+        FOREIGN KEY (serial_id)
+        REFERENCES item_serials(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_serial_events_rack
+        FOREIGN KEY (rack_id)
+        REFERENCES racks(id),
+
+    CONSTRAINT fk_serial_events_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id)
+);
+
+-- FIFO cost layers: every stock increase adds a layer at its unit cost and
+-- every decrease consumes the oldest layers first, the remaining quantities of
+-- an item's layers sum to items.stock
+CREATE TABLE cost_layers (
+    id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL,
+    unit_cost NUMERIC(15,2) NOT NULL CHECK (unit_cost >= 0),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    remaining INTEGER NOT NULL CHECK (remaining >= 0 AND remaining <= quantity),
+    reference_type VARCHAR(20), -- document that brought the stock in
+    reference_id INTEGER,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_cost_layers_item
+        FOREIGN KEY (item_id)
+        REFERENCES items(id)
+        ON DELETE CASCADE
+);
+
+CREATE TABLE customers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    email VARCHAR(100) UNIQUE,
+    phone VARCHAR(30),
+    address TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
 CREATE TABLE sales (
     id SERIAL PRIMARY KEY,
     user_id INTEGER NOT NULL,
-    total_amount NUMERIC(15,2) NOT NULL CHECK (total_amount >= 0),
+    customer_id INTEGER, -- NULL for walk-in sales
+    gross_amount NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (gross_amount >= 0),
+    discount_amount NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (discount_amount >= 0),
+    tax_amount NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (tax_amount >= 0),
+    total_amount NUMERIC(15,2) NOT NULL CHECK (total_amount >= 0), -- gross - discount + tax
     created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMPTZ,
 
     CONSTRAINT fk_sales_user
         FOREIGN KEY (user_id)
-        REFERENCES users(id)
+        REFERENCES users(id),
+
+    CONSTRAINT fk_sales_customer
+        FOREIGN KEY (customer_id)
+        REFERENCES customers(id)
+        ON DELETE SET NULL
 );
 
 CREATE TABLE sale_items (
     id SERIAL PRIMARY KEY,
     sale_id INTEGER NOT NULL,
     item_id INTEGER NOT NULL,
+    rack_id INTEGER,
     quantity INTEGER NOT NULL CHECK (quantity > 0),
     price_at_sale NUMERIC(15,2) NOT NULL CHECK (price_at_sale >= 0),
-    subtotal NUMERIC(15,2) NOT NULL CHECK (subtotal >= 0),
+    gross_amount NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (gross_amount >= 0),
+    discount_amount NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (discount_amount >= 0), -- line discount plus its share of the sale discount
+    tax_rate NUMERIC(5,2) NOT NULL DEFAULT 0,
+    tax_amount NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (tax_amount >= 0),
+    subtotal NUMERIC(15,2) NOT NULL CHECK (subtotal >= 0), -- net: gross - discount + tax
+    lot_number VARCHAR(50), -- lot the units came from, for recall tracing
+    serial_numbers TEXT[], -- units sold, for serialized items
+    unit_cost NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (unit_cost >= 0), -- cost of goods sold per unit
+    cost_amount NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (cost_amount >= 0), -- cost of goods sold of the line
 
     CONSTRAINT fk_sale_items_sale
         FOREIGN KEY (sale_id)
@@ -117,16 +292,366 @@ CREATE TABLE sale_items (
         ON DELETE CASCADE,
 
     CONSTRAINT fk_sale_items_item
+        FOREIGN KEY (item_id)
+        REFERENCES items(id),
+
+    CONSTRAINT fk_sale_items_rack
+        FOREIGN KEY (rack_id)
+        REFERENCES racks(id)
+);
+
+CREATE TABLE sale_returns (
+    id SERIAL PRIMARY KEY,
+    sale_id INTEGER NOT NULL,
+    note TEXT,
+    refund_amount NUMERIC(15,2) NOT NULL CHECK (refund_amount >= 0),
+    created_by INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_sale_returns_sale
+        FOREIGN KEY (sale_id)
+        REFERENCES sales(id),
+
+    CONSTRAINT fk_sale_returns_created_by
+        FOREIGN KEY (created_by)
+        REFERENCES users(id)
+);
+
+-- Returned lines keep their sale line, so a sale with returns can't be edited or voided
+CREATE TABLE sale_return_items (
+    id SERIAL PRIMARY KEY,
+    return_id INTEGER NOT NULL,
+    sale_item_id INTEGER NOT NULL,
+    item_id INTEGER NOT NULL,
+    rack_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    condition VARCHAR(20) NOT NULL CHECK (condition IN ('restock', 'damaged')),
+    price_at_sale NUMERIC(15,2) NOT NULL CHECK (price_at_sale >= 0),
+    refund_amount NUMERIC(15,2) NOT NULL CHECK (refund_amount >= 0),
+    serial_numbers TEXT[],
+
+    CONSTRAINT fk_sale_return_items_return
+        FOREIGN KEY (return_id)
+        REFERENCES sale_returns(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_sale_return_items_sale_item
+        FOREIGN KEY (sale_item_id)
+        REFERENCES sale_items(id),
+
+    CONSTRAINT fk_sale_return_items_item
+        FOREIGN KEY (item_id)
+        REFERENCES items(id),
+
+    CONSTRAINT fk_sale_return_items_rack
+        FOREIGN KEY (rack_id)
+        REFERENCES racks(id)
+);
+
+-- Stock promised to a customer before the sale, held until released, converted
+-- into a sale or past expires_at; available stock is stock minus active holds
+CREATE TABLE stock_reservations (
+    id SERIAL PRIMARY KEY,
+    customer_id INTEGER,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'released', 'converted')),
+    note TEXT,
+    expires_at TIMESTAMPTZ NOT NULL,
+    sale_id INTEGER, -- set when converted into a sale
+    created_by INTEGER NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_stock_reservations_customer
+        FOREIGN KEY (customer_id)
+        REFERENCES customers(id),
+
+    CONSTRAINT fk_stock_reservations_sale
+        FOREIGN KEY (sale_id)
+        REFERENCES sales(id),
+
+    CONSTRAINT fk_stock_reservations_created_by
+        FOREIGN KEY (created_by)
+        REFERENCES users(id)
+);
+
+CREATE TABLE stock_reservation_items (
+    id SERIAL PRIMARY KEY,
+    reservation_id INTEGER NOT NULL,
+    item_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+
+    CONSTRAINT uq_stock_reservation_items_item UNIQUE (reservation_id, item_id),
+
+    CONSTRAINT fk_stock_reservation_items_reservation
+        FOREIGN KEY (reservation_id)
+        REFERENCES stock_reservations(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_stock_reservation_items_item
+        FOREIGN KEY (item_id)
+        REFERENCES items(id)
+);
+
+CREATE TABLE stock_movements (
+    id SERIAL PRIMARY KEY,
+    item_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    rack_id INTEGER NOT NULL,
+    movement_type VARCHAR(30) NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity <> 0),
+    balance_after INTEGER NOT NULL CHECK (balance_after >= 0),
+    reason_code VARCHAR(30),
+    reason TEXT,
+    reference_type VARCHAR(30),
+    reference_id INTEGER,
+    lot_number VARCHAR(50), -- lot changed with the stock, for lot tracked items
+    average_cost NUMERIC(15,2), -- items.average_cost right after the movement, for valuation as of a date
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_stock_movements_item
+        FOREIGN KEY (item_id)
+        REFERENCES items(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_stock_movements_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(id),
+
+    CONSTRAINT fk_stock_movements_rack
+        FOREIGN KEY (rack_id)
+        REFERENCES racks(id)
+);
+
+CREATE TABLE stock_transfers (
+    id SERIAL PRIMARY KEY,
+    source_rack_id INTEGER NOT NULL,
+    destination_rack_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'in_transit', 'received', 'cancelled')),
+    note TEXT,
+    created_by INTEGER NOT NULL,
+    dispatched_by INTEGER,
+    dispatched_at TIMESTAMPTZ,
+    received_by INTEGER,
+    received_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_stock_transfers_source_rack
+        FOREIGN KEY (source_rack_id)
+        REFERENCES racks(id),
+
+    CONSTRAINT fk_stock_transfers_destination_rack
+        FOREIGN KEY (destination_rack_id)
+        REFERENCES racks(id),
+
+    CONSTRAINT fk_stock_transfers_created_by
+        FOREIGN KEY (created_by)
+        REFERENCES users(id),
+
+    CONSTRAINT chk_stock_transfers_racks
+        CHECK (source_rack_id <> destination_rack_id)
+);
+
+CREATE TABLE stock_transfer_items (
+    id SERIAL PRIMARY KEY,
+    transfer_id INTEGER NOT NULL,
+    item_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    lot_number VARCHAR(50), -- lot the units move, for lot tracked items
+    serial_numbers TEXT[],
+
+    CONSTRAINT fk_stock_transfer_items_transfer
+        FOREIGN KEY (transfer_id)
+        REFERENCES stock_transfers(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_stock_transfer_items_item
         FOREIGN KEY (item_id)
         REFERENCES items(id)
 );
 
+-- Physical counts of a warehouse or a set of racks. Expected quantities are
+-- snapshotted when the count opens, approval posts counted minus expected to
+-- the stock ledger, with expected rebased by the stock_movements of the rack
+-- between the snapshot and the count
+CREATE TABLE stocktakes (
+    id SERIAL PRIMARY KEY,
+    warehouse_id INTEGER, -- NULL when a set of racks is counted
+    rack_ids INTEGER[] NOT NULL, -- racks in scope of the count
+    status VARCHAR(20) NOT NULL DEFAULT 'open'
+        CHECK (status IN ('open', 'approved', 'cancelled')),
+    freeze_sales BOOLEAN NOT NULL DEFAULT FALSE, -- counted items can't be sold from the counted racks while open
+    abc_classes TEXT[], -- only items of these ABC classes are counted, NULL counts every item
+    note TEXT,
+    created_by INTEGER NOT NULL,
+    approved_by INTEGER,
+    approved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_stocktakes_warehouse
+        FOREIGN KEY (warehouse_id)
+        REFERENCES warehouses(id),
+
+    CONSTRAINT fk_stocktakes_created_by
+        FOREIGN KEY (created_by)
+        REFERENCES users(id)
+);
+
+CREATE TABLE stocktake_items (
+    id SERIAL PRIMARY KEY,
+    stocktake_id INTEGER NOT NULL,
+    item_id INTEGER NOT NULL,
+    rack_id INTEGER NOT NULL,
+    expected_quantity INTEGER NOT NULL, -- rack stock when the count opened, 0 for items found
+    counted_quantity INTEGER CHECK (counted_quantity >= 0), -- NULL until counted
+    counted_by INTEGER,
+    counted_at TIMESTAMPTZ,
+    excluded BOOLEAN NOT NULL DEFAULT FALSE, -- left out of the approval, e.g. lot tracked or serialized lines adjusted per lot or serial
+
+    CONSTRAINT fk_stocktake_items_stocktake
+        FOREIGN KEY (stocktake_id)
+        REFERENCES stocktakes(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_stocktake_items_item
+        FOREIGN KEY (item_id)
+        REFERENCES items(id),
+
+    CONSTRAINT fk_stocktake_items_rack
+        FOREIGN KEY (rack_id)
+        REFERENCES racks(id),
+
+    CONSTRAINT uq_stocktake_items_item_rack
+        UNIQUE (stocktake_id, item_id, rack_id)
+);
+
+CREATE TABLE suppliers (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    contact_name VARCHAR(100),
+    phone VARCHAR(30),
+    email VARCHAR(100),
+    address TEXT,
+    lead_time_days INTEGER CHECK (lead_time_days >= 0), -- days from order to delivery, NULL when unknown
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE purchase_orders (
+    id SERIAL PRIMARY KEY,
+    supplier_id INTEGER NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'approved', 'partially_received', 'received', 'cancelled')),
+    note TEXT,
+    total_amount NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (total_amount >= 0),
+    created_by INTEGER NOT NULL,
+    approved_by INTEGER,
+    approved_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_purchase_orders_supplier
+        FOREIGN KEY (supplier_id)
+        REFERENCES suppliers(id),
+
+    CONSTRAINT fk_purchase_orders_created_by
+        FOREIGN KEY (created_by)
+        REFERENCES users(id),
+
+    CONSTRAINT fk_purchase_orders_approved_by
+        FOREIGN KEY (approved_by)
+        REFERENCES users(id)
+);
+
+CREATE TABLE purchase_order_items (
+    id SERIAL PRIMARY KEY,
+    purchase_order_id INTEGER NOT NULL,
+    item_id INTEGER NOT NULL,
+    quantity_ordered INTEGER NOT NULL CHECK (quantity_ordered > 0),
+    quantity_received INTEGER NOT NULL DEFAULT 0 CHECK (quantity_received >= 0),
+    unit_cost NUMERIC(15,2) NOT NULL CHECK (unit_cost >= 0),
+    subtotal NUMERIC(15,2) NOT NULL CHECK (subtotal >= 0),
+
+    CONSTRAINT fk_purchase_order_items_order
+        FOREIGN KEY (purchase_order_id)
+        REFERENCES purchase_orders(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_purchase_order_items_item
+        FOREIGN KEY (item_id)
+        REFERENCES items(id),
+
+    CONSTRAINT uq_purchase_order_item
+        UNIQUE (purchase_order_id, item_id)
+);
+
+CREATE TABLE goods_receipts (
+    id SERIAL PRIMARY KEY,
+    supplier_id INTEGER NOT NULL,
+    purchase_order_id INTEGER,
+    delivery_note_number VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'received'
+        CHECK (status IN ('received', 'voided')),
+    note TEXT,
+    total_amount NUMERIC(15,2) NOT NULL DEFAULT 0 CHECK (total_amount >= 0),
+    received_by INTEGER NOT NULL,
+    voided_by INTEGER,
+    voided_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT fk_goods_receipts_supplier
+        FOREIGN KEY (supplier_id)
+        REFERENCES suppliers(id),
+
+    CONSTRAINT fk_goods_receipts_purchase_order
+        FOREIGN KEY (purchase_order_id)
+        REFERENCES purchase_orders(id),
+
+    CONSTRAINT fk_goods_receipts_received_by
+        FOREIGN KEY (received_by)
+        REFERENCES users(id),
+
+    CONSTRAINT fk_goods_receipts_voided_by
+        FOREIGN KEY (voided_by)
+        REFERENCES users(id)
+);
+
+CREATE TABLE goods_receipt_items (
+    id SERIAL PRIMARY KEY,
+    receipt_id INTEGER NOT NULL,
+    item_id INTEGER NOT NULL,
+    rack_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    unit_cost NUMERIC(15,2) NOT NULL CHECK (unit_cost >= 0),
+    subtotal NUMERIC(15,2) NOT NULL CHECK (subtotal >= 0),
+    lot_number VARCHAR(50),
+    expiry_date DATE,
+    serial_numbers TEXT[],
+
+    CONSTRAINT fk_goods_receipt_items_receipt
+        FOREIGN KEY (receipt_id)
+        REFERENCES goods_receipts(id)
+        ON DELETE CASCADE,
+
+    CONSTRAINT fk_goods_receipt_items_item
+        FOREIGN KEY (item_id)
+        REFERENCES items(id),
+
+    CONSTRAINT fk_goods_receipt_items_rack
+        FOREIGN KEY (rack_id)
+        REFERENCES racks(id)
+);
+
 -- User & Auth
 CREATE INDEX idx_users_role_id ON users(role_id);
 CREATE INDEX idx_users_email ON users(email);
 CREATE INDEX idx_sessions_user_id ON sessions(user_id);
 CREATE INDEX idx_sessions_token ON sessions(token);
 CREATE INDEX idx_sessions_expired_at ON sessions(expired_at);
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
 
 -- Inventory
 CREATE INDEX idx_items_category_id ON items(category_id);
@@ -134,9 +659,45 @@ CREATE INDEX idx_items_rack_id ON items(rack_id);
 CREATE INDEX idx_items_stock ON items(stock);
 CREATE INDEX idx_items_sku ON items(sku);
 CREATE INDEX idx_racks_warehouse_id ON racks(warehouse_id);
+CREATE INDEX idx_item_locations_rack_id ON item_locations(rack_id);
+CREATE INDEX idx_item_lots_expiry_date ON item_lots(expiry_date);
+CREATE INDEX idx_item_serials_item_id_status ON item_serials(item_id, status);
+CREATE INDEX idx_serial_events_serial_id ON serial_events(serial_id);
+CREATE INDEX idx_cost_layers_item_id_open ON cost_layers(item_id, created_at, id) WHERE remaining > 0;
 
 -- Sales & Report
 CREATE INDEX idx_sales_user_id ON sales(user_id);
 CREATE INDEX idx_sales_created_at ON sales(created_at);
+CREATE INDEX idx_sales_customer_id ON sales(customer_id);
 CREATE INDEX idx_sale_items_sale_id ON sale_items(sale_id);
 CREATE INDEX idx_sale_items_item_id ON sale_items(item_id);
+CREATE INDEX idx_sale_items_lot_number ON sale_items(lot_number);
+CREATE INDEX idx_sale_returns_sale_id ON sale_returns(sale_id);
+CREATE INDEX idx_sale_return_items_sale_item_id ON sale_return_items(sale_item_id);
+
+-- Reservations
+CREATE INDEX idx_stock_reservations_status_expires_at ON stock_reservations(status, expires_at);
+CREATE INDEX idx_stock_reservation_items_item_id ON stock_reservation_items(item_id);
+
+-- Transfers
+CREATE INDEX idx_stock_transfers_status ON stock_transfers(status);
+CREATE INDEX idx_stock_transfer_items_transfer_id ON stock_transfer_items(transfer_id);
+
+-- Stocktakes
+CREATE INDEX idx_stocktakes_status ON stocktakes(status);
+CREATE INDEX idx_stocktake_items_item_id ON stocktake_items(item_id);
+
+-- Purchasing
+CREATE INDEX idx_purchase_orders_supplier_id ON purchase_orders(supplier_id);
+CREATE INDEX idx_purchase_orders_status ON purchase_orders(status);
+CREATE INDEX idx_purchase_order_items_order_id ON purchase_order_items(purchase_order_id);
+
+-- Goods Receipts, a delivery note can only be booked once per supplier
+CREATE UNIQUE INDEX uq_goods_receipts_delivery_note ON goods_receipts(supplier_id, delivery_note_number)
+    WHERE status = 'received';
+CREATE INDEX idx_goods_receipts_purchase_order_id ON goods_receipts(purchase_order_id);
+CREATE INDEX idx_goods_receipt_items_receipt_id ON goods_receipt_items(receipt_id);
+
+-- Stock Ledger
+CREATE INDEX idx_stock_movements_item_id_created_at ON stock_movements(item_id, created_at);
+CREATE INDEX idx_stock_movements_reference ON stock_movements(reference_type, reference_id);
@@ -48,33 +48,48 @@ INSERT INTO items (sku, name, category_id, rack_id, stock, minimum_stock, price,
 ('HRD-001', 'Hammer Tool Set', 4, 4, 80, 15, 350000.00, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
 ('CLO-001', 'Work Uniform Shirt', 5, 5, 100, 20, 125000.00, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
 
+-- Place the initial stock of every item on its home rack
+INSERT INTO item_locations (item_id, rack_id, quantity, created_at, updated_at)
+SELECT id, rack_id, stock, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP FROM items WHERE stock > 0;
+
+-- Insert Suppliers (3 suppliers)
+INSERT INTO suppliers (name, contact_name, phone, email, address, created_at, updated_at) VALUES
+('PT Sinar Elektronik', 'Budi Santoso', '021-5550101', 'sales@sinarelektronik.co.id', 'Jl. Mangga Dua No. 10, Jakarta Utara', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+('CV Mebel Jaya', 'Siti Rahma', '021-5550202', 'order@mebeljaya.co.id', 'Jl. Raya Jepara No. 5, Jepara', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+('PT Alat Tulis Nusantara', 'Agus Wijaya', '021-5550303', 'cs@atknusantara.co.id', 'Jl. Gajah Mada No. 21, Jakarta Pusat', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+-- Insert Customers (2 customers, sales without customer are walk-in)
+INSERT INTO customers (name, email, phone, address, created_at, updated_at) VALUES
+('PT Maju Bersama', 'purchasing@majubersama.co.id', '021-7770101', 'Jl. Sudirman No. 1, Jakarta Pusat', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
+('Rina Kartika', 'rina.kartika@mail.com', '0812-3456-7890', 'Jl. Kemang Raya No. 8, Jakarta Selatan', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
 -- Insert Sales (5 sales transactions)
-INSERT INTO sales (user_id, total_amount, created_at, updated_at) VALUES
-(3, 9000000.00, '2025-12-01 10:30:00+07', '2025-12-01 10:30:00+07'),
-(3, 1750000.00, '2025-12-02 14:15:00+07', '2025-12-02 14:15:00+07'),
-(3, 3335000.00, '2025-12-05 09:45:00+07', '2025-12-05 09:45:00+07'),
-(2, 500000.00, '2025-12-10 16:20:00+07', '2025-12-10 16:20:00+07'),
-(2, 1000000.00, '2025-12-15 11:00:00+07', '2025-12-15 11:00:00+07');
+INSERT INTO sales (user_id, customer_id, gross_amount, total_amount, created_at, updated_at) VALUES
+(3, 1, 9000000.00, 9000000.00, '2025-12-01 10:30:00+07', '2025-12-01 10:30:00+07'),
+(3, NULL, 1750000.00, 1750000.00, '2025-12-02 14:15:00+07', '2025-12-02 14:15:00+07'),
+(3, 1, 3335000.00, 3335000.00, '2025-12-05 09:45:00+07', '2025-12-05 09:45:00+07'),
+(2, 2, 500000.00, 500000.00, '2025-12-10 16:20:00+07', '2025-12-10 16:20:00+07'),
+(2, NULL, 1000000.00, 1000000.00, '2025-12-15 11:00:00+07', '2025-12-15 11:00:00+07');
 
 -- Insert Sale Items (detailed items for each sale)
-INSERT INTO sale_items (sale_id, item_id, quantity, price_at_sale, subtotal) VALUES
+INSERT INTO sale_items (sale_id, item_id, quantity, price_at_sale, gross_amount, subtotal) VALUES
 -- Sale 1: Laptop + Mouse
-(1, 1, 1, 8500000.00, 8500000.00),
-(1, 2, 2, 250000.00, 500000.00),
+(1, 1, 1, 8500000.00, 8500000.00, 8500000.00),
+(1, 2, 2, 250000.00, 500000.00, 500000.00),
 
 -- Sale 2: Office Chair + Mouse
-(2, 4, 1, 1500000.00, 1500000.00),
-(2, 2, 1, 250000.00, 250000.00),
+(2, 4, 1, 1500000.00, 1500000.00, 1500000.00),
+(2, 2, 1, 250000.00, 250000.00, 250000.00),
 
 -- Sale 3: Printer + Paper
-(3, 6, 1, 3200000.00, 3200000.00),
-(3, 7, 3, 45000.00, 135000.00),
+(3, 6, 1, 3200000.00, 3200000.00, 3200000.00),
+(3, 7, 3, 45000.00, 135000.00, 135000.00),
 
 -- Sale 4: Mouse
-(4, 2, 2, 250000.00, 500000.00),
+(4, 2, 2, 250000.00, 500000.00, 500000.00),
 
 -- Sale 5: Uniforms
-(5, 10, 8, 125000.00, 1000000.00);
+(5, 10, 8, 125000.00, 1000000.00, 1000000.00);
 
 -- Insert Sessions (3 active sessions for testing - one for each role)
 INSERT INTO sessions (user_id, token, expired_at, revoked_at, created_at) VALUES
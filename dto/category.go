@@ -1,19 +1,24 @@
 package dto
 
+import "project-app-inventory/money"
+
 type CategoryRequest struct {
-	Name        string `json:"name" validate:"required,min=3,max=100"`
-	Description string `json:"description" validate:"omitempty,max=500"`
+	Name        string     `json:"name" validate:"required,min=3,max=100"`
+	Description string     `json:"description" validate:"omitempty,max=500"`
+	TaxRate     money.Rate `json:"tax_rate"` // percent applied to sales of the category's items
 }
 
 type CategoryUpdateRequest struct {
-	Name        string `json:"name" validate:"omitempty,min=3,max=100"`
-	Description string `json:"description" validate:"omitempty,max=500"`
+	Name        string      `json:"name" validate:"omitempty,min=3,max=100"`
+	Description string      `json:"description" validate:"omitempty,max=500"`
+	TaxRate     *money.Rate `json:"tax_rate"` // optional, keeps the current rate when empty
 }
 
 type CategoryResponse struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	TaxRate     money.Rate `json:"tax_rate"`
+	CreatedAt   string     `json:"created_at"`
+	UpdatedAt   string     `json:"updated_at"`
 }
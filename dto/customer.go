@@ -0,0 +1,27 @@
+package dto
+
+import "project-app-inventory/money"
+
+type CustomerRequest struct {
+	Name    string `json:"name" validate:"required,min=3,max=100"`
+	Email   string `json:"email" validate:"omitempty,email,max=100"`
+	Phone   string `json:"phone" validate:"omitempty,max=30"`
+	Address string `json:"address" validate:"omitempty,max=500"`
+}
+
+type CustomerUpdateRequest struct {
+	Name    string `json:"name" validate:"omitempty,min=3,max=100"`
+	Email   string `json:"email" validate:"omitempty,email,max=100"`
+	Phone   string `json:"phone" validate:"omitempty,max=30"`
+	Address string `json:"address" validate:"omitempty,max=500"`
+}
+
+type CustomerSalesResponse struct {
+	CustomerID      int            `json:"customer_id"`
+	CustomerName    string         `json:"customer_name"`
+	TotalSales      int            `json:"total_sales"`
+	LifetimeRevenue money.Amount   `json:"lifetime_revenue"`
+	FirstSaleAt     *string        `json:"first_sale_at,omitempty"`
+	LastSaleAt      *string        `json:"last_sale_at,omitempty"`
+	Sales           []SaleResponse `json:"sales"`
+}
@@ -0,0 +1,31 @@
+package dto
+
+// ForecastParamsRequest holds the forecast params of the query, nil for the
+// ones left to their default
+type ForecastParamsRequest struct {
+	Method      string
+	Days        *int
+	HistoryDays *int
+	Window      *int
+	Alpha       *float64
+}
+
+type ForecastDayResponse struct {
+	Date   string  `json:"date"`
+	Demand float64 `json:"demand"`
+}
+
+type ItemForecastResponse struct {
+	ItemID             int                   `json:"item_id"`
+	SKU                string                `json:"sku"`
+	Name               string                `json:"name"`
+	Available          int                   `json:"available"` // stock not held by reservations
+	Method             string                `json:"method"`
+	HistoryDays        int                   `json:"history_days"`
+	AverageDailyDemand float64               `json:"average_daily_demand"` // before weekday seasonality
+	WeekdayFactors     map[string]float64    `json:"weekday_factors"`      // demand of the weekday against the average day
+	ForecastDays       int                   `json:"forecast_days"`
+	ExpectedDemand     float64               `json:"expected_demand"` // over the forecast days
+	StockOutDate       *string               `json:"stock_out_date"`  // null when the stock lasts beyond a year
+	Days               []ForecastDayResponse `json:"days,omitempty"`
+}
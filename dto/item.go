@@ -1,34 +1,107 @@
 package dto
 
+import "project-app-inventory/money"
+
 type ItemRequest struct {
-	SKU          string  `json:"sku" validate:"required,min=3,max=50"`
-	Name         string  `json:"name" validate:"required,min=3,max=150"`
-	CategoryID   int     `json:"category_id" validate:"required,gt=0"`
-	RackID       int     `json:"rack_id" validate:"required,gt=0"`
-	Stock        int     `json:"stock" validate:"required,gte=0"`
-	MinimumStock int     `json:"minimum_stock" validate:"required,gte=0"`
-	Price        float64 `json:"price" validate:"required,gt=0"`
+	SKU          string       `json:"sku" validate:"required,min=3,max=50"`
+	Name         string       `json:"name" validate:"required,min=3,max=150"`
+	CategoryID   int          `json:"category_id" validate:"required,gt=0"`
+	RackID       int          `json:"rack_id" validate:"required,gt=0"`
+	Stock        int          `json:"stock" validate:"required,gte=0"`
+	MinimumStock int          `json:"minimum_stock" validate:"required,gte=0"`
+	Price        money.Amount `json:"price" validate:"required,gt=0"`
+	TaxRate      *money.Rate  `json:"tax_rate"`                   // optional, overrides the category rate
+	TrackLots    bool         `json:"track_lots"`                 // fixed once the item is created
+	Serialized   bool         `json:"serialized"`                 // fixed once the item is created
+	UnitCost     money.Amount `json:"unit_cost" validate:"gte=0"` // cost per unit of the opening stock, the starting average cost
 }
 
 type ItemUpdateRequest struct {
-	SKU          string  `json:"sku" validate:"omitempty,min=3,max=50"`
-	Name         string  `json:"name" validate:"omitempty,min=3,max=150"`
-	CategoryID   int     `json:"category_id" validate:"omitempty,gt=0"`
-	RackID       int     `json:"rack_id" validate:"omitempty,gt=0"`
-	Stock        int     `json:"stock" validate:"omitempty,gte=0"`
-	MinimumStock int     `json:"minimum_stock" validate:"omitempty,gte=0"`
-	Price        float64 `json:"price" validate:"omitempty,gt=0"`
+	SKU          string       `json:"sku" validate:"omitempty,min=3,max=50"`
+	Name         string       `json:"name" validate:"omitempty,min=3,max=150"`
+	CategoryID   int          `json:"category_id" validate:"omitempty,gt=0"`
+	RackID       int          `json:"rack_id" validate:"omitempty,gt=0"`
+	MinimumStock int          `json:"minimum_stock" validate:"omitempty,gte=0"`
+	Price        money.Amount `json:"price" validate:"omitempty,gt=0"`
+	TaxRate      *money.Rate  `json:"tax_rate"` // optional, keeps the current rate when empty
 }
 
 type ItemResponse struct {
-	ID           int     `json:"id"`
-	SKU          string  `json:"sku"`
-	Name         string  `json:"name"`
-	CategoryID   int     `json:"category_id"`
-	RackID       int     `json:"rack_id"`
-	Stock        int     `json:"stock"`
-	MinimumStock int     `json:"minimum_stock"`
-	Price        float64 `json:"price"`
-	CreatedAt    string  `json:"created_at"`
-	UpdatedAt    string  `json:"updated_at"`
+	ID           int          `json:"id"`
+	SKU          string       `json:"sku"`
+	Name         string       `json:"name"`
+	CategoryID   int          `json:"category_id"`
+	RackID       int          `json:"rack_id"`
+	Stock        int          `json:"stock"`
+	InTransit    int          `json:"in_transit"`
+	MinimumStock int          `json:"minimum_stock"`
+	Price        money.Amount `json:"price"`
+	TaxRate      *money.Rate  `json:"tax_rate"`
+	TrackLots    bool         `json:"track_lots"`
+	Serialized   bool         `json:"serialized"`
+	AverageCost  money.Amount `json:"average_cost"`
+	ABCClass     *string      `json:"abc_class"`
+	Reserved     int          `json:"reserved"`
+	Available    int          `json:"available"`
+	CreatedAt    string       `json:"created_at"`
+	UpdatedAt    string       `json:"updated_at"`
+}
+
+// StockAdjustmentRequest applies a signed delta to an item's stock.
+// Stock can only change through this endpoint or through sales, never via item update.
+type StockAdjustmentRequest struct {
+	RackID        int      `json:"rack_id" validate:"omitempty,gt=0"`                         // defaults to the item's home rack
+	LotNumber     string   `json:"lot_number" validate:"omitempty,max=50"`                    // required for lot tracked items
+	SerialNumbers []string `json:"serial_numbers" validate:"omitempty,dive,required,max=100"` // one per unit for serialized items
+	ReasonCode    string   `json:"reason_code" validate:"required,oneof=damage loss found correction write_off"`
+	Quantity      int      `json:"quantity" validate:"required,ne=0"`
+	Note          string   `json:"note" validate:"omitempty,max=500"`
+}
+
+// ReorderParamsRequest holds the reorder suggestion params of the query, in
+// days, nil for the ones left to their default
+type ReorderParamsRequest struct {
+	SalesDays    *int
+	SafetyDays   *int
+	CoverDays    *int
+	LeadTimeDays *int
+}
+
+// ReorderAcceptRequest sets the minimum stock of the items to their suggested
+// reorder point
+type ReorderAcceptRequest struct {
+	ItemIDs []int `json:"item_ids" validate:"required,min=1,dive,gt=0"`
+}
+
+// ItemImportRow is one CSV row of an item import as read from the file.
+// Category is a category name or id, Rack a rack code.
+type ItemImportRow struct {
+	Line         int // line of the row in the file, the header is line 1
+	SKU          string
+	Name         string
+	Category     string
+	Rack         string
+	Stock        string
+	MinimumStock string
+	Price        string
+}
+
+type ItemImportRowResult struct {
+	Line   int      `json:"line"`
+	SKU    string   `json:"sku"`
+	Status string   `json:"status"`            // created, updated, failed or skipped
+	ItemID *int     `json:"item_id,omitempty"` // not known for new items of a dry run
+	Errors []string `json:"errors,omitempty"`
+}
+
+type ItemImportResponse struct {
+	DryRun   bool                  `json:"dry_run"`
+	Partial  bool                  `json:"partial"`
+	Imported bool                  `json:"imported"` // rows were written, false for a dry run or an import stopped by a failed row
+	Total    int                   `json:"total"`
+	Created  int                   `json:"created"`
+	Updated  int                   `json:"updated"`
+	Failed   int                   `json:"failed"`
+	Skipped  int                   `json:"skipped"`
+	Rows     []ItemImportRowResult `json:"rows"`
 }
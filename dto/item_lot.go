@@ -0,0 +1,16 @@
+package dto
+
+type ItemLotResponse struct {
+	ID           int     `json:"id"`
+	ItemID       int     `json:"item_id"`
+	SKU          string  `json:"sku"`
+	ItemName     string  `json:"item_name"`
+	RackID       int     `json:"rack_id"`
+	RackCode     string  `json:"rack_code"`
+	LotNumber    string  `json:"lot_number"`
+	ExpiryDate   *string `json:"expiry_date"`
+	DaysToExpiry *int    `json:"days_to_expiry"` // negative once expired
+	Quantity     int     `json:"quantity"`
+	CreatedAt    string  `json:"created_at"`
+	UpdatedAt    string  `json:"updated_at"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+type ItemSerialResponse struct {
+	ID           int                   `json:"id"`
+	ItemID       int                   `json:"item_id"`
+	SKU          string                `json:"sku"`
+	ItemName     string                `json:"item_name"`
+	SerialNumber string                `json:"serial_number"`
+	RackID       int                   `json:"rack_id"`
+	Status       string                `json:"status"`
+	Events       []SerialEventResponse `json:"events,omitempty"`
+	CreatedAt    string                `json:"created_at"`
+	UpdatedAt    string                `json:"updated_at"`
+}
+
+type SerialEventResponse struct {
+	ID            int     `json:"id"`
+	EventType     string  `json:"event_type"`
+	RackID        int     `json:"rack_id"`
+	UserID        int     `json:"user_id"`
+	ReferenceType *string `json:"reference_type,omitempty"`
+	ReferenceID   *int    `json:"reference_id,omitempty"`
+	CreatedAt     string  `json:"created_at"`
+}
@@ -0,0 +1,46 @@
+package dto
+
+import "project-app-inventory/money"
+
+type PurchaseOrderItemRequest struct {
+	ItemID   int          `json:"item_id" validate:"required,gt=0"`
+	Quantity int          `json:"quantity" validate:"required,gt=0"`
+	UnitCost money.Amount `json:"unit_cost" validate:"gte=0"`
+}
+
+type PurchaseOrderRequest struct {
+	SupplierID int                        `json:"supplier_id" validate:"required,gt=0"`
+	Note       string                     `json:"note" validate:"omitempty,max=500"`
+	Items      []PurchaseOrderItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// LowStockPurchaseOrderRequest raises a draft PO from GET /items/low-stock,
+// an empty item_ids takes every item currently below its minimum stock
+type LowStockPurchaseOrderRequest struct {
+	SupplierID int    `json:"supplier_id" validate:"required,gt=0"`
+	ItemIDs    []int  `json:"item_ids" validate:"omitempty,dive,gt=0"`
+	Note       string `json:"note" validate:"omitempty,max=500"`
+}
+
+type PurchaseOrderItemResponse struct {
+	ID               int          `json:"id"`
+	ItemID           int          `json:"item_id"`
+	QuantityOrdered  int          `json:"quantity_ordered"`
+	QuantityReceived int          `json:"quantity_received"`
+	UnitCost         money.Amount `json:"unit_cost"`
+	Subtotal         money.Amount `json:"subtotal"`
+}
+
+type PurchaseOrderResponse struct {
+	ID          int                         `json:"id"`
+	SupplierID  int                         `json:"supplier_id"`
+	Status      string                      `json:"status"`
+	Note        string                      `json:"note,omitempty"`
+	TotalAmount money.Amount                `json:"total_amount"`
+	CreatedBy   int                         `json:"created_by"`
+	ApprovedBy  *int                        `json:"approved_by,omitempty"`
+	ApprovedAt  *string                     `json:"approved_at,omitempty"`
+	Items       []PurchaseOrderItemResponse `json:"items,omitempty"`
+	CreatedAt   string                      `json:"created_at"`
+	UpdatedAt   string                      `json:"updated_at"`
+}
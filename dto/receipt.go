@@ -0,0 +1,49 @@
+package dto
+
+import "project-app-inventory/money"
+
+type ReceiptItemRequest struct {
+	ItemID        int          `json:"item_id" validate:"required,gt=0"`
+	RackID        int          `json:"rack_id" validate:"required,gt=0"`
+	Quantity      int          `json:"quantity" validate:"required,gt=0"`
+	UnitCost      money.Amount `json:"unit_cost" validate:"gte=0"`
+	LotNumber     string       `json:"lot_number" validate:"omitempty,max=50"`                    // required for lot tracked items
+	ExpiryDate    string       `json:"expiry_date" validate:"omitempty,datetime=2006-01-02"`      // optional, YYYY-MM-DD
+	SerialNumbers []string     `json:"serial_numbers" validate:"omitempty,dive,required,max=100"` // one per unit for serialized items
+}
+
+type ReceiptRequest struct {
+	SupplierID         int                  `json:"supplier_id" validate:"required,gt=0"`
+	PurchaseOrderID    int                  `json:"purchase_order_id" validate:"omitempty,gt=0"` // optional, receives against the order
+	DeliveryNoteNumber string               `json:"delivery_note_number" validate:"required,max=50"`
+	Note               string               `json:"note" validate:"omitempty,max=500"`
+	Items              []ReceiptItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+type ReceiptItemResponse struct {
+	ID            int          `json:"id"`
+	ItemID        int          `json:"item_id"`
+	RackID        int          `json:"rack_id"`
+	Quantity      int          `json:"quantity"`
+	UnitCost      money.Amount `json:"unit_cost"`
+	Subtotal      money.Amount `json:"subtotal"`
+	LotNumber     *string      `json:"lot_number,omitempty"`
+	ExpiryDate    *string      `json:"expiry_date,omitempty"`
+	SerialNumbers []string     `json:"serial_numbers,omitempty"`
+}
+
+type ReceiptResponse struct {
+	ID                 int                   `json:"id"`
+	SupplierID         int                   `json:"supplier_id"`
+	PurchaseOrderID    *int                  `json:"purchase_order_id,omitempty"`
+	DeliveryNoteNumber string                `json:"delivery_note_number"`
+	Status             string                `json:"status"`
+	Note               string                `json:"note,omitempty"`
+	TotalAmount        money.Amount          `json:"total_amount"`
+	ReceivedBy         int                   `json:"received_by"`
+	VoidedBy           *int                  `json:"voided_by,omitempty"`
+	VoidedAt           *string               `json:"voided_at,omitempty"`
+	Items              []ReceiptItemResponse `json:"items,omitempty"`
+	CreatedAt          string                `json:"created_at"`
+	UpdatedAt          string                `json:"updated_at"`
+}
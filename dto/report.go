@@ -1,11 +1,85 @@
 package dto
 
+import "project-app-inventory/money"
+
 type ReportSummaryResponse struct {
-	TotalItems      int     `json:"total_items"`
-	LowStockItems   int     `json:"low_stock_items"`
-	TotalSales      int     `json:"total_sales"`
-	TotalRevenue    float64 `json:"total_revenue"`
-	ActiveUsers     int     `json:"active_users"`
-	TotalCategories int     `json:"total_categories"`
-	TotalWarehouses int     `json:"total_warehouses"`
+	TotalItems      int          `json:"total_items"`
+	LowStockItems   int          `json:"low_stock_items"`
+	TotalSales      int          `json:"total_sales"`
+	TotalRevenue    money.Amount `json:"total_revenue"` // gross less discounts, before tax, net of returns
+	TotalRefunds    money.Amount `json:"total_refunds"` // paid out, tax included
+	GrossSales      money.Amount `json:"gross_sales"`
+	TotalDiscounts  money.Amount `json:"total_discounts"`
+	TotalTax        money.Amount `json:"total_tax"`
+	ActiveUsers     int          `json:"active_users"`
+	TotalCategories int          `json:"total_categories"`
+	TotalWarehouses int          `json:"total_warehouses"`
+}
+
+type InventoryValuationLineResponse struct {
+	WarehouseID   *int         `json:"warehouse_id"` // null for stock in transit
+	WarehouseName *string      `json:"warehouse_name"`
+	RackID        *int         `json:"rack_id"` // null for stock in transit
+	RackCode      *string      `json:"rack_code"`
+	CategoryID    int          `json:"category_id"`
+	CategoryName  string       `json:"category_name"`
+	Quantity      int          `json:"quantity"`
+	Value         money.Amount `json:"value"`
+}
+
+type InventoryValuationResponse struct {
+	AsOf          *string                          `json:"as_of"` // YYYY-MM-DD, null for the current stock
+	TotalQuantity int                              `json:"total_quantity"`
+	TotalValue    money.Amount                     `json:"total_value"`
+	Lines         []InventoryValuationLineResponse `json:"lines"`
+}
+
+type SalesPeriodResponse struct {
+	Period     string       `json:"period"` // first day of the bucket, YYYY-MM-DD
+	GroupID    *int         `json:"group_id,omitempty"`
+	GroupName  *string      `json:"group_name,omitempty"`
+	Revenue    money.Amount `json:"revenue"` // gross less discounts, before tax, net of returns
+	SalesCount int          `json:"sales_count"`
+	UnitsSold  int          `json:"units_sold"` // net of returned units
+}
+
+type SalesReportResponse struct {
+	From         string                `json:"from"`
+	To           string                `json:"to"`
+	Interval     string                `json:"interval"`
+	GroupBy      string                `json:"group_by,omitempty"`
+	Timezone     string                `json:"timezone"`
+	TotalRevenue money.Amount          `json:"total_revenue"`
+	TotalUnits   int                   `json:"total_units"`
+	Periods      []SalesPeriodResponse `json:"periods"`
+}
+
+// ABCClassSummary is the number of items and the value that fell in one class
+type ABCClassSummary struct {
+	Class     string       `json:"class"`
+	ItemCount int          `json:"item_count"`
+	Value     money.Amount `json:"value"`
+	Share     money.Rate   `json:"share"`
+}
+
+type ABCItemResponse struct {
+	ItemID          int          `json:"item_id"`
+	SKU             string       `json:"sku"`
+	Name            string       `json:"name"`
+	Value           money.Amount `json:"value"`
+	Share           money.Rate   `json:"share"`
+	CumulativeShare money.Rate   `json:"cumulative_share"`
+	Class           string       `json:"class"`
+}
+
+type ABCReportResponse struct {
+	From       string            `json:"from"`
+	To         string            `json:"to"`
+	Basis      string            `json:"basis"`       // revenue or consumption
+	AThreshold money.Rate        `json:"a_threshold"` // cumulative share that closes class A
+	BThreshold money.Rate        `json:"b_threshold"` // and class B
+	TotalValue money.Amount      `json:"total_value"`
+	Applied    bool              `json:"applied"` // classes were stored on the items
+	Classes    []ABCClassSummary `json:"classes"`
+	Items      []ABCItemResponse `json:"items"`
 }
@@ -0,0 +1,37 @@
+package dto
+
+type ReservationItemRequest struct {
+	ItemID   int `json:"item_id" validate:"required,gt=0"`
+	Quantity int `json:"quantity" validate:"required,gt=0"`
+}
+
+type ReservationRequest struct {
+	CustomerID     int                      `json:"customer_id" validate:"omitempty,gt=0"`
+	ExpiresInHours int                      `json:"expires_in_hours" validate:"required,gt=0,lte=720"`
+	Note           string                   `json:"note" validate:"omitempty,max=500"`
+	Items          []ReservationItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// ReservationExtendRequest pushes the expiry of a reservation back by Hours
+type ReservationExtendRequest struct {
+	Hours int `json:"hours" validate:"required,gt=0,lte=720"`
+}
+
+type ReservationItemResponse struct {
+	ID       int `json:"id"`
+	ItemID   int `json:"item_id"`
+	Quantity int `json:"quantity"`
+}
+
+type ReservationResponse struct {
+	ID         int                       `json:"id"`
+	CustomerID *int                      `json:"customer_id,omitempty"`
+	Status     string                    `json:"status"`
+	Note       string                    `json:"note,omitempty"`
+	ExpiresAt  string                    `json:"expires_at"`
+	SaleID     *int                      `json:"sale_id,omitempty"`
+	CreatedBy  int                       `json:"created_by"`
+	Items      []ReservationItemResponse `json:"items,omitempty"`
+	CreatedAt  string                    `json:"created_at"`
+	UpdatedAt  string                    `json:"updated_at"`
+}
@@ -1,30 +1,51 @@
 package dto
 
+import "project-app-inventory/money"
+
 type SaleItemRequest struct {
-	ItemID   int `json:"item_id" validate:"required,gt=0"`
-	Quantity int `json:"quantity" validate:"required,gt=0"`
+	ItemID          int          `json:"item_id" validate:"required,gt=0"`
+	RackID          int          `json:"rack_id" validate:"omitempty,gt=0"` // optional, picked automatically when empty
+	Quantity        int          `json:"quantity" validate:"required,gt=0"`
+	DiscountPercent money.Rate   `json:"discount_percent"` // optional, either percent or amount
+	DiscountAmount  money.Amount `json:"discount_amount"`
+	SerialNumbers   []string     `json:"serial_numbers" validate:"omitempty,dive,required,max=100"` // one per unit for serialized items
 }
 
 type SaleRequest struct {
-	Items []SaleItemRequest `json:"items" validate:"required,min=1,dive"`
+	CustomerID      int               `json:"customer_id" validate:"omitempty,gt=0"`    // optional, empty for walk-in sales
+	ReservationID   int               `json:"reservation_id" validate:"omitempty,gt=0"` // optional, reservation the sale is made from
+	DiscountPercent money.Rate        `json:"discount_percent"`                         // optional sale discount, either percent or amount
+	DiscountAmount  money.Amount      `json:"discount_amount"`
+	Items           []SaleItemRequest `json:"items" validate:"required,min=1,dive"`
 }
 
 type SaleItemResponse struct {
-	ID          int     `json:"id"`
-	ItemID      int     `json:"item_id"`
-	ItemName    string  `json:"item_name,omitempty"`
-	Quantity    int     `json:"quantity"`
-	PriceAtSale float64 `json:"price_at_sale"`
-	Subtotal    float64 `json:"subtotal"`
+	ID             int          `json:"id"`
+	ItemID         int          `json:"item_id"`
+	ItemName       string       `json:"item_name,omitempty"`
+	RackID         int          `json:"rack_id"`
+	Quantity       int          `json:"quantity"`
+	PriceAtSale    money.Amount `json:"price_at_sale"`
+	GrossAmount    money.Amount `json:"gross_amount"`
+	DiscountAmount money.Amount `json:"discount_amount"`
+	TaxRate        money.Rate   `json:"tax_rate"`
+	TaxAmount      money.Amount `json:"tax_amount"`
+	Subtotal       money.Amount `json:"subtotal"`
+	LotNumber      *string      `json:"lot_number,omitempty"`
+	SerialNumbers  []string     `json:"serial_numbers,omitempty"`
 }
 
 type SaleResponse struct {
-	ID          int                `json:"id"`
-	UserID      int                `json:"user_id"`
-	UserName    string             `json:"user_name,omitempty"`
-	TotalAmount float64            `json:"total_amount"`
-	Items       []SaleItemResponse `json:"items,omitempty"`
-	CreatedAt   string             `json:"created_at"`
-	UpdatedAt   string             `json:"updated_at"`
-	DeletedAt   *string            `json:"deleted_at,omitempty"`
+	ID             int                `json:"id"`
+	UserID         int                `json:"user_id"`
+	UserName       string             `json:"user_name,omitempty"`
+	CustomerID     *int               `json:"customer_id,omitempty"`
+	GrossAmount    money.Amount       `json:"gross_amount"`
+	DiscountAmount money.Amount       `json:"discount_amount"`
+	TaxAmount      money.Amount       `json:"tax_amount"`
+	TotalAmount    money.Amount       `json:"total_amount"`
+	Items          []SaleItemResponse `json:"items,omitempty"`
+	CreatedAt      string             `json:"created_at"`
+	UpdatedAt      string             `json:"updated_at"`
+	DeletedAt      *string            `json:"deleted_at,omitempty"`
 }
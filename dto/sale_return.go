@@ -0,0 +1,38 @@
+package dto
+
+import "project-app-inventory/money"
+
+type SaleReturnItemRequest struct {
+	SaleItemID    int      `json:"sale_item_id" validate:"required,gt=0"`
+	Quantity      int      `json:"quantity" validate:"required,gt=0"`
+	Condition     string   `json:"condition" validate:"required,oneof=restock damaged"`
+	RackID        int      `json:"rack_id" validate:"omitempty,gt=0"`                         // optional, restocks into the rack the line was sold from
+	SerialNumbers []string `json:"serial_numbers" validate:"omitempty,dive,required,max=100"` // units returned from a serialized line
+}
+
+type SaleReturnRequest struct {
+	Note  string                  `json:"note" validate:"omitempty,max=500"`
+	Items []SaleReturnItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+type SaleReturnItemResponse struct {
+	ID            int          `json:"id"`
+	SaleItemID    int          `json:"sale_item_id"`
+	ItemID        int          `json:"item_id"`
+	RackID        int          `json:"rack_id"`
+	Quantity      int          `json:"quantity"`
+	Condition     string       `json:"condition"`
+	PriceAtSale   money.Amount `json:"price_at_sale"`
+	RefundAmount  money.Amount `json:"refund_amount"`
+	SerialNumbers []string     `json:"serial_numbers,omitempty"`
+}
+
+type SaleReturnResponse struct {
+	ID           int                      `json:"id"`
+	SaleID       int                      `json:"sale_id"`
+	Note         string                   `json:"note,omitempty"`
+	RefundAmount money.Amount             `json:"refund_amount"`
+	CreatedBy    int                      `json:"created_by"`
+	Items        []SaleReturnItemResponse `json:"items,omitempty"`
+	CreatedAt    string                   `json:"created_at"`
+}
@@ -0,0 +1,56 @@
+package dto
+
+type StocktakeRequest struct {
+	WarehouseID int      `json:"warehouse_id" validate:"omitempty,gt=0"`                     // counts every rack of the warehouse
+	RackIDs     []int    `json:"rack_ids" validate:"required_without=WarehouseID,dive,gt=0"` // or only these racks
+	FreezeSales bool     `json:"freeze_sales"`                                               // counted items can't be sold from the counted racks while open
+	ABCClasses  []string `json:"abc_classes" validate:"omitempty,dive,oneof=A B C"`          // cycle count of these classes only
+	Note        string   `json:"note" validate:"omitempty,max=500"`
+}
+
+type StocktakeCountRequest struct {
+	ItemID          int  `json:"item_id" validate:"required,gt=0"`
+	RackID          int  `json:"rack_id" validate:"required,gt=0"`
+	CountedQuantity *int `json:"counted_quantity" validate:"required,gte=0"`
+}
+
+type StocktakeCountsRequest struct {
+	Counts []StocktakeCountRequest `json:"counts" validate:"required,min=1,dive"`
+}
+
+type StocktakeApproveRequest struct {
+	ExcludeItemIDs []int `json:"exclude_item_ids" validate:"omitempty,dive,gt=0"` // stocktake lines left out of the approval
+}
+
+type StocktakeItemResponse struct {
+	ID               int     `json:"id"`
+	ItemID           int     `json:"item_id"`
+	SKU              string  `json:"sku"`
+	ItemName         string  `json:"item_name"`
+	RackID           int     `json:"rack_id"`
+	ExpectedQuantity int     `json:"expected_quantity"`
+	MovedQuantity    int     `json:"moved_quantity"` // stock moved in the rack between the snapshot and the count
+	CountedQuantity  *int    `json:"counted_quantity"`
+	Variance         *int    `json:"variance"` // counted minus expected plus moved, null until counted
+	CountedBy        *int    `json:"counted_by,omitempty"`
+	CountedAt        *string `json:"counted_at,omitempty"`
+	Excluded         bool    `json:"excluded"`
+}
+
+type StocktakeResponse struct {
+	ID          int                     `json:"id"`
+	WarehouseID *int                    `json:"warehouse_id,omitempty"`
+	RackIDs     []int                   `json:"rack_ids"`
+	Status      string                  `json:"status"`
+	FreezeSales bool                    `json:"freeze_sales"`
+	ABCClasses  []string                `json:"abc_classes,omitempty"`
+	Note        string                  `json:"note,omitempty"`
+	CreatedBy   int                     `json:"created_by"`
+	ApprovedBy  *int                    `json:"approved_by,omitempty"`
+	ApprovedAt  *string                 `json:"approved_at,omitempty"`
+	Counted     int                     `json:"counted"`     // lines counted so far
+	TotalLines  int                     `json:"total_lines"` // lines in the count
+	Items       []StocktakeItemResponse `json:"items,omitempty"`
+	CreatedAt   string                  `json:"created_at"`
+	UpdatedAt   string                  `json:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+type SupplierRequest struct {
+	Name         string `json:"name" validate:"required,min=3,max=100"`
+	ContactName  string `json:"contact_name" validate:"omitempty,max=100"`
+	Phone        string `json:"phone" validate:"omitempty,max=30"`
+	Email        string `json:"email" validate:"omitempty,email,max=100"`
+	Address      string `json:"address" validate:"omitempty,max=500"`
+	LeadTimeDays *int   `json:"lead_time_days" validate:"omitempty,gte=0,lte=365"`
+}
+
+type SupplierUpdateRequest struct {
+	Name         string `json:"name" validate:"omitempty,min=3,max=100"`
+	ContactName  string `json:"contact_name" validate:"omitempty,max=100"`
+	Phone        string `json:"phone" validate:"omitempty,max=30"`
+	Email        string `json:"email" validate:"omitempty,email,max=100"`
+	Address      string `json:"address" validate:"omitempty,max=500"`
+	LeadTimeDays *int   `json:"lead_time_days" validate:"omitempty,gte=0,lte=365"`
+}
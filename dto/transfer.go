@@ -0,0 +1,39 @@
+package dto
+
+type TransferItemRequest struct {
+	ItemID        int      `json:"item_id" validate:"required,gt=0"`
+	Quantity      int      `json:"quantity" validate:"required,gt=0"`
+	SerialNumbers []string `json:"serial_numbers" validate:"omitempty,dive,required,max=100"` // one per unit for serialized items
+}
+
+type TransferRequest struct {
+	SourceRackID      int                   `json:"source_rack_id" validate:"required,gt=0"`
+	DestinationRackID int                   `json:"destination_rack_id" validate:"required,gt=0,nefield=SourceRackID"`
+	Note              string                `json:"note" validate:"omitempty,max=500"`
+	Items             []TransferItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+type TransferItemResponse struct {
+	ID            int      `json:"id"`
+	ItemID        int      `json:"item_id"`
+	ItemName      string   `json:"item_name,omitempty"`
+	Quantity      int      `json:"quantity"`
+	LotNumber     *string  `json:"lot_number,omitempty"`
+	SerialNumbers []string `json:"serial_numbers,omitempty"`
+}
+
+type TransferResponse struct {
+	ID                int                    `json:"id"`
+	SourceRackID      int                    `json:"source_rack_id"`
+	DestinationRackID int                    `json:"destination_rack_id"`
+	Status            string                 `json:"status"`
+	Note              string                 `json:"note,omitempty"`
+	CreatedBy         int                    `json:"created_by"`
+	DispatchedBy      *int                   `json:"dispatched_by,omitempty"`
+	DispatchedAt      *string                `json:"dispatched_at,omitempty"`
+	ReceivedBy        *int                   `json:"received_by,omitempty"`
+	ReceivedAt        *string                `json:"received_at,omitempty"`
+	Items             []TransferItemResponse `json:"items,omitempty"`
+	CreatedAt         string                 `json:"created_at"`
+	UpdatedAt         string                 `json:"updated_at"`
+}
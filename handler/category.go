@@ -47,6 +47,7 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	category := model.Category{
 		Name:        req.Name,
 		Description: description,
+		TaxRate:     &req.TaxRate,
 	}
 
 	// Create category service
@@ -60,6 +61,11 @@ func (h *CategoryHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *CategoryHandler) List(w http.ResponseWriter, r *http.Request) {
+	if utils.WantsCSV(r) {
+		writeCSVPages(w, "categories.csv", h.CategoryService.GetCategoriesAfter, func(category model.Category) int { return category.ID })
+		return
+	}
+
 	page, err := strconv.Atoi(r.URL.Query().Get("page"))
 	if err != nil || page < 1 {
 		page = 1
@@ -127,6 +133,7 @@ func (h *CategoryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	category := model.Category{
 		Name:        req.Name,
 		Description: description,
+		TaxRate:     req.TaxRate,
 	}
 
 	err = h.CategoryService.Update(categoryID, &category)
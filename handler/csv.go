@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"project-app-inventory/utils"
+)
+
+// csvPageSize is how many rows a CSV export reads from the database at a time
+const csvPageSize = 500
+
+// csvAllRows is the limit that reads a report export in one query. Report rows
+// are aggregates ranked by their figures, which no keyset can page through
+// while sales change, so the whole report comes from a single snapshot.
+const csvAllRows = math.MaxInt32
+
+// writeCSVPages streams the rows fetch returns as one CSV file, a page at a
+// time in id order. Each page starts after the id of the last row sent, so rows
+// added or removed during the export never shift a page. A failure on the first
+// page is answered as JSON; once rows are sent the response can only be cut
+// short, so the client sees a broken download instead of a short file.
+func writeCSVPages[T any](w http.ResponseWriter, filename string, fetch func(afterID, limit int) ([]T, error), id func(T) int) {
+	rows, err := fetch(0, csvPageSize)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	csvWriter := utils.NewCSVWriter(w, filename)
+	for {
+		if err := csvWriter.Write(rows); err != nil {
+			panic(http.ErrAbortHandler)
+		}
+		if len(rows) < csvPageSize {
+			return
+		}
+
+		rows, err = fetch(id(rows[len(rows)-1]), csvPageSize)
+		if err != nil {
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// writeCSV sends rows that are already read in full as one CSV file
+func writeCSV[T any](w http.ResponseWriter, filename string, rows []T) {
+	if err := utils.NewCSVWriter(w, filename).Write(rows); err != nil {
+		panic(http.ErrAbortHandler)
+	}
+}
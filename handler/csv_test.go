@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type csvTestRow struct {
+	ID int `json:"id"`
+}
+
+func csvTestRowID(row csvTestRow) int {
+	return row.ID
+}
+
+// csvTestRows returns the rows with ids from..to
+func csvTestRows(from, to int) []csvTestRow {
+	var rows []csvTestRow
+	for id := from; id <= to; id++ {
+		rows = append(rows, csvTestRow{ID: id})
+	}
+	return rows
+}
+
+// TestWriteCSVPages tests every page is fetched after the last id sent and written under one header row
+func TestWriteCSVPages(t *testing.T) {
+	var afterIDs []int
+	fetch := func(afterID, limit int) ([]csvTestRow, error) {
+		afterIDs = append(afterIDs, afterID)
+		require.Equal(t, csvPageSize, limit)
+		if afterID == 0 {
+			return csvTestRows(1, csvPageSize), nil
+		}
+		return csvTestRows(afterID+1, afterID+2), nil
+	}
+
+	recorder := httptest.NewRecorder()
+	writeCSVPages(recorder, "rows.csv", fetch, csvTestRowID)
+
+	require.Equal(t, []int{0, csvPageSize}, afterIDs)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	lines := strings.Split(strings.TrimSuffix(recorder.Body.String(), "\n"), "\n")
+	require.Len(t, lines, csvPageSize+3)
+	require.Equal(t, "id", lines[0])
+	require.Equal(t, strconv.Itoa(csvPageSize+2), lines[len(lines)-1])
+}
+
+// TestWriteCSVPages_RowDeleted tests a row deleted during the export doesn't make the next page skip a row
+func TestWriteCSVPages_RowDeleted(t *testing.T) {
+	table := csvTestRows(1, csvPageSize+1)
+	fetch := func(afterID, limit int) ([]csvTestRow, error) {
+		var page []csvTestRow
+		for _, row := range table {
+			if row.ID > afterID && len(page) < limit {
+				page = append(page, row)
+			}
+		}
+		// Row 1 is deleted once the first page is sent
+		if afterID == 0 {
+			table = table[1:]
+		}
+		return page, nil
+	}
+
+	recorder := httptest.NewRecorder()
+	writeCSVPages(recorder, "rows.csv", fetch, csvTestRowID)
+
+	lines := strings.Split(strings.TrimSuffix(recorder.Body.String(), "\n"), "\n")
+	require.Len(t, lines, csvPageSize+2)
+	require.Equal(t, strconv.Itoa(csvPageSize+1), lines[len(lines)-1])
+}
+
+// TestWriteCSVPages_Empty tests an empty list still gets its header row
+func TestWriteCSVPages_Empty(t *testing.T) {
+	fetch := func(afterID, limit int) ([]csvTestRow, error) {
+		return nil, nil
+	}
+
+	recorder := httptest.NewRecorder()
+	writeCSVPages(recorder, "rows.csv", fetch, csvTestRowID)
+
+	require.Equal(t, "id\n", recorder.Body.String())
+}
+
+// TestWriteCSVPages_FirstPageFails tests a failure before any row is sent is a server error
+func TestWriteCSVPages_FirstPageFails(t *testing.T) {
+	fetch := func(afterID, limit int) ([]csvTestRow, error) {
+		return nil, errors.New("connection lost")
+	}
+
+	recorder := httptest.NewRecorder()
+	writeCSVPages(recorder, "rows.csv", fetch, csvTestRowID)
+
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "connection lost")
+}
+
+// TestWriteCSVPages_LaterPageFails tests a failure after the first page aborts the response
+func TestWriteCSVPages_LaterPageFails(t *testing.T) {
+	fetch := func(afterID, limit int) ([]csvTestRow, error) {
+		if afterID > 0 {
+			return nil, errors.New("connection lost")
+		}
+		return csvTestRows(1, csvPageSize), nil
+	}
+
+	recorder := httptest.NewRecorder()
+	require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		writeCSVPages(recorder, "rows.csv", fetch, csvTestRowID)
+	})
+	require.True(t, strings.HasSuffix(recorder.Body.String(), "\n"+strconv.Itoa(csvPageSize)+"\n"))
+}
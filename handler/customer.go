@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CustomerHandler struct {
+	CustomerService service.CustomerService
+	Config          utils.Configuration
+}
+
+func NewCustomerHandler(customerService service.CustomerService, config utils.Configuration) CustomerHandler {
+	return CustomerHandler{
+		CustomerService: customerService,
+		Config:          config,
+	}
+}
+
+func (h *CustomerHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	customer := model.Customer{
+		Name:    req.Name,
+		Email:   optionalString(req.Email),
+		Phone:   optionalString(req.Phone),
+		Address: optionalString(req.Address),
+	}
+
+	err = h.CustomerService.Create(&customer)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "customer created successfully", customer)
+}
+
+func (h *CustomerHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit := h.Config.Limit
+
+	customers, pagination, err := h.CustomerService.GetAllCustomers(page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusInternalServerError, "failed to fetch customers: "+err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get data", customers, *pagination)
+}
+
+func (h *CustomerHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	customerID, err := strconv.Atoi(chi.URLParam(r, "customer_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid customer id", nil)
+		return
+	}
+
+	customer, err := h.CustomerService.GetCustomerByID(customerID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get customer by id", customer)
+}
+
+func (h *CustomerHandler) ListSales(w http.ResponseWriter, r *http.Request) {
+	customerID, err := strconv.Atoi(chi.URLParam(r, "customer_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid customer id", nil)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit := h.Config.Limit
+
+	customer, summary, sales, pagination, err := h.CustomerService.GetCustomerSales(customerID, page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	response := dto.CustomerSalesResponse{
+		CustomerID:      customer.ID,
+		CustomerName:    customer.Name,
+		TotalSales:      summary.TotalSales,
+		LifetimeRevenue: summary.LifetimeRevenue,
+		Sales:           []dto.SaleResponse{},
+	}
+	if summary.FirstSaleAt != nil {
+		firstSaleAtStr := summary.FirstSaleAt.Format("2006-01-02 15:04:05")
+		response.FirstSaleAt = &firstSaleAtStr
+	}
+	if summary.LastSaleAt != nil {
+		lastSaleAtStr := summary.LastSaleAt.Format("2006-01-02 15:04:05")
+		response.LastSaleAt = &lastSaleAtStr
+	}
+
+	for _, sale := range sales {
+		response.Sales = append(response.Sales, dto.SaleResponse{
+			ID:             sale.ID,
+			UserID:         sale.UserID,
+			CustomerID:     sale.CustomerID,
+			GrossAmount:    sale.GrossAmount,
+			DiscountAmount: sale.DiscountAmount,
+			TaxAmount:      sale.TaxAmount,
+			TotalAmount:    sale.TotalAmount,
+			CreatedAt:      sale.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:      sale.UpdatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get customer sales", response, *pagination)
+}
+
+func (h *CustomerHandler) Update(w http.ResponseWriter, r *http.Request) {
+	customerID, err := strconv.Atoi(chi.URLParam(r, "customer_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid customer id", nil)
+		return
+	}
+
+	var req dto.CustomerUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	customer := model.Customer{
+		Name:    req.Name,
+		Email:   optionalString(req.Email),
+		Phone:   optionalString(req.Phone),
+		Address: optionalString(req.Address),
+	}
+
+	err = h.CustomerService.Update(customerID, &customer)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "customer updated successfully", nil)
+}
+
+func (h *CustomerHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	customerID, err := strconv.Atoi(chi.URLParam(r, "customer_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid customer id", nil)
+		return
+	}
+
+	err = h.CustomerService.Delete(customerID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "customer deleted successfully", nil)
+}
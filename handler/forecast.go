@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ForecastHandler struct {
+	ForecastService service.ForecastService
+	Config          utils.Configuration
+}
+
+func NewForecastHandler(forecastService service.ForecastService, config utils.Configuration) ForecastHandler {
+	return ForecastHandler{
+		ForecastService: forecastService,
+		Config:          config,
+	}
+}
+
+// GetByItem forecasts the demand of an item day by day for the next days (14)
+// and projects when its available stock runs out
+func (h *ForecastHandler) GetByItem(w http.ResponseWriter, r *http.Request) {
+	itemIDstr := chi.URLParam(r, "item_id")
+
+	itemID, err := strconv.Atoi(itemIDstr)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid item id", nil)
+		return
+	}
+
+	params, err := parseForecastParams(r)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	forecast, err := h.ForecastService.ForecastItem(itemID, params)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get item forecast", forecast)
+}
+
+// List forecasts the expected demand and stock-out date of every item
+func (h *ForecastHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	params, err := parseForecastParams(r)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	limit := h.Config.Limit
+
+	forecasts, pagination, err := h.ForecastService.ForecastItems(params, page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get item forecasts", forecasts, *pagination)
+}
+
+// parseForecastParams reads method (moving_average or exponential_smoothing),
+// days, history_days, window and alpha, the service fills in what is left out
+func parseForecastParams(r *http.Request) (dto.ForecastParamsRequest, error) {
+	params := dto.ForecastParamsRequest{Method: r.URL.Query().Get("method")}
+	var err error
+	if params.Days, err = daysParam(r, "days"); err != nil {
+		return params, err
+	}
+	if params.HistoryDays, err = daysParam(r, "history_days"); err != nil {
+		return params, err
+	}
+	if params.Window, err = daysParam(r, "window"); err != nil {
+		return params, err
+	}
+	if alphaStr := r.URL.Query().Get("alpha"); alphaStr != "" {
+		alpha, err := strconv.ParseFloat(alphaStr, 64)
+		if err != nil {
+			return params, errors.New("invalid alpha")
+		}
+		params.Alpha = &alpha
+	}
+	return params, nil
+}
@@ -1,34 +1,68 @@
 package handler
 
 import (
+	"net/http"
+	"project-app-inventory/model"
 	"project-app-inventory/service"
 	"project-app-inventory/utils"
 )
 
 type Handler struct {
-	HandlerAuth       AuthHandler
-	HandlerMenu       MenuHandler
-	AssignmentHandler AssignmentHandler
-	ItemHandler       ItemHandler
-	CategoryHandler   CategoryHandler
-	RackHandler       RackHandler
-	WarehouseHandler  WarehouseHandler
-	SaleHandler       SaleHandler
-	UserHandler       UserHandler
-	ReportHandler     ReportHandler
+	HandlerAuth          AuthHandler
+	HandlerMenu          MenuHandler
+	AssignmentHandler    AssignmentHandler
+	ItemHandler          ItemHandler
+	CategoryHandler      CategoryHandler
+	RackHandler          RackHandler
+	WarehouseHandler     WarehouseHandler
+	SaleHandler          SaleHandler
+	UserHandler          UserHandler
+	ReportHandler        ReportHandler
+	StockMovementHandler StockMovementHandler
+	ItemLocationHandler  ItemLocationHandler
+	ItemLotHandler       ItemLotHandler
+	ItemSerialHandler    ItemSerialHandler
+	TransferHandler      TransferHandler
+	SupplierHandler      SupplierHandler
+	PurchaseOrderHandler PurchaseOrderHandler
+	ReceiptHandler       ReceiptHandler
+	CustomerHandler      CustomerHandler
+	SaleReturnHandler    SaleReturnHandler
+	ReservationHandler   ReservationHandler
+	StocktakeHandler     StocktakeHandler
+	ForecastHandler      ForecastHandler
 }
 
 func NewHandler(service service.Service, config utils.Configuration) Handler {
 	return Handler{
 		HandlerAuth: NewAuthHandler(service),
 		// HandlerMenu:       NewMenuHandler(),
-		AssignmentHandler: NewAssignmentHandler(service.AssignmentService, config),
-		ItemHandler:       NewItemHandler(service.ItemService, config),
-		CategoryHandler:   NewCategoryHandler(service.CategoryService, config),
-		RackHandler:       NewRackHandler(service.RackService, config),
-		WarehouseHandler:  NewWarehouseHandler(service.WarehouseService, config),
-		SaleHandler:       NewSaleHandler(service.SaleService, config),
-		UserHandler:       NewUserHandler(service.UserService, config),
-		ReportHandler:     *NewReportHandler(service.ReportService),
+		AssignmentHandler:    NewAssignmentHandler(service.AssignmentService, config),
+		ItemHandler:          NewItemHandler(service.ItemService, config),
+		CategoryHandler:      NewCategoryHandler(service.CategoryService, config),
+		RackHandler:          NewRackHandler(service.RackService, config),
+		WarehouseHandler:     NewWarehouseHandler(service.WarehouseService, config),
+		SaleHandler:          NewSaleHandler(service.SaleService, config),
+		UserHandler:          NewUserHandler(service.UserService, config),
+		ReportHandler:        *NewReportHandler(service.ReportService, config),
+		StockMovementHandler: NewStockMovementHandler(service.StockMovementService, config),
+		ItemLocationHandler:  NewItemLocationHandler(service.ItemLocationService, config),
+		ItemLotHandler:       NewItemLotHandler(service.ItemLotService, config),
+		ItemSerialHandler:    NewItemSerialHandler(service.ItemSerialService, config),
+		TransferHandler:      NewTransferHandler(service.TransferService, config),
+		SupplierHandler:      NewSupplierHandler(service.SupplierService, config),
+		PurchaseOrderHandler: NewPurchaseOrderHandler(service.PurchaseOrderService, config),
+		ReceiptHandler:       NewReceiptHandler(service.ReceiptService, config),
+		CustomerHandler:      NewCustomerHandler(service.CustomerService, config),
+		SaleReturnHandler:    NewSaleReturnHandler(service.SaleReturnService, config),
+		ReservationHandler:   NewReservationHandler(service.ReservationService, config),
+		StocktakeHandler:     NewStocktakeHandler(service.StocktakeService, config),
+		ForecastHandler:      NewForecastHandler(service.ForecastService, config),
 	}
 }
+
+// currentUser returns the authenticated user stored in the request context by AuthMiddleware
+func currentUser(r *http.Request) (*model.User, bool) {
+	user, ok := r.Context().Value("user").(*model.User)
+	return user, ok && user != nil
+}
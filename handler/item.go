@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"project-app-inventory/dto"
 	"project-app-inventory/model"
@@ -47,10 +48,20 @@ func (h *ItemHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Stock:        req.Stock,
 		MinimumStock: req.MinimumStock,
 		Price:        req.Price,
+		TaxRate:      req.TaxRate,
+		TrackLots:    req.TrackLots,
+		Serialized:   req.Serialized,
+		AverageCost:  req.UnitCost,
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
 	}
 
 	// Create item service
-	err = h.ItemService.Create(&item)
+	err = h.ItemService.Create(&item, user.ID)
 	if err != nil {
 		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
 		return
@@ -60,6 +71,11 @@ func (h *ItemHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *ItemHandler) List(w http.ResponseWriter, r *http.Request) {
+	if utils.WantsCSV(r) {
+		writeCSVPages(w, "items.csv", h.ItemService.GetItemsAfter, func(item model.Item) int { return item.ID })
+		return
+	}
+
 	page, err := strconv.Atoi(r.URL.Query().Get("page"))
 	if err != nil || page < 1 {
 		page = 1
@@ -86,8 +102,19 @@ func (h *ItemHandler) GetLowStock(w http.ResponseWriter, r *http.Request) {
 
 	limit := h.Config.Limit
 
+	// basis=available leaves out stock held by reservations
+	var useAvailable bool
+	switch r.URL.Query().Get("basis") {
+	case "", "stock":
+	case "available":
+		useAvailable = true
+	default:
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "basis must be stock or available", nil)
+		return
+	}
+
 	// Get low stock items from service
-	items, pagination, err := h.ItemService.GetLowStockItems(page, limit)
+	items, pagination, err := h.ItemService.GetLowStockItems(page, limit, useAvailable)
 	if err != nil {
 		utils.ResponseBadRequest(w, http.StatusInternalServerError, "failed to fetch low stock items: "+err.Error(), nil)
 		return
@@ -96,6 +123,95 @@ func (h *ItemHandler) GetLowStock(w http.ResponseWriter, r *http.Request) {
 	utils.ResponsePagination(w, http.StatusOK, "success get low stock items", items, *pagination)
 }
 
+// GetReorderSuggestions lists suggested reorder points and order quantities
+// from the average daily sales over the last days (90), the supplier lead time
+// (lead_time_days, 7, for items without one), safety_days (7) of safety stock
+// and cover_days (30) of sales per order
+func (h *ItemHandler) GetReorderSuggestions(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	params, err := parseReorderParams(r)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	limit := h.Config.Limit
+
+	suggestions, pagination, err := h.ItemService.GetReorderSuggestions(params, page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get reorder suggestions", suggestions, *pagination)
+}
+
+// AcceptReorderSuggestions sets the minimum stock of item_ids to their reorder
+// point, suggested with the same parameters as GetReorderSuggestions
+func (h *ItemHandler) AcceptReorderSuggestions(w http.ResponseWriter, r *http.Request) {
+	params, err := parseReorderParams(r)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	var req dto.ReorderAcceptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	accepted, err := h.ItemService.AcceptReorderSuggestions(params, req.ItemIDs)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "minimum stock updated from reorder suggestions", accepted)
+}
+
+func parseReorderParams(r *http.Request) (dto.ReorderParamsRequest, error) {
+	var params dto.ReorderParamsRequest
+	var err error
+	if params.SalesDays, err = daysParam(r, "days"); err != nil {
+		return params, err
+	}
+	if params.SafetyDays, err = daysParam(r, "safety_days"); err != nil {
+		return params, err
+	}
+	if params.CoverDays, err = daysParam(r, "cover_days"); err != nil {
+		return params, err
+	}
+	if params.LeadTimeDays, err = daysParam(r, "lead_time_days"); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+// daysParam reads a number of days, nil when the param is not given
+func daysParam(r *http.Request, param string) (*int, error) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return nil, nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", param)
+	}
+	return &days, nil
+}
+
 func (h *ItemHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	itemIDstr := chi.URLParam(r, "item_id")
 
@@ -142,9 +258,9 @@ func (h *ItemHandler) Update(w http.ResponseWriter, r *http.Request) {
 		Name:         req.Name,
 		CategoryID:   req.CategoryID,
 		RackID:       req.RackID,
-		Stock:        req.Stock,
 		MinimumStock: req.MinimumStock,
 		Price:        req.Price,
+		TaxRate:      req.TaxRate,
 	}
 
 	err = h.ItemService.Update(itemID, &item)
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/utils"
+	"strconv"
+	"strings"
+)
+
+// Limits of an item import file
+const (
+	itemImportMaxBytes = 10 << 20
+	itemImportMaxRows  = 5000
+)
+
+// itemImportColumns maps the accepted header names to the column they fill
+var itemImportColumns = map[string]string{
+	"sku":           "sku",
+	"name":          "name",
+	"category":      "category",
+	"category_id":   "category",
+	"category_name": "category",
+	"rack":          "rack",
+	"rack_code":     "rack",
+	"stock":         "stock",
+	"minimum_stock": "minimum_stock",
+	"price":         "price",
+}
+
+// Import creates and updates items from a CSV file, sent as the request body or
+// as the file field of a multipart form. The header row names the columns:
+// sku, name, category (name or id), rack (code), stock, minimum_stock and
+// price. warehouse_id picks the warehouse the rack codes are in, dry_run=true
+// only reports what would happen and partial=true keeps the rows that succeed
+// when others fail.
+func (h *ItemHandler) Import(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var warehouseID int
+	if warehouseIDStr := query.Get("warehouse_id"); warehouseIDStr != "" {
+		var err error
+		warehouseID, err = strconv.Atoi(warehouseIDStr)
+		if err != nil {
+			utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid warehouse_id", nil)
+			return
+		}
+	}
+
+	dryRun, err := boolParam(r, "dry_run")
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	partial, err := boolParam(r, "partial")
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	rows, err := readItemImport(w, r)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	report, err := h.ItemService.ImportItems(rows, warehouseID, user.ID, partial, dryRun)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusInternalServerError, "failed to import items: "+err.Error(), nil)
+		return
+	}
+
+	switch {
+	case report.Failed > 0 && !partial:
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "import has failed rows, no item was changed", report)
+	case dryRun:
+		utils.ResponseSuccess(w, http.StatusOK, "dry run, no item was changed", report)
+	default:
+		utils.ResponseSuccess(w, http.StatusOK, "items imported", report)
+	}
+}
+
+// readItemImport reads the rows of the uploaded CSV file
+func readItemImport(w http.ResponseWriter, r *http.Request) ([]dto.ItemImportRow, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, itemImportMaxBytes)
+
+	var file io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		upload, _, err := r.FormFile("file")
+		if err != nil {
+			return nil, errors.New("file is required")
+		}
+		defer upload.Close()
+		file = upload
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		column, ok := itemImportColumns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %s", name)
+		}
+		if _, ok := columns[column]; ok {
+			return nil, fmt.Errorf("column %s is given twice", column)
+		}
+		columns[column] = i
+	}
+	for _, column := range []string{"sku", "name", "category", "rack", "stock", "minimum_stock", "price"} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("column %s is required", column)
+		}
+	}
+
+	var rows []dto.ItemImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		field := func(column string) string {
+			if i := columns[column]; i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+
+		// Blank lines at the end of a spreadsheet export are not rows
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		if len(rows) == itemImportMaxRows {
+			return nil, fmt.Errorf("file has more than %d rows", itemImportMaxRows)
+		}
+		rows = append(rows, dto.ItemImportRow{
+			Line:         line,
+			SKU:          field("sku"),
+			Name:         field("name"),
+			Category:     field("category"),
+			Rack:         field("rack"),
+			Stock:        field("stock"),
+			MinimumStock: field("minimum_stock"),
+			Price:        field("price"),
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("file has no rows")
+	}
+	return rows, nil
+}
+
+// boolParam reads a true or false param, false when it is not given
+func boolParam(r *http.Request, param string) (bool, error) {
+	value := r.URL.Query().Get(param)
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", param)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"net/http"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ItemLocationHandler struct {
+	ItemLocationService service.ItemLocationService
+	Config              utils.Configuration
+}
+
+func NewItemLocationHandler(itemLocationService service.ItemLocationService, config utils.Configuration) ItemLocationHandler {
+	return ItemLocationHandler{
+		ItemLocationService: itemLocationService,
+		Config:              config,
+	}
+}
+
+func (h *ItemLocationHandler) ListByItem(w http.ResponseWriter, r *http.Request) {
+	itemIDstr := chi.URLParam(r, "item_id")
+
+	itemID, err := strconv.Atoi(itemIDstr)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid item id", nil)
+		return
+	}
+
+	locations, err := h.ItemLocationService.GetItemLocations(itemID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get item locations", locations)
+}
+
+func (h *ItemLocationHandler) ListByRack(w http.ResponseWriter, r *http.Request) {
+	rackIDstr := chi.URLParam(r, "rack_id")
+
+	rackID, err := strconv.Atoi(rackIDstr)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid rack id", nil)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit := h.Config.Limit
+
+	locations, pagination, err := h.ItemLocationService.GetRackStock(rackID, page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get rack stock", locations, *pagination)
+}
+
+func (h *ItemLocationHandler) ListByWarehouse(w http.ResponseWriter, r *http.Request) {
+	warehouseIDstr := chi.URLParam(r, "warehouse_id")
+
+	warehouseID, err := strconv.Atoi(warehouseIDstr)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid warehouse id", nil)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit := h.Config.Limit
+
+	stocks, pagination, err := h.ItemLocationService.GetWarehouseStock(warehouseID, page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get warehouse stock", stocks, *pagination)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ItemLotHandler struct {
+	ItemLotService service.ItemLotService
+	Config         utils.Configuration
+}
+
+func NewItemLotHandler(itemLotService service.ItemLotService, config utils.Configuration) ItemLotHandler {
+	return ItemLotHandler{
+		ItemLotService: itemLotService,
+		Config:         config,
+	}
+}
+
+func (h *ItemLotHandler) ListByItem(w http.ResponseWriter, r *http.Request) {
+	itemIDstr := chi.URLParam(r, "item_id")
+
+	itemID, err := strconv.Atoi(itemIDstr)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid item id", nil)
+		return
+	}
+
+	lots, err := h.ItemLotService.GetItemLots(itemID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get item lots", toItemLotResponses(*lots))
+}
+
+// ListExpiring lists lots expiring within ?within=30d, the default is 30 days
+func (h *ItemLotHandler) ListExpiring(w http.ResponseWriter, r *http.Request) {
+	withinDays := 30
+	if within := r.URL.Query().Get("within"); within != "" {
+		days, err := strconv.Atoi(strings.TrimSuffix(within, "d"))
+		if err != nil || days < 0 {
+			utils.ResponseBadRequest(w, http.StatusBadRequest, "within must be a number of days such as 30d", nil)
+			return
+		}
+		withinDays = days
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit := h.Config.Limit
+
+	lots, pagination, err := h.ItemLotService.GetExpiringLots(withinDays, page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get expiring lots", toItemLotResponses(*lots), *pagination)
+}
+
+func toItemLotResponses(lots []model.ItemLot) []dto.ItemLotResponse {
+	responses := []dto.ItemLotResponse{}
+	for _, lot := range lots {
+		response := dto.ItemLotResponse{
+			ID:           lot.ID,
+			ItemID:       lot.ItemID,
+			SKU:          lot.SKU,
+			ItemName:     lot.ItemName,
+			RackID:       lot.RackID,
+			RackCode:     lot.RackCode,
+			LotNumber:    lot.LotNumber,
+			DaysToExpiry: lot.DaysToExpiry,
+			Quantity:     lot.Quantity,
+			CreatedAt:    lot.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt:    lot.UpdatedAt.Format("2006-01-02 15:04:05"),
+		}
+		if lot.ExpiryDate != nil {
+			expiryDateStr := lot.ExpiryDate.Format("2006-01-02")
+			response.ExpiryDate = &expiryDateStr
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ItemSerialHandler struct {
+	ItemSerialService service.ItemSerialService
+	Config            utils.Configuration
+}
+
+func NewItemSerialHandler(itemSerialService service.ItemSerialService, config utils.Configuration) ItemSerialHandler {
+	return ItemSerialHandler{
+		ItemSerialService: itemSerialService,
+		Config:            config,
+	}
+}
+
+// GetBySerial returns a serial with its lifecycle, oldest event first
+func (h *ItemSerialHandler) GetBySerial(w http.ResponseWriter, r *http.Request) {
+	serialNumber := chi.URLParam(r, "serial")
+
+	serial, events, err := h.ItemSerialService.GetSerial(serialNumber)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	response := toItemSerialResponse(*serial)
+	for _, event := range events {
+		response.Events = append(response.Events, dto.SerialEventResponse{
+			ID:            event.ID,
+			EventType:     event.EventType,
+			RackID:        event.RackID,
+			UserID:        event.UserID,
+			ReferenceType: event.ReferenceType,
+			ReferenceID:   event.ReferenceID,
+			CreatedAt:     event.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get serial number", response)
+}
+
+// ListByItem lists the serials of an item, ?status= narrows them down
+func (h *ItemSerialHandler) ListByItem(w http.ResponseWriter, r *http.Request) {
+	itemIDstr := chi.URLParam(r, "item_id")
+
+	itemID, err := strconv.Atoi(itemIDstr)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid item id", nil)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit := h.Config.Limit
+
+	serials, pagination, err := h.ItemSerialService.GetItemSerials(itemID, r.URL.Query().Get("status"), page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	responses := []dto.ItemSerialResponse{}
+	for _, serial := range *serials {
+		responses = append(responses, toItemSerialResponse(serial))
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get item serials", responses, *pagination)
+}
+
+func toItemSerialResponse(serial model.ItemSerial) dto.ItemSerialResponse {
+	return dto.ItemSerialResponse{
+		ID:           serial.ID,
+		ItemID:       serial.ItemID,
+		SKU:          serial.SKU,
+		ItemName:     serial.ItemName,
+		SerialNumber: serial.SerialNumber,
+		RackID:       serial.RackID,
+		Status:       serial.Status,
+		CreatedAt:    serial.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:    serial.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
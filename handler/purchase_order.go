@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type PurchaseOrderHandler struct {
+	PurchaseOrderService service.PurchaseOrderService
+	Config               utils.Configuration
+}
+
+func NewPurchaseOrderHandler(purchaseOrderService service.PurchaseOrderService, config utils.Configuration) PurchaseOrderHandler {
+	return PurchaseOrderHandler{
+		PurchaseOrderService: purchaseOrderService,
+		Config:               config,
+	}
+}
+
+func (h *PurchaseOrderHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.PurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	order, items, err := h.PurchaseOrderService.Create(user.ID, req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "purchase order created successfully", toPurchaseOrderResponse(order, items))
+}
+
+func (h *PurchaseOrderHandler) CreateFromLowStock(w http.ResponseWriter, r *http.Request) {
+	var req dto.LowStockPurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	order, items, err := h.PurchaseOrderService.CreateFromLowStock(user.ID, req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "purchase order created successfully", toPurchaseOrderResponse(order, items))
+}
+
+func (h *PurchaseOrderHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit := h.Config.Limit
+	status := r.URL.Query().Get("status")
+
+	supplierID := 0
+	if value := r.URL.Query().Get("supplier_id"); value != "" {
+		supplierID, err = strconv.Atoi(value)
+		if err != nil || supplierID < 1 {
+			utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid supplier id", nil)
+			return
+		}
+	}
+
+	orders, pagination, err := h.PurchaseOrderService.GetAllPurchaseOrders(status, supplierID, page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "failed to fetch purchase orders: "+err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get data", orders, *pagination)
+}
+
+func (h *PurchaseOrderHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.Atoi(chi.URLParam(r, "purchase_order_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid purchase order id", nil)
+		return
+	}
+
+	order, items, err := h.PurchaseOrderService.GetPurchaseOrderByID(orderID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get purchase order by id", toPurchaseOrderResponse(order, items))
+}
+
+func (h *PurchaseOrderHandler) Update(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.Atoi(chi.URLParam(r, "purchase_order_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid purchase order id", nil)
+		return
+	}
+
+	var req dto.PurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	order, items, err := h.PurchaseOrderService.Update(orderID, req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "purchase order updated successfully", toPurchaseOrderResponse(order, items))
+}
+
+func (h *PurchaseOrderHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.Atoi(chi.URLParam(r, "purchase_order_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid purchase order id", nil)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	err = h.PurchaseOrderService.Approve(orderID, user.ID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "purchase order approved successfully", nil)
+}
+
+func (h *PurchaseOrderHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	orderID, err := strconv.Atoi(chi.URLParam(r, "purchase_order_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid purchase order id", nil)
+		return
+	}
+
+	err = h.PurchaseOrderService.Cancel(orderID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "purchase order cancelled successfully", nil)
+}
+
+func toPurchaseOrderResponse(order *model.PurchaseOrder, items []model.PurchaseOrderItem) dto.PurchaseOrderResponse {
+	response := dto.PurchaseOrderResponse{
+		ID:          order.ID,
+		SupplierID:  order.SupplierID,
+		Status:      order.Status,
+		TotalAmount: order.TotalAmount,
+		CreatedBy:   order.CreatedBy,
+		ApprovedBy:  order.ApprovedBy,
+		CreatedAt:   order.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   order.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if order.Note != nil {
+		response.Note = *order.Note
+	}
+	if order.ApprovedAt != nil {
+		approvedAtStr := order.ApprovedAt.Format("2006-01-02 15:04:05")
+		response.ApprovedAt = &approvedAtStr
+	}
+
+	for _, item := range items {
+		response.Items = append(response.Items, dto.PurchaseOrderItemResponse{
+			ID:               item.ID,
+			ItemID:           item.ItemID,
+			QuantityOrdered:  item.QuantityOrdered,
+			QuantityReceived: item.QuantityReceived,
+			UnitCost:         item.UnitCost,
+			Subtotal:         item.Subtotal,
+		})
+	}
+
+	return response
+}
@@ -80,6 +80,13 @@ func (h *RackHandler) List(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if utils.WantsCSV(r) {
+			writeCSVPages(w, "racks.csv", func(afterID, limit int) ([]model.Rack, error) {
+				return h.RackService.GetRacksAfter(warehouseID, afterID, limit)
+			}, func(rack model.Rack) int { return rack.ID })
+			return
+		}
+
 		racks, pagination, err := h.RackService.GetRacksByWarehouse(warehouseID, page, limit)
 		if err != nil {
 			utils.ResponseBadRequest(w, http.StatusInternalServerError, "failed to fetch racks: "+err.Error(), nil)
@@ -90,6 +97,13 @@ func (h *RackHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if utils.WantsCSV(r) {
+		writeCSVPages(w, "racks.csv", func(afterID, limit int) ([]model.Rack, error) {
+			return h.RackService.GetRacksAfter(0, afterID, limit)
+		}, func(rack model.Rack) int { return rack.ID })
+		return
+	}
+
 	// Get all racks
 	racks, pagination, err := h.RackService.GetAllRacks(page, limit)
 	if err != nil {
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ReceiptHandler struct {
+	ReceiptService service.ReceiptService
+	Config         utils.Configuration
+}
+
+func NewReceiptHandler(receiptService service.ReceiptService, config utils.Configuration) ReceiptHandler {
+	return ReceiptHandler{
+		ReceiptService: receiptService,
+		Config:         config,
+	}
+}
+
+func (h *ReceiptHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.ReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	receipt, items, err := h.ReceiptService.Create(user.ID, req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "receipt created successfully", toReceiptResponse(receipt, items))
+}
+
+func (h *ReceiptHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit := h.Config.Limit
+	status := r.URL.Query().Get("status")
+
+	supplierID := 0
+	if value := r.URL.Query().Get("supplier_id"); value != "" {
+		supplierID, err = strconv.Atoi(value)
+		if err != nil || supplierID < 1 {
+			utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid supplier id", nil)
+			return
+		}
+	}
+
+	receipts, pagination, err := h.ReceiptService.GetAllReceipts(status, supplierID, page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "failed to fetch receipts: "+err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get data", receipts, *pagination)
+}
+
+func (h *ReceiptHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	receiptID, err := strconv.Atoi(chi.URLParam(r, "receipt_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid receipt id", nil)
+		return
+	}
+
+	receipt, items, err := h.ReceiptService.GetReceiptByID(receiptID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get receipt by id", toReceiptResponse(receipt, items))
+}
+
+func (h *ReceiptHandler) Void(w http.ResponseWriter, r *http.Request) {
+	receiptID, err := strconv.Atoi(chi.URLParam(r, "receipt_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid receipt id", nil)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	err = h.ReceiptService.Void(receiptID, user.ID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "receipt voided successfully", nil)
+}
+
+func toReceiptResponse(receipt *model.Receipt, items []model.ReceiptItem) dto.ReceiptResponse {
+	response := dto.ReceiptResponse{
+		ID:                 receipt.ID,
+		SupplierID:         receipt.SupplierID,
+		PurchaseOrderID:    receipt.PurchaseOrderID,
+		DeliveryNoteNumber: receipt.DeliveryNoteNumber,
+		Status:             receipt.Status,
+		TotalAmount:        receipt.TotalAmount,
+		ReceivedBy:         receipt.ReceivedBy,
+		VoidedBy:           receipt.VoidedBy,
+		CreatedAt:          receipt.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:          receipt.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if receipt.Note != nil {
+		response.Note = *receipt.Note
+	}
+	if receipt.VoidedAt != nil {
+		voidedAtStr := receipt.VoidedAt.Format("2006-01-02 15:04:05")
+		response.VoidedAt = &voidedAtStr
+	}
+
+	for _, item := range items {
+		line := dto.ReceiptItemResponse{
+			ID:            item.ID,
+			ItemID:        item.ItemID,
+			RackID:        item.RackID,
+			Quantity:      item.Quantity,
+			UnitCost:      item.UnitCost,
+			Subtotal:      item.Subtotal,
+			LotNumber:     item.LotNumber,
+			SerialNumbers: item.SerialNumbers,
+		}
+		if item.ExpiryDate != nil {
+			expiryDateStr := item.ExpiryDate.Format("2006-01-02")
+			line.ExpiryDate = &expiryDateStr
+		}
+		response.Items = append(response.Items, line)
+	}
+
+	return response
+}
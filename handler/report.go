@@ -1,17 +1,24 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
 	"project-app-inventory/service"
 	"project-app-inventory/utils"
+	"strconv"
+	"time"
 )
 
 type ReportHandler struct {
 	ReportService service.ReportService
+	Config        utils.Configuration
 }
 
-func NewReportHandler(reportService service.ReportService) *ReportHandler {
-	return &ReportHandler{ReportService: reportService}
+func NewReportHandler(reportService service.ReportService, config utils.Configuration) *ReportHandler {
+	return &ReportHandler{ReportService: reportService, Config: config}
 }
 
 func (h *ReportHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
@@ -21,5 +28,348 @@ func (h *ReportHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if utils.WantsCSV(r) {
+		writeCSV(w, "report-summary.csv", []dto.ReportSummaryResponse{*report})
+		return
+	}
+	if utils.WantsXLSX(r) {
+		utils.ResponseXLSX(w, "report-summary.xlsx", summaryXLSX(report))
+		return
+	}
+
 	utils.ResponseSuccess(w, http.StatusOK, "success get report summary", report)
 }
+
+// GetGrossMargin reports revenue against cost of goods sold, group_by is
+// sale (default), item or category
+func (h *ReportHandler) GetGrossMargin(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	groupBy := r.URL.Query().Get("group_by")
+	if utils.WantsCSV(r) {
+		margins, _, err := h.ReportService.GetGrossMargin(groupBy, from, to, 1, csvAllRows)
+		if err != nil {
+			utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		writeCSV(w, "gross-margin.csv", margins)
+		return
+	}
+
+	limit := h.Config.Limit
+
+	margins, pagination, err := h.ReportService.GetGrossMargin(groupBy, from, to, page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get gross margin report", margins, *pagination)
+}
+
+// GetInventoryValuation values the stock per warehouse, rack and category, at
+// the end of the optional as_of day (YYYY-MM-DD)
+func (h *ReportHandler) GetInventoryValuation(w http.ResponseWriter, r *http.Request) {
+	var asOf *time.Time
+	if asOfStr := r.URL.Query().Get("as_of"); asOfStr != "" {
+		parsed, err := time.Parse("2006-01-02", asOfStr)
+		if err != nil {
+			utils.ResponseBadRequest(w, http.StatusBadRequest, errInvalidDate("as_of").Error(), nil)
+			return
+		}
+		asOf = &parsed
+	}
+
+	report, err := h.ReportService.GetInventoryValuation(asOf)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if utils.WantsCSV(r) {
+		writeCSV(w, "inventory-valuation.csv", report.Lines)
+		return
+	}
+	if utils.WantsXLSX(r) {
+		utils.ResponseXLSX(w, "inventory-valuation.xlsx", inventoryValuationXLSX(report))
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get inventory valuation", report)
+}
+
+// GetSalesByPeriod reports sales per day, week or month (interval) between the
+// required from and to dates (YYYY-MM-DD), optionally per category, item,
+// warehouse or cashier (group_by). Days are taken in the tz timezone, UTC by
+// default.
+func (h *ReportHandler) GetSalesByPeriod(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	location := time.UTC
+	if tz := query.Get("tz"); tz != "" {
+		loaded, err := time.LoadLocation(tz)
+		if err != nil {
+			utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid tz, expected an IANA timezone such as Asia/Jakarta", nil)
+			return
+		}
+		location = loaded
+	}
+
+	if query.Get("from") == "" || query.Get("to") == "" {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "from and to dates are required", nil)
+		return
+	}
+	from, err := time.ParseInLocation("2006-01-02", query.Get("from"), location)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, errInvalidDate("from").Error(), nil)
+		return
+	}
+	to, err := time.ParseInLocation("2006-01-02", query.Get("to"), location)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, errInvalidDate("to").Error(), nil)
+		return
+	}
+
+	report, err := h.ReportService.GetSalesByPeriod(query.Get("interval"), query.Get("group_by"), location, from, to)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if utils.WantsCSV(r) {
+		writeCSV(w, "sales-report.csv", report.Periods)
+		return
+	}
+	if utils.WantsXLSX(r) {
+		utils.ResponseXLSX(w, "sales-report.xlsx", salesReportXLSX(report))
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get sales report", report)
+}
+
+// GetTopSellers lists the best selling items by units or revenue (sort_by) over
+// the from and to dates, the last 30 days by default
+func (h *ReportHandler) GetTopSellers(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	filter, err := parseItemMovementFilter(r)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+	filter.SortBy = r.URL.Query().Get("sort_by")
+
+	if utils.WantsCSV(r) {
+		items, _, err := h.ReportService.GetTopSellers(filter, 1, csvAllRows)
+		if err != nil {
+			utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		writeCSV(w, "top-sellers.csv", items)
+		return
+	}
+
+	items, pagination, err := h.ReportService.GetTopSellers(filter, page, h.Config.Limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get top sellers", items, *pagination)
+}
+
+// GetSlowMovers lists the items in stock with the lowest sell-through over the
+// from and to dates, the last 30 days by default
+func (h *ReportHandler) GetSlowMovers(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	filter, err := parseItemMovementFilter(r)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if utils.WantsCSV(r) {
+		items, _, err := h.ReportService.GetSlowMovers(filter, 1, csvAllRows)
+		if err != nil {
+			utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		writeCSV(w, "slow-movers.csv", items)
+		return
+	}
+
+	items, pagination, err := h.ReportService.GetSlowMovers(filter, page, h.Config.Limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get slow movers", items, *pagination)
+}
+
+// GetDeadStock lists the items in stock without a sale in the last days, 90 by
+// default
+func (h *ReportHandler) GetDeadStock(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	filter, err := parseItemMovementFilter(r)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	days := 90
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		days, err = strconv.Atoi(daysStr)
+		if err != nil {
+			utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid days", nil)
+			return
+		}
+	}
+
+	if utils.WantsCSV(r) {
+		items, _, err := h.ReportService.GetDeadStock(days, filter, 1, csvAllRows)
+		if err != nil {
+			utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		writeCSV(w, "dead-stock.csv", items)
+		return
+	}
+
+	items, pagination, err := h.ReportService.GetDeadStock(days, filter, page, h.Config.Limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get dead stock", items, *pagination)
+}
+
+// parseItemMovementFilter reads the optional from and to dates and the
+// category_id and warehouse_id filters of the item movement reports
+func parseItemMovementFilter(r *http.Request) (model.ItemMovementFilter, error) {
+	var filter model.ItemMovementFilter
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		return filter, err
+	}
+	if from != nil {
+		filter.From = *from
+	}
+	if to != nil {
+		filter.To = *to
+	}
+
+	if categoryIDStr := r.URL.Query().Get("category_id"); categoryIDStr != "" {
+		categoryID, err := strconv.Atoi(categoryIDStr)
+		if err != nil {
+			return filter, errors.New("invalid category_id")
+		}
+		filter.CategoryID = &categoryID
+	}
+
+	if warehouseIDStr := r.URL.Query().Get("warehouse_id"); warehouseIDStr != "" {
+		warehouseID, err := strconv.Atoi(warehouseIDStr)
+		if err != nil {
+			return filter, errors.New("invalid warehouse_id")
+		}
+		filter.WarehouseID = &warehouseID
+	}
+
+	return filter, nil
+}
+
+// GetABCClassification bands the items into A, B and C by their share of revenue
+// (basis=revenue) or consumption value (basis=consumption) over from and to,
+// the last 90 days by default. a_threshold and b_threshold are the cumulative
+// percentages that close classes A and B, 80 and 95 by default.
+func (h *ReportHandler) GetABCClassification(w http.ResponseWriter, r *http.Request) {
+	settings, err := parseABCSettings(r)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	report, err := h.ReportService.GetABCClassification(settings)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	if utils.WantsCSV(r) {
+		writeCSV(w, "abc-classification.csv", report.Items)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get abc classification", report)
+}
+
+// ApplyABCClassification classifies the items with the same parameters as
+// GetABCClassification and stores the classes on the items
+func (h *ReportHandler) ApplyABCClassification(w http.ResponseWriter, r *http.Request) {
+	settings, err := parseABCSettings(r)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	report, err := h.ReportService.ApplyABCClassification(settings)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "abc classes applied to items", report)
+}
+
+func parseABCSettings(r *http.Request) (model.ABCSettings, error) {
+	settings := model.ABCSettings{Basis: r.URL.Query().Get("basis")}
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		return settings, err
+	}
+	if from != nil {
+		settings.From = *from
+	}
+	if to != nil {
+		settings.To = *to
+	}
+
+	if aStr := r.URL.Query().Get("a_threshold"); aStr != "" {
+		settings.AThreshold, err = money.ParseRate(aStr)
+		if err != nil {
+			return settings, errors.New("invalid a_threshold")
+		}
+	}
+	if bStr := r.URL.Query().Get("b_threshold"); bStr != "" {
+		settings.BThreshold, err = money.ParseRate(bStr)
+		if err != nil {
+			return settings, errors.New("invalid b_threshold")
+		}
+	}
+
+	return settings, nil
+}
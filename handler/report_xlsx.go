@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"project-app-inventory/dto"
+	"project-app-inventory/utils"
+	"time"
+)
+
+// summaryXLSX lists the summary figures one per row, the summary is a single
+// set of totals so it has no totals row of its own
+func summaryXLSX(report *dto.ReportSummaryResponse) utils.XLSXSheet {
+	return utils.XLSXSheet{
+		Name:   "Summary",
+		Header: []string{"metric", "value"},
+		Rows: [][]any{
+			{"total_items", report.TotalItems},
+			{"low_stock_items", report.LowStockItems},
+			{"total_sales", report.TotalSales},
+			{"total_revenue", report.TotalRevenue},
+			{"total_refunds", report.TotalRefunds},
+			{"gross_sales", report.GrossSales},
+			{"total_discounts", report.TotalDiscounts},
+			{"total_tax", report.TotalTax},
+			{"active_users", report.ActiveUsers},
+			{"total_categories", report.TotalCategories},
+			{"total_warehouses", report.TotalWarehouses},
+		},
+	}
+}
+
+// salesReportXLSX has a row per period, and per group when the report is
+// grouped, with the periods as date cells. A sale with items of several groups
+// counts in each of them, so a grouped report has no sales count total.
+func salesReportXLSX(report *dto.SalesReportResponse) utils.XLSXSheet {
+	grouped := report.GroupBy != ""
+
+	header := []string{"period"}
+	if grouped {
+		header = append(header, "group_id", "group_name")
+	}
+	header = append(header, "revenue", "sales_count", "units_sold")
+
+	var rows [][]any
+	var salesCount int
+	for _, period := range report.Periods {
+		var row []any
+		if day, err := time.Parse("2006-01-02", period.Period); err == nil {
+			row = append(row, day)
+		} else {
+			row = append(row, period.Period)
+		}
+		if grouped {
+			row = append(row, period.GroupID, period.GroupName)
+		}
+		row = append(row, period.Revenue, period.SalesCount, period.UnitsSold)
+		rows = append(rows, row)
+		salesCount += period.SalesCount
+	}
+
+	totals := []any{"Total"}
+	if grouped {
+		totals = append(totals, nil, nil, report.TotalRevenue, nil, report.TotalUnits)
+	} else {
+		totals = append(totals, report.TotalRevenue, salesCount, report.TotalUnits)
+	}
+
+	return utils.XLSXSheet{
+		Name:   "Sales " + report.From + " to " + report.To,
+		Header: header,
+		Rows:   rows,
+		Totals: totals,
+	}
+}
+
+func inventoryValuationXLSX(report *dto.InventoryValuationResponse) utils.XLSXSheet {
+	name := "Inventory valuation"
+	if report.AsOf != nil {
+		name += " " + *report.AsOf
+	}
+
+	var rows [][]any
+	for _, line := range report.Lines {
+		rows = append(rows, []any{
+			line.WarehouseID, line.WarehouseName, line.RackID, line.RackCode,
+			line.CategoryID, line.CategoryName, line.Quantity, line.Value,
+		})
+	}
+
+	return utils.XLSXSheet{
+		Name: name,
+		Header: []string{"warehouse_id", "warehouse_name", "rack_id", "rack_code",
+			"category_id", "category_name", "quantity", "value"},
+		Rows:   rows,
+		Totals: []any{"Total", nil, nil, nil, nil, nil, report.TotalQuantity, report.TotalValue},
+	}
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ReservationHandler struct {
+	ReservationService service.ReservationService
+	Config             utils.Configuration
+}
+
+func NewReservationHandler(reservationService service.ReservationService, config utils.Configuration) ReservationHandler {
+	return ReservationHandler{
+		ReservationService: reservationService,
+		Config:             config,
+	}
+}
+
+func (h *ReservationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.ReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	reservation, items, err := h.ReservationService.Create(user.ID, req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "reservation created successfully", toReservationResponse(reservation, items))
+}
+
+func (h *ReservationHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit := h.Config.Limit
+	status := r.URL.Query().Get("status")
+
+	reservations, pagination, err := h.ReservationService.GetAllReservations(status, page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "failed to fetch reservations: "+err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get data", reservations, *pagination)
+}
+
+func (h *ReservationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	reservationID, err := strconv.Atoi(chi.URLParam(r, "reservation_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid reservation id", nil)
+		return
+	}
+
+	reservation, items, err := h.ReservationService.GetReservationByID(reservationID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get reservation by id", toReservationResponse(reservation, items))
+}
+
+func (h *ReservationHandler) Extend(w http.ResponseWriter, r *http.Request) {
+	reservationID, err := strconv.Atoi(chi.URLParam(r, "reservation_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid reservation id", nil)
+		return
+	}
+
+	var req dto.ReservationExtendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	reservation, err := h.ReservationService.Extend(reservationID, req.Hours)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "reservation extended successfully", toReservationResponse(reservation, nil))
+}
+
+func (h *ReservationHandler) Release(w http.ResponseWriter, r *http.Request) {
+	reservationID, err := strconv.Atoi(chi.URLParam(r, "reservation_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid reservation id", nil)
+		return
+	}
+
+	err = h.ReservationService.Release(reservationID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "reservation released successfully", nil)
+}
+
+func (h *ReservationHandler) Convert(w http.ResponseWriter, r *http.Request) {
+	reservationID, err := strconv.Atoi(chi.URLParam(r, "reservation_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid reservation id", nil)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	sale, err := h.ReservationService.Convert(reservationID, user.ID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "reservation converted to sale successfully", sale)
+}
+
+func toReservationResponse(reservation *model.Reservation, items []model.ReservationItem) dto.ReservationResponse {
+	response := dto.ReservationResponse{
+		ID:         reservation.ID,
+		CustomerID: reservation.CustomerID,
+		Status:     reservation.Status,
+		ExpiresAt:  reservation.ExpiresAt.Format("2006-01-02 15:04:05"),
+		SaleID:     reservation.SaleID,
+		CreatedBy:  reservation.CreatedBy,
+		CreatedAt:  reservation.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  reservation.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if reservation.Note != nil {
+		response.Note = *reservation.Note
+	}
+
+	for _, item := range items {
+		response.Items = append(response.Items, dto.ReservationItemResponse{
+			ID:       item.ID,
+			ItemID:   item.ItemID,
+			Quantity: item.Quantity,
+		})
+	}
+
+	return response
+}
@@ -39,19 +39,13 @@ func (h *SaleHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user from context (set by auth middleware)
-	userCtx := r.Context().Value("user")
-	if userCtx == nil {
-		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
-		return
-	}
-
-	user, ok := userCtx.(*model.User)
+	user, ok := currentUser(r)
 	if !ok {
-		utils.ResponseBadRequest(w, http.StatusUnauthorized, "invalid user context", nil)
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
 		return
 	}
 
-	sale, err := h.SaleService.Create(user.ID, req.Items)
+	sale, err := h.SaleService.Create(user.ID, req)
 	if err != nil {
 		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
 		return
@@ -61,6 +55,11 @@ func (h *SaleHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *SaleHandler) List(w http.ResponseWriter, r *http.Request) {
+	if utils.WantsCSV(r) {
+		writeCSVPages(w, "sales.csv", h.SaleService.GetSalesAfter, func(sale model.Sale) int { return sale.ID })
+		return
+	}
+
 	page, err := strconv.Atoi(r.URL.Query().Get("page"))
 	if err != nil || page < 1 {
 		page = 1
@@ -94,11 +93,15 @@ func (h *SaleHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	// Build response with items
 	response := dto.SaleResponse{
-		ID:          sale.ID,
-		UserID:      sale.UserID,
-		TotalAmount: sale.TotalAmount,
-		CreatedAt:   sale.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt:   sale.UpdatedAt.Format("2006-01-02 15:04:05"),
+		ID:             sale.ID,
+		UserID:         sale.UserID,
+		CustomerID:     sale.CustomerID,
+		GrossAmount:    sale.GrossAmount,
+		DiscountAmount: sale.DiscountAmount,
+		TaxAmount:      sale.TaxAmount,
+		TotalAmount:    sale.TotalAmount,
+		CreatedAt:      sale.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:      sale.UpdatedAt.Format("2006-01-02 15:04:05"),
 	}
 
 	if sale.DeletedAt != nil {
@@ -109,11 +112,18 @@ func (h *SaleHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	var saleItems []dto.SaleItemResponse
 	for _, item := range items {
 		saleItems = append(saleItems, dto.SaleItemResponse{
-			ID:          item.ID,
-			ItemID:      item.ItemID,
-			Quantity:    item.Quantity,
-			PriceAtSale: item.PriceAtSale,
-			Subtotal:    item.Subtotal,
+			ID:             item.ID,
+			ItemID:         item.ItemID,
+			RackID:         item.RackID,
+			Quantity:       item.Quantity,
+			PriceAtSale:    item.PriceAtSale,
+			GrossAmount:    item.GrossAmount,
+			DiscountAmount: item.DiscountAmount,
+			TaxRate:        item.TaxRate,
+			TaxAmount:      item.TaxAmount,
+			Subtotal:       item.Subtotal,
+			LotNumber:      item.LotNumber,
+			SerialNumbers:  item.SerialNumbers,
 		})
 	}
 	response.Items = saleItems
@@ -143,7 +153,13 @@ func (h *SaleHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.SaleService.Update(saleID, req.Items)
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	err = h.SaleService.Update(saleID, user.ID, req)
 	if err != nil {
 		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
 		return
@@ -161,7 +177,13 @@ func (h *SaleHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.SaleService.Delete(saleID)
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	err = h.SaleService.Delete(saleID, user.ID)
 	if err != nil {
 		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
 		return
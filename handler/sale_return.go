@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SaleReturnHandler struct {
+	SaleReturnService service.SaleReturnService
+	Config            utils.Configuration
+}
+
+func NewSaleReturnHandler(saleReturnService service.SaleReturnService, config utils.Configuration) SaleReturnHandler {
+	return SaleReturnHandler{
+		SaleReturnService: saleReturnService,
+		Config:            config,
+	}
+}
+
+func (h *SaleReturnHandler) Create(w http.ResponseWriter, r *http.Request) {
+	saleID, err := strconv.Atoi(chi.URLParam(r, "sale_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid sale id", nil)
+		return
+	}
+
+	var req dto.SaleReturnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	saleReturn, items, err := h.SaleReturnService.Create(saleID, user.ID, req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "sale return created successfully", toSaleReturnResponse(saleReturn, items))
+}
+
+func (h *SaleReturnHandler) List(w http.ResponseWriter, r *http.Request) {
+	saleID, err := strconv.Atoi(chi.URLParam(r, "sale_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid sale id", nil)
+		return
+	}
+
+	returns, items, err := h.SaleReturnService.GetSaleReturns(saleID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	// Group the lines under their return
+	itemsByReturn := make(map[int][]model.SaleReturnItem)
+	for _, item := range items {
+		itemsByReturn[item.ReturnID] = append(itemsByReturn[item.ReturnID], item)
+	}
+
+	response := []dto.SaleReturnResponse{}
+	for i := range returns {
+		response = append(response, toSaleReturnResponse(&returns[i], itemsByReturn[returns[i].ID]))
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get sale returns", response)
+}
+
+func toSaleReturnResponse(saleReturn *model.SaleReturn, items []model.SaleReturnItem) dto.SaleReturnResponse {
+	response := dto.SaleReturnResponse{
+		ID:           saleReturn.ID,
+		SaleID:       saleReturn.SaleID,
+		RefundAmount: saleReturn.RefundAmount,
+		CreatedBy:    saleReturn.CreatedBy,
+		CreatedAt:    saleReturn.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if saleReturn.Note != nil {
+		response.Note = *saleReturn.Note
+	}
+
+	for _, item := range items {
+		response.Items = append(response.Items, dto.SaleReturnItemResponse{
+			ID:            item.ID,
+			SaleItemID:    item.SaleItemID,
+			ItemID:        item.ItemID,
+			RackID:        item.RackID,
+			Quantity:      item.Quantity,
+			Condition:     item.Condition,
+			PriceAtSale:   item.PriceAtSale,
+			RefundAmount:  item.RefundAmount,
+			SerialNumbers: item.SerialNumbers,
+		})
+	}
+
+	return response
+}
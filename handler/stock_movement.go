@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type StockMovementHandler struct {
+	StockMovementService service.StockMovementService
+	Config               utils.Configuration
+}
+
+func NewStockMovementHandler(stockMovementService service.StockMovementService, config utils.Configuration) StockMovementHandler {
+	return StockMovementHandler{
+		StockMovementService: stockMovementService,
+		Config:               config,
+	}
+}
+
+func (h *StockMovementHandler) ListByItem(w http.ResponseWriter, r *http.Request) {
+	itemIDstr := chi.URLParam(r, "item_id")
+
+	itemID, err := strconv.Atoi(itemIDstr)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid item id", nil)
+		return
+	}
+
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	from, to, err := parseDateRange(r)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	limit := h.Config.Limit
+
+	movements, pagination, err := h.StockMovementService.GetItemMovements(itemID, from, to, page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get stock movements", movements, *pagination)
+}
+
+func (h *StockMovementHandler) Adjust(w http.ResponseWriter, r *http.Request) {
+	itemIDstr := chi.URLParam(r, "item_id")
+
+	itemID, err := strconv.Atoi(itemIDstr)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid item id", nil)
+		return
+	}
+
+	var req dto.StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	movement, err := h.StockMovementService.AdjustStock(itemID, user.ID, req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "stock adjusted successfully", movement)
+}
+
+// parseDateRange reads the optional from/to query params (YYYY-MM-DD).
+// The returned upper bound is exclusive, so to=2025-01-31 covers the whole day.
+func parseDateRange(r *http.Request) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		parsed, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return nil, nil, errInvalidDate("from")
+		}
+		from = &parsed
+	}
+
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		parsed, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return nil, nil, errInvalidDate("to")
+		}
+		parsed = parsed.AddDate(0, 0, 1)
+		to = &parsed
+	}
+
+	return from, to, nil
+}
+
+func errInvalidDate(param string) error {
+	return fmt.Errorf("invalid %s date, expected format YYYY-MM-DD", param)
+}
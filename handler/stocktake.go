@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type StocktakeHandler struct {
+	StocktakeService service.StocktakeService
+	Config           utils.Configuration
+}
+
+func NewStocktakeHandler(stocktakeService service.StocktakeService, config utils.Configuration) StocktakeHandler {
+	return StocktakeHandler{
+		StocktakeService: stocktakeService,
+		Config:           config,
+	}
+}
+
+func (h *StocktakeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.StocktakeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	stocktake, items, err := h.StocktakeService.Create(user.ID, req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "stocktake opened successfully", toStocktakeResponse(stocktake, items))
+}
+
+func (h *StocktakeHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit := h.Config.Limit
+	status := r.URL.Query().Get("status")
+
+	stocktakes, pagination, err := h.StocktakeService.GetAllStocktakes(status, page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "failed to fetch stocktakes: "+err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get data", stocktakes, *pagination)
+}
+
+// GetByID returns the stocktake with every line and its variance
+func (h *StocktakeHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	stocktakeID, err := strconv.Atoi(chi.URLParam(r, "stocktake_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid stocktake id", nil)
+		return
+	}
+
+	stocktake, items, err := h.StocktakeService.GetStocktakeByID(stocktakeID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get stocktake by id", toStocktakeResponse(stocktake, items))
+}
+
+func (h *StocktakeHandler) SubmitCounts(w http.ResponseWriter, r *http.Request) {
+	stocktakeID, err := strconv.Atoi(chi.URLParam(r, "stocktake_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid stocktake id", nil)
+		return
+	}
+
+	var req dto.StocktakeCountsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	err = h.StocktakeService.SubmitCounts(stocktakeID, user.ID, req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "counts submitted successfully", nil)
+}
+
+func (h *StocktakeHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	stocktakeID, err := strconv.Atoi(chi.URLParam(r, "stocktake_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid stocktake id", nil)
+		return
+	}
+
+	// The body is optional, without it every line is approved
+	var req dto.StocktakeApproveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	err = h.StocktakeService.Approve(stocktakeID, user.ID, req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "stocktake approved successfully", nil)
+}
+
+func (h *StocktakeHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	stocktakeID, err := strconv.Atoi(chi.URLParam(r, "stocktake_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid stocktake id", nil)
+		return
+	}
+
+	err = h.StocktakeService.Cancel(stocktakeID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "stocktake cancelled successfully", nil)
+}
+
+func toStocktakeResponse(stocktake *model.Stocktake, items []model.StocktakeItem) dto.StocktakeResponse {
+	response := dto.StocktakeResponse{
+		ID:          stocktake.ID,
+		WarehouseID: stocktake.WarehouseID,
+		RackIDs:     stocktake.RackIDs,
+		Status:      stocktake.Status,
+		FreezeSales: stocktake.FreezeSales,
+		ABCClasses:  stocktake.ABCClasses,
+		CreatedBy:   stocktake.CreatedBy,
+		ApprovedBy:  stocktake.ApprovedBy,
+		TotalLines:  len(items),
+		CreatedAt:   stocktake.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   stocktake.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if stocktake.Note != nil {
+		response.Note = *stocktake.Note
+	}
+	if stocktake.ApprovedAt != nil {
+		approvedAtStr := stocktake.ApprovedAt.Format("2006-01-02 15:04:05")
+		response.ApprovedAt = &approvedAtStr
+	}
+
+	for _, item := range items {
+		line := dto.StocktakeItemResponse{
+			ID:               item.ID,
+			ItemID:           item.ItemID,
+			SKU:              item.SKU,
+			ItemName:         item.ItemName,
+			RackID:           item.RackID,
+			ExpectedQuantity: item.ExpectedQuantity,
+			MovedQuantity:    item.MovedQuantity,
+			CountedQuantity:  item.CountedQuantity,
+			Variance:         item.Variance(),
+			CountedBy:        item.CountedBy,
+			Excluded:         item.Excluded,
+		}
+		if item.CountedAt != nil {
+			countedAtStr := item.CountedAt.Format("2006-01-02 15:04:05")
+			line.CountedAt = &countedAtStr
+		}
+		if item.CountedQuantity != nil {
+			response.Counted++
+		}
+		response.Items = append(response.Items, line)
+	}
+
+	return response
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SupplierHandler struct {
+	SupplierService service.SupplierService
+	Config          utils.Configuration
+}
+
+func NewSupplierHandler(supplierService service.SupplierService, config utils.Configuration) SupplierHandler {
+	return SupplierHandler{
+		SupplierService: supplierService,
+		Config:          config,
+	}
+}
+
+func (h *SupplierHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.SupplierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	supplier := model.Supplier{
+		Name:         req.Name,
+		ContactName:  optionalString(req.ContactName),
+		Phone:        optionalString(req.Phone),
+		Email:        optionalString(req.Email),
+		Address:      optionalString(req.Address),
+		LeadTimeDays: req.LeadTimeDays,
+	}
+
+	err = h.SupplierService.Create(&supplier)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "supplier created successfully", supplier)
+}
+
+func (h *SupplierHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit := h.Config.Limit
+
+	suppliers, pagination, err := h.SupplierService.GetAllSuppliers(page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusInternalServerError, "failed to fetch suppliers: "+err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get data", suppliers, *pagination)
+}
+
+func (h *SupplierHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	supplierID, err := strconv.Atoi(chi.URLParam(r, "supplier_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid supplier id", nil)
+		return
+	}
+
+	supplier, err := h.SupplierService.GetSupplierByID(supplierID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get supplier by id", supplier)
+}
+
+func (h *SupplierHandler) Update(w http.ResponseWriter, r *http.Request) {
+	supplierID, err := strconv.Atoi(chi.URLParam(r, "supplier_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid supplier id", nil)
+		return
+	}
+
+	var req dto.SupplierUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	supplier := model.Supplier{
+		Name:         req.Name,
+		ContactName:  optionalString(req.ContactName),
+		Phone:        optionalString(req.Phone),
+		Email:        optionalString(req.Email),
+		Address:      optionalString(req.Address),
+		LeadTimeDays: req.LeadTimeDays,
+	}
+
+	err = h.SupplierService.Update(supplierID, &supplier)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "supplier updated successfully", nil)
+}
+
+func (h *SupplierHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	supplierID, err := strconv.Atoi(chi.URLParam(r, "supplier_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid supplier id", nil)
+		return
+	}
+
+	err = h.SupplierService.Delete(supplierID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "supplier deleted successfully", nil)
+}
+
+// optionalString maps an empty request field to NULL
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TransferHandler struct {
+	TransferService service.TransferService
+	Config          utils.Configuration
+}
+
+func NewTransferHandler(transferService service.TransferService, config utils.Configuration) TransferHandler {
+	return TransferHandler{
+		TransferService: transferService,
+		Config:          config,
+	}
+}
+
+func (h *TransferHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request data", nil)
+		return
+	}
+
+	// Validation
+	messages, err := utils.ValidateErrors(req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), messages)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	transfer, items, err := h.TransferService.Create(user.ID, req)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusCreated, "transfer created successfully", toTransferResponse(transfer, items))
+}
+
+func (h *TransferHandler) List(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit := h.Config.Limit
+	status := r.URL.Query().Get("status")
+
+	transfers, pagination, err := h.TransferService.GetAllTransfers(status, page, limit)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "failed to fetch transfers: "+err.Error(), nil)
+		return
+	}
+
+	utils.ResponsePagination(w, http.StatusOK, "success get data", transfers, *pagination)
+}
+
+func (h *TransferHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	transferID, err := strconv.Atoi(chi.URLParam(r, "transfer_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid transfer id", nil)
+		return
+	}
+
+	transfer, items, err := h.TransferService.GetTransferByID(transferID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "success get transfer by id", toTransferResponse(transfer, items))
+}
+
+func (h *TransferHandler) Dispatch(w http.ResponseWriter, r *http.Request) {
+	transferID, err := strconv.Atoi(chi.URLParam(r, "transfer_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid transfer id", nil)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	err = h.TransferService.Dispatch(transferID, user.ID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "transfer dispatched successfully", nil)
+}
+
+func (h *TransferHandler) Receive(w http.ResponseWriter, r *http.Request) {
+	transferID, err := strconv.Atoi(chi.URLParam(r, "transfer_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid transfer id", nil)
+		return
+	}
+
+	user, ok := currentUser(r)
+	if !ok {
+		utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+		return
+	}
+
+	err = h.TransferService.Receive(transferID, user.ID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "transfer received successfully", nil)
+}
+
+func (h *TransferHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	transferID, err := strconv.Atoi(chi.URLParam(r, "transfer_id"))
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid transfer id", nil)
+		return
+	}
+
+	err = h.TransferService.Cancel(transferID)
+	if err != nil {
+		utils.ResponseBadRequest(w, http.StatusBadRequest, err.Error(), nil)
+		return
+	}
+
+	utils.ResponseSuccess(w, http.StatusOK, "transfer cancelled successfully", nil)
+}
+
+func toTransferResponse(transfer *model.Transfer, items []model.TransferItem) dto.TransferResponse {
+	response := dto.TransferResponse{
+		ID:                transfer.ID,
+		SourceRackID:      transfer.SourceRackID,
+		DestinationRackID: transfer.DestinationRackID,
+		Status:            transfer.Status,
+		CreatedBy:         transfer.CreatedBy,
+		DispatchedBy:      transfer.DispatchedBy,
+		ReceivedBy:        transfer.ReceivedBy,
+		CreatedAt:         transfer.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:         transfer.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if transfer.Note != nil {
+		response.Note = *transfer.Note
+	}
+	if transfer.DispatchedAt != nil {
+		dispatchedAtStr := transfer.DispatchedAt.Format("2006-01-02 15:04:05")
+		response.DispatchedAt = &dispatchedAtStr
+	}
+	if transfer.ReceivedAt != nil {
+		receivedAtStr := transfer.ReceivedAt.Format("2006-01-02 15:04:05")
+		response.ReceivedAt = &receivedAtStr
+	}
+
+	for _, item := range items {
+		response.Items = append(response.Items, dto.TransferItemResponse{
+			ID:            item.ID,
+			ItemID:        item.ItemID,
+			Quantity:      item.Quantity,
+			LotNumber:     item.LotNumber,
+			SerialNumbers: item.SerialNumbers,
+		})
+	}
+
+	return response
+}
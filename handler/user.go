@@ -62,6 +62,12 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
+	if utils.WantsCSV(r) {
+		// Password hashes are not part of the json fields, so not of the columns
+		writeCSVPages(w, "users.csv", h.UserService.GetUsersAfter, func(user model.User) int { return user.ID })
+		return
+	}
+
 	page, err := strconv.Atoi(r.URL.Query().Get("page"))
 	if err != nil || page < 1 {
 		page = 1
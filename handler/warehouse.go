@@ -53,6 +53,11 @@ func (h *WarehouseHandler) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *WarehouseHandler) List(w http.ResponseWriter, r *http.Request) {
+	if utils.WantsCSV(r) {
+		writeCSVPages(w, "warehouses.csv", h.WarehouseService.GetWarehousesAfter, func(warehouse model.Warehouse) int { return warehouse.ID })
+		return
+	}
+
 	page, err := strconv.Atoi(r.URL.Query().Get("page"))
 	if err != nil || page < 1 {
 		page = 1
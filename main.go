@@ -10,6 +10,9 @@ import (
 	"project-app-inventory/router"
 	"project-app-inventory/service"
 	"project-app-inventory/utils"
+	"time"
+
+	"go.uber.org/zap"
 )
 
 func main() {
@@ -32,11 +35,20 @@ func main() {
 
 	// logger, err := utils.InitLogger(config.PathLogging, config.Debug)
 
-	repo := repository.NewRepository(db, logger)
+	repo := repository.NewRepository(db, logger, config.CostingMethod)
 	service := service.NewService(repo)
+
+	// Expired idempotency keys are never replayed again, purge them hourly
+	go func() {
+		for range time.Tick(time.Hour) {
+			if _, err := service.IdempotencyKeyService.PurgeExpired(); err != nil {
+				logger.Error("error purging expired idempotency keys", zap.Error(err))
+			}
+		}
+	}()
 	handler := handler.NewHandler(service, config)
 
-	r := router.NewRouter(handler, service, logger)
+	r := router.NewRouter(handler, service, logger, config)
 
 	fmt.Println("server running on port " + config.Port)
 	if err := http.ListenAndServe(":"+config.Port, r); err != nil {
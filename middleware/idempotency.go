@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"project-app-inventory/model"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	idempotentReplayHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodyBytes caps the body read into memory to be hashed, the
+	// largest a handler accepts is an item import file
+	maxIdempotentBodyBytes = 10 << 20
+)
+
+// Idempotency makes mutating requests that carry an Idempotency-Key header safe
+// to retry. The first request runs and its response is stored; a retry with the
+// same key, query and body gets that response back without running again, the
+// same key with a different query or body is rejected. A replay carries the
+// stored status, headers and body. Requests without the header are untouched.
+// Must run after AuthMiddleware, keys are scoped per user.
+func (mw *MiddlewareCostume) Idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" || !isMutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			utils.ResponseBadRequest(w, http.StatusBadRequest, "idempotency key is too long", nil)
+			return
+		}
+
+		user, ok := r.Context().Value("user").(*model.User)
+		if !ok || user == nil {
+			utils.ResponseBadRequest(w, http.StatusUnauthorized, "unauthorized", nil)
+			return
+		}
+
+		// The body is hashed and then handed on to the handler
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodyBytes))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.ResponseBadRequest(w, http.StatusRequestEntityTooLarge, "request body is too large", nil)
+			return
+		}
+		if err != nil {
+			utils.ResponseBadRequest(w, http.StatusBadRequest, "invalid request body", nil)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, replay, err := mw.Service.IdempotencyKeyService.Begin(user.ID, key, r.Method, requestTarget(r), body, mw.Config.IdempotencyTTL)
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			utils.ResponseBadRequest(w, http.StatusUnprocessableEntity, err.Error(), nil)
+			return
+		case errors.Is(err, service.ErrIdempotencyKeyInProgress):
+			utils.ResponseBadRequest(w, http.StatusConflict, err.Error(), nil)
+			return
+		case err != nil:
+			utils.ResponseBadRequest(w, http.StatusInternalServerError, "failed to check idempotency key", nil)
+			return
+		}
+
+		if replay {
+			for name, values := range record.ResponseHeader {
+				w.Header()[name] = values
+			}
+			w.Header().Set(idempotentReplayHeader, "true")
+			w.WriteHeader(*record.StatusCode)
+			w.Write(record.ResponseBody)
+			return
+		}
+
+		// A panicking handler gave no answer to store, the key is freed for a retry
+		defer func() {
+			if p := recover(); p != nil {
+				if err := mw.Service.IdempotencyKeyService.Release(record); err != nil {
+					mw.Log.Error("error releasing idempotency key", zap.String("key", key), zap.Error(err))
+				}
+				panic(p)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// Server errors are not remembered so the client can retry them
+		if recorder.statusCode >= http.StatusInternalServerError {
+			err = mw.Service.IdempotencyKeyService.Release(record)
+		} else {
+			err = mw.Service.IdempotencyKeyService.Complete(record, recorder.statusCode, recorder.sentHeader(), recorder.body.Bytes())
+		}
+		if err != nil {
+			mw.Log.Error("error storing idempotency key outcome", zap.String("key", key), zap.Error(err))
+		}
+	})
+}
+
+// requestTarget is the path with the query params in a fixed order, params
+// such as dry_run change what a request does as much as its body
+func requestTarget(r *http.Request) string {
+	if query := r.URL.Query().Encode(); query != "" {
+		return r.URL.Path + "?" + query
+	}
+	return r.URL.Path
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// responseRecorder passes the response through while keeping a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	header     http.Header // as sent, later changes don't reach the client
+	body       bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	if rec.header == nil {
+		rec.statusCode = statusCode
+		rec.header = rec.ResponseWriter.Header().Clone()
+	}
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.header == nil {
+		rec.WriteHeader(http.StatusOK)
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// sentHeader is the header of the response, without the ones that describe
+// this particular answer rather than its content
+func (rec *responseRecorder) sentHeader() http.Header {
+	header := rec.header
+	if header == nil {
+		// Nothing was written, the header goes out once the handler returns
+		header = rec.ResponseWriter.Header().Clone()
+	}
+	header.Del("Date")
+	header.Del(idempotentReplayHeader)
+	return header
+}
@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"project-app-inventory/service"
+	"project-app-inventory/utils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeIdempotencyKeyRepository keeps the keys in memory with a clock the test
+// moves, so leases can run out without waiting
+type fakeIdempotencyKeyRepository struct {
+	keys   map[string]*model.IdempotencyKey
+	now    time.Time
+	nextID int
+}
+
+func newFakeIdempotencyKeyRepository() *fakeIdempotencyKeyRepository {
+	return &fakeIdempotencyKeyRepository{keys: map[string]*model.IdempotencyKey{}, now: time.Now()}
+}
+
+func (f *fakeIdempotencyKeyRepository) Reserve(key *model.IdempotencyKey, lease time.Duration) (bool, error) {
+	name := key.Key
+	if existing, ok := f.keys[name]; ok {
+		abandoned := existing.StatusCode == nil && !f.now.Before(existing.CreatedAt.Add(lease))
+		if f.now.Before(existing.ExpiresAt) && !abandoned {
+			return false, nil
+		}
+		key.ID = existing.ID
+	} else {
+		f.nextID++
+		key.ID = f.nextID
+	}
+	key.CreatedAt = f.now
+	record := *key
+	f.keys[name] = &record
+	return true, nil
+}
+
+func (f *fakeIdempotencyKeyRepository) FindByKey(userID int, key string) (*model.IdempotencyKey, error) {
+	record, ok := f.keys[key]
+	if !ok {
+		return nil, nil
+	}
+	found := *record
+	return &found, nil
+}
+
+func (f *fakeIdempotencyKeyRepository) find(id int, token string) (string, bool) {
+	for name, record := range f.keys {
+		if record.ID == id && record.Token == token {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func (f *fakeIdempotencyKeyRepository) SaveResponse(id int, token string, statusCode int, header map[string][]string, body []byte) error {
+	name, ok := f.find(id, token)
+	if !ok {
+		return errors.New("idempotency key not found")
+	}
+	f.keys[name].StatusCode = &statusCode
+	f.keys[name].ResponseHeader = header
+	f.keys[name].ResponseBody = body
+	return nil
+}
+
+func (f *fakeIdempotencyKeyRepository) Delete(id int, token string) error {
+	if name, ok := f.find(id, token); ok {
+		delete(f.keys, name)
+	}
+	return nil
+}
+
+func (f *fakeIdempotencyKeyRepository) DeleteExpired() (int64, error) {
+	return 0, nil
+}
+
+func newIdempotencyTestMiddleware(repo *fakeIdempotencyKeyRepository) MiddlewareCostume {
+	svc := service.Service{
+		IdempotencyKeyService: service.NewIdempotencyKeyService(repository.Repository{IdempotencyKeyRepo: repo}),
+	}
+	return NewMiddlewareCustome(svc, zap.NewNop(), utils.Configuration{IdempotencyTTL: time.Hour})
+}
+
+// newIdempotentRequest is a POST by user 3 with the given key and body
+func newIdempotentRequest(key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/items/import", strings.NewReader(body))
+	req.Header.Set(idempotencyKeyHeader, key)
+	return req.WithContext(context.WithValue(req.Context(), "user", &model.User{ID: 3}))
+}
+
+// TestIdempotency_ReplaysStoredResponse tests a retry gets the first status,
+// headers and body back without running the handler again
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	mw := newIdempotencyTestMiddleware(newFakeIdempotencyKeyRepository())
+
+	calls := 0
+	handler := mw.Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="errors.csv"`)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("row,error\n"))
+	}))
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, newIdempotentRequest("abc", "sku,name\n"))
+	require.Equal(t, http.StatusCreated, first.Code)
+	require.Empty(t, first.Header().Get(idempotentReplayHeader))
+
+	retry := httptest.NewRecorder()
+	handler.ServeHTTP(retry, newIdempotentRequest("abc", "sku,name\n"))
+
+	require.Equal(t, 1, calls)
+	require.Equal(t, http.StatusCreated, retry.Code)
+	require.Equal(t, "text/csv", retry.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="errors.csv"`, retry.Header().Get("Content-Disposition"))
+	require.Equal(t, "true", retry.Header().Get(idempotentReplayHeader))
+	require.Equal(t, "row,error\n", retry.Body.String())
+}
+
+// TestIdempotency_DifferentBody tests the same key sent with another body is rejected
+func TestIdempotency_DifferentBody(t *testing.T) {
+	mw := newIdempotencyTestMiddleware(newFakeIdempotencyKeyRepository())
+
+	calls := 0
+	handler := mw.Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("abc", "sku,name\nA,1\n"))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newIdempotentRequest("abc", "sku,name\nB,2\n"))
+
+	require.Equal(t, 1, calls)
+	require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	require.Contains(t, recorder.Body.String(), service.ErrIdempotencyKeyReused.Error())
+}
+
+// TestIdempotency_LeaseTakeover tests a request still running past its lease
+// loses the key to a retry, and its late response doesn't overwrite the retry's
+func TestIdempotency_LeaseTakeover(t *testing.T) {
+	repo := newFakeIdempotencyKeyRepository()
+	mw := newIdempotencyTestMiddleware(repo)
+
+	var handler http.Handler
+	calls := 0
+	handler = mw.Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			// While the first request hangs its lease runs out and the client retries
+			repo.now = repo.now.Add(10 * time.Minute)
+			retry := httptest.NewRecorder()
+			handler.ServeHTTP(retry, newIdempotentRequest("abc", "sku,name\n"))
+			require.Equal(t, http.StatusCreated, retry.Code)
+			require.Equal(t, "second", retry.Body.String())
+
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("first"))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("second"))
+	}))
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, newIdempotentRequest("abc", "sku,name\n"))
+	require.Equal(t, "first", first.Body.String())
+
+	replay := httptest.NewRecorder()
+	handler.ServeHTTP(replay, newIdempotentRequest("abc", "sku,name\n"))
+
+	require.Equal(t, 2, calls)
+	require.Equal(t, http.StatusCreated, replay.Code)
+	require.Equal(t, "second", replay.Body.String())
+}
+
+// TestIdempotency_PanicReleasesKey tests a panicking handler frees the key so
+// the retry runs instead of waiting out the lease
+func TestIdempotency_PanicReleasesKey(t *testing.T) {
+	repo := newFakeIdempotencyKeyRepository()
+	mw := newIdempotencyTestMiddleware(repo)
+
+	calls := 0
+	handler := mw.Idempotency(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			panic("nil map")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	require.PanicsWithValue(t, "nil map", func() {
+		handler.ServeHTTP(httptest.NewRecorder(), newIdempotentRequest("abc", "sku,name\n"))
+	})
+	require.Empty(t, repo.keys)
+
+	retry := httptest.NewRecorder()
+	handler.ServeHTTP(retry, newIdempotentRequest("abc", "sku,name\n"))
+
+	require.Equal(t, 2, calls)
+	require.Equal(t, http.StatusCreated, retry.Code)
+}
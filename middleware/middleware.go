@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"project-app-inventory/service"
+	"project-app-inventory/utils"
 
 	"go.uber.org/zap"
 )
@@ -9,11 +10,13 @@ import (
 type MiddlewareCostume struct {
 	Service service.Service
 	Log     *zap.Logger
+	Config  utils.Configuration
 }
 
-func NewMiddlewareCustome(service service.Service, log *zap.Logger) MiddlewareCostume {
+func NewMiddlewareCustome(service service.Service, log *zap.Logger, config utils.Configuration) MiddlewareCostume {
 	return MiddlewareCostume{
 		Service: service,
 		Log:     log,
+		Config:  config,
 	}
 }
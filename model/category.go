@@ -1,11 +1,15 @@
 package model
 
-import "time"
+import (
+	"project-app-inventory/money"
+	"time"
+)
 
 type Category struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Description *string   `json:"description,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int         `json:"id"`
+	Name        string      `json:"name"`
+	Description *string     `json:"description,omitempty"`
+	TaxRate     *money.Rate `json:"tax_rate"` // percent, nil only in partial updates
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
 }
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"project-app-inventory/money"
+	"time"
+)
+
+type Customer struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     *string   `json:"email,omitempty"`
+	Phone     *string   `json:"phone,omitempty"`
+	Address   *string   `json:"address,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CustomerSalesSummary aggregates the active (not voided) sales of a customer
+type CustomerSalesSummary struct {
+	CustomerID      int          `json:"customer_id"`
+	TotalSales      int          `json:"total_sales"`
+	LifetimeRevenue money.Amount `json:"lifetime_revenue"`
+	FirstSaleAt     *time.Time   `json:"first_sale_at,omitempty"`
+	LastSaleAt      *time.Time   `json:"last_sale_at,omitempty"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Demand forecasting methods
+const (
+	ForecastMovingAverage        = "moving_average"
+	ForecastExponentialSmoothing = "exponential_smoothing"
+)
+
+// DailySales is the units of an item sold on one day, in UTC
+type DailySales struct {
+	ItemID int
+	Day    time.Time
+	Units  int
+}
+
+// ForecastParams are the inputs of a demand forecast
+type ForecastParams struct {
+	Method      string
+	Days        int     // days ahead to forecast, starting today
+	HistoryDays int     // days of sales before today the forecast is fitted on
+	Window      int     // days averaged by the moving average
+	Alpha       float64 // smoothing factor of exponential smoothing, weight of the latest day
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// IdempotencyKey remembers the outcome of a mutating request so a retry sent
+// with the same Idempotency-Key header replays it instead of running again
+type IdempotencyKey struct {
+	ID             int                 `json:"id"`
+	UserID         int                 `json:"user_id"`
+	Key            string              `json:"key"`
+	Method         string              `json:"method"`
+	Path           string              `json:"path"`
+	RequestHash    string              `json:"request_hash"`
+	Token          string              `json:"-"`                     // new for every reservation of the key
+	StatusCode     *int                `json:"status_code,omitempty"` // nil while the first request is still running
+	ResponseHeader map[string][]string `json:"-"`
+	ResponseBody   []byte              `json:"-"`
+	CreatedAt      time.Time           `json:"created_at"`
+	ExpiresAt      time.Time           `json:"expires_at"`
+}
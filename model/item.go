@@ -1,16 +1,71 @@
 package model
 
-import "time"
+import (
+	"project-app-inventory/money"
+	"time"
+)
 
 type Item struct {
-	ID           int       `json:"id"`
-	SKU          string    `json:"sku"`
-	Name         string    `json:"name"`
-	CategoryID   int       `json:"category_id"`
-	RackID       int       `json:"rack_id"`
-	Stock        int       `json:"stock"`
-	MinimumStock int       `json:"minimum_stock"`
-	Price        float64   `json:"price"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           int          `json:"id"`
+	SKU          string       `json:"sku"`
+	Name         string       `json:"name"`
+	CategoryID   int          `json:"category_id"`
+	RackID       int          `json:"rack_id"`
+	Stock        int          `json:"stock"`      // in the racks plus in transit between them
+	InTransit    int          `json:"in_transit"` // dispatched by a transfer, not yet received
+	MinimumStock int          `json:"minimum_stock"`
+	Price        money.Amount `json:"price"`
+	TaxRate      *money.Rate  `json:"tax_rate"`     // percent, nil uses the category rate
+	TrackLots    bool         `json:"track_lots"`   // stock is received and sold per lot
+	Serialized   bool         `json:"serialized"`   // every unit has a serial number, stock counts the serials in stock or in transit
+	AverageCost  money.Amount `json:"average_cost"` // weighted average cost of the stock on hand
+	ABCClass     *string      `json:"abc_class"`    // A, B or C from the last ABC classification, nil until classified
+	Reserved     int          `json:"reserved"`     // held by active reservations
+	Available    int          `json:"available"`    // stock - in_transit - reserved
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// ReorderParams are the inputs of the reorder suggestions, in days
+type ReorderParams struct {
+	SalesDays    int // days of sales the average daily sales is taken over
+	SafetyDays   int // days of average sales kept as safety stock
+	CoverDays    int // days of average sales an order should cover
+	LeadTimeDays int // for items whose supplier has no lead time
+}
+
+// ReorderSuggestion is the reorder point and order quantity an item's sales
+// call for: the reorder point covers the sales during the supplier's lead time
+// plus the safety stock
+type ReorderSuggestion struct {
+	ItemID            int     `json:"item_id"`
+	SKU               string  `json:"sku"`
+	Name              string  `json:"name"`
+	Stock             int     `json:"stock"`
+	MinimumStock      int     `json:"minimum_stock"`
+	UnitsSold         int     `json:"units_sold"`
+	AverageDailySales float64 `json:"average_daily_sales"`
+	SupplierID        *int    `json:"supplier_id"` // of the item's latest purchase order
+	SupplierName      *string `json:"supplier_name"`
+	LeadTimeDays      int     `json:"lead_time_days"`
+	SafetyStock       int     `json:"safety_stock"`
+	ReorderPoint      int     `json:"suggested_reorder_point"`
+	OrderQuantity     int     `json:"suggested_order_quantity"`
+}
+
+// Outcomes of a row of an item import
+const (
+	ItemImportCreated = "created"
+	ItemImportUpdated = "updated"
+	ItemImportFailed  = "failed"
+	ItemImportSkipped = "skipped" // valid, but not written because another row failed
+)
+
+// ItemImport is a row of an item import resolved to the item it creates, or
+// updates when Item.ID is set. Status and Err are filled in by the import.
+type ItemImport struct {
+	Line   int // line of the row in the file, the header is line 1
+	Item   Item
+	Status string
+	Err    error
 }
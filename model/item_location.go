@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+type ItemLocation struct {
+	ID          int       `json:"id"`
+	ItemID      int       `json:"item_id"`
+	SKU         string    `json:"sku,omitempty"`       // from join with items table
+	ItemName    string    `json:"item_name,omitempty"` // from join with items table
+	RackID      int       `json:"rack_id"`
+	RackCode    string    `json:"rack_code,omitempty"` // from join with racks table
+	WarehouseID int       `json:"warehouse_id"`        // from join with racks table
+	Quantity    int       `json:"quantity"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WarehouseStock is the quantity of one item summed over all racks of a warehouse
+type WarehouseStock struct {
+	WarehouseID int    `json:"warehouse_id"`
+	ItemID      int    `json:"item_id"`
+	SKU         string `json:"sku"`
+	ItemName    string `json:"item_name"`
+	Quantity    int    `json:"quantity"`
+	RackCount   int    `json:"rack_count"`
+}
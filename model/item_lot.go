@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type ItemLot struct {
+	ID           int        `json:"id"`
+	ItemID       int        `json:"item_id"`
+	SKU          string     `json:"sku,omitempty"`       // from join with items table
+	ItemName     string     `json:"item_name,omitempty"` // from join with items table
+	RackID       int        `json:"rack_id"`             // 0 for a lot summed over its racks
+	RackCode     string     `json:"rack_code,omitempty"` // from join with racks table
+	LotNumber    string     `json:"lot_number"`
+	ExpiryDate   *time.Time `json:"expiry_date"`    // nil for lots that don't expire
+	DaysToExpiry *int       `json:"days_to_expiry"` // negative once expired
+	Quantity     int        `json:"quantity"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Expired reports whether the lot is past its expiry date on the given day
+func (l ItemLot) Expired(today time.Time) bool {
+	return l.ExpiryDate != nil && l.ExpiryDate.Before(today)
+}
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// Serial lifecycle: in_stock while on a rack, in_transit between dispatch and
+// receipt of a transfer, sold, damaged when returned damaged, removed when
+// taken out of stock otherwise (receipt void, adjustment)
+const (
+	SerialStatusInStock   = "in_stock"
+	SerialStatusInTransit = "in_transit"
+	SerialStatusSold      = "sold"
+	SerialStatusDamaged   = "damaged"
+	SerialStatusRemoved   = "removed"
+)
+
+// SerialEventDamagedReturn records a serial returned damaged, which doesn't
+// move stock; every other event type is the movement type of the ledger entry
+const SerialEventDamagedReturn = "sale_return_damaged"
+
+type ItemSerial struct {
+	ID           int       `json:"id"`
+	ItemID       int       `json:"item_id"`
+	SKU          string    `json:"sku,omitempty"`       // from join with items table
+	ItemName     string    `json:"item_name,omitempty"` // from join with items table
+	SerialNumber string    `json:"serial_number"`
+	RackID       int       `json:"rack_id"` // current rack, or the last one while not in stock
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type SerialEvent struct {
+	ID            int       `json:"id"`
+	SerialID      int       `json:"serial_id"`
+	EventType     string    `json:"event_type"`
+	RackID        int       `json:"rack_id"`
+	UserID        int       `json:"user_id"`
+	ReferenceType *string   `json:"reference_type,omitempty"`
+	ReferenceID   *int      `json:"reference_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// SerialStatusAfter is the status a serial gets when a movement takes it out of stock
+func SerialStatusAfter(movementType string) string {
+	switch movementType {
+	case MovementTypeSale, MovementTypeSaleEdit:
+		return SerialStatusSold
+	case MovementTypeTransferOut:
+		return SerialStatusInTransit
+	default:
+		return SerialStatusRemoved
+	}
+}
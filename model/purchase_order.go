@@ -0,0 +1,39 @@
+package model
+
+import (
+	"project-app-inventory/money"
+	"time"
+)
+
+// Purchase order lifecycle: draft -> approved -> partially_received -> received,
+// a draft or approved order can be cancelled until goods start arriving
+const (
+	PurchaseOrderStatusDraft             = "draft"
+	PurchaseOrderStatusApproved          = "approved"
+	PurchaseOrderStatusPartiallyReceived = "partially_received"
+	PurchaseOrderStatusReceived          = "received"
+	PurchaseOrderStatusCancelled         = "cancelled"
+)
+
+type PurchaseOrder struct {
+	ID          int          `json:"id"`
+	SupplierID  int          `json:"supplier_id"`
+	Status      string       `json:"status"`
+	Note        *string      `json:"note,omitempty"`
+	TotalAmount money.Amount `json:"total_amount"`
+	CreatedBy   int          `json:"created_by"`
+	ApprovedBy  *int         `json:"approved_by,omitempty"`
+	ApprovedAt  *time.Time   `json:"approved_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+type PurchaseOrderItem struct {
+	ID               int          `json:"id"`
+	PurchaseOrderID  int          `json:"purchase_order_id"`
+	ItemID           int          `json:"item_id"`
+	QuantityOrdered  int          `json:"quantity_ordered"`
+	QuantityReceived int          `json:"quantity_received"`
+	UnitCost         money.Amount `json:"unit_cost"`
+	Subtotal         money.Amount `json:"subtotal"`
+}
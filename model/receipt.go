@@ -0,0 +1,40 @@
+package model
+
+import (
+	"project-app-inventory/money"
+	"time"
+)
+
+// A goods receipt is posted as received, voiding it reverses its stock
+const (
+	ReceiptStatusReceived = "received"
+	ReceiptStatusVoided   = "voided"
+)
+
+type Receipt struct {
+	ID                 int          `json:"id"`
+	SupplierID         int          `json:"supplier_id"`
+	PurchaseOrderID    *int         `json:"purchase_order_id,omitempty"`
+	DeliveryNoteNumber string       `json:"delivery_note_number"`
+	Status             string       `json:"status"`
+	Note               *string      `json:"note,omitempty"`
+	TotalAmount        money.Amount `json:"total_amount"`
+	ReceivedBy         int          `json:"received_by"`
+	VoidedBy           *int         `json:"voided_by,omitempty"`
+	VoidedAt           *time.Time   `json:"voided_at,omitempty"`
+	CreatedAt          time.Time    `json:"created_at"`
+	UpdatedAt          time.Time    `json:"updated_at"`
+}
+
+type ReceiptItem struct {
+	ID            int          `json:"id"`
+	ReceiptID     int          `json:"receipt_id"`
+	ItemID        int          `json:"item_id"`
+	RackID        int          `json:"rack_id"`
+	Quantity      int          `json:"quantity"`
+	UnitCost      money.Amount `json:"unit_cost"`
+	Subtotal      money.Amount `json:"subtotal"`
+	LotNumber     *string      `json:"lot_number,omitempty"`
+	ExpiryDate    *time.Time   `json:"expiry_date,omitempty"`
+	SerialNumbers []string     `json:"serial_numbers,omitempty"`
+}
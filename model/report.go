@@ -0,0 +1,130 @@
+package model
+
+import (
+	"project-app-inventory/money"
+	"time"
+)
+
+// Groupings of the gross margin report
+const (
+	GrossMarginBySale     = "sale"
+	GrossMarginByItem     = "item"
+	GrossMarginByCategory = "category"
+)
+
+// GrossMargin is the revenue of sold goods against their cost for one sale,
+// item or category. Returned units are taken out of both.
+type GrossMargin struct {
+	ID            int          `json:"id"`
+	Name          string       `json:"name,omitempty"`    // item or category name
+	SoldAt        *time.Time   `json:"sold_at,omitempty"` // for sales
+	Quantity      int          `json:"quantity"`
+	Revenue       money.Amount `json:"revenue"` // gross less discounts, before tax, net of returns
+	Cost          money.Amount `json:"cost"`
+	GrossMargin   money.Amount `json:"gross_margin"`
+	MarginPercent money.Rate   `json:"margin_percent"` // of revenue
+}
+
+// InventoryValuation is the stock of one category on one rack and what it is
+// worth at cost, or at the selling price for items without a known cost. Stock
+// in transit between racks has no warehouse and rack.
+type InventoryValuation struct {
+	WarehouseID   *int
+	WarehouseName *string
+	RackID        *int
+	RackCode      *string
+	CategoryID    int
+	CategoryName  string
+	Quantity      int
+	Value         money.Amount
+}
+
+// Intervals and groupings of the sales by period report
+const (
+	SalesIntervalDay   = "day"
+	SalesIntervalWeek  = "week"
+	SalesIntervalMonth = "month"
+
+	SalesGroupByCategory  = "category"
+	SalesGroupByItem      = "item"
+	SalesGroupByWarehouse = "warehouse"
+	SalesGroupByCashier   = "cashier"
+)
+
+// SalesPeriod is what was sold in one time bucket, for one group when the
+// report is grouped
+type SalesPeriod struct {
+	Period     time.Time // start of the bucket, wall clock time of the report's timezone
+	GroupID    *int
+	GroupName  *string
+	Revenue    money.Amount // gross less discounts, before tax, net of returns
+	SalesCount int
+	UnitsSold  int // net of returned units
+}
+
+// Item movement reports and the orders of the top sellers report
+const (
+	ItemMovementTopSellers = "top_sellers"
+	ItemMovementSlowMovers = "slow_movers"
+	ItemMovementDeadStock  = "dead_stock"
+
+	TopSellersByUnits   = "units"
+	TopSellersByRevenue = "revenue"
+)
+
+// ItemMovementFilter narrows the item movement reports down. Sales count from
+// From up to but not including To; dead stock is stock not sold since From.
+type ItemMovementFilter struct {
+	From        time.Time
+	To          time.Time
+	CategoryID  *int
+	WarehouseID *int   // sales and stock of that warehouse only
+	SortBy      string // top sellers only, units or revenue
+}
+
+// ItemMovement is how an item sold over a window against the stock it holds
+type ItemMovement struct {
+	ID                int          `json:"id"`
+	SKU               string       `json:"sku"`
+	Name              string       `json:"name"`
+	CategoryID        int          `json:"category_id"`
+	CategoryName      string       `json:"category_name"`
+	Stock             int          `json:"stock"`
+	UnitsSold         int          `json:"units_sold"`   // net of returned units
+	Revenue           money.Amount `json:"revenue"`      // gross less discounts, before tax, net of returns
+	SellThrough       money.Rate   `json:"sell_through"` // units sold of units sold plus stock
+	LastSoldAt        *time.Time   `json:"last_sold_at"`
+	DaysSinceLastSale *int         `json:"days_since_last_sale,omitempty"` // dead stock only
+}
+
+// ABC classes and what items are ranked by: revenue, or consumption value
+// (units sold at their cost)
+const (
+	ABCClassA = "A"
+	ABCClassB = "B"
+	ABCClassC = "C"
+
+	ABCBasisRevenue     = "revenue"
+	ABCBasisConsumption = "consumption"
+)
+
+// ABCSettings is the period an ABC classification looks at, up to but not
+// including To, and the cumulative shares of value that close the A and B bands
+type ABCSettings struct {
+	Basis      string
+	From       time.Time
+	To         time.Time
+	AThreshold money.Rate
+	BThreshold money.Rate
+}
+
+// ABCItem is an item ranked by its value over the period of an ABC classification
+type ABCItem struct {
+	ItemID          int
+	SKU             string
+	Name            string
+	Value           money.Amount
+	Share           money.Rate // of the value of all items
+	CumulativeShare money.Rate // of this item and every item ranked above it
+	Class           string
+}
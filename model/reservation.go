@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// Reservation statuses, an active reservation holds stock until it expires
+const (
+	ReservationStatusActive    = "active"
+	ReservationStatusReleased  = "released"
+	ReservationStatusConverted = "converted"
+	ReservationStatusExpired   = "expired" // active but past expires_at, derived when reading
+)
+
+type Reservation struct {
+	ID         int       `json:"id"`
+	CustomerID *int      `json:"customer_id,omitempty"`
+	Status     string    `json:"status"`
+	Note       *string   `json:"note,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	SaleID     *int      `json:"sale_id,omitempty"` // sale the reservation was converted into
+	CreatedBy  int       `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ReservationItem struct {
+	ID            int `json:"id"`
+	ReservationID int `json:"reservation_id"`
+	ItemID        int `json:"item_id"`
+	Quantity      int `json:"quantity"`
+}
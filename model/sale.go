@@ -1,21 +1,38 @@
 package model
 
-import "time"
+import (
+	"project-app-inventory/money"
+	"time"
+)
 
 type Sale struct {
-	ID          int        `json:"id"`
-	UserID      int        `json:"user_id"`
-	TotalAmount float64    `json:"total_amount"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	ID             int          `json:"id"`
+	UserID         int          `json:"user_id"`
+	CustomerID     *int         `json:"customer_id,omitempty"`    // nil for walk-in sales
+	ReservationID  *int         `json:"reservation_id,omitempty"` // reservation converted on create, not stored on the sale
+	GrossAmount    money.Amount `json:"gross_amount"`
+	DiscountAmount money.Amount `json:"discount_amount"`
+	TaxAmount      money.Amount `json:"tax_amount"`
+	TotalAmount    money.Amount `json:"total_amount"` // net: gross - discount + tax
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	DeletedAt      *time.Time   `json:"deleted_at,omitempty"`
 }
 
 type SaleItem struct {
-	ID          int     `json:"id"`
-	SaleID      int     `json:"sale_id"`
-	ItemID      int     `json:"item_id"`
-	Quantity    int     `json:"quantity"`
-	PriceAtSale float64 `json:"price_at_sale"`
-	Subtotal    float64 `json:"subtotal"`
+	ID             int          `json:"id"`
+	SaleID         int          `json:"sale_id"`
+	ItemID         int          `json:"item_id"`
+	RackID         int          `json:"rack_id"`
+	Quantity       int          `json:"quantity"`
+	PriceAtSale    money.Amount `json:"price_at_sale"`
+	GrossAmount    money.Amount `json:"gross_amount"`
+	DiscountAmount money.Amount `json:"discount_amount"` // line discount plus its share of the sale discount
+	TaxRate        money.Rate   `json:"tax_rate"`
+	TaxAmount      money.Amount `json:"tax_amount"`
+	Subtotal       money.Amount `json:"subtotal"`                 // net: gross - discount + tax
+	LotNumber      *string      `json:"lot_number,omitempty"`     // lot the units came from, for lot tracked items
+	SerialNumbers  []string     `json:"serial_numbers,omitempty"` // units sold, for serialized items
+	UnitCost       money.Amount `json:"unit_cost"`                // cost of goods sold per unit
+	CostAmount     money.Amount `json:"cost_amount"`              // cost of goods sold of the line
 }
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"project-app-inventory/money"
+	"time"
+)
+
+// Returned goods either go back on the rack or are written off as damaged
+const (
+	ReturnConditionRestock = "restock"
+	ReturnConditionDamaged = "damaged"
+)
+
+type SaleReturn struct {
+	ID           int          `json:"id"`
+	SaleID       int          `json:"sale_id"`
+	Note         *string      `json:"note,omitempty"`
+	RefundAmount money.Amount `json:"refund_amount"`
+	CreatedBy    int          `json:"created_by"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+type SaleReturnItem struct {
+	ID            int          `json:"id"`
+	ReturnID      int          `json:"return_id"`
+	SaleItemID    int          `json:"sale_item_id"`
+	ItemID        int          `json:"item_id"`
+	RackID        int          `json:"rack_id"`
+	Quantity      int          `json:"quantity"`
+	Condition     string       `json:"condition"`
+	PriceAtSale   money.Amount `json:"price_at_sale"`
+	RefundAmount  money.Amount `json:"refund_amount"`
+	SerialNumbers []string     `json:"serial_numbers,omitempty"`
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"project-app-inventory/money"
+	"time"
+)
+
+// Movement types recorded in the stock ledger
+const (
+	MovementTypeOpening     = "opening"
+	MovementTypeSale        = "sale"
+	MovementTypeSaleEdit    = "sale_edit"
+	MovementTypeSaleVoid    = "sale_void"
+	MovementTypeSaleReturn  = "sale_return"
+	MovementTypeAdjustment  = "adjustment"
+	MovementTypeTransferOut = "transfer_out"
+	MovementTypeTransferIn  = "transfer_in"
+	MovementTypeReceipt     = "receipt"
+	MovementTypeReceiptVoid = "receipt_void"
+	MovementTypeStocktake   = "stocktake"
+)
+
+// Costing methods for the cost of goods sold on sale lines
+const (
+	CostingMethodAverage = "average" // weighted average cost of the stock on hand
+	CostingMethodFIFO    = "fifo"    // cost of the oldest cost layers
+)
+
+// Reason codes accepted for manual stock adjustments
+const (
+	AdjustmentReasonDamage     = "damage"
+	AdjustmentReasonLoss       = "loss"
+	AdjustmentReasonFound      = "found"
+	AdjustmentReasonCorrection = "correction"
+	AdjustmentReasonWriteOff   = "write_off"
+)
+
+// Reference types pointing a movement back to its source document
+const (
+	ReferenceTypeItem      = "item"
+	ReferenceTypeSale      = "sale"
+	ReferenceTypeReturn    = "sale_return"
+	ReferenceTypeTransfer  = "transfer"
+	ReferenceTypeReceipt   = "receipt"
+	ReferenceTypeStocktake = "stocktake"
+)
+
+type StockMovement struct {
+	ID            int       `json:"id"`
+	ItemID        int       `json:"item_id"`
+	UserID        int       `json:"user_id"`
+	RackID        int       `json:"rack_id"`
+	MovementType  string    `json:"movement_type"`
+	Quantity      int       `json:"quantity"`      // signed delta applied to stock
+	BalanceAfter  int       `json:"balance_after"` // stock right after this movement
+	ReasonCode    *string   `json:"reason_code,omitempty"`
+	Reason        *string   `json:"reason,omitempty"`
+	ReferenceType *string   `json:"reference_type,omitempty"`
+	ReferenceID   *int      `json:"reference_id,omitempty"`
+	LotNumber     *string   `json:"lot_number,omitempty"`     // lot changed with the stock, for lot tracked items
+	SerialNumbers []string  `json:"serial_numbers,omitempty"` // units moved, for serialized items
+	CreatedAt     time.Time `json:"created_at"`
+
+	// UnitCost is the cost per unit of stock coming in, or of stock going back
+	// to where it came from such as a voided receipt. Incoming stock without
+	// a cost comes in at the item's average cost.
+	UnitCost *money.Amount `json:"-"`
+	// Cost is filled in for outgoing stock with what the units cost
+	Cost StockCost `json:"-"`
+}
+
+// StockCost is the cost of the units taken out of stock by a movement under
+// each costing method
+type StockCost struct {
+	Average money.Amount
+	FIFO    money.Amount
+}
+
+// Amount returns the cost under method, the weighted average unless method is fifo
+func (c StockCost) Amount(method string) money.Amount {
+	if method == CostingMethodFIFO {
+		return c.FIFO
+	}
+	return c.Average
+}
@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Stocktake lifecycle: open while counting, then approved or cancelled
+const (
+	StocktakeStatusOpen      = "open"
+	StocktakeStatusApproved  = "approved"
+	StocktakeStatusCancelled = "cancelled"
+)
+
+type Stocktake struct {
+	ID          int        `json:"id"`
+	WarehouseID *int       `json:"warehouse_id,omitempty"`
+	RackIDs     []int      `json:"rack_ids"`
+	Status      string     `json:"status"`
+	FreezeSales bool       `json:"freeze_sales"`
+	ABCClasses  []string   `json:"abc_classes,omitempty"` // only items of these classes are counted, all when empty
+	Note        *string    `json:"note,omitempty"`
+	CreatedBy   int        `json:"created_by"`
+	ApprovedBy  *int       `json:"approved_by,omitempty"`
+	ApprovedAt  *time.Time `json:"approved_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type StocktakeItem struct {
+	ID               int        `json:"id"`
+	StocktakeID      int        `json:"stocktake_id"`
+	ItemID           int        `json:"item_id"`
+	SKU              string     `json:"sku,omitempty"`       // from join with items table
+	ItemName         string     `json:"item_name,omitempty"` // from join with items table
+	RackID           int        `json:"rack_id"`
+	ExpectedQuantity int        `json:"expected_quantity"`
+	MovedQuantity    int        `json:"moved_quantity"` // net ledger movements of the rack between the snapshot and the count
+	CountedQuantity  *int       `json:"counted_quantity,omitempty"`
+	CountedBy        *int       `json:"counted_by,omitempty"`
+	CountedAt        *time.Time `json:"counted_at,omitempty"`
+	Excluded         bool       `json:"excluded"` // left out of the approval, nothing is posted
+}
+
+// Variance is counted minus expected rebased by the stock moved since the
+// snapshot, nil until the line is counted
+func (i StocktakeItem) Variance() *int {
+	if i.CountedQuantity == nil {
+		return nil
+	}
+	variance := *i.CountedQuantity - (i.ExpectedQuantity + i.MovedQuantity)
+	return &variance
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+type Supplier struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	ContactName  *string   `json:"contact_name,omitempty"`
+	Phone        *string   `json:"phone,omitempty"`
+	Email        *string   `json:"email,omitempty"`
+	Address      *string   `json:"address,omitempty"`
+	LeadTimeDays *int      `json:"lead_time_days,omitempty"` // days from order to delivery
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Transfer lifecycle: draft -> in_transit -> received, a draft can be cancelled
+const (
+	TransferStatusDraft     = "draft"
+	TransferStatusInTransit = "in_transit"
+	TransferStatusReceived  = "received"
+	TransferStatusCancelled = "cancelled"
+)
+
+type Transfer struct {
+	ID                int        `json:"id"`
+	SourceRackID      int        `json:"source_rack_id"`
+	DestinationRackID int        `json:"destination_rack_id"`
+	Status            string     `json:"status"`
+	Note              *string    `json:"note,omitempty"`
+	CreatedBy         int        `json:"created_by"`
+	DispatchedBy      *int       `json:"dispatched_by,omitempty"`
+	DispatchedAt      *time.Time `json:"dispatched_at,omitempty"`
+	ReceivedBy        *int       `json:"received_by,omitempty"`
+	ReceivedAt        *time.Time `json:"received_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+type TransferItem struct {
+	ID            int      `json:"id"`
+	TransferID    int      `json:"transfer_id"`
+	ItemID        int      `json:"item_id"`
+	Quantity      int      `json:"quantity"`
+	LotNumber     *string  `json:"lot_number,omitempty"` // for lot tracked items, a line per lot
+	SerialNumbers []string `json:"serial_numbers,omitempty"`
+}
@@ -0,0 +1,270 @@
+// Package money holds Amount, an exact amount of money in cents, and Rate, a
+// percentage with two decimals. Both match the NUMERIC(15,2) and NUMERIC(5,2)
+// columns of the database exactly: they scan from and encode to pgx numerics
+// without going through float64, and are written to JSON as numbers with
+// exactly two decimals.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Amount is an amount of money in cents
+type Amount int64
+
+var ErrInvalidAmount = errors.New("invalid money amount")
+
+// FromUnits returns the amount of whole currency units
+func FromUnits(units int64) Amount {
+	return Amount(units * 100)
+}
+
+// FromFloat rounds f to the nearest cent, halves away from zero
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * 100))
+}
+
+// Parse reads a decimal string such as "1500", "-12.5" or "19999.99". More than
+// two decimals are rounded to the cent, halves away from zero.
+func Parse(s string) (Amount, error) {
+	cents, err := parseFixed(s)
+	return Amount(cents), err
+}
+
+// parseFixed reads a decimal string into hundredths
+func parseFixed(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" && fraction == "" {
+		return 0, ErrInvalidAmount
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	if !isDigits(whole) || !isDigits(fraction) {
+		return 0, ErrInvalidAmount
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > math.MaxInt64/100-1 {
+		return 0, ErrInvalidAmount
+	}
+
+	// Hundredths from the first two decimals, the third decides rounding
+	fraction += "000"
+	hundredths := int64(fraction[0]-'0')*10 + int64(fraction[1]-'0')
+	if fraction[2] >= '5' {
+		hundredths++
+	}
+
+	value := units*100 + hundredths
+	if negative {
+		value = -value
+	}
+	return value, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Mul returns the amount multiplied by a quantity
+func (a Amount) Mul(quantity int) Amount {
+	return a * Amount(quantity)
+}
+
+// Percent returns rate percent of the amount, rounded to the cent
+func (a Amount) Percent(rate Rate) Amount {
+	return Amount(divRound(big.NewInt(int64(a)), big.NewInt(int64(rate)), big.NewInt(10000)))
+}
+
+// Share returns part/whole of the amount, rounded to the cent
+func (a Amount) Share(part, whole int64) Amount {
+	if whole == 0 {
+		return 0
+	}
+	return Amount(divRound(big.NewInt(int64(a)), big.NewInt(part), big.NewInt(whole)))
+}
+
+// Allocate splits the amount over weights pro rata. The shares are taken from
+// the running total so they always add up to the amount exactly.
+func (a Amount) Allocate(weights []int64) []Amount {
+	var whole int64
+	for _, weight := range weights {
+		whole += weight
+	}
+
+	shares := make([]Amount, len(weights))
+	if whole == 0 {
+		return shares
+	}
+
+	var cumulative int64
+	var allocated Amount
+	for i, weight := range weights {
+		cumulative += weight
+		upTo := a.Share(cumulative, whole)
+		shares[i] = upTo - allocated
+		allocated = upTo
+	}
+	return shares
+}
+
+// divRound returns x*y/z rounded half away from zero
+func divRound(x, y, z *big.Int) int64 {
+	product := new(big.Int).Mul(x, y)
+	quotient, remainder := new(big.Int).QuoRem(product, z, new(big.Int))
+
+	// Compare twice the remainder with the divisor to round halves away from zero
+	remainder.Abs(remainder).Mul(remainder, big.NewInt(2))
+	if remainder.Cmp(new(big.Int).Abs(z)) >= 0 {
+		if product.Sign()*z.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient.Int64()
+}
+
+// Float64 converts to float64 for ratios and reporting, never for arithmetic on amounts
+func (a Amount) Float64() float64 {
+	return float64(a) / 100
+}
+
+// String formats the amount with exactly two decimals, e.g. "1500.00"
+func (a Amount) String() string {
+	return formatFixed(int64(a))
+}
+
+func formatFixed(value int64) string {
+	sign := ""
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/100, value%100)
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding a decimal, both are
+// read from their text so no precision is lost on the way in
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	return unmarshalFixed(data, (*int64)(a))
+}
+
+// ScanNumeric implements pgtype.NumericScanner, used when scanning NUMERIC columns
+func (a *Amount) ScanNumeric(n pgtype.Numeric) error {
+	return scanNumericFixed(n, (*int64)(a))
+}
+
+// NumericValue implements pgtype.NumericValuer, used when encoding query arguments
+func (a Amount) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(a)), Exp: -2, Valid: true}, nil
+}
+
+// Scan implements sql.Scanner for drivers that hand over plain Go values
+func (a *Amount) Scan(src any) error {
+	return scanFixed(src, (*int64)(a))
+}
+
+func unmarshalFixed(data []byte, dest *int64) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	value, err := parseFixed(s)
+	if err != nil {
+		return err
+	}
+	*dest = value
+	return nil
+}
+
+// scanNumericFixed stores the numeric in hundredths, rounding extra decimals
+func scanNumericFixed(n pgtype.Numeric, dest *int64) error {
+	if !n.Valid {
+		*dest = 0
+		return nil
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return ErrInvalidAmount
+	}
+
+	// value = Int * 10^Exp, hundredths = Int * 10^(Exp+2)
+	value := new(big.Int).Set(n.Int)
+	exp := n.Exp + 2
+	if exp >= 0 {
+		value.Mul(value, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+		if !value.IsInt64() {
+			return ErrInvalidAmount
+		}
+		*dest = value.Int64()
+		return nil
+	}
+
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-exp)), nil)
+	*dest = divRound(value, big.NewInt(1), divisor)
+	return nil
+}
+
+func scanFixed(src any, dest *int64) error {
+	switch v := src.(type) {
+	case nil:
+		*dest = 0
+	case float64:
+		*dest = int64(math.Round(v * 100))
+	case int64:
+		*dest = v * 100
+	case int:
+		*dest = int64(v) * 100
+	case string:
+		value, err := parseFixed(v)
+		if err != nil {
+			return err
+		}
+		*dest = value
+	case []byte:
+		value, err := parseFixed(string(v))
+		if err != nil {
+			return err
+		}
+		*dest = value
+	case pgtype.Numeric:
+		return scanNumericFixed(v, dest)
+	default:
+		return fmt.Errorf("cannot scan %T into fixed point value", src)
+	}
+	return nil
+}
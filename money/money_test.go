@@ -0,0 +1,129 @@
+package money
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]Amount{
+		"1500":     150000,
+		"19999.99": 1999999,
+		"12.5":     1250,
+		".75":      75,
+		"-3.10":    -310,
+		"0.005":    1, // halves round away from zero
+		"-0.005":   -1,
+		"2.994":    299,
+	}
+	for input, want := range cases {
+		got, err := Parse(input)
+		require.NoError(t, err, input)
+		require.Equal(t, want, got, input)
+	}
+
+	for _, input := range []string{"", "-", ".", "abc", "1.2.3", "1e5"} {
+		_, err := Parse(input)
+		require.ErrorIs(t, err, ErrInvalidAmount, input)
+	}
+}
+
+func TestAmount_Arithmetic(t *testing.T) {
+	// 0.1 + 0.2 stays exact, unlike float64
+	var total Amount
+	for _, price := range []string{"0.10", "0.20"} {
+		amount, err := Parse(price)
+		require.NoError(t, err)
+		total += amount
+	}
+	require.Equal(t, "0.30", total.String())
+
+	require.Equal(t, Amount(3999998), Amount(1999999).Mul(2))
+	require.Equal(t, "-12.05", Amount(-1205).String())
+}
+
+func TestAmount_JSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Price Amount `json:"price"`
+	}{Price: 1500050})
+	require.NoError(t, err)
+	require.Equal(t, `{"price":15000.50}`, string(data))
+
+	var req struct {
+		Price Amount `json:"price"`
+		Cost  Amount `json:"cost"`
+	}
+	err = json.Unmarshal([]byte(`{"price": 19999.99, "cost": "12.30"}`), &req)
+	require.NoError(t, err)
+	require.Equal(t, Amount(1999999), req.Price)
+	require.Equal(t, Amount(1230), req.Cost)
+
+	err = json.Unmarshal([]byte(`{"price": "abc"}`), &req)
+	require.Error(t, err)
+}
+
+func TestAmount_ScanNumeric(t *testing.T) {
+	var a Amount
+
+	// NUMERIC(15,2) value 1234.56
+	require.NoError(t, a.ScanNumeric(pgtype.Numeric{Int: big.NewInt(123456), Exp: -2, Valid: true}))
+	require.Equal(t, Amount(123456), a)
+
+	// Whole number sent with positive exponent, 15 * 10^3
+	require.NoError(t, a.ScanNumeric(pgtype.Numeric{Int: big.NewInt(15), Exp: 3, Valid: true}))
+	require.Equal(t, Amount(1500000), a)
+
+	// Aggregates with more decimals round to the cent, 2.345 -> 2.35
+	require.NoError(t, a.ScanNumeric(pgtype.Numeric{Int: big.NewInt(2345), Exp: -3, Valid: true}))
+	require.Equal(t, Amount(235), a)
+	require.NoError(t, a.ScanNumeric(pgtype.Numeric{Int: big.NewInt(-2345), Exp: -3, Valid: true}))
+	require.Equal(t, Amount(-235), a)
+
+	require.NoError(t, a.ScanNumeric(pgtype.Numeric{}))
+	require.Equal(t, Amount(0), a)
+
+	require.Error(t, a.ScanNumeric(pgtype.Numeric{NaN: true, Valid: true}))
+}
+
+func TestAmount_NumericValue(t *testing.T) {
+	n, err := Amount(123456).NumericValue()
+	require.NoError(t, err)
+	require.Equal(t, int64(123456), n.Int.Int64())
+	require.Equal(t, int32(-2), n.Exp)
+	require.True(t, n.Valid)
+}
+
+func TestAmount_Percent(t *testing.T) {
+	require.Equal(t, Amount(11000), Amount(100000).Percent(1100)) // 11% of 1000.00
+	require.Equal(t, Amount(14), Amount(125).Percent(1100))       // 0.1375 -> 0.14
+	require.Equal(t, Amount(-14), Amount(-125).Percent(1100))     // halves away from zero
+	require.Equal(t, Amount(33), Amount(100).Percent(3333))       // 0.3333 -> 0.33
+	require.Equal(t, Amount(100000), Amount(100000).Percent(MaxRate))
+}
+
+func TestAmount_Allocate(t *testing.T) {
+	// 100.00 over three equal lines, shares add up exactly
+	shares := Amount(10000).Allocate([]int64{1, 1, 1})
+	require.Equal(t, []Amount{3333, 3334, 3333}, shares)
+
+	shares = Amount(1000).Allocate([]int64{300000, 100000})
+	require.Equal(t, []Amount{750, 250}, shares)
+
+	require.Equal(t, []Amount{0, 0}, Amount(1000).Allocate([]int64{0, 0}))
+}
+
+func TestRate_JSON(t *testing.T) {
+	var req struct {
+		TaxRate Rate `json:"tax_rate"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"tax_rate": 11}`), &req))
+	require.Equal(t, Rate(1100), req.TaxRate)
+
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+	require.Equal(t, `{"tax_rate":11.00}`, string(data))
+}
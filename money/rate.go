@@ -0,0 +1,47 @@
+package money
+
+import (
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Rate is a percentage in hundredths of a percent, 1100 is 11.00%
+type Rate int64
+
+// MaxRate is 100%
+const MaxRate Rate = 10000
+
+// ParseRate reads a percentage such as "11" or "12.5"
+func ParseRate(s string) (Rate, error) {
+	value, err := parseFixed(s)
+	return Rate(value), err
+}
+
+// String formats the rate with exactly two decimals, e.g. "11.00"
+func (r Rate) String() string {
+	return formatFixed(int64(r))
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	return unmarshalFixed(data, (*int64)(r))
+}
+
+// ScanNumeric implements pgtype.NumericScanner
+func (r *Rate) ScanNumeric(n pgtype.Numeric) error {
+	return scanNumericFixed(n, (*int64)(r))
+}
+
+// NumericValue implements pgtype.NumericValuer
+func (r Rate) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(r)), Exp: -2, Valid: true}, nil
+}
+
+// Scan implements sql.Scanner
+func (r *Rate) Scan(src any) error {
+	return scanFixed(src, (*int64)(r))
+}
@@ -15,6 +15,7 @@ type CategoryRepository interface {
 	FindByID(id int) (*model.Category, error)
 	FindByName(name string) (*model.Category, error)
 	FindAll(page, limit int) ([]model.Category, int, error)
+	FindAfter(afterID, limit int) ([]model.Category, error)
 	Update(id int, data *model.Category) error
 	Delete(id int) error
 }
@@ -30,12 +31,12 @@ func NewCategoryRepository(db database.PgxIface, log *zap.Logger) CategoryReposi
 
 func (r *categoryRepository) Create(category *model.Category) error {
 	query := `
-		INSERT INTO categories (name, description, created_at, updated_at)
-		VALUES ($1, $2, NOW(), NOW())
+		INSERT INTO categories (name, description, tax_rate, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
 		RETURNING id, created_at, updated_at
 	`
 	err := r.db.QueryRow(context.Background(), query,
-		category.Name, category.Description,
+		category.Name, category.Description, category.TaxRate,
 	).Scan(&category.ID, &category.CreatedAt, &category.UpdatedAt)
 
 	if err != nil {
@@ -46,13 +47,13 @@ func (r *categoryRepository) Create(category *model.Category) error {
 
 func (r *categoryRepository) FindByID(id int) (*model.Category, error) {
 	query := `
-		SELECT id, name, description, created_at, updated_at
+		SELECT id, name, description, tax_rate, created_at, updated_at
 		FROM categories 
 		WHERE id = $1
 	`
 	var category model.Category
 	err := r.db.QueryRow(context.Background(), query, id).Scan(
-		&category.ID, &category.Name, &category.Description,
+		&category.ID, &category.Name, &category.Description, &category.TaxRate,
 		&category.CreatedAt, &category.UpdatedAt,
 	)
 
@@ -68,13 +69,13 @@ func (r *categoryRepository) FindByID(id int) (*model.Category, error) {
 
 func (r *categoryRepository) FindByName(name string) (*model.Category, error) {
 	query := `
-		SELECT id, name, description, created_at, updated_at
+		SELECT id, name, description, tax_rate, created_at, updated_at
 		FROM categories 
 		WHERE name = $1
 	`
 	var category model.Category
 	err := r.db.QueryRow(context.Background(), query, name).Scan(
-		&category.ID, &category.Name, &category.Description,
+		&category.ID, &category.Name, &category.Description, &category.TaxRate,
 		&category.CreatedAt, &category.UpdatedAt,
 	)
 
@@ -102,7 +103,7 @@ func (r *categoryRepository) FindAll(page, limit int) ([]model.Category, int, er
 
 	// Get data with pagination
 	query := `
-		SELECT id, name, description, created_at, updated_at
+		SELECT id, name, description, tax_rate, created_at, updated_at
 		FROM categories
 		ORDER BY name ASC
 		LIMIT $1 OFFSET $2
@@ -118,7 +119,7 @@ func (r *categoryRepository) FindAll(page, limit int) ([]model.Category, int, er
 	for rows.Next() {
 		var category model.Category
 		err := rows.Scan(
-			&category.ID, &category.Name, &category.Description,
+			&category.ID, &category.Name, &category.Description, &category.TaxRate,
 			&category.CreatedAt, &category.UpdatedAt,
 		)
 		if err != nil {
@@ -131,14 +132,48 @@ func (r *categoryRepository) FindAll(page, limit int) ([]model.Category, int, er
 	return categories, total, nil
 }
 
+// FindAfter reads up to limit categories with an id above afterID in id order. Exports
+// page with it, a page can't skip or repeat categories added or removed meanwhile.
+func (r *categoryRepository) FindAfter(afterID, limit int) ([]model.Category, error) {
+	query := `
+		SELECT id, name, description, tax_rate, created_at, updated_at
+		FROM categories
+		WHERE id > $1
+		ORDER BY id ASC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(context.Background(), query, afterID, limit)
+	if err != nil {
+		r.Logger.Error("error querying categories after id", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []model.Category
+	for rows.Next() {
+		var category model.Category
+		err := rows.Scan(
+			&category.ID, &category.Name, &category.Description, &category.TaxRate,
+			&category.CreatedAt, &category.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning category", zap.Error(err))
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, nil
+}
+
 func (r *categoryRepository) Update(id int, data *model.Category) error {
 	query := `
 		UPDATE categories
-		SET name = $1, description = $2, updated_at = NOW()
-		WHERE id = $3
+		SET name = $1, description = $2, tax_rate = $3, updated_at = NOW()
+		WHERE id = $4
 	`
 	result, err := r.db.Exec(context.Background(), query,
-		data.Name, data.Description, id,
+		data.Name, data.Description, data.TaxRate, id,
 	)
 	if err != nil {
 		r.Logger.Error("error updating category", zap.Error(err))
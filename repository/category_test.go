@@ -3,6 +3,7 @@ package repository
 import (
 	"errors"
 	"project-app-inventory/model"
+	"project-app-inventory/money"
 	"testing"
 	"time"
 
@@ -19,14 +20,16 @@ func TestCategoryRepository_Create_Success(t *testing.T) {
 	repo := NewCategoryRepository(mockDB, zap.NewNop())
 
 	desc := "Electronics and gadgets"
+	taxRate := money.Rate(1100)
 	category := &model.Category{
 		Name:        "Electronics",
 		Description: &desc,
+		TaxRate:     &taxRate,
 	}
 
 	mockDB.
 		ExpectQuery(`INSERT INTO categories`).
-		WithArgs(category.Name, category.Description).
+		WithArgs(category.Name, category.Description, category.TaxRate).
 		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).
 			AddRow(1, time.Now(), time.Now()))
 
@@ -51,7 +54,7 @@ func TestCategoryRepository_Create_Error(t *testing.T) {
 
 	mockDB.
 		ExpectQuery(`INSERT INTO categories`).
-		WithArgs(category.Name, category.Description).
+		WithArgs(category.Name, category.Description, category.TaxRate).
 		WillReturnError(errors.New("database error"))
 
 	err = repo.Create(category)
@@ -69,17 +72,19 @@ func TestCategoryRepository_FindByID_Success(t *testing.T) {
 	repo := NewCategoryRepository(mockDB, zap.NewNop())
 
 	desc := "Test description"
+	taxRate := money.Rate(1100)
 	mockDB.
 		ExpectQuery(`SELECT (.+) FROM categories WHERE id`).
 		WithArgs(1).
-		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
-			AddRow(1, "Electronics", &desc, time.Now(), time.Now()))
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description", "tax_rate", "created_at", "updated_at"}).
+			AddRow(1, "Electronics", &desc, &taxRate, time.Now(), time.Now()))
 
 	category, err := repo.FindByID(1)
 	require.NoError(t, err)
 	require.NotNil(t, category)
 	require.Equal(t, 1, category.ID)
 	require.Equal(t, "Electronics", category.Name)
+	require.Equal(t, money.Rate(1100), *category.TaxRate)
 
 	require.NoError(t, mockDB.ExpectationsWereMet())
 }
@@ -94,8 +99,8 @@ func TestCategoryRepository_FindByName_Success(t *testing.T) {
 	mockDB.
 		ExpectQuery(`SELECT (.+) FROM categories WHERE name`).
 		WithArgs("Electronics").
-		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at"}).
-			AddRow(1, "Electronics", nil, time.Now(), time.Now()))
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "description", "tax_rate", "created_at", "updated_at"}).
+			AddRow(1, "Electronics", nil, nil, time.Now(), time.Now()))
 
 	category, err := repo.FindByName("Electronics")
 	require.NoError(t, err)
@@ -120,7 +125,7 @@ func TestCategoryRepository_Update_Success(t *testing.T) {
 
 	mockDB.
 		ExpectExec(`UPDATE categories`).
-		WithArgs(category.Name, category.Description, 1).
+		WithArgs(category.Name, category.Description, category.TaxRate, 1).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
 	err = repo.Update(1, category)
@@ -143,7 +148,7 @@ func TestCategoryRepository_Update_Error(t *testing.T) {
 
 	mockDB.
 		ExpectExec(`UPDATE categories`).
-		WithArgs(category.Name, category.Description, 999).
+		WithArgs(category.Name, category.Description, category.TaxRate, 999).
 		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
 
 	err = repo.Update(999, category)
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+)
+
+// applyCost keeps the cost of an item in step with a stock movement that has
+// already changed items.stock. Incoming stock is averaged into the item's
+// average cost and added as a FIFO cost layer; outgoing stock leaves the
+// average cost alone, consumes the oldest layers and reports its cost under
+// both methods on movement.Cost. Layers brought in by the movement's own
+// document are consumed first, so voiding a receipt takes out the stock it
+// received. Outgoing stock with a unit cost goes back where it came from and
+// is also taken out of the average at that cost. Units in transit between
+// racks are still part of items.stock, so they keep their weight in the
+// average while they are on the way.
+func applyCost(ctx context.Context, tx database.PgxIface, movement *model.StockMovement) error {
+	if movement.Quantity > 0 {
+		// The items row is locked by the stock update, so the average and the
+		// layers of an item change one movement at a time
+		incomingQuery := `
+			WITH cost AS (
+				SELECT COALESCE($3::numeric, average_cost) AS unit_cost FROM items WHERE id = $1
+			), average AS (
+				UPDATE items
+				SET average_cost = ROUND(((items.stock - $2) * items.average_cost + $2 * cost.unit_cost) / items.stock, 2)
+				FROM cost
+				WHERE items.id = $1
+			), layer AS (
+				INSERT INTO cost_layers (item_id, unit_cost, quantity, remaining, reference_type, reference_id, created_at)
+				SELECT $1, unit_cost, $2, $2, $4, $5, NOW() FROM cost
+			)
+			SELECT unit_cost FROM cost
+		`
+		var unitCost money.Amount
+		err := tx.QueryRow(ctx, incomingQuery,
+			movement.ItemID, movement.Quantity, movement.UnitCost, movement.ReferenceType, movement.ReferenceID,
+		).Scan(&unitCost)
+		if err != nil {
+			return err
+		}
+		movement.UnitCost = &unitCost
+		return nil
+	}
+
+	quantity := -movement.Quantity
+	outgoingQuery := `
+		WITH layers AS (
+			SELECT id, remaining, unit_cost,
+			       SUM(remaining) OVER (
+			           ORDER BY COALESCE(reference_type = $3 AND reference_id = $4, FALSE) DESC, created_at, id
+			       ) - remaining AS taken_before
+			FROM cost_layers
+			WHERE item_id = $1 AND remaining > 0
+		), taken AS (
+			SELECT id, LEAST(remaining, $2 - taken_before) AS quantity, unit_cost
+			FROM layers
+			WHERE taken_before < $2
+		), consumed AS (
+			UPDATE cost_layers
+			SET remaining = cost_layers.remaining - taken.quantity
+			FROM taken
+			WHERE cost_layers.id = taken.id
+			RETURNING taken.quantity, taken.unit_cost
+		), average AS (
+			UPDATE items
+			SET average_cost = CASE
+			    WHEN $5::numeric IS NULL OR stock = 0 THEN average_cost
+			    ELSE GREATEST(ROUND(((stock + $2) * average_cost - $2 * $5) / stock, 2), 0)
+			END
+			WHERE id = $1
+		)
+		SELECT i.average_cost,
+		       COALESCE((SELECT SUM(quantity) FROM consumed), 0),
+		       COALESCE((SELECT SUM(quantity * unit_cost) FROM consumed), 0)
+		FROM items i
+		WHERE i.id = $1
+	`
+	var averageCost, layeredCost money.Amount
+	var layeredQuantity int
+	err := tx.QueryRow(ctx, outgoingQuery,
+		movement.ItemID, quantity, movement.ReferenceType, movement.ReferenceID, movement.UnitCost,
+	).Scan(&averageCost, &layeredQuantity, &layeredCost)
+	if err != nil {
+		return err
+	}
+
+	if movement.UnitCost != nil {
+		cost := movement.UnitCost.Mul(quantity)
+		movement.Cost = model.StockCost{Average: cost, FIFO: cost}
+		return nil
+	}
+
+	// Stock older than its layers, if any, costs the average
+	movement.Cost = model.StockCost{
+		Average: averageCost.Mul(quantity),
+		FIFO:    layeredCost + averageCost.Mul(quantity-layeredQuantity),
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyCost_OutgoingAtUnitCost(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	// A voided receipt takes its units back out at the cost they came in at
+	referenceType := model.ReferenceTypeReceipt
+	receiptID := 9
+	unitCost := money.FromUnits(50000)
+	movement := &model.StockMovement{
+		ItemID: 3, RackID: 1, MovementType: model.MovementTypeReceiptVoid, Quantity: -4,
+		ReferenceType: &referenceType, ReferenceID: &receiptID, UnitCost: &unitCost,
+	}
+
+	mockDB.
+		ExpectQuery(`UPDATE cost_layers`).
+		WithArgs(3, 4, &referenceType, &receiptID, &unitCost).
+		WillReturnRows(pgxmock.NewRows([]string{"average_cost", "layered_quantity", "layered_cost"}).
+			AddRow(money.FromUnits(45000), 4, money.FromUnits(200000)))
+
+	err = applyCost(context.Background(), mockDB, movement)
+	require.NoError(t, err)
+	require.Equal(t, money.FromUnits(200000), movement.Cost.Average)
+	require.Equal(t, money.FromUnits(200000), movement.Cost.FIFO)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestApplyCost_IncomingAtUnitCost(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	unitCost := money.FromUnits(52000)
+	movement := &model.StockMovement{ItemID: 3, RackID: 1, MovementType: model.MovementTypeReceipt, Quantity: 5, UnitCost: &unitCost}
+
+	mockDB.
+		ExpectQuery(`INSERT INTO cost_layers`).
+		WithArgs(3, 5, &unitCost, movement.ReferenceType, movement.ReferenceID).
+		WillReturnRows(pgxmock.NewRows([]string{"unit_cost"}).AddRow(unitCost))
+
+	err = applyCost(context.Background(), mockDB, movement)
+	require.NoError(t, err)
+	require.Equal(t, unitCost, *movement.UnitCost)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestApplyCost_ReceiptWhileInTransit(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	// 10 units on hand at 5 are all dispatched, then 10 are received at 7
+	dispatch := &model.StockMovement{ItemID: 3, UserID: 2, RackID: 1, MovementType: model.MovementTypeTransferOut, Quantity: -10}
+	unitCost := money.FromUnits(7)
+	receipt := &model.StockMovement{ItemID: 3, UserID: 2, RackID: 2, MovementType: model.MovementTypeReceipt, Quantity: 10, UnitCost: &unitCost}
+
+	// The dispatch leaves the stock and its cost alone
+	mockDB.
+		ExpectExec(`UPDATE item_locations`).
+		WithArgs(3, 1, -10).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockDB.
+		ExpectQuery(`UPDATE items SET in_transit`).
+		WithArgs(-10, 3).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(10))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(3, 2, 1, model.MovementTypeTransferOut, -10, 10,
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	// The receipt is averaged over all 20 units, the 10 in transit included:
+	// (10 * 5 + 10 * 7) / 20 = 6
+	mockDB.
+		ExpectExec(`INSERT INTO item_locations`).
+		WithArgs(3, 2, 10).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockDB.
+		ExpectQuery(`UPDATE items SET stock = stock \+ \$1`).
+		WithArgs(10, 3).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(20))
+	mockDB.
+		ExpectQuery(`SET average_cost = ROUND\(\(\(items.stock - \$2\) \* items.average_cost \+ \$2 \* cost.unit_cost\) / items.stock, 2\)`).
+		WithArgs(3, 10, &unitCost, receipt.ReferenceType, receipt.ReferenceID).
+		WillReturnRows(pgxmock.NewRows([]string{"unit_cost"}).AddRow(unitCost))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(3, 2, 2, model.MovementTypeReceipt, 10, 20,
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(2, time.Now()))
+
+	require.NoError(t, applyStockMovement(context.Background(), mockDB, dispatch))
+	require.NoError(t, applyStockMovement(context.Background(), mockDB, receipt))
+	require.Equal(t, 20, receipt.BalanceAfter)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
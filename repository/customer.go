@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type CustomerRepository interface {
+	Create(customer *model.Customer) error
+	FindByID(id int) (*model.Customer, error)
+	FindByEmail(email string) (*model.Customer, error)
+	FindAll(page, limit int) ([]model.Customer, int, error)
+	GetSalesSummary(customerID int) (*model.CustomerSalesSummary, error)
+	Update(id int, data *model.Customer) error
+	Delete(id int) error
+}
+
+type customerRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewCustomerRepository(db database.PgxIface, log *zap.Logger) CustomerRepository {
+	return &customerRepository{db: db, Logger: log}
+}
+
+func (r *customerRepository) Create(customer *model.Customer) error {
+	query := `
+		INSERT INTO customers (name, email, phone, address, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRow(context.Background(), query,
+		customer.Name, customer.Email, customer.Phone, customer.Address,
+	).Scan(&customer.ID, &customer.CreatedAt, &customer.UpdatedAt)
+
+	if err != nil {
+		r.Logger.Error("error creating customer", zap.Error(err))
+	}
+	return err
+}
+
+func (r *customerRepository) FindByID(id int) (*model.Customer, error) {
+	query := `
+		SELECT id, name, email, phone, address, created_at, updated_at
+		FROM customers
+		WHERE id = $1
+	`
+	var customer model.Customer
+	err := r.db.QueryRow(context.Background(), query, id).Scan(
+		&customer.ID, &customer.Name, &customer.Email, &customer.Phone,
+		&customer.Address, &customer.CreatedAt, &customer.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.Error("error finding customer by id", zap.Error(err))
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (r *customerRepository) FindByEmail(email string) (*model.Customer, error) {
+	query := `
+		SELECT id, name, email, phone, address, created_at, updated_at
+		FROM customers
+		WHERE email = $1
+	`
+	var customer model.Customer
+	err := r.db.QueryRow(context.Background(), query, email).Scan(
+		&customer.ID, &customer.Name, &customer.Email, &customer.Phone,
+		&customer.Address, &customer.CreatedAt, &customer.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.Error("error finding customer by email", zap.Error(err))
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (r *customerRepository) FindAll(page, limit int) ([]model.Customer, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count
+	var total int
+	countQuery := `SELECT COUNT(*) FROM customers`
+	err := r.db.QueryRow(context.Background(), countQuery).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting customers", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := `
+		SELECT id, name, email, phone, address, created_at, updated_at
+		FROM customers
+		ORDER BY name ASC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.db.Query(context.Background(), query, limit, offset)
+	if err != nil {
+		r.Logger.Error("error querying customers", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var customers []model.Customer
+	for rows.Next() {
+		var customer model.Customer
+		err := rows.Scan(
+			&customer.ID, &customer.Name, &customer.Email, &customer.Phone,
+			&customer.Address, &customer.CreatedAt, &customer.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning customer", zap.Error(err))
+			return nil, 0, err
+		}
+		customers = append(customers, customer)
+	}
+
+	return customers, total, nil
+}
+
+// GetSalesSummary returns the lifetime revenue of the customer over sales that
+// are not voided, net of refunds for returned goods
+func (r *customerRepository) GetSalesSummary(customerID int) (*model.CustomerSalesSummary, error) {
+	query := `
+		SELECT COUNT(*),
+		       COALESCE(SUM(s.total_amount), 0) - COALESCE((
+		           SELECT SUM(sr.refund_amount)
+		           FROM sale_returns sr
+		           JOIN sales rs ON rs.id = sr.sale_id
+		           WHERE rs.customer_id = $1 AND rs.deleted_at IS NULL
+		       ), 0),
+		       MIN(s.created_at), MAX(s.created_at)
+		FROM sales s
+		WHERE s.customer_id = $1 AND s.deleted_at IS NULL
+	`
+	summary := model.CustomerSalesSummary{CustomerID: customerID}
+	err := r.db.QueryRow(context.Background(), query, customerID).Scan(
+		&summary.TotalSales, &summary.LifetimeRevenue, &summary.FirstSaleAt, &summary.LastSaleAt,
+	)
+	if err != nil {
+		r.Logger.Error("error getting customer sales summary", zap.Error(err))
+		return nil, err
+	}
+	return &summary, nil
+}
+
+func (r *customerRepository) Update(id int, data *model.Customer) error {
+	query := `
+		UPDATE customers
+		SET name = $1, email = $2, phone = $3, address = $4, updated_at = NOW()
+		WHERE id = $5
+	`
+	result, err := r.db.Exec(context.Background(), query,
+		data.Name, data.Email, data.Phone, data.Address, id,
+	)
+	if err != nil {
+		r.Logger.Error("error updating customer", zap.Error(err))
+		return err
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.New("customer not found")
+	}
+	return nil
+}
+
+func (r *customerRepository) Delete(id int) error {
+	query := `
+		DELETE FROM customers
+		WHERE id = $1
+	`
+	result, err := r.db.Exec(context.Background(), query, id)
+	if err != nil {
+		r.Logger.Error("error deleting customer", zap.Error(err))
+		return err
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.New("customer not found")
+	}
+	return nil
+}
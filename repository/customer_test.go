@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestCustomerRepository_Create_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewCustomerRepository(mockDB, zap.NewNop())
+
+	email := "budi@example.com"
+	customer := &model.Customer{
+		Name:  "Budi Santoso",
+		Email: &email,
+	}
+
+	mockDB.
+		ExpectQuery(`INSERT INTO customers`).
+		WithArgs(customer.Name, customer.Email, customer.Phone, customer.Address).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).
+			AddRow(3, time.Now(), time.Now()))
+
+	err = repo.Create(customer)
+	require.NoError(t, err)
+	require.Equal(t, 3, customer.ID)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestCustomerRepository_GetSalesSummary_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewCustomerRepository(mockDB, zap.NewNop())
+
+	first := time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC)
+	last := time.Date(2024, 3, 1, 15, 30, 0, 0, time.UTC)
+
+	mockDB.
+		ExpectQuery(`SELECT COUNT\(\*\), COALESCE\(SUM\(s.total_amount\), 0\) - COALESCE\((.+)FROM sale_returns sr`).
+		WithArgs(1).
+		WillReturnRows(pgxmock.NewRows([]string{"count", "sum", "min", "max"}).
+			AddRow(2, money.FromUnits(2750000), &first, &last))
+
+	summary, err := repo.GetSalesSummary(1)
+	require.NoError(t, err)
+	require.Equal(t, 1, summary.CustomerID)
+	require.Equal(t, 2, summary.TotalSales)
+	require.Equal(t, money.FromUnits(2750000), summary.LifetimeRevenue)
+	require.Equal(t, first, *summary.FirstSaleAt)
+	require.Equal(t, last, *summary.LastSaleAt)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestCustomerRepository_Delete_NotFound(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewCustomerRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectExec(`DELETE FROM customers`).
+		WithArgs(99).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	err = repo.Delete(99)
+	require.Error(t, err)
+	require.Equal(t, "customer not found", err.Error())
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
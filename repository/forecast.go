@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type ForecastRepository interface {
+	FindDailySales(itemIDs []int, from, to time.Time) ([]model.DailySales, error)
+}
+
+type forecastRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewForecastRepository(db database.PgxIface, log *zap.Logger) ForecastRepository {
+	return &forecastRepository{db: db, Logger: log}
+}
+
+// FindDailySales sums the units of the items sold per UTC day from from up to
+// but not including to, by item and day, less the units returned to stock like
+// the reorder suggestions do; damaged returns never refill the stock so they
+// still count as sold. Days without sales are left out.
+func (r *forecastRepository) FindDailySales(itemIDs []int, from, to time.Time) ([]model.DailySales, error) {
+	query := `
+		WITH restocked AS (
+			SELECT sale_item_id, SUM(quantity) AS quantity
+			FROM sale_return_items
+			WHERE condition = 'restock'
+			GROUP BY sale_item_id
+		)
+		SELECT si.item_id, (s.created_at AT TIME ZONE 'UTC')::date AS day, SUM(si.quantity - COALESCE(rs.quantity, 0))
+		FROM sale_items si
+		JOIN sales s ON s.id = si.sale_id
+		LEFT JOIN restocked rs ON rs.sale_item_id = si.id
+		WHERE s.deleted_at IS NULL AND si.item_id = ANY($1) AND s.created_at >= $2 AND s.created_at < $3
+		GROUP BY 1, 2
+		ORDER BY 1 ASC, 2 ASC
+	`
+	rows, err := r.db.Query(context.Background(), query, itemIDs, from, to)
+	if err != nil {
+		r.Logger.Error("error querying daily sales", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []model.DailySales
+	for rows.Next() {
+		var day model.DailySales
+		err := rows.Scan(&day.ItemID, &day.Day, &day.Units)
+		if err != nil {
+			r.Logger.Error("error scanning daily sales", zap.Error(err))
+			return nil, err
+		}
+		sales = append(sales, day)
+	}
+
+	return sales, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestForecastRepository_FindDailySales(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewForecastRepository(mockDB, zap.NewNop())
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mockDB.
+		ExpectQuery(`WITH restocked AS (.+) WHERE condition = 'restock' (.+) SELECT si.item_id, \(s.created_at AT TIME ZONE 'UTC'\)::date AS day, SUM\(si.quantity - COALESCE\(rs.quantity, 0\)\) (.+) GROUP BY 1, 2`).
+		WithArgs([]int{1, 2}, from, to).
+		WillReturnRows(pgxmock.NewRows([]string{"item_id", "day", "units"}).
+			AddRow(1, from, 4).
+			AddRow(2, from.AddDate(0, 0, 3), 7))
+
+	sales, err := repo.FindDailySales([]int{1, 2}, from, to)
+	require.NoError(t, err)
+	require.Len(t, sales, 2)
+	require.Equal(t, 2, sales[1].ItemID)
+	require.Equal(t, 7, sales[1].Units)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type IdempotencyKeyRepository interface {
+	Reserve(key *model.IdempotencyKey, lease time.Duration) (bool, error)
+	FindByKey(userID int, key string) (*model.IdempotencyKey, error)
+	SaveResponse(id int, token string, statusCode int, header map[string][]string, body []byte) error
+	Delete(id int, token string) error
+	DeleteExpired() (int64, error)
+}
+
+type idempotencyKeyRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewIdempotencyKeyRepository(db database.PgxIface, log *zap.Logger) IdempotencyKeyRepository {
+	return &idempotencyKeyRepository{db: db, Logger: log}
+}
+
+// Reserve claims the key for a new request. It returns false when the user
+// already holds the key and it has not expired yet. An expired key is taken
+// over as if it never existed, and so is a key whose request is still without
+// a response after lease: its server went down before it could answer. A
+// takeover keeps the row id but stores key.Token, so the request that lost the
+// key can no longer store or free it.
+func (r *idempotencyKeyRepository) Reserve(key *model.IdempotencyKey, lease time.Duration) (bool, error) {
+	query := `
+		INSERT INTO idempotency_keys (user_id, key, method, path, request_hash, token, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), $7)
+		ON CONFLICT (user_id, key) DO UPDATE
+		SET method = EXCLUDED.method, path = EXCLUDED.path, request_hash = EXCLUDED.request_hash, token = EXCLUDED.token,
+		    status_code = NULL, response_headers = NULL, response_body = NULL, created_at = NOW(), expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+		   OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at <= NOW() - $8 * INTERVAL '1 second')
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRow(context.Background(), query,
+		key.UserID, key.Key, key.Method, key.Path, key.RequestHash, key.Token, key.ExpiresAt, int(lease.Seconds()),
+	).Scan(&key.ID, &key.CreatedAt)
+
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		r.Logger.Error("error reserving idempotency key", zap.Error(err))
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *idempotencyKeyRepository) FindByKey(userID int, key string) (*model.IdempotencyKey, error) {
+	query := `
+		SELECT id, user_id, key, method, path, request_hash, status_code, response_headers, response_body, created_at, expires_at
+		FROM idempotency_keys
+		WHERE user_id = $1 AND key = $2
+	`
+	var record model.IdempotencyKey
+	err := r.db.QueryRow(context.Background(), query, userID, key).Scan(
+		&record.ID, &record.UserID, &record.Key, &record.Method, &record.Path, &record.RequestHash,
+		&record.StatusCode, &record.ResponseHeader, &record.ResponseBody, &record.CreatedAt, &record.ExpiresAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.Error("error finding idempotency key", zap.Error(err))
+		return nil, err
+	}
+	return &record, nil
+}
+
+// SaveResponse stores the response of the finished request for later replays.
+// It fails when the key was taken over under another token in the meantime.
+func (r *idempotencyKeyRepository) SaveResponse(id int, token string, statusCode int, header map[string][]string, body []byte) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, response_headers = $2, response_body = $3
+		WHERE id = $4 AND token = $5
+	`
+	result, err := r.db.Exec(context.Background(), query, statusCode, header, body, id, token)
+	if err != nil {
+		r.Logger.Error("error saving idempotency response", zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("idempotency key not found")
+	}
+	return nil
+}
+
+// Delete frees the key, unless it was taken over under another token
+func (r *idempotencyKeyRepository) Delete(id int, token string) error {
+	query := `DELETE FROM idempotency_keys WHERE id = $1 AND token = $2`
+	_, err := r.db.Exec(context.Background(), query, id, token)
+	if err != nil {
+		r.Logger.Error("error deleting idempotency key", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteExpired removes the keys past their expiry, which are never replayed
+// again, and returns how many were removed
+func (r *idempotencyKeyRepository) DeleteExpired() (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at <= NOW()`
+	result, err := r.db.Exec(context.Background(), query)
+	if err != nil {
+		r.Logger.Error("error deleting expired idempotency keys", zap.Error(err))
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"project-app-inventory/model"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestIdempotencyKeyRepository_Reserve_New(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewIdempotencyKeyRepository(mockDB, zap.NewNop())
+
+	key := &model.IdempotencyKey{
+		UserID: 3, Key: "abc", Method: "POST", Path: "/api/v1/sales",
+		RequestHash: "hash", Token: "token-1", ExpiresAt: time.Now().Add(time.Hour),
+	}
+	mockDB.
+		ExpectQuery(`INSERT INTO idempotency_keys (.+) ON CONFLICT \(user_id, key\) DO UPDATE (.+) token = EXCLUDED.token`).
+		WithArgs(3, "abc", "POST", "/api/v1/sales", "hash", "token-1", key.ExpiresAt, 300).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(9, time.Now()))
+
+	reserved, err := repo.Reserve(key, 5*time.Minute)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	require.Equal(t, 9, key.ID)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestIdempotencyKeyRepository_Reserve_Taken(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewIdempotencyKeyRepository(mockDB, zap.NewNop())
+
+	// A live key makes the conditional upsert return no row
+	mockDB.
+		ExpectQuery(`INSERT INTO idempotency_keys`).
+		WithArgs(3, "abc", "POST", "/api/v1/sales", "hash", "token-1", pgxmock.AnyArg(), 300).
+		WillReturnError(pgx.ErrNoRows)
+
+	reserved, err := repo.Reserve(&model.IdempotencyKey{
+		UserID: 3, Key: "abc", Method: "POST", Path: "/api/v1/sales", RequestHash: "hash", Token: "token-1",
+	}, 5*time.Minute)
+	require.NoError(t, err)
+	require.False(t, reserved)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestIdempotencyKeyRepository_SaveResponse(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewIdempotencyKeyRepository(mockDB, zap.NewNop())
+
+	body := []byte(`{"status":true}`)
+	header := map[string][]string{"Content-Type": {"application/json"}}
+	mockDB.
+		ExpectExec(`UPDATE idempotency_keys (.+) WHERE id = \$4 AND token = \$5`).
+		WithArgs(201, header, body, 9, "token-1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	err = repo.SaveResponse(9, "token-1", 201, header, body)
+	require.NoError(t, err)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+// TestIdempotencyKeyRepository_SaveResponse_TakenOver tests a request can't store
+// its response once another reservation took the key over
+func TestIdempotencyKeyRepository_SaveResponse_TakenOver(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewIdempotencyKeyRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectExec(`UPDATE idempotency_keys`).
+		WithArgs(201, pgxmock.AnyArg(), pgxmock.AnyArg(), 9, "token-1").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	err = repo.SaveResponse(9, "token-1", 201, nil, nil)
+	require.EqualError(t, err, "idempotency key not found")
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestIdempotencyKeyRepository_Delete(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewIdempotencyKeyRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectExec(`DELETE FROM idempotency_keys WHERE id = \$1 AND token = \$2`).
+		WithArgs(9, "token-1").
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	err = repo.Delete(9, "token-1")
+	require.NoError(t, err)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestIdempotencyKeyRepository_DeleteExpired(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewIdempotencyKeyRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectExec(`DELETE FROM idempotency_keys WHERE expires_at <= NOW\(\)`).
+		WillReturnResult(pgxmock.NewResult("DELETE", 4))
+
+	deleted, err := repo.DeleteExpired()
+	require.NoError(t, err)
+	require.Equal(t, int64(4), deleted)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
@@ -3,19 +3,26 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"project-app-inventory/database"
 	"project-app-inventory/model"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
 type ItemRepository interface {
-	Create(item *model.Item) error
+	Create(item *model.Item, userID int) error
 	FindByID(id int) (*model.Item, error)
 	FindBySKU(sku string) (*model.Item, error)
 	FindAll(page, limit int) ([]model.Item, int, error)
-	FindLowStock(page, limit int) ([]model.Item, int, error)
+	FindAfter(afterID, limit int) ([]model.Item, error)
+	FindLowStock(page, limit int, useAvailable bool) ([]model.Item, int, error)
+	UpdateABCClasses(items []model.ABCItem) error
+	FindReorderSuggestions(params model.ReorderParams, itemIDs []int, page, limit int) ([]model.ReorderSuggestion, int, error)
+	UpdateMinimumStocks(suggestions []model.ReorderSuggestion) error
+	Import(rows []model.ItemImport, userID int, partial, dryRun bool) error
 	Update(id int, data *model.Item) error
 	Delete(id int) error
 }
@@ -29,36 +36,208 @@ func NewItemRepository(db database.PgxIface, log *zap.Logger) ItemRepository {
 	return &itemRepository{db: db, Logger: log}
 }
 
-func (r *itemRepository) Create(item *model.Item) error {
+func (r *itemRepository) Create(item *model.Item, userID int) error {
+	// Begin transaction
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	if err := insertItem(context.Background(), tx, item, userID); err != nil {
+		r.Logger.Error("error creating item", zap.Error(err))
+		return err
+	}
+
+	// Commit transaction
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// insertItem inserts the item and books its initial stock on its home rack
+func insertItem(ctx context.Context, tx database.PgxIface, item *model.Item, userID int) error {
+	// Item starts empty, the initial stock is booked on its home rack through the ledger below
 	query := `
-		INSERT INTO items (sku, name, category_id, rack_id, stock, minimum_stock, price, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		INSERT INTO items (sku, name, category_id, rack_id, stock, minimum_stock, price, tax_rate, track_lots, serialized,
+		                   average_cost, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 0, $5, $6, $7, $8, $9, $10, NOW(), NOW())
 		RETURNING id, created_at, updated_at
 	`
-	err := r.db.QueryRow(context.Background(), query,
+	err := tx.QueryRow(ctx, query,
 		item.SKU, item.Name, item.CategoryID, item.RackID,
-		item.Stock, item.MinimumStock, item.Price,
+		item.MinimumStock, item.Price, item.TaxRate, item.TrackLots, item.Serialized, item.AverageCost,
 	).Scan(&item.ID, &item.CreatedAt, &item.UpdatedAt)
+	if err != nil {
+		return err
+	}
 
+	if item.Stock > 0 {
+		referenceType := model.ReferenceTypeItem
+		movement := &model.StockMovement{
+			ItemID:        item.ID,
+			UserID:        userID,
+			RackID:        item.RackID,
+			MovementType:  model.MovementTypeOpening,
+			Quantity:      item.Stock,
+			ReferenceType: &referenceType,
+			ReferenceID:   &item.ID,
+			UnitCost:      &item.AverageCost,
+		}
+		if err := applyStockMovement(ctx, tx, movement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Import creates or updates the items of rows in one transaction. An existing
+// item takes the row's name, category, rack, minimum stock and price, and its
+// stock is corrected to the row's through the ledger on that rack. A row can't
+// tell which rack a correction belongs to, so the stock of an item held in any
+// other rack can't be changed by an import.
+//
+// A row that fails gets its error on the row. Unless partial, the first
+// failure stops the import and nothing is written; with partial each row is
+// written in a savepoint of its own and the rows that succeed are kept. A dry
+// run does all the same work and rolls it back.
+func (r *itemRepository) Import(rows []model.ItemImport, userID int, partial, dryRun bool) error {
+	ctx := context.Background()
+
+	tx, err := beginTx(r.db)
 	if err != nil {
-		r.Logger.Error("error creating item", zap.Error(err))
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for i := range rows {
+		row := &rows[i]
+
+		if !partial {
+			if err := importItem(ctx, tx, row, userID); err != nil {
+				row.Status, row.Err = model.ItemImportFailed, err
+				return nil
+			}
+			continue
+		}
+
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			r.Logger.Error("error creating savepoint", zap.Error(err))
+			return err
+		}
+		if err := importItem(ctx, savepoint, row, userID); err != nil {
+			row.Status, row.Err = model.ItemImportFailed, err
+			if err := savepoint.Rollback(ctx); err != nil {
+				r.Logger.Error("error rolling back savepoint", zap.Error(err))
+				return err
+			}
+			continue
+		}
+		if err := savepoint.Commit(ctx); err != nil {
+			r.Logger.Error("error releasing savepoint", zap.Error(err))
+			return err
+		}
+	}
+
+	if dryRun {
+		return nil
 	}
-	return err
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func importItem(ctx context.Context, tx database.PgxIface, row *model.ItemImport, userID int) error {
+	item := &row.Item
+	if item.ID == 0 {
+		if err := insertItem(ctx, tx, item, userID); err != nil {
+			return err
+		}
+		row.Status = model.ItemImportCreated
+		return nil
+	}
+
+	// The row lock taken here keeps the stock read until the correction below
+	query := `
+		UPDATE items
+		SET name = $1, category_id = $2, rack_id = $3, minimum_stock = $4, price = $5, updated_at = NOW()
+		WHERE id = $6
+		RETURNING stock
+	`
+	var stock int
+	err := tx.QueryRow(ctx, query,
+		item.Name, item.CategoryID, item.RackID, item.MinimumStock, item.Price, item.ID,
+	).Scan(&stock)
+	if err == pgx.ErrNoRows {
+		return errors.New("item not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if delta := item.Stock - stock; delta != 0 {
+		otherRacksQuery := `
+			SELECT COALESCE(array_agg(r.code ORDER BY r.code), '{}')
+			FROM item_locations l
+			JOIN racks r ON r.id = l.rack_id
+			WHERE l.item_id = $1 AND l.rack_id <> $2 AND l.quantity > 0
+		`
+		var otherRacks []string
+		if err := tx.QueryRow(ctx, otherRacksQuery, item.ID, item.RackID).Scan(&otherRacks); err != nil {
+			return err
+		}
+		if len(otherRacks) > 0 {
+			return fmt.Errorf("stock of an item also held in rack %s can't be changed by an import, adjust it per rack", strings.Join(otherRacks, ", "))
+		}
+
+		reasonCode := model.AdjustmentReasonCorrection
+		reason := "item import"
+		referenceType := model.ReferenceTypeItem
+		movement := &model.StockMovement{
+			ItemID:        item.ID,
+			UserID:        userID,
+			RackID:        item.RackID,
+			MovementType:  model.MovementTypeAdjustment,
+			Quantity:      delta,
+			ReasonCode:    &reasonCode,
+			Reason:        &reason,
+			ReferenceType: &referenceType,
+			ReferenceID:   &item.ID,
+		}
+		if err := applyStockMovement(ctx, tx, movement); err != nil {
+			return err
+		}
+	}
+
+	row.Status = model.ItemImportUpdated
+	return nil
 }
 
 func (r *itemRepository) FindByID(id int) (*model.Item, error) {
 	query := `
-		SELECT id, sku, name, category_id, rack_id, stock, minimum_stock, price, 
-		       created_at, updated_at
+		SELECT id, sku, name, category_id, rack_id, stock, in_transit, minimum_stock, price, tax_rate, track_lots, serialized,
+		       average_cost, abc_class, ` + reservedQuantitySQL + `, created_at, updated_at
 		FROM items 
 		WHERE id = $1
 	`
 	var item model.Item
 	err := r.db.QueryRow(context.Background(), query, id).Scan(
 		&item.ID, &item.SKU, &item.Name, &item.CategoryID, &item.RackID,
-		&item.Stock, &item.MinimumStock, &item.Price,
-		&item.CreatedAt, &item.UpdatedAt,
+		&item.Stock, &item.InTransit, &item.MinimumStock, &item.Price, &item.TaxRate, &item.TrackLots, &item.Serialized,
+		&item.AverageCost, &item.ABCClass, &item.Reserved, &item.CreatedAt, &item.UpdatedAt,
 	)
+	item.Available = item.Stock - item.InTransit - item.Reserved
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -72,17 +251,18 @@ func (r *itemRepository) FindByID(id int) (*model.Item, error) {
 
 func (r *itemRepository) FindBySKU(sku string) (*model.Item, error) {
 	query := `
-		SELECT id, sku, name, category_id, rack_id, stock, minimum_stock, price, 
-		       created_at, updated_at
+		SELECT id, sku, name, category_id, rack_id, stock, in_transit, minimum_stock, price, tax_rate, track_lots, serialized,
+		       average_cost, abc_class, ` + reservedQuantitySQL + `, created_at, updated_at
 		FROM items 
 		WHERE sku = $1
 	`
 	var item model.Item
 	err := r.db.QueryRow(context.Background(), query, sku).Scan(
 		&item.ID, &item.SKU, &item.Name, &item.CategoryID, &item.RackID,
-		&item.Stock, &item.MinimumStock, &item.Price,
-		&item.CreatedAt, &item.UpdatedAt,
+		&item.Stock, &item.InTransit, &item.MinimumStock, &item.Price, &item.TaxRate, &item.TrackLots, &item.Serialized,
+		&item.AverageCost, &item.ABCClass, &item.Reserved, &item.CreatedAt, &item.UpdatedAt,
 	)
+	item.Available = item.Stock - item.InTransit - item.Reserved
 
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -108,8 +288,8 @@ func (r *itemRepository) FindAll(page, limit int) ([]model.Item, int, error) {
 
 	// Get data with pagination
 	query := `
-		SELECT id, sku, name, category_id, rack_id, stock, minimum_stock, price, 
-		       created_at, updated_at
+		SELECT id, sku, name, category_id, rack_id, stock, in_transit, minimum_stock, price, tax_rate, track_lots, serialized,
+		       average_cost, abc_class, ` + reservedQuantitySQL + `, created_at, updated_at
 		FROM items
 		ORDER BY name ASC
 		LIMIT $1 OFFSET $2
@@ -126,25 +306,71 @@ func (r *itemRepository) FindAll(page, limit int) ([]model.Item, int, error) {
 		var item model.Item
 		err := rows.Scan(
 			&item.ID, &item.SKU, &item.Name, &item.CategoryID, &item.RackID,
-			&item.Stock, &item.MinimumStock, &item.Price,
-			&item.CreatedAt, &item.UpdatedAt,
+			&item.Stock, &item.InTransit, &item.MinimumStock, &item.Price, &item.TaxRate, &item.TrackLots, &item.Serialized,
+			&item.AverageCost, &item.ABCClass, &item.Reserved, &item.CreatedAt, &item.UpdatedAt,
 		)
 		if err != nil {
 			r.Logger.Error("error scanning item", zap.Error(err))
 			return nil, 0, err
 		}
+		item.Available = item.Stock - item.InTransit - item.Reserved
 		items = append(items, item)
 	}
 
 	return items, total, nil
 }
 
-func (r *itemRepository) FindLowStock(page, limit int) ([]model.Item, int, error) {
+// FindAfter reads up to limit items with an id above afterID in id order. Exports
+// page with it, a page can't skip or repeat items added or removed meanwhile.
+func (r *itemRepository) FindAfter(afterID, limit int) ([]model.Item, error) {
+	query := `
+		SELECT id, sku, name, category_id, rack_id, stock, in_transit, minimum_stock, price, tax_rate, track_lots, serialized,
+		       average_cost, abc_class, ` + reservedQuantitySQL + `, created_at, updated_at
+		FROM items
+		WHERE id > $1
+		ORDER BY id ASC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(context.Background(), query, afterID, limit)
+	if err != nil {
+		r.Logger.Error("error querying items after id", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.Item
+	for rows.Next() {
+		var item model.Item
+		err := rows.Scan(
+			&item.ID, &item.SKU, &item.Name, &item.CategoryID, &item.RackID,
+			&item.Stock, &item.InTransit, &item.MinimumStock, &item.Price, &item.TaxRate, &item.TrackLots, &item.Serialized,
+			&item.AverageCost, &item.ABCClass, &item.Reserved, &item.CreatedAt, &item.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning item", zap.Error(err))
+			return nil, err
+		}
+		item.Available = item.Stock - item.InTransit - item.Reserved
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// FindLowStock lists items under their minimum stock, A items first. Stock in
+// transit between racks still counts. With useAvailable the quantity in transit
+// or held by reservations is taken off first, so promised stock counts as gone.
+func (r *itemRepository) FindLowStock(page, limit int, useAvailable bool) ([]model.Item, int, error) {
 	offset := (page - 1) * limit
 
+	quantity := "stock"
+	if useAvailable {
+		quantity = "stock - in_transit - " + reservedQuantitySQL
+	}
+
 	// Get total count of low stock items
 	var total int
-	countQuery := `SELECT COUNT(*) FROM items WHERE stock < minimum_stock`
+	countQuery := `SELECT COUNT(*) FROM items WHERE ` + quantity + ` < minimum_stock`
 	err := r.db.QueryRow(context.Background(), countQuery).Scan(&total)
 	if err != nil {
 		r.Logger.Error("error counting low stock items", zap.Error(err))
@@ -153,10 +379,11 @@ func (r *itemRepository) FindLowStock(page, limit int) ([]model.Item, int, error
 
 	// Get data with pagination
 	query := `
-		SELECT id, sku, name, category_id, rack_id, stock, minimum_stock, price, created_at, updated_at
+		SELECT id, sku, name, category_id, rack_id, stock, in_transit, minimum_stock, price, tax_rate, track_lots, serialized,
+		       average_cost, abc_class, ` + reservedQuantitySQL + `, created_at, updated_at
 		FROM items
-		WHERE stock < minimum_stock
-		ORDER BY stock ASC, name ASC
+		WHERE ` + quantity + ` < minimum_stock
+		ORDER BY abc_class ASC NULLS LAST, ` + quantity + ` ASC, name ASC
 		LIMIT $1 OFFSET $2
 	`
 	rows, err := r.db.Query(context.Background(), query, limit, offset)
@@ -171,29 +398,176 @@ func (r *itemRepository) FindLowStock(page, limit int) ([]model.Item, int, error
 		var item model.Item
 		err := rows.Scan(
 			&item.ID, &item.SKU, &item.Name, &item.CategoryID, &item.RackID,
-			&item.Stock, &item.MinimumStock, &item.Price,
-			&item.CreatedAt, &item.UpdatedAt,
+			&item.Stock, &item.InTransit, &item.MinimumStock, &item.Price, &item.TaxRate, &item.TrackLots, &item.Serialized,
+			&item.AverageCost, &item.ABCClass, &item.Reserved, &item.CreatedAt, &item.UpdatedAt,
 		)
 		if err != nil {
 			r.Logger.Error("error scanning low stock item", zap.Error(err))
 			return nil, 0, err
 		}
+		item.Available = item.Stock - item.InTransit - item.Reserved
 		items = append(items, item)
 	}
 
 	return items, total, nil
 }
 
+// UpdateABCClasses stores the class of every classified item. Items left out,
+// such as ones created since, keep the class they have.
+func (r *itemRepository) UpdateABCClasses(items []model.ABCItem) error {
+	ids := make([]int, 0, len(items))
+	classes := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ItemID)
+		classes = append(classes, item.Class)
+	}
+
+	query := `
+		UPDATE items
+		SET abc_class = c.class, updated_at = NOW()
+		FROM unnest($1::int[], $2::text[]) AS c(id, class)
+		WHERE items.id = c.id
+	`
+	_, err := r.db.Exec(context.Background(), query, ids, classes)
+	if err != nil {
+		r.Logger.Error("error updating abc classes", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// reorderSuggestionsSQL works out the reorder point and order quantity of the
+// items $5 (every item when NULL) from their units sold over the last $1 days,
+// less the units returned to stock; damaged returns never refill the stock so
+// they still count as sold. Safety stock is $2 days of sales, the reorder point the sales over the lead
+// time plus the safety stock, the order quantity $3 days of sales. The lead
+// time is the one of the supplier of the item's latest purchase order, $4 days
+// otherwise. Only items whose reorder point differs from their minimum stock
+// are listed.
+const reorderSuggestionsSQL = `
+	WITH restocked AS (
+		SELECT sale_item_id, SUM(quantity) AS quantity
+		FROM sale_return_items
+		WHERE condition = 'restock'
+		GROUP BY sale_item_id
+	), sold AS (
+		SELECT si.item_id, SUM(si.quantity - COALESCE(rs.quantity, 0)) AS units
+		FROM sale_items si
+		JOIN sales s ON s.id = si.sale_id
+		LEFT JOIN restocked rs ON rs.sale_item_id = si.id
+		WHERE s.deleted_at IS NULL AND s.created_at >= NOW() - make_interval(days => $1)
+		GROUP BY si.item_id
+	), supplier AS (
+		SELECT DISTINCT ON (poi.item_id) poi.item_id, sp.id, sp.name, sp.lead_time_days
+		FROM purchase_order_items poi
+		JOIN purchase_orders po ON po.id = poi.purchase_order_id
+		JOIN suppliers sp ON sp.id = po.supplier_id
+		WHERE po.status <> 'cancelled'
+		ORDER BY poi.item_id, po.created_at DESC, po.id DESC
+	), demand AS (
+		SELECT i.id, i.sku, i.name, i.stock, i.minimum_stock, COALESCE(sold.units, 0) AS units,
+		       supplier.id AS supplier_id, supplier.name AS supplier_name,
+		       COALESCE(supplier.lead_time_days, $4) AS lead_time_days
+		FROM items i
+		LEFT JOIN sold ON sold.item_id = i.id
+		LEFT JOIN supplier ON supplier.item_id = i.id
+		WHERE $5::int[] IS NULL OR i.id = ANY($5)
+	), suggestions AS (
+		SELECT *,
+		       CEIL(units::numeric * $2 / $1)::int AS safety_stock,
+		       CEIL(units::numeric * (lead_time_days + $2) / $1)::int AS reorder_point,
+		       CEIL(units::numeric * $3 / $1)::int AS order_quantity
+		FROM demand
+	)
+`
+
+// FindReorderSuggestions lists the items whose suggested reorder point differs
+// from their minimum stock, the ones closest to running out first
+func (r *itemRepository) FindReorderSuggestions(params model.ReorderParams, itemIDs []int, page, limit int) ([]model.ReorderSuggestion, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count
+	var total int
+	countQuery := reorderSuggestionsSQL + `SELECT COUNT(*) FROM suggestions WHERE reorder_point <> minimum_stock`
+	err := r.db.QueryRow(context.Background(), countQuery,
+		params.SalesDays, params.SafetyDays, params.CoverDays, params.LeadTimeDays, itemIDs,
+	).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting reorder suggestions", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := reorderSuggestionsSQL + `
+		SELECT id, sku, name, stock, minimum_stock, units, supplier_id, supplier_name,
+		       lead_time_days, safety_stock, reorder_point, order_quantity
+		FROM suggestions
+		WHERE reorder_point <> minimum_stock
+		ORDER BY stock - reorder_point ASC, id ASC
+		LIMIT $6 OFFSET $7
+	`
+	rows, err := r.db.Query(context.Background(), query,
+		params.SalesDays, params.SafetyDays, params.CoverDays, params.LeadTimeDays, itemIDs, limit, offset,
+	)
+	if err != nil {
+		r.Logger.Error("error querying reorder suggestions", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var suggestions []model.ReorderSuggestion
+	for rows.Next() {
+		var suggestion model.ReorderSuggestion
+		err := rows.Scan(
+			&suggestion.ItemID, &suggestion.SKU, &suggestion.Name, &suggestion.Stock, &suggestion.MinimumStock,
+			&suggestion.UnitsSold, &suggestion.SupplierID, &suggestion.SupplierName, &suggestion.LeadTimeDays,
+			&suggestion.SafetyStock, &suggestion.ReorderPoint, &suggestion.OrderQuantity,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning reorder suggestion", zap.Error(err))
+			return nil, 0, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, total, nil
+}
+
+// UpdateMinimumStocks sets the minimum stock of every suggested item to its
+// suggested reorder point
+func (r *itemRepository) UpdateMinimumStocks(suggestions []model.ReorderSuggestion) error {
+	ids := make([]int, 0, len(suggestions))
+	points := make([]int, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		ids = append(ids, suggestion.ItemID)
+		points = append(points, suggestion.ReorderPoint)
+	}
+
+	query := `
+		UPDATE items
+		SET minimum_stock = s.reorder_point, updated_at = NOW()
+		FROM unnest($1::int[], $2::int[]) AS s(id, reorder_point)
+		WHERE items.id = s.id
+	`
+	_, err := r.db.Exec(context.Background(), query, ids, points)
+	if err != nil {
+		r.Logger.Error("error updating minimum stocks", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
 func (r *itemRepository) Update(id int, data *model.Item) error {
 	query := `
 		UPDATE items
 		SET sku = $1, name = $2, category_id = $3, rack_id = $4,
-		    stock = $5, minimum_stock = $6, price = $7, updated_at = NOW()
+		    minimum_stock = $5, price = $6, tax_rate = $7, updated_at = NOW()
 		WHERE id = $8
 	`
+	// Stock is not written here, it only changes through the stock ledger
 	result, err := r.db.Exec(context.Background(), query,
 		data.SKU, data.Name, data.CategoryID, data.RackID,
-		data.Stock, data.MinimumStock, data.Price, id,
+		data.MinimumStock, data.Price, data.TaxRate, id,
 	)
 	if err != nil {
 		r.Logger.Error("error updating item", zap.Error(err))
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+
+	"go.uber.org/zap"
+)
+
+type ItemLocationRepository interface {
+	FindByItemID(itemID int) ([]model.ItemLocation, error)
+	FindByRackID(rackID, page, limit int) ([]model.ItemLocation, int, error)
+	FindByWarehouseID(warehouseID, page, limit int) ([]model.WarehouseStock, int, error)
+}
+
+type itemLocationRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewItemLocationRepository(db database.PgxIface, log *zap.Logger) ItemLocationRepository {
+	return &itemLocationRepository{db: db, Logger: log}
+}
+
+// FindByItemID returns every rack holding the item, home rack first then fullest racks
+func (r *itemLocationRepository) FindByItemID(itemID int) ([]model.ItemLocation, error) {
+	query := `
+		SELECT il.id, il.item_id, i.sku, i.name, il.rack_id, rk.code, rk.warehouse_id,
+		       il.quantity, il.created_at, il.updated_at
+		FROM item_locations il
+		JOIN items i ON i.id = il.item_id
+		JOIN racks rk ON rk.id = il.rack_id
+		WHERE il.item_id = $1 AND il.quantity > 0
+		ORDER BY (il.rack_id = i.rack_id) DESC, il.quantity DESC, il.rack_id ASC
+	`
+	rows, err := r.db.Query(context.Background(), query, itemID)
+	if err != nil {
+		r.Logger.Error("error querying item locations", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []model.ItemLocation
+	for rows.Next() {
+		var location model.ItemLocation
+		err := rows.Scan(
+			&location.ID, &location.ItemID, &location.SKU, &location.ItemName,
+			&location.RackID, &location.RackCode, &location.WarehouseID,
+			&location.Quantity, &location.CreatedAt, &location.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning item location", zap.Error(err))
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+
+	return locations, nil
+}
+
+func (r *itemLocationRepository) FindByRackID(rackID, page, limit int) ([]model.ItemLocation, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count
+	var total int
+	countQuery := `SELECT COUNT(*) FROM item_locations WHERE rack_id = $1 AND quantity > 0`
+	err := r.db.QueryRow(context.Background(), countQuery, rackID).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting rack stock", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := `
+		SELECT il.id, il.item_id, i.sku, i.name, il.rack_id, rk.code, rk.warehouse_id,
+		       il.quantity, il.created_at, il.updated_at
+		FROM item_locations il
+		JOIN items i ON i.id = il.item_id
+		JOIN racks rk ON rk.id = il.rack_id
+		WHERE il.rack_id = $1 AND il.quantity > 0
+		ORDER BY i.name ASC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(context.Background(), query, rackID, limit, offset)
+	if err != nil {
+		r.Logger.Error("error querying rack stock", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var locations []model.ItemLocation
+	for rows.Next() {
+		var location model.ItemLocation
+		err := rows.Scan(
+			&location.ID, &location.ItemID, &location.SKU, &location.ItemName,
+			&location.RackID, &location.RackCode, &location.WarehouseID,
+			&location.Quantity, &location.CreatedAt, &location.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning rack stock", zap.Error(err))
+			return nil, 0, err
+		}
+		locations = append(locations, location)
+	}
+
+	return locations, total, nil
+}
+
+func (r *itemLocationRepository) FindByWarehouseID(warehouseID, page, limit int) ([]model.WarehouseStock, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count of distinct items stored in the warehouse
+	var total int
+	countQuery := `
+		SELECT COUNT(DISTINCT il.item_id)
+		FROM item_locations il
+		JOIN racks rk ON rk.id = il.rack_id
+		WHERE rk.warehouse_id = $1 AND il.quantity > 0
+	`
+	err := r.db.QueryRow(context.Background(), countQuery, warehouseID).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting warehouse stock", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := `
+		SELECT rk.warehouse_id, i.id, i.sku, i.name, SUM(il.quantity), COUNT(il.rack_id)
+		FROM item_locations il
+		JOIN items i ON i.id = il.item_id
+		JOIN racks rk ON rk.id = il.rack_id
+		WHERE rk.warehouse_id = $1 AND il.quantity > 0
+		GROUP BY rk.warehouse_id, i.id, i.sku, i.name
+		ORDER BY i.name ASC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(context.Background(), query, warehouseID, limit, offset)
+	if err != nil {
+		r.Logger.Error("error querying warehouse stock", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var stocks []model.WarehouseStock
+	for rows.Next() {
+		var stock model.WarehouseStock
+		err := rows.Scan(
+			&stock.WarehouseID, &stock.ItemID, &stock.SKU, &stock.ItemName,
+			&stock.Quantity, &stock.RackCount,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning warehouse stock", zap.Error(err))
+			return nil, 0, err
+		}
+		stocks = append(stocks, stock)
+	}
+
+	return stocks, total, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestItemLocationRepository_FindByItemID_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewItemLocationRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM item_locations il (.+) WHERE il.item_id`).
+		WithArgs(1).
+		WillReturnRows(pgxmock.NewRows([]string{
+			"id", "item_id", "sku", "name", "rack_id", "code", "warehouse_id",
+			"quantity", "created_at", "updated_at",
+		}).
+			AddRow(1, 1, "ELC-001", "Laptop", 1, "A-01", 1, 8, time.Now(), time.Now()).
+			AddRow(2, 1, "ELC-001", "Laptop", 3, "C-01", 2, 2, time.Now(), time.Now()))
+
+	locations, err := repo.FindByItemID(1)
+	require.NoError(t, err)
+	require.Len(t, locations, 2)
+	require.Equal(t, "A-01", locations[0].RackCode)
+	require.Equal(t, 2, locations[1].WarehouseID)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestItemLocationRepository_FindByWarehouseID_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewItemLocationRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectQuery(`SELECT COUNT\(DISTINCT il.item_id\)`).
+		WithArgs(1).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM item_locations il (.+) GROUP BY`).
+		WithArgs(1, 10, 0).
+		WillReturnRows(pgxmock.NewRows([]string{"warehouse_id", "id", "sku", "name", "sum", "count"}).
+			AddRow(1, 1, "ELC-001", "Laptop", 10, 2))
+
+	stocks, total, err := repo.FindByWarehouseID(1, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, 10, stocks[0].Quantity)
+	require.Equal(t, 2, stocks[0].RackCount)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestItemLocationRepository_FindByRackID_Error(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewItemLocationRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectQuery(`SELECT COUNT\(\*\) FROM item_locations`).
+		WithArgs(1).
+		WillReturnError(errors.New("database error"))
+
+	locations, total, err := repo.FindByRackID(1, 1, 10)
+	require.Error(t, err)
+	require.Nil(t, locations)
+	require.Equal(t, 0, total)
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type ItemLotRepository interface {
+	FindByItemID(itemID int) ([]model.ItemLot, error)
+	FindByLotNumber(itemID int, lotNumber string) (*model.ItemLot, error)
+	FindExpiring(before time.Time, page, limit int) ([]model.ItemLot, int, error)
+}
+
+type itemLotRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewItemLotRepository(db database.PgxIface, log *zap.Logger) ItemLotRepository {
+	return &itemLotRepository{db: db, Logger: log}
+}
+
+// FindByItemID returns every lot of the item per rack in FEFO order, earliest
+// expiry first and lots without expiry last, including empty and expired lots
+func (r *itemLotRepository) FindByItemID(itemID int) ([]model.ItemLot, error) {
+	query := `
+		SELECT l.id, l.item_id, i.sku, i.name, l.rack_id, rk.code, l.lot_number, l.expiry_date, l.expiry_date - CURRENT_DATE,
+		       l.quantity, l.created_at, l.updated_at
+		FROM item_lots l
+		JOIN items i ON i.id = l.item_id
+		JOIN racks rk ON rk.id = l.rack_id
+		WHERE l.item_id = $1
+		ORDER BY l.expiry_date ASC NULLS LAST, l.id ASC
+	`
+	rows, err := r.db.Query(context.Background(), query, itemID)
+	if err != nil {
+		r.Logger.Error("error querying item lots", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lots []model.ItemLot
+	for rows.Next() {
+		var lot model.ItemLot
+		err := rows.Scan(
+			&lot.ID, &lot.ItemID, &lot.SKU, &lot.ItemName, &lot.RackID, &lot.RackCode, &lot.LotNumber, &lot.ExpiryDate, &lot.DaysToExpiry,
+			&lot.Quantity, &lot.CreatedAt, &lot.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning item lot", zap.Error(err))
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+
+	return lots, nil
+}
+
+// FindByLotNumber returns the lot over all the racks it sits in, with their
+// quantities summed and no rack of its own
+func (r *itemLotRepository) FindByLotNumber(itemID int, lotNumber string) (*model.ItemLot, error) {
+	query := `
+		SELECT MIN(l.id), l.item_id, i.sku, i.name, l.lot_number, MIN(l.expiry_date), MIN(l.expiry_date) - CURRENT_DATE,
+		       SUM(l.quantity), MIN(l.created_at), MAX(l.updated_at)
+		FROM item_lots l
+		JOIN items i ON i.id = l.item_id
+		WHERE l.item_id = $1 AND l.lot_number = $2
+		GROUP BY l.item_id, i.sku, i.name, l.lot_number
+	`
+	var lot model.ItemLot
+	err := r.db.QueryRow(context.Background(), query, itemID, lotNumber).Scan(
+		&lot.ID, &lot.ItemID, &lot.SKU, &lot.ItemName, &lot.LotNumber, &lot.ExpiryDate, &lot.DaysToExpiry,
+		&lot.Quantity, &lot.CreatedAt, &lot.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.Error("error finding item lot", zap.Error(err))
+		return nil, err
+	}
+	return &lot, nil
+}
+
+// FindExpiring lists lots still in stock that expire on or before the given
+// day per rack, already expired lots included, soonest first
+func (r *itemLotRepository) FindExpiring(before time.Time, page, limit int) ([]model.ItemLot, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count
+	var total int
+	countQuery := `SELECT COUNT(*) FROM item_lots WHERE quantity > 0 AND expiry_date <= $1`
+	err := r.db.QueryRow(context.Background(), countQuery, before).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting expiring lots", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := `
+		SELECT l.id, l.item_id, i.sku, i.name, l.rack_id, rk.code, l.lot_number, l.expiry_date, l.expiry_date - CURRENT_DATE,
+		       l.quantity, l.created_at, l.updated_at
+		FROM item_lots l
+		JOIN items i ON i.id = l.item_id
+		JOIN racks rk ON rk.id = l.rack_id
+		WHERE l.quantity > 0 AND l.expiry_date <= $1
+		ORDER BY l.expiry_date ASC, i.name ASC, rk.code ASC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(context.Background(), query, before, limit, offset)
+	if err != nil {
+		r.Logger.Error("error querying expiring lots", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var lots []model.ItemLot
+	for rows.Next() {
+		var lot model.ItemLot
+		err := rows.Scan(
+			&lot.ID, &lot.ItemID, &lot.SKU, &lot.ItemName, &lot.RackID, &lot.RackCode, &lot.LotNumber, &lot.ExpiryDate, &lot.DaysToExpiry,
+			&lot.Quantity, &lot.CreatedAt, &lot.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning expiring lot", zap.Error(err))
+			return nil, 0, err
+		}
+		lots = append(lots, lot)
+	}
+
+	return lots, total, nil
+}
+
+// openLot creates an empty lot in the rack of a receipt line so the receipt's
+// stock movement can fill it; receiving more of a lot the rack holds keeps it
+func openLot(ctx context.Context, tx database.PgxIface, itemID, rackID int, lotNumber string, expiryDate *time.Time) error {
+	query := `
+		INSERT INTO item_lots (item_id, rack_id, lot_number, expiry_date, quantity, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 0, NOW(), NOW())
+		ON CONFLICT (item_id, rack_id, lot_number) DO NOTHING
+	`
+	_, err := tx.Exec(ctx, query, itemID, rackID, lotNumber, expiryDate)
+	return err
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestItemLotRepository_FindExpiring(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewItemLotRepository(mockDB, zap.NewNop())
+
+	before := time.Date(2026, 11, 16, 0, 0, 0, 0, time.UTC)
+	expiry := time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC)
+	days := 3
+
+	mockDB.
+		ExpectQuery(`SELECT COUNT\(\*\) FROM item_lots`).
+		WithArgs(before).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM item_lots l`).
+		WithArgs(before, 10, 0).
+		WillReturnRows(pgxmock.NewRows([]string{
+			"id", "item_id", "sku", "name", "rack_id", "rack_code", "lot_number", "expiry_date", "days_to_expiry",
+			"quantity", "created_at", "updated_at",
+		}).AddRow(1, 3, "MILK-1L", "Milk 1L", 2, "A-01", "LOT-A", &expiry, &days, 12, time.Now(), time.Now()))
+
+	lots, total, err := repo.FindExpiring(before, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Len(t, lots, 1)
+	require.Equal(t, "LOT-A", lots[0].LotNumber)
+	require.Equal(t, 2, lots[0].RackID)
+	require.Equal(t, 3, *lots[0].DaysToExpiry)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
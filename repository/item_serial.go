@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrSerialNotInStock = errors.New("serial number is not in stock in the rack")
+	ErrSerialConflict   = errors.New("serial number is already in stock or belongs to another item")
+	ErrSerialNotSold    = errors.New("serial number is not sold")
+)
+
+type ItemSerialRepository interface {
+	FindBySerialNumber(serialNumber string) (*model.ItemSerial, error)
+	FindBySerialNumbers(itemID int, serialNumbers []string) ([]model.ItemSerial, error)
+	FindByItemID(itemID int, status string, page, limit int) ([]model.ItemSerial, int, error)
+	FindEvents(serialID int) ([]model.SerialEvent, error)
+}
+
+type itemSerialRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewItemSerialRepository(db database.PgxIface, log *zap.Logger) ItemSerialRepository {
+	return &itemSerialRepository{db: db, Logger: log}
+}
+
+func (r *itemSerialRepository) FindBySerialNumber(serialNumber string) (*model.ItemSerial, error) {
+	query := `
+		SELECT s.id, s.item_id, i.sku, i.name, s.serial_number, s.rack_id, s.status, s.created_at, s.updated_at
+		FROM item_serials s
+		JOIN items i ON i.id = s.item_id
+		WHERE s.serial_number = $1
+	`
+	var serial model.ItemSerial
+	err := r.db.QueryRow(context.Background(), query, serialNumber).Scan(
+		&serial.ID, &serial.ItemID, &serial.SKU, &serial.ItemName, &serial.SerialNumber,
+		&serial.RackID, &serial.Status, &serial.CreatedAt, &serial.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.Error("error finding serial number", zap.Error(err))
+		return nil, err
+	}
+	return &serial, nil
+}
+
+// FindBySerialNumbers returns the serials of the item among serialNumbers,
+// unknown serials and serials of other items are left out
+func (r *itemSerialRepository) FindBySerialNumbers(itemID int, serialNumbers []string) ([]model.ItemSerial, error) {
+	query := `
+		SELECT s.id, s.item_id, i.sku, i.name, s.serial_number, s.rack_id, s.status, s.created_at, s.updated_at
+		FROM item_serials s
+		JOIN items i ON i.id = s.item_id
+		WHERE s.item_id = $1 AND s.serial_number = ANY($2)
+	`
+	rows, err := r.db.Query(context.Background(), query, itemID, serialNumbers)
+	if err != nil {
+		r.Logger.Error("error querying serial numbers", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var serials []model.ItemSerial
+	for rows.Next() {
+		var serial model.ItemSerial
+		err := rows.Scan(
+			&serial.ID, &serial.ItemID, &serial.SKU, &serial.ItemName, &serial.SerialNumber,
+			&serial.RackID, &serial.Status, &serial.CreatedAt, &serial.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning serial number", zap.Error(err))
+			return nil, err
+		}
+		serials = append(serials, serial)
+	}
+
+	return serials, nil
+}
+
+func (r *itemSerialRepository) FindByItemID(itemID int, status string, page, limit int) ([]model.ItemSerial, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count, empty status means all serials
+	var total int
+	countQuery := `SELECT COUNT(*) FROM item_serials WHERE item_id = $1 AND ($2 = '' OR status = $2)`
+	err := r.db.QueryRow(context.Background(), countQuery, itemID, status).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting item serials", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := `
+		SELECT s.id, s.item_id, i.sku, i.name, s.serial_number, s.rack_id, s.status, s.created_at, s.updated_at
+		FROM item_serials s
+		JOIN items i ON i.id = s.item_id
+		WHERE s.item_id = $1 AND ($2 = '' OR s.status = $2)
+		ORDER BY s.serial_number ASC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(context.Background(), query, itemID, status, limit, offset)
+	if err != nil {
+		r.Logger.Error("error querying item serials", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var serials []model.ItemSerial
+	for rows.Next() {
+		var serial model.ItemSerial
+		err := rows.Scan(
+			&serial.ID, &serial.ItemID, &serial.SKU, &serial.ItemName, &serial.SerialNumber,
+			&serial.RackID, &serial.Status, &serial.CreatedAt, &serial.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning item serial", zap.Error(err))
+			return nil, 0, err
+		}
+		serials = append(serials, serial)
+	}
+
+	return serials, total, nil
+}
+
+// FindEvents returns the lifecycle of a serial, oldest first
+func (r *itemSerialRepository) FindEvents(serialID int) ([]model.SerialEvent, error) {
+	query := `
+		SELECT id, serial_id, event_type, rack_id, user_id, reference_type, reference_id, created_at
+		FROM serial_events
+		WHERE serial_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+	rows, err := r.db.Query(context.Background(), query, serialID)
+	if err != nil {
+		r.Logger.Error("error querying serial events", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []model.SerialEvent
+	for rows.Next() {
+		var event model.SerialEvent
+		err := rows.Scan(
+			&event.ID, &event.SerialID, &event.EventType, &event.RackID, &event.UserID,
+			&event.ReferenceType, &event.ReferenceID, &event.CreatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning serial event", zap.Error(err))
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// moveSerials puts the serials of a movement into its rack or takes them out
+// of it, so the serials in stock always match the stock the movement leaves,
+// and records the movement in their lifecycle
+func moveSerials(ctx context.Context, tx database.PgxIface, movement *model.StockMovement) error {
+	quantity := movement.Quantity
+	if quantity < 0 {
+		quantity = -quantity
+	}
+	if len(movement.SerialNumbers) != quantity {
+		return errors.New("serial numbers must match the quantity moved")
+	}
+
+	if movement.Quantity > 0 {
+		// New serials are created, known ones come back unless already in stock
+		query := `
+			INSERT INTO item_serials (item_id, serial_number, rack_id, status, created_at, updated_at)
+			SELECT $1::int, serial, $2::int, $4::text, NOW(), NOW() FROM unnest($3::text[]) AS serial
+			ON CONFLICT (serial_number) DO UPDATE
+			SET rack_id = EXCLUDED.rack_id, status = EXCLUDED.status, updated_at = NOW()
+			WHERE item_serials.item_id = EXCLUDED.item_id AND item_serials.status <> EXCLUDED.status
+		`
+		result, err := tx.Exec(ctx, query, movement.ItemID, movement.RackID, movement.SerialNumbers, model.SerialStatusInStock)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() != int64(quantity) {
+			return ErrSerialConflict
+		}
+	} else {
+		query := `
+			UPDATE item_serials
+			SET status = $4, updated_at = NOW()
+			WHERE item_id = $1 AND rack_id = $2 AND serial_number = ANY($3) AND status = $5
+		`
+		result, err := tx.Exec(ctx, query, movement.ItemID, movement.RackID, movement.SerialNumbers,
+			model.SerialStatusAfter(movement.MovementType), model.SerialStatusInStock,
+		)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() != int64(quantity) {
+			return ErrSerialNotInStock
+		}
+	}
+
+	return recordSerialEvents(ctx, tx, movement.ItemID, movement.SerialNumbers, movement.MovementType,
+		movement.RackID, movement.UserID, movement.ReferenceType, movement.ReferenceID,
+	)
+}
+
+// damageSerials marks sold serials returned damaged, they stay out of stock
+func damageSerials(ctx context.Context, tx database.PgxIface, itemID, rackID, userID, returnID int, serialNumbers []string) error {
+	query := `
+		UPDATE item_serials
+		SET status = $3, updated_at = NOW()
+		WHERE item_id = $1 AND serial_number = ANY($2) AND status = $4
+	`
+	result, err := tx.Exec(ctx, query, itemID, serialNumbers, model.SerialStatusDamaged, model.SerialStatusSold)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() != int64(len(serialNumbers)) {
+		return ErrSerialNotSold
+	}
+
+	referenceType := model.ReferenceTypeReturn
+	return recordSerialEvents(ctx, tx, itemID, serialNumbers, model.SerialEventDamagedReturn,
+		rackID, userID, &referenceType, &returnID,
+	)
+}
+
+func recordSerialEvents(ctx context.Context, tx database.PgxIface, itemID int, serialNumbers []string, eventType string,
+	rackID, userID int, referenceType *string, referenceID *int) error {
+	query := `
+		INSERT INTO serial_events (serial_id, event_type, rack_id, user_id, reference_type, reference_id, created_at)
+		SELECT id, $3::text, $4::int, $5::int, $6::text, $7::int, NOW()
+		FROM item_serials
+		WHERE item_id = $1 AND serial_number = ANY($2)
+	`
+	_, err := tx.Exec(ctx, query, itemID, serialNumbers, eventType, rackID, userID, referenceType, referenceID)
+	return err
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"project-app-inventory/model"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestItemSerialRepository_FindBySerialNumber(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewItemSerialRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM item_serials s (.+) WHERE s.serial_number`).
+		WithArgs("SN-1").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "item_id", "sku", "name", "serial_number", "rack_id", "status", "created_at", "updated_at"}).
+			AddRow(1, 3, "LAP-001", "Laptop", "SN-1", 2, model.SerialStatusSold, time.Now(), time.Now()))
+
+	serial, err := repo.FindBySerialNumber("SN-1")
+	require.NoError(t, err)
+	require.NotNil(t, serial)
+	require.Equal(t, model.SerialStatusSold, serial.Status)
+	require.Equal(t, "LAP-001", serial.SKU)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestItemSerialRepository_FindBySerialNumber_NotFound(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewItemSerialRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM item_serials s (.+) WHERE s.serial_number`).
+		WithArgs("SN-X").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "item_id", "sku", "name", "serial_number", "rack_id", "status", "created_at", "updated_at"}))
+
+	serial, err := repo.FindBySerialNumber("SN-X")
+	require.NoError(t, err)
+	require.Nil(t, serial)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestItemSerialRepository_FindEvents(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewItemSerialRepository(mockDB, zap.NewNop())
+
+	saleRef := model.ReferenceTypeSale
+	saleID := 12
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM serial_events WHERE serial_id`).
+		WithArgs(1).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "serial_id", "event_type", "rack_id", "user_id", "reference_type", "reference_id", "created_at"}).
+			AddRow(1, 1, model.MovementTypeReceipt, 2, 1, nil, nil, time.Now()).
+			AddRow(2, 1, model.MovementTypeSale, 2, 4, &saleRef, &saleID, time.Now()))
+
+	events, err := repo.FindEvents(1)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, model.MovementTypeSale, events[1].EventType)
+	require.Equal(t, 12, *events[1].ReferenceID)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
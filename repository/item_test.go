@@ -3,6 +3,7 @@ package repository
 import (
 	"errors"
 	"project-app-inventory/model"
+	"project-app-inventory/money"
 	"testing"
 	"time"
 
@@ -29,30 +30,48 @@ func TestItemRepository_Create(t *testing.T) {
 		RackID:       1,
 		Stock:        10,
 		MinimumStock: 5,
-		Price:        100000,
+		Price:        money.FromUnits(100000),
 	}
 
 	t.Run("Success", func(t *testing.T) {
 		rows := pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).
 			AddRow(1, time.Now(), time.Now())
 
+		mock.ExpectBegin()
 		mock.ExpectQuery("INSERT INTO items").
 			WithArgs(item.SKU, item.Name, item.CategoryID, item.RackID,
-				item.Stock, item.MinimumStock, item.Price).
+				item.MinimumStock, item.Price, item.TaxRate, item.TrackLots, item.Serialized, item.AverageCost).
 			WillReturnRows(rows)
-
-		err := repo.Create(item)
+		mock.ExpectExec("INSERT INTO item_locations").
+			WithArgs(1, item.RackID, item.Stock).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectQuery("UPDATE items").
+			WithArgs(item.Stock, 1).
+			WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(item.Stock))
+		mock.ExpectQuery("INSERT INTO cost_layers").
+			WithArgs(1, item.Stock, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnRows(pgxmock.NewRows([]string{"unit_cost"}).AddRow(item.AverageCost))
+		mock.ExpectQuery("INSERT INTO stock_movements").
+			WithArgs(1, 7, item.RackID, "opening", item.Stock, item.Stock,
+				pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+		mock.ExpectCommit()
+
+		err := repo.Create(item, 7)
 		assert.NoError(t, err)
 		assert.Equal(t, 1, item.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Error - Database Error", func(t *testing.T) {
+		mock.ExpectBegin()
 		mock.ExpectQuery("INSERT INTO items").
 			WithArgs(item.SKU, item.Name, item.CategoryID, item.RackID,
-				item.Stock, item.MinimumStock, item.Price).
+				item.MinimumStock, item.Price, item.TaxRate, item.TrackLots, item.Serialized, item.AverageCost).
 			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
 
-		err := repo.Create(item)
+		err := repo.Create(item, 7)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "database error")
 	})
@@ -71,10 +90,10 @@ func TestItemRepository_FindByID(t *testing.T) {
 	t.Run("Success - Item Found", func(t *testing.T) {
 		rows := pgxmock.NewRows([]string{
 			"id", "sku", "name", "category_id", "rack_id",
-			"stock", "minimum_stock", "price", "created_at", "updated_at",
+			"stock", "in_transit", "minimum_stock", "price", "tax_rate", "track_lots", "serialized", "average_cost", "abc_class", "reserved", "created_at", "updated_at",
 		}).AddRow(
 			1, "TEST-001", "Test Item", 1, 1,
-			10, 5, 100000.0, time.Now(), time.Now(),
+			10, 0, 5, money.FromUnits(100000), nil, false, false, money.Amount(0), (*string)(nil), 0, time.Now(), time.Now(),
 		)
 
 		mock.ExpectQuery("SELECT (.+) FROM items WHERE id").
@@ -118,16 +137,16 @@ func TestItemRepository_FindLowStock(t *testing.T) {
 		// Mock data query
 		dataRows := pgxmock.NewRows([]string{
 			"id", "sku", "name", "category_id", "rack_id",
-			"stock", "minimum_stock", "price", "created_at", "updated_at",
+			"stock", "in_transit", "minimum_stock", "price", "tax_rate", "track_lots", "serialized", "average_cost", "abc_class", "reserved", "created_at", "updated_at",
 		}).
-			AddRow(1, "LOW-001", "Low Stock Item 1", 1, 1, 2, 5, 50000.0, time.Now(), time.Now()).
-			AddRow(2, "LOW-002", "Low Stock Item 2", 1, 1, 3, 10, 75000.0, time.Now(), time.Now())
+			AddRow(1, "LOW-001", "Low Stock Item 1", 1, 1, 2, 0, 5, money.FromUnits(50000), nil, false, false, money.Amount(0), (*string)(nil), 0, time.Now(), time.Now()).
+			AddRow(2, "LOW-002", "Low Stock Item 2", 1, 1, 3, 0, 10, money.FromUnits(75000), nil, false, false, money.Amount(0), (*string)(nil), 0, time.Now(), time.Now())
 
 		mock.ExpectQuery("SELECT (.+) FROM items WHERE stock < minimum_stock").
 			WithArgs(10, 0).
 			WillReturnRows(dataRows)
 
-		items, total, err := repo.FindLowStock(1, 10)
+		items, total, err := repo.FindLowStock(1, 10, false)
 		assert.NoError(t, err)
 		assert.Equal(t, 2, total)
 		assert.Len(t, items, 2)
@@ -141,19 +160,182 @@ func TestItemRepository_FindLowStock(t *testing.T) {
 
 		dataRows := pgxmock.NewRows([]string{
 			"id", "sku", "name", "category_id", "rack_id",
-			"stock", "minimum_stock", "price", "created_at", "updated_at",
+			"stock", "in_transit", "minimum_stock", "price", "tax_rate", "track_lots", "serialized", "average_cost", "abc_class", "reserved", "created_at", "updated_at",
 		})
 		mock.ExpectQuery("SELECT (.+) FROM items WHERE stock < minimum_stock").
 			WithArgs(10, 0).
 			WillReturnRows(dataRows)
 
-		items, total, err := repo.FindLowStock(1, 10)
+		items, total, err := repo.FindLowStock(1, 10, false)
 		assert.NoError(t, err)
 		assert.Equal(t, 0, total)
 		assert.Len(t, items, 0)
 	})
 }
 
+func TestItemRepository_UpdateABCClasses(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	logger, _ := zap.NewDevelopment()
+	repo := NewItemRepository(mock, logger)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec("UPDATE items SET abc_class (.+) FROM unnest").
+			WithArgs([]int{4, 1, 2}, []string{"A", "B", "C"}).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 3))
+
+		err := repo.UpdateABCClasses([]model.ABCItem{
+			{ItemID: 4, Class: "A"}, {ItemID: 1, Class: "B"}, {ItemID: 2, Class: "C"},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestItemRepository_FindReorderSuggestions(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	logger, _ := zap.NewDevelopment()
+	repo := NewItemRepository(mock, logger)
+
+	params := model.ReorderParams{SalesDays: 90, SafetyDays: 7, CoverDays: 30, LeadTimeDays: 7}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery("WITH restocked AS (.+) WHERE condition = 'restock' (.+) sold AS (.+) SELECT COUNT(.+) FROM suggestions WHERE reorder_point <> minimum_stock").
+			WithArgs(90, 7, 30, 7, []int(nil)).
+			WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+
+		supplierID, supplierName := 2, "PT Sumber Pangan"
+		mock.ExpectQuery("WITH restocked AS (.+) FROM suggestions (.+) ORDER BY stock - reorder_point ASC").
+			WithArgs(90, 7, 30, 7, []int(nil), 10, 0).
+			WillReturnRows(pgxmock.NewRows([]string{
+				"id", "sku", "name", "stock", "minimum_stock", "units", "supplier_id", "supplier_name",
+				"lead_time_days", "safety_stock", "reorder_point", "order_quantity",
+			}).AddRow(1, "RICE-5KG", "Beras 5kg", 12, 5, 180, &supplierID, &supplierName, 14, 14, 42, 60))
+
+		suggestions, total, err := repo.FindReorderSuggestions(params, nil, 1, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Len(t, suggestions, 1)
+		assert.Equal(t, 42, suggestions[0].ReorderPoint)
+		assert.Equal(t, "PT Sumber Pangan", *suggestions[0].SupplierName)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestItemRepository_UpdateMinimumStocks(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	logger, _ := zap.NewDevelopment()
+	repo := NewItemRepository(mock, logger)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec("UPDATE items SET minimum_stock (.+) FROM unnest").
+			WithArgs([]int{1, 3}, []int{42, 9}).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+		err := repo.UpdateMinimumStocks([]model.ReorderSuggestion{{ItemID: 1, ReorderPoint: 42}, {ItemID: 3, ReorderPoint: 9}})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestItemRepository_Import(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	logger, _ := zap.NewDevelopment()
+	repo := NewItemRepository(mock, logger)
+
+	importRows := func() []model.ItemImport {
+		return []model.ItemImport{
+			{Line: 2, Item: model.Item{SKU: "MOU-001", Name: "Mouse", CategoryID: 2, RackID: 3, MinimumStock: 1, Price: money.FromUnits(150000)}},
+			{Line: 3, Item: model.Item{ID: 8, SKU: "KEY-001", Name: "Keyboard", CategoryID: 2, RackID: 3, Stock: 4, MinimumStock: 1, Price: money.FromUnits(250000)}},
+		}
+	}
+
+	t.Run("Partial - Failed Row Rolled Back Alone", func(t *testing.T) {
+		rows := importRows()
+
+		mock.ExpectBegin()
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO items").
+			WithArgs("MOU-001", "Mouse", 2, 3, 1, money.FromUnits(150000), (*money.Rate)(nil), false, false, money.Amount(0)).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(9, time.Now(), time.Now()))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE items").
+			WithArgs("Keyboard", 2, 3, 1, money.FromUnits(250000), 8).
+			WillReturnError(pgx.ErrNoRows)
+		mock.ExpectRollback()
+		mock.ExpectCommit()
+
+		err := repo.Import(rows, 7, true, false)
+		assert.NoError(t, err)
+		assert.Equal(t, model.ItemImportCreated, rows[0].Status)
+		assert.Equal(t, 9, rows[0].Item.ID)
+		assert.Equal(t, model.ItemImportFailed, rows[1].Status)
+		assert.EqualError(t, rows[1].Err, "item not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error - Stock Correction Beyond Rack Stock", func(t *testing.T) {
+		rows := importRows()[1:]
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE items").
+			WithArgs("Keyboard", 2, 3, 1, money.FromUnits(250000), 8).
+			WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(6))
+		mock.ExpectQuery("FROM item_locations l").
+			WithArgs(8, 3).
+			WillReturnRows(pgxmock.NewRows([]string{"codes"}).AddRow([]string{}))
+		mock.ExpectExec("UPDATE item_locations").
+			WithArgs(8, 3, -2).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectRollback()
+
+		err := repo.Import(rows, 7, false, false)
+		assert.NoError(t, err)
+		assert.Equal(t, model.ItemImportFailed, rows[0].Status)
+		assert.ErrorIs(t, rows[0].Err, ErrInsufficientStock)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error - Stock Change Of Item In Other Racks", func(t *testing.T) {
+		rows := importRows()[1:]
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("UPDATE items").
+			WithArgs("Keyboard", 2, 3, 1, money.FromUnits(250000), 8).
+			WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(6))
+		mock.ExpectQuery(`FROM item_locations l (.+) l.rack_id <> \$2 AND l.quantity > 0`).
+			WithArgs(8, 3).
+			WillReturnRows(pgxmock.NewRows([]string{"codes"}).AddRow([]string{"B-02", "C-01"}))
+		mock.ExpectRollback()
+
+		err := repo.Import(rows, 7, false, false)
+		assert.NoError(t, err)
+		assert.Equal(t, model.ItemImportFailed, rows[0].Status)
+		assert.EqualError(t, rows[0].Err, "stock of an item also held in rack B-02, C-01 can't be changed by an import, adjust it per rack")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestItemRepository_Update(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -171,13 +353,13 @@ func TestItemRepository_Update(t *testing.T) {
 		RackID:       1,
 		Stock:        20,
 		MinimumStock: 5,
-		Price:        150000,
+		Price:        money.FromUnits(150000),
 	}
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectExec("UPDATE items").
 			WithArgs(item.SKU, item.Name, item.CategoryID, item.RackID,
-				item.Stock, item.MinimumStock, item.Price, 1).
+				item.MinimumStock, item.Price, item.TaxRate, 1).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
 		err := repo.Update(1, item)
@@ -187,7 +369,7 @@ func TestItemRepository_Update(t *testing.T) {
 	t.Run("Error - Item Not Found", func(t *testing.T) {
 		mock.ExpectExec("UPDATE items").
 			WithArgs(item.SKU, item.Name, item.CategoryID, item.RackID,
-				item.Stock, item.MinimumStock, item.Price, 999).
+				item.MinimumStock, item.Price, item.TaxRate, 999).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
 
 		err := repo.Update(999, item)
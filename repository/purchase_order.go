@@ -0,0 +1,280 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type PurchaseOrderRepository interface {
+	Create(order *model.PurchaseOrder, items []model.PurchaseOrderItem) error
+	FindByID(id int) (*model.PurchaseOrder, error)
+	FindOrderItems(orderID int) ([]model.PurchaseOrderItem, error)
+	FindAll(status string, supplierID, page, limit int) ([]model.PurchaseOrder, int, error)
+	Update(id int, order *model.PurchaseOrder, items []model.PurchaseOrderItem) error
+	Approve(id int, userID int) error
+	Cancel(id int) error
+}
+
+type purchaseOrderRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewPurchaseOrderRepository(db database.PgxIface, log *zap.Logger) PurchaseOrderRepository {
+	return &purchaseOrderRepository{db: db, Logger: log}
+}
+
+func (r *purchaseOrderRepository) Create(order *model.PurchaseOrder, items []model.PurchaseOrderItem) error {
+	// Begin transaction
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	// Insert purchase order header
+	query := `
+		INSERT INTO purchase_orders (supplier_id, status, note, total_amount, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	order.Status = model.PurchaseOrderStatusDraft
+	err = tx.QueryRow(context.Background(), query,
+		order.SupplierID, order.Status, order.Note, order.TotalAmount, order.CreatedBy,
+	).Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
+
+	if err != nil {
+		r.Logger.Error("error creating purchase order", zap.Error(err))
+		return err
+	}
+
+	if err := r.insertOrderItems(tx, order.ID, items); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *purchaseOrderRepository) FindByID(id int) (*model.PurchaseOrder, error) {
+	query := `
+		SELECT id, supplier_id, status, note, total_amount, created_by,
+		       approved_by, approved_at, created_at, updated_at
+		FROM purchase_orders
+		WHERE id = $1
+	`
+	var order model.PurchaseOrder
+	err := r.db.QueryRow(context.Background(), query, id).Scan(
+		&order.ID, &order.SupplierID, &order.Status, &order.Note, &order.TotalAmount,
+		&order.CreatedBy, &order.ApprovedBy, &order.ApprovedAt, &order.CreatedAt, &order.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.Error("error finding purchase order by id", zap.Error(err))
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *purchaseOrderRepository) FindOrderItems(orderID int) ([]model.PurchaseOrderItem, error) {
+	query := `
+		SELECT id, purchase_order_id, item_id, quantity_ordered, quantity_received, unit_cost, subtotal
+		FROM purchase_order_items
+		WHERE purchase_order_id = $1
+		ORDER BY id ASC
+	`
+	rows, err := r.db.Query(context.Background(), query, orderID)
+	if err != nil {
+		r.Logger.Error("error querying purchase order items", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.PurchaseOrderItem
+	for rows.Next() {
+		var item model.PurchaseOrderItem
+		err := rows.Scan(
+			&item.ID, &item.PurchaseOrderID, &item.ItemID, &item.QuantityOrdered,
+			&item.QuantityReceived, &item.UnitCost, &item.Subtotal,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning purchase order item", zap.Error(err))
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r *purchaseOrderRepository) FindAll(status string, supplierID, page, limit int) ([]model.PurchaseOrder, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count, empty status and zero supplier mean no filter
+	var total int
+	countQuery := `
+		SELECT COUNT(*) FROM purchase_orders
+		WHERE ($1 = '' OR status = $1) AND ($2 = 0 OR supplier_id = $2)
+	`
+	err := r.db.QueryRow(context.Background(), countQuery, status, supplierID).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting purchase orders", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := `
+		SELECT id, supplier_id, status, note, total_amount, created_by,
+		       approved_by, approved_at, created_at, updated_at
+		FROM purchase_orders
+		WHERE ($1 = '' OR status = $1) AND ($2 = 0 OR supplier_id = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(context.Background(), query, status, supplierID, limit, offset)
+	if err != nil {
+		r.Logger.Error("error querying purchase orders", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var orders []model.PurchaseOrder
+	for rows.Next() {
+		var order model.PurchaseOrder
+		err := rows.Scan(
+			&order.ID, &order.SupplierID, &order.Status, &order.Note, &order.TotalAmount,
+			&order.CreatedBy, &order.ApprovedBy, &order.ApprovedAt, &order.CreatedAt, &order.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning purchase order", zap.Error(err))
+			return nil, 0, err
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, total, nil
+}
+
+// Update replaces the header and lines of a draft purchase order
+func (r *purchaseOrderRepository) Update(id int, order *model.PurchaseOrder, items []model.PurchaseOrderItem) error {
+	// Begin transaction
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	query := `
+		UPDATE purchase_orders
+		SET supplier_id = $1, note = $2, total_amount = $3, updated_at = NOW()
+		WHERE id = $4 AND status = $5
+	`
+	result, err := tx.Exec(context.Background(), query,
+		order.SupplierID, order.Note, order.TotalAmount, id, model.PurchaseOrderStatusDraft,
+	)
+	if err != nil {
+		r.Logger.Error("error updating purchase order", zap.Error(err))
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New("only draft purchase orders can be updated")
+	}
+
+	// Replace the lines
+	_, err = tx.Exec(context.Background(), `DELETE FROM purchase_order_items WHERE purchase_order_id = $1`, id)
+	if err != nil {
+		r.Logger.Error("error deleting purchase order items", zap.Error(err))
+		return err
+	}
+
+	if err := r.insertOrderItems(tx, id, items); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *purchaseOrderRepository) Approve(id int, userID int) error {
+	query := `
+		UPDATE purchase_orders
+		SET status = $1, approved_by = $2, approved_at = NOW(), updated_at = NOW()
+		WHERE id = $3 AND status = $4
+	`
+	result, err := r.db.Exec(context.Background(), query,
+		model.PurchaseOrderStatusApproved, userID, id, model.PurchaseOrderStatusDraft,
+	)
+	if err != nil {
+		r.Logger.Error("error approving purchase order", zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("only draft purchase orders can be approved")
+	}
+	return nil
+}
+
+// Cancel is only allowed before any goods have been received against the order
+func (r *purchaseOrderRepository) Cancel(id int) error {
+	query := `
+		UPDATE purchase_orders
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status IN ($3, $4)
+	`
+	result, err := r.db.Exec(context.Background(), query,
+		model.PurchaseOrderStatusCancelled, id, model.PurchaseOrderStatusDraft, model.PurchaseOrderStatusApproved,
+	)
+	if err != nil {
+		r.Logger.Error("error cancelling purchase order", zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("only draft or approved purchase orders can be cancelled")
+	}
+	return nil
+}
+
+func (r *purchaseOrderRepository) insertOrderItems(tx pgx.Tx, orderID int, items []model.PurchaseOrderItem) error {
+	itemQuery := `
+		INSERT INTO purchase_order_items (purchase_order_id, item_id, quantity_ordered, quantity_received, unit_cost, subtotal)
+		VALUES ($1, $2, $3, 0, $4, $5)
+		RETURNING id
+	`
+	for i := range items {
+		items[i].PurchaseOrderID = orderID
+		err := tx.QueryRow(context.Background(), itemQuery,
+			items[i].PurchaseOrderID, items[i].ItemID, items[i].QuantityOrdered,
+			items[i].UnitCost, items[i].Subtotal,
+		).Scan(&items[i].ID)
+
+		if err != nil {
+			r.Logger.Error("error creating purchase order item", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
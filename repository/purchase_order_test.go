@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestPurchaseOrderRepository_Create_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewPurchaseOrderRepository(mockDB, zap.NewNop())
+
+	order := &model.PurchaseOrder{SupplierID: 1, TotalAmount: money.FromUnits(500000), CreatedBy: 2}
+	items := []model.PurchaseOrderItem{
+		{ItemID: 3, QuantityOrdered: 10, UnitCost: money.FromUnits(50000), Subtotal: money.FromUnits(500000)},
+	}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`INSERT INTO purchase_orders`).
+		WithArgs(1, model.PurchaseOrderStatusDraft, order.Note, money.FromUnits(500000), 2).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).
+			AddRow(7, time.Now(), time.Now()))
+	mockDB.
+		ExpectQuery(`INSERT INTO purchase_order_items`).
+		WithArgs(7, 3, 10, money.FromUnits(50000), money.FromUnits(500000)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+	mockDB.ExpectCommit()
+
+	err = repo.Create(order, items)
+	require.NoError(t, err)
+	require.Equal(t, 7, order.ID)
+	require.Equal(t, model.PurchaseOrderStatusDraft, order.Status)
+	require.Equal(t, 7, items[0].PurchaseOrderID)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestPurchaseOrderRepository_Update_NotDraft(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewPurchaseOrderRepository(mockDB, zap.NewNop())
+
+	order := &model.PurchaseOrder{SupplierID: 1, TotalAmount: money.FromUnits(100)}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectExec(`UPDATE purchase_orders`).
+		WithArgs(1, order.Note, money.FromUnits(100), 7, model.PurchaseOrderStatusDraft).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mockDB.ExpectRollback()
+
+	err = repo.Update(7, order, nil)
+	require.Error(t, err)
+	require.Equal(t, "only draft purchase orders can be updated", err.Error())
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestPurchaseOrderRepository_Approve_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewPurchaseOrderRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectExec(`UPDATE purchase_orders`).
+		WithArgs(model.PurchaseOrderStatusApproved, 2, 7, model.PurchaseOrderStatusDraft).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	err = repo.Approve(7, 2)
+	require.NoError(t, err)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestPurchaseOrderRepository_Cancel_AlreadyReceiving(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewPurchaseOrderRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectExec(`UPDATE purchase_orders`).
+		WithArgs(model.PurchaseOrderStatusCancelled, 7, model.PurchaseOrderStatusDraft, model.PurchaseOrderStatusApproved).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	err = repo.Cancel(7)
+	require.Error(t, err)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
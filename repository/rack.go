@@ -14,7 +14,9 @@ type RackRepository interface {
 	Create(rack *model.Rack) error
 	FindByID(id int) (*model.Rack, error)
 	FindByWarehouseAndCode(warehouseID int, code string) (*model.Rack, error)
+	FindByCode(code string) ([]model.Rack, error)
 	FindAll(page, limit int) ([]model.Rack, int, error)
+	FindAfter(warehouseID, afterID, limit int) ([]model.Rack, error)
 	FindByWarehouseID(warehouseID, page, limit int) ([]model.Rack, int, error)
 	Update(id int, data *model.Rack) error
 	Delete(id int) error
@@ -89,6 +91,38 @@ func (r *rackRepository) FindByWarehouseAndCode(warehouseID int, code string) (*
 	return &rack, nil
 }
 
+// FindByCode finds the racks with the code in every warehouse, codes are only
+// unique within a warehouse
+func (r *rackRepository) FindByCode(code string) ([]model.Rack, error) {
+	query := `
+		SELECT id, warehouse_id, code, description, created_at, updated_at
+		FROM racks
+		WHERE code = $1
+		ORDER BY warehouse_id
+	`
+	rows, err := r.db.Query(context.Background(), query, code)
+	if err != nil {
+		r.Logger.Error("error finding racks by code", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var racks []model.Rack
+	for rows.Next() {
+		var rack model.Rack
+		err := rows.Scan(
+			&rack.ID, &rack.WarehouseID, &rack.Code, &rack.Description,
+			&rack.CreatedAt, &rack.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning rack", zap.Error(err))
+			return nil, err
+		}
+		racks = append(racks, rack)
+	}
+	return racks, nil
+}
+
 func (r *rackRepository) FindAll(page, limit int) ([]model.Rack, int, error) {
 	offset := (page - 1) * limit
 
@@ -132,6 +166,41 @@ func (r *rackRepository) FindAll(page, limit int) ([]model.Rack, int, error) {
 	return racks, total, nil
 }
 
+// FindAfter reads up to limit racks with an id above afterID in id order, of
+// one warehouse or of all when warehouseID is 0. Exports page with it, a page
+// can't skip or repeat racks added or removed meanwhile.
+func (r *rackRepository) FindAfter(warehouseID, afterID, limit int) ([]model.Rack, error) {
+	query := `
+		SELECT id, warehouse_id, code, description, created_at, updated_at
+		FROM racks
+		WHERE ($1 = 0 OR warehouse_id = $1) AND id > $2
+		ORDER BY id ASC
+		LIMIT $3
+	`
+	rows, err := r.db.Query(context.Background(), query, warehouseID, afterID, limit)
+	if err != nil {
+		r.Logger.Error("error querying racks after id", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var racks []model.Rack
+	for rows.Next() {
+		var rack model.Rack
+		err := rows.Scan(
+			&rack.ID, &rack.WarehouseID, &rack.Code, &rack.Description,
+			&rack.CreatedAt, &rack.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning rack", zap.Error(err))
+			return nil, err
+		}
+		racks = append(racks, rack)
+	}
+
+	return racks, nil
+}
+
 func (r *rackRepository) FindByWarehouseID(warehouseID, page, limit int) ([]model.Rack, int, error) {
 	offset := (page - 1) * limit
 
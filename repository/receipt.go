@@ -0,0 +1,390 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var ErrOverReceipt = errors.New("received quantity exceeds purchase order")
+
+type ReceiptRepository interface {
+	Create(receipt *model.Receipt, items []model.ReceiptItem) error
+	FindByID(id int) (*model.Receipt, error)
+	FindByDeliveryNote(supplierID int, deliveryNoteNumber string) (*model.Receipt, error)
+	FindReceiptItems(receiptID int) ([]model.ReceiptItem, error)
+	FindAll(status string, supplierID, page, limit int) ([]model.Receipt, int, error)
+	Void(id int, userID int) error
+}
+
+type receiptRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewReceiptRepository(db database.PgxIface, log *zap.Logger) ReceiptRepository {
+	return &receiptRepository{db: db, Logger: log}
+}
+
+// Create books the receipt and puts every line into its rack in one transaction,
+// lines received against a purchase order also move the order forward
+func (r *receiptRepository) Create(receipt *model.Receipt, items []model.ReceiptItem) error {
+	// Begin transaction
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	// Lock the purchase order so concurrent receipts can't over-receive it
+	if receipt.PurchaseOrderID != nil {
+		var status string
+		lockQuery := `SELECT status FROM purchase_orders WHERE id = $1 FOR UPDATE`
+		err = tx.QueryRow(context.Background(), lockQuery, *receipt.PurchaseOrderID).Scan(&status)
+		if err == pgx.ErrNoRows {
+			return errors.New("purchase order not found")
+		}
+		if err != nil {
+			r.Logger.Error("error locking purchase order", zap.Error(err))
+			return err
+		}
+		if status != model.PurchaseOrderStatusApproved && status != model.PurchaseOrderStatusPartiallyReceived {
+			return errors.New("purchase order is not open for receiving")
+		}
+	}
+
+	// Insert receipt header
+	query := `
+		INSERT INTO goods_receipts (supplier_id, purchase_order_id, delivery_note_number, status, note,
+		                            total_amount, received_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	receipt.Status = model.ReceiptStatusReceived
+	err = tx.QueryRow(context.Background(), query,
+		receipt.SupplierID, receipt.PurchaseOrderID, receipt.DeliveryNoteNumber, receipt.Status,
+		receipt.Note, receipt.TotalAmount, receipt.ReceivedBy,
+	).Scan(&receipt.ID, &receipt.CreatedAt, &receipt.UpdatedAt)
+
+	if err != nil {
+		r.Logger.Error("error creating receipt", zap.Error(err))
+		return err
+	}
+
+	// Insert receipt items
+	itemQuery := `
+		INSERT INTO goods_receipt_items (receipt_id, item_id, rack_id, quantity, unit_cost, subtotal,
+		                                 lot_number, expiry_date, serial_numbers)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+	for i := range items {
+		items[i].ReceiptID = receipt.ID
+		err = tx.QueryRow(context.Background(), itemQuery,
+			items[i].ReceiptID, items[i].ItemID, items[i].RackID, items[i].Quantity,
+			items[i].UnitCost, items[i].Subtotal, items[i].LotNumber, items[i].ExpiryDate,
+			items[i].SerialNumbers,
+		).Scan(&items[i].ID)
+
+		if err != nil {
+			r.Logger.Error("error creating receipt item", zap.Error(err))
+			return err
+		}
+
+		if items[i].LotNumber != nil {
+			if err := openLot(context.Background(), tx, items[i].ItemID, items[i].RackID, *items[i].LotNumber, items[i].ExpiryDate); err != nil {
+				r.Logger.Error("error creating item lot", zap.Error(err))
+				return err
+			}
+		}
+
+		// Update item stock
+		movement := receiptMovement(receipt.ID, receipt.ReceivedBy, model.MovementTypeReceipt, items[i], items[i].Quantity)
+		if err := applyStockMovement(context.Background(), tx, movement); err != nil {
+			r.Logger.Error("error updating item stock", zap.Error(err))
+			return err
+		}
+
+		if receipt.PurchaseOrderID != nil {
+			if err := r.receiveOrderItem(tx, *receipt.PurchaseOrderID, items[i].ItemID, items[i].Quantity); err != nil {
+				return err
+			}
+		}
+	}
+
+	if receipt.PurchaseOrderID != nil {
+		if err := r.refreshOrderStatus(tx, *receipt.PurchaseOrderID); err != nil {
+			return err
+		}
+	}
+
+	// Commit transaction
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *receiptRepository) FindByID(id int) (*model.Receipt, error) {
+	query := `
+		SELECT id, supplier_id, purchase_order_id, delivery_note_number, status, note, total_amount,
+		       received_by, voided_by, voided_at, created_at, updated_at
+		FROM goods_receipts
+		WHERE id = $1
+	`
+	var receipt model.Receipt
+	err := r.db.QueryRow(context.Background(), query, id).Scan(
+		&receipt.ID, &receipt.SupplierID, &receipt.PurchaseOrderID, &receipt.DeliveryNoteNumber,
+		&receipt.Status, &receipt.Note, &receipt.TotalAmount, &receipt.ReceivedBy,
+		&receipt.VoidedBy, &receipt.VoidedAt, &receipt.CreatedAt, &receipt.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.Error("error finding receipt by id", zap.Error(err))
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// FindByDeliveryNote returns the active receipt booked for the supplier's delivery note
+func (r *receiptRepository) FindByDeliveryNote(supplierID int, deliveryNoteNumber string) (*model.Receipt, error) {
+	query := `
+		SELECT id, supplier_id, purchase_order_id, delivery_note_number, status, note, total_amount,
+		       received_by, voided_by, voided_at, created_at, updated_at
+		FROM goods_receipts
+		WHERE supplier_id = $1 AND delivery_note_number = $2 AND status = $3
+	`
+	var receipt model.Receipt
+	err := r.db.QueryRow(context.Background(), query, supplierID, deliveryNoteNumber, model.ReceiptStatusReceived).Scan(
+		&receipt.ID, &receipt.SupplierID, &receipt.PurchaseOrderID, &receipt.DeliveryNoteNumber,
+		&receipt.Status, &receipt.Note, &receipt.TotalAmount, &receipt.ReceivedBy,
+		&receipt.VoidedBy, &receipt.VoidedAt, &receipt.CreatedAt, &receipt.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.Error("error finding receipt by delivery note", zap.Error(err))
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+func (r *receiptRepository) FindReceiptItems(receiptID int) ([]model.ReceiptItem, error) {
+	return r.findReceiptItems(r.db, receiptID)
+}
+
+func (r *receiptRepository) findReceiptItems(db database.PgxIface, receiptID int) ([]model.ReceiptItem, error) {
+	query := `
+		SELECT id, receipt_id, item_id, rack_id, quantity, unit_cost, subtotal, lot_number, expiry_date, serial_numbers
+		FROM goods_receipt_items
+		WHERE receipt_id = $1
+		ORDER BY id ASC
+	`
+	rows, err := db.Query(context.Background(), query, receiptID)
+	if err != nil {
+		r.Logger.Error("error querying receipt items", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.ReceiptItem
+	for rows.Next() {
+		var item model.ReceiptItem
+		err := rows.Scan(
+			&item.ID, &item.ReceiptID, &item.ItemID, &item.RackID,
+			&item.Quantity, &item.UnitCost, &item.Subtotal, &item.LotNumber, &item.ExpiryDate, &item.SerialNumbers,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning receipt item", zap.Error(err))
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r *receiptRepository) FindAll(status string, supplierID, page, limit int) ([]model.Receipt, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count, empty status and zero supplier mean no filter
+	var total int
+	countQuery := `
+		SELECT COUNT(*) FROM goods_receipts
+		WHERE ($1 = '' OR status = $1) AND ($2 = 0 OR supplier_id = $2)
+	`
+	err := r.db.QueryRow(context.Background(), countQuery, status, supplierID).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting receipts", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := `
+		SELECT id, supplier_id, purchase_order_id, delivery_note_number, status, note, total_amount,
+		       received_by, voided_by, voided_at, created_at, updated_at
+		FROM goods_receipts
+		WHERE ($1 = '' OR status = $1) AND ($2 = 0 OR supplier_id = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(context.Background(), query, status, supplierID, limit, offset)
+	if err != nil {
+		r.Logger.Error("error querying receipts", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var receipts []model.Receipt
+	for rows.Next() {
+		var receipt model.Receipt
+		err := rows.Scan(
+			&receipt.ID, &receipt.SupplierID, &receipt.PurchaseOrderID, &receipt.DeliveryNoteNumber,
+			&receipt.Status, &receipt.Note, &receipt.TotalAmount, &receipt.ReceivedBy,
+			&receipt.VoidedBy, &receipt.VoidedAt, &receipt.CreatedAt, &receipt.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning receipt", zap.Error(err))
+			return nil, 0, err
+		}
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts, total, nil
+}
+
+// Void takes the received goods back out of their racks, it fails with
+// ErrInsufficientStock when part of the delivery has already left the rack
+func (r *receiptRepository) Void(id int, userID int) error {
+	// Begin transaction
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	var purchaseOrderID *int
+	query := `
+		UPDATE goods_receipts
+		SET status = $1, voided_by = $2, voided_at = NOW(), updated_at = NOW()
+		WHERE id = $3 AND status = $4
+		RETURNING purchase_order_id
+	`
+	err = tx.QueryRow(context.Background(), query,
+		model.ReceiptStatusVoided, userID, id, model.ReceiptStatusReceived,
+	).Scan(&purchaseOrderID)
+	if err == pgx.ErrNoRows {
+		return errors.New("receipt is already voided")
+	}
+	if err != nil {
+		r.Logger.Error("error voiding receipt", zap.Error(err))
+		return err
+	}
+
+	items, err := r.findReceiptItems(tx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		movement := receiptMovement(id, userID, model.MovementTypeReceiptVoid, item, -item.Quantity)
+		if err := applyStockMovement(context.Background(), tx, movement); err != nil {
+			r.Logger.Error("error reversing receipt stock", zap.Error(err))
+			return err
+		}
+
+		if purchaseOrderID != nil {
+			if err := r.receiveOrderItem(tx, *purchaseOrderID, item.ItemID, -item.Quantity); err != nil {
+				return err
+			}
+		}
+	}
+
+	if purchaseOrderID != nil {
+		if err := r.refreshOrderStatus(tx, *purchaseOrderID); err != nil {
+			return err
+		}
+	}
+
+	// Commit transaction
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// receiveOrderItem moves the received quantity of a purchase order line by quantity
+func (r *receiptRepository) receiveOrderItem(tx pgx.Tx, orderID, itemID, quantity int) error {
+	query := `
+		UPDATE purchase_order_items
+		SET quantity_received = quantity_received + $1
+		WHERE purchase_order_id = $2 AND item_id = $3
+		  AND quantity_received + $1 BETWEEN 0 AND quantity_ordered
+	`
+	result, err := tx.Exec(context.Background(), query, quantity, orderID, itemID)
+	if err != nil {
+		r.Logger.Error("error updating purchase order item", zap.Error(err))
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrOverReceipt
+	}
+	return nil
+}
+
+// refreshOrderStatus derives the purchase order status from its received quantities
+func (r *receiptRepository) refreshOrderStatus(tx pgx.Tx, orderID int) error {
+	query := `
+		UPDATE purchase_orders po
+		SET status = CASE
+		        WHEN NOT EXISTS (SELECT 1 FROM purchase_order_items
+		                         WHERE purchase_order_id = po.id AND quantity_received < quantity_ordered) THEN $2
+		        WHEN EXISTS (SELECT 1 FROM purchase_order_items
+		                     WHERE purchase_order_id = po.id AND quantity_received > 0) THEN $3
+		        ELSE $4
+		    END,
+		    updated_at = NOW()
+		WHERE po.id = $1
+	`
+	_, err := tx.Exec(context.Background(), query, orderID,
+		model.PurchaseOrderStatusReceived, model.PurchaseOrderStatusPartiallyReceived, model.PurchaseOrderStatusApproved,
+	)
+	if err != nil {
+		r.Logger.Error("error updating purchase order status", zap.Error(err))
+	}
+	return err
+}
+
+// receiptMovement builds the ledger entry for one receipt line
+func receiptMovement(receiptID, userID int, movementType string, line model.ReceiptItem, quantity int) *model.StockMovement {
+	referenceType := model.ReferenceTypeReceipt
+	return &model.StockMovement{
+		ItemID:        line.ItemID,
+		UserID:        userID,
+		RackID:        line.RackID,
+		MovementType:  movementType,
+		Quantity:      quantity,
+		ReferenceType: &referenceType,
+		ReferenceID:   &receiptID,
+		LotNumber:     line.LotNumber,
+		SerialNumbers: line.SerialNumbers,
+		UnitCost:      &line.UnitCost,
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReceiptRepository_Create_WithPurchaseOrder(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReceiptRepository(mockDB, zap.NewNop())
+
+	orderID := 4
+	receipt := &model.Receipt{
+		SupplierID:         1,
+		PurchaseOrderID:    &orderID,
+		DeliveryNoteNumber: "SJ-001",
+		TotalAmount:        money.FromUnits(500000),
+		ReceivedBy:         2,
+	}
+	items := []model.ReceiptItem{
+		{ItemID: 3, RackID: 1, Quantity: 10, UnitCost: money.FromUnits(50000), Subtotal: money.FromUnits(500000)},
+	}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`SELECT status FROM purchase_orders`).
+		WithArgs(4).
+		WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(model.PurchaseOrderStatusApproved))
+	mockDB.
+		ExpectQuery(`INSERT INTO goods_receipts`).
+		WithArgs(1, &orderID, "SJ-001", model.ReceiptStatusReceived, receipt.Note, money.FromUnits(500000), 2).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(9, time.Now(), time.Now()))
+	mockDB.
+		ExpectQuery(`INSERT INTO goods_receipt_items`).
+		WithArgs(9, 3, 1, 10, money.FromUnits(50000), money.FromUnits(500000), (*string)(nil), (*time.Time)(nil), []string(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+	mockDB.
+		ExpectExec(`INSERT INTO item_locations`).
+		WithArgs(3, 1, 10).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockDB.
+		ExpectQuery(`UPDATE items`).
+		WithArgs(10, 3).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(13))
+	unitCost := money.FromUnits(50000)
+	mockDB.
+		ExpectQuery(`INSERT INTO cost_layers`).
+		WithArgs(3, 10, &unitCost, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"unit_cost"}).AddRow(unitCost))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(3, 2, 1, model.MovementTypeReceipt, 10, 13,
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	mockDB.
+		ExpectExec(`UPDATE purchase_order_items`).
+		WithArgs(10, 4, 3).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockDB.
+		ExpectExec(`UPDATE purchase_orders po`).
+		WithArgs(4, model.PurchaseOrderStatusReceived, model.PurchaseOrderStatusPartiallyReceived, model.PurchaseOrderStatusApproved).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockDB.ExpectCommit()
+
+	err = repo.Create(receipt, items)
+	require.NoError(t, err)
+	require.Equal(t, 9, receipt.ID)
+	require.Equal(t, 9, items[0].ReceiptID)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReceiptRepository_Create_OverReceipt(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReceiptRepository(mockDB, zap.NewNop())
+
+	orderID := 4
+	receipt := &model.Receipt{SupplierID: 1, PurchaseOrderID: &orderID, DeliveryNoteNumber: "SJ-002", ReceivedBy: 2}
+	items := []model.ReceiptItem{{ItemID: 3, RackID: 1, Quantity: 99}}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`SELECT status FROM purchase_orders`).
+		WithArgs(4).
+		WillReturnRows(pgxmock.NewRows([]string{"status"}).AddRow(model.PurchaseOrderStatusPartiallyReceived))
+	mockDB.
+		ExpectQuery(`INSERT INTO goods_receipts`).
+		WithArgs(1, &orderID, "SJ-002", model.ReceiptStatusReceived, receipt.Note, money.Amount(0), 2).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(10, time.Now(), time.Now()))
+	mockDB.
+		ExpectQuery(`INSERT INTO goods_receipt_items`).
+		WithArgs(10, 3, 1, 99, money.Amount(0), money.Amount(0), (*string)(nil), (*time.Time)(nil), []string(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(2))
+	mockDB.
+		ExpectExec(`INSERT INTO item_locations`).
+		WithArgs(3, 1, 99).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockDB.
+		ExpectQuery(`UPDATE items`).
+		WithArgs(99, 3).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(102))
+	mockDB.
+		ExpectQuery(`INSERT INTO cost_layers`).
+		WithArgs(3, 99, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"unit_cost"}).AddRow(money.Amount(0)))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(3, 2, 1, model.MovementTypeReceipt, 99, 102,
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(2, time.Now()))
+	mockDB.
+		ExpectExec(`UPDATE purchase_order_items`).
+		WithArgs(99, 4, 3).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mockDB.ExpectRollback()
+
+	err = repo.Create(receipt, items)
+	require.ErrorIs(t, err, ErrOverReceipt)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReceiptRepository_Void_InsufficientStock(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReceiptRepository(mockDB, zap.NewNop())
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`UPDATE goods_receipts`).
+		WithArgs(model.ReceiptStatusVoided, 2, 9, model.ReceiptStatusReceived).
+		WillReturnRows(pgxmock.NewRows([]string{"purchase_order_id"}).AddRow(nil))
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM goods_receipt_items`).
+		WithArgs(9).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "receipt_id", "item_id", "rack_id", "quantity", "unit_cost", "subtotal", "lot_number", "expiry_date", "serial_numbers"}).
+			AddRow(1, 9, 3, 1, 10, money.FromUnits(50000), money.FromUnits(500000), nil, nil, nil))
+	mockDB.
+		ExpectExec(`UPDATE item_locations`).
+		WithArgs(3, 1, -10).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mockDB.ExpectRollback()
+
+	err = repo.Void(9, 2)
+	require.ErrorIs(t, err, ErrInsufficientStock)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReceiptRepository_Void_AlreadyVoided(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReceiptRepository(mockDB, zap.NewNop())
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`UPDATE goods_receipts`).
+		WithArgs(model.ReceiptStatusVoided, 2, 9, model.ReceiptStatusReceived).
+		WillReturnRows(pgxmock.NewRows([]string{"purchase_order_id"}))
+	mockDB.ExpectRollback()
+
+	err = repo.Void(9, 2)
+	require.Error(t, err)
+	require.Equal(t, "receipt is already voided", err.Error())
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
@@ -3,6 +3,9 @@ package repository
 import (
 	"context"
 	"project-app-inventory/database"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -11,10 +14,17 @@ type ReportRepository interface {
 	GetTotalItems() (int, error)
 	GetLowStockItems() (int, error)
 	GetTotalSales() (int, error)
-	GetTotalRevenue() (float64, error)
+	GetTotalRevenue() (money.Amount, error)
+	GetTotalRefunds() (money.Amount, error)
+	GetSalesBreakdown() (gross, discount, tax money.Amount, err error)
 	GetActiveUsers() (int, error)
 	GetTotalCategories() (int, error)
 	GetTotalWarehouses() (int, error)
+	GetGrossMargin(groupBy string, from, to *time.Time, page, limit int) ([]model.GrossMargin, int, error)
+	GetInventoryValuation(asOf *time.Time) ([]model.InventoryValuation, error)
+	GetSalesByPeriod(interval, groupBy, timezone string, from, to time.Time) ([]model.SalesPeriod, error)
+	GetItemMovement(report string, filter model.ItemMovementFilter, page, limit int) ([]model.ItemMovement, int, error)
+	GetABCValues(basis string, from, to time.Time) ([]model.ABCItem, error)
 }
 
 type reportRepository struct {
@@ -65,10 +75,12 @@ func (r *reportRepository) GetTotalSales() (int, error) {
 	return total, nil
 }
 
-func (r *reportRepository) GetTotalRevenue() (float64, error) {
-	var total float64
-	query := `SELECT COALESCE(SUM(total_amount), 0) FROM sales WHERE deleted_at IS NULL`
-	err := r.db.QueryRow(context.Background(), query).Scan(&total)
+// GetTotalRevenue sums the revenue of every sale that is not voided, net of
+// returns like every report counts it (see grossMarginLinesSQL)
+func (r *reportRepository) GetTotalRevenue() (money.Amount, error) {
+	var total money.Amount
+	query := grossMarginLinesSQL + `SELECT COALESCE(SUM(revenue), 0) FROM lines`
+	err := r.db.QueryRow(context.Background(), query, nil, nil).Scan(&total)
 	if err != nil {
 		if r.Logger != nil {
 			r.Logger.Error("error getting total revenue", zap.Error(err))
@@ -78,6 +90,42 @@ func (r *reportRepository) GetTotalRevenue() (float64, error) {
 	return total, nil
 }
 
+// GetTotalRefunds sums the refunds of returns booked against sales that are not voided
+func (r *reportRepository) GetTotalRefunds() (money.Amount, error) {
+	var total money.Amount
+	query := `
+		SELECT COALESCE(SUM(sr.refund_amount), 0)
+		FROM sale_returns sr
+		JOIN sales s ON s.id = sr.sale_id
+		WHERE s.deleted_at IS NULL
+	`
+	err := r.db.QueryRow(context.Background(), query).Scan(&total)
+	if err != nil {
+		if r.Logger != nil {
+			r.Logger.Error("error getting total refunds", zap.Error(err))
+		}
+		return 0, err
+	}
+	return total, nil
+}
+
+// GetSalesBreakdown sums the gross amount, discounts and tax of sales that are not voided
+func (r *reportRepository) GetSalesBreakdown() (gross, discount, tax money.Amount, err error) {
+	query := `
+		SELECT COALESCE(SUM(gross_amount), 0), COALESCE(SUM(discount_amount), 0), COALESCE(SUM(tax_amount), 0)
+		FROM sales
+		WHERE deleted_at IS NULL
+	`
+	err = r.db.QueryRow(context.Background(), query).Scan(&gross, &discount, &tax)
+	if err != nil {
+		if r.Logger != nil {
+			r.Logger.Error("error getting sales breakdown", zap.Error(err))
+		}
+		return 0, 0, 0, err
+	}
+	return gross, discount, tax, nil
+}
+
 func (r *reportRepository) GetActiveUsers() (int, error) {
 	var total int
 	query := `SELECT COUNT(*) FROM users WHERE is_active = true`
@@ -116,3 +164,375 @@ func (r *reportRepository) GetTotalWarehouses() (int, error) {
 	}
 	return total, nil
 }
+
+// grossMarginLinesSQL holds the sale lines of sales that are not voided within
+// $1 and $2, net of returns. Returned units give back their share of the line
+// revenue; restocked units also give back their cost, damaged ones stay a cost.
+// Its revenue is the one every report uses: gross less discounts, before tax,
+// less the share of the units returned, booked when the sale was made.
+const grossMarginLinesSQL = `
+	WITH returned AS (
+		SELECT sale_item_id, SUM(quantity) AS quantity,
+		       COALESCE(SUM(quantity) FILTER (WHERE condition = 'restock'), 0) AS restocked
+		FROM sale_return_items
+		GROUP BY sale_item_id
+	), lines AS (
+		SELECT si.sale_id, s.created_at AS sold_at, s.user_id, i.id AS item_id, i.name AS item_name,
+		       c.id AS category_id, c.name AS category_name, COALESCE(si.rack_id, i.rack_id) AS rack_id,
+		       si.quantity - COALESCE(r.quantity, 0) AS quantity,
+		       ROUND((si.gross_amount - si.discount_amount) * (si.quantity - COALESCE(r.quantity, 0)) / si.quantity, 2) AS revenue,
+		       si.cost_amount - si.unit_cost * COALESCE(r.restocked, 0) AS cost
+		FROM sale_items si
+		JOIN sales s ON s.id = si.sale_id
+		JOIN items i ON i.id = si.item_id
+		JOIN categories c ON c.id = i.category_id
+		LEFT JOIN returned r ON r.sale_item_id = si.id
+		WHERE s.deleted_at IS NULL
+		  AND ($1::timestamptz IS NULL OR s.created_at >= $1)
+		  AND ($2::timestamptz IS NULL OR s.created_at < $2)
+	)
+`
+
+// GetGrossMargin sums revenue and cost of goods sold per sale, item or
+// category, highest margin first
+func (r *reportRepository) GetGrossMargin(groupBy string, from, to *time.Time, page, limit int) ([]model.GrossMargin, int, error) {
+	offset := (page - 1) * limit
+
+	var key, name, soldAt string
+	switch groupBy {
+	case model.GrossMarginByItem:
+		key, name, soldAt = "item_id", "MIN(item_name)", "NULL::timestamptz"
+	case model.GrossMarginByCategory:
+		key, name, soldAt = "category_id", "MIN(category_name)", "NULL::timestamptz"
+	default:
+		key, name, soldAt = "sale_id", "''", "MIN(sold_at)"
+	}
+
+	// Get total count
+	var total int
+	countQuery := grossMarginLinesSQL + `SELECT COUNT(DISTINCT ` + key + `) FROM lines`
+	err := r.db.QueryRow(context.Background(), countQuery, from, to).Scan(&total)
+	if err != nil {
+		if r.Logger != nil {
+			r.Logger.Error("error counting gross margin rows", zap.Error(err))
+		}
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := grossMarginLinesSQL + `
+		SELECT ` + key + `, ` + name + `, ` + soldAt + `, SUM(quantity), SUM(revenue), SUM(cost)
+		FROM lines
+		GROUP BY ` + key + `
+		ORDER BY SUM(revenue) - SUM(cost) DESC, ` + key + ` ASC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.Query(context.Background(), query, from, to, limit, offset)
+	if err != nil {
+		if r.Logger != nil {
+			r.Logger.Error("error querying gross margin", zap.Error(err))
+		}
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var margins []model.GrossMargin
+	for rows.Next() {
+		var margin model.GrossMargin
+		err := rows.Scan(&margin.ID, &margin.Name, &margin.SoldAt, &margin.Quantity, &margin.Revenue, &margin.Cost)
+		if err != nil {
+			if r.Logger != nil {
+				r.Logger.Error("error scanning gross margin", zap.Error(err))
+			}
+			return nil, 0, err
+		}
+		margins = append(margins, margin)
+	}
+
+	return margins, total, nil
+}
+
+// GetInventoryValuation values the stock per warehouse, rack and category, and
+// the stock in transit between racks per category on lines without warehouse
+// and rack. With asOf the stock of every location, and in transit, is rolled
+// back by the ledger movements booked from asOf on, and each item is valued at
+// the average cost it had then. Items without a known cost are valued at their
+// selling price.
+func (r *reportRepository) GetInventoryValuation(asOf *time.Time) ([]model.InventoryValuation, error) {
+	query := `
+		WITH stock AS (
+			SELECT il.item_id, il.rack_id,
+			       il.quantity - COALESCE((
+			           SELECT SUM(sm.quantity) FROM stock_movements sm
+			           WHERE sm.item_id = il.item_id AND sm.rack_id = il.rack_id AND sm.created_at >= $1::timestamptz
+			       ), 0) AS quantity
+			FROM item_locations il
+		), transit AS (
+			SELECT i.id AS item_id,
+			       i.in_transit + COALESCE((
+			           SELECT SUM(sm.quantity) FROM stock_movements sm
+			           WHERE sm.item_id = i.id AND sm.movement_type IN ('transfer_out', 'transfer_in')
+			             AND sm.created_at >= $1::timestamptz
+			       ), 0) AS quantity
+			FROM items i
+		), costs AS (
+			SELECT i.id AS item_id, i.category_id,
+			       COALESCE(NULLIF((
+			           SELECT sm.average_cost FROM stock_movements sm
+			           WHERE sm.item_id = i.id AND sm.created_at < $1::timestamptz AND sm.average_cost IS NOT NULL
+			           ORDER BY sm.created_at DESC, sm.id DESC
+			           LIMIT 1
+			       ), 0), NULLIF(i.average_cost, 0), i.price) AS unit_cost
+			FROM items i
+		)
+		SELECT w.id, w.name AS warehouse_name, r.id, r.code AS rack_code, c.id, c.name AS category_name,
+		       SUM(s.quantity), SUM(s.quantity * costs.unit_cost)
+		FROM stock s
+		JOIN costs ON costs.item_id = s.item_id
+		JOIN racks r ON r.id = s.rack_id
+		JOIN warehouses w ON w.id = r.warehouse_id
+		JOIN categories c ON c.id = costs.category_id
+		WHERE s.quantity > 0
+		GROUP BY w.id, w.name, r.id, r.code, c.id, c.name
+		UNION ALL
+		SELECT NULL, NULL, NULL, NULL, c.id, c.name, SUM(t.quantity), SUM(t.quantity * costs.unit_cost)
+		FROM transit t
+		JOIN costs ON costs.item_id = t.item_id
+		JOIN categories c ON c.id = costs.category_id
+		WHERE t.quantity > 0
+		GROUP BY c.id, c.name
+		ORDER BY warehouse_name ASC NULLS LAST, rack_code ASC, category_name ASC
+	`
+	rows, err := r.db.Query(context.Background(), query, asOf)
+	if err != nil {
+		if r.Logger != nil {
+			r.Logger.Error("error querying inventory valuation", zap.Error(err))
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []model.InventoryValuation
+	for rows.Next() {
+		var line model.InventoryValuation
+		err := rows.Scan(
+			&line.WarehouseID, &line.WarehouseName, &line.RackID, &line.RackCode,
+			&line.CategoryID, &line.CategoryName, &line.Quantity, &line.Value,
+		)
+		if err != nil {
+			if r.Logger != nil {
+				r.Logger.Error("error scanning inventory valuation", zap.Error(err))
+			}
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// GetSalesByPeriod sums the sale lines of sales that are not voided between
+// from and to per interval bucket, and per category, item, warehouse or
+// cashier when groupBy is set. Buckets start at midnight in timezone. Revenue
+// and units are net of returns, taken off the bucket the sale fell in like the
+// gross margin report does.
+func (r *reportRepository) GetSalesByPeriod(interval, groupBy, timezone string, from, to time.Time) ([]model.SalesPeriod, error) {
+	key, name, join := "NULL::int", "NULL::text", ""
+	switch groupBy {
+	case model.SalesGroupByCategory:
+		key, name = "l.category_id", "l.category_name"
+	case model.SalesGroupByItem:
+		key, name = "l.item_id", "l.item_name"
+	case model.SalesGroupByWarehouse:
+		key, name, join = "w.id", "w.name", `
+			JOIN racks r ON r.id = l.rack_id
+			JOIN warehouses w ON w.id = r.warehouse_id`
+	case model.SalesGroupByCashier:
+		key, name, join = "u.id", "u.name", "JOIN users u ON u.id = l.user_id"
+	}
+
+	query := grossMarginLinesSQL + `
+		SELECT date_trunc($3, l.sold_at AT TIME ZONE $4) AS period, ` + key + `, ` + name + `,
+		       SUM(l.revenue), COUNT(DISTINCT l.sale_id), SUM(l.quantity)
+		FROM lines l ` + join + `
+		GROUP BY 1, 2, 3
+		ORDER BY 1 ASC, 3 ASC
+	`
+	rows, err := r.db.Query(context.Background(), query, from, to, interval, timezone)
+	if err != nil {
+		if r.Logger != nil {
+			r.Logger.Error("error querying sales by period", zap.Error(err))
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []model.SalesPeriod
+	for rows.Next() {
+		var period model.SalesPeriod
+		err := rows.Scan(&period.Period, &period.GroupID, &period.GroupName, &period.Revenue, &period.SalesCount, &period.UnitsSold)
+		if err != nil {
+			if r.Logger != nil {
+				r.Logger.Error("error scanning sales period", zap.Error(err))
+			}
+			return nil, err
+		}
+		periods = append(periods, period)
+	}
+
+	return periods, nil
+}
+
+// itemMovementLinesSQL lists every item with its stock and what it sold from $1
+// up to $2, narrowed to the warehouse $3 and the category $4 when they are set.
+// Sales are net of returns like the gross margin report: returned units are not
+// sold and give back their share of the line revenue, and a sale returned in
+// full is no sale for last_sold_at. Lines recorded before per-rack stock fall
+// back to the item's home rack.
+const itemMovementLinesSQL = `
+	WITH returned AS (
+		SELECT sale_item_id, SUM(quantity) AS quantity
+		FROM sale_return_items
+		GROUP BY sale_item_id
+	), sale_lines AS (
+		SELECT si.item_id, si.quantity - COALESCE(rt.quantity, 0) AS quantity,
+		       ROUND((si.gross_amount - si.discount_amount) * (si.quantity - COALESCE(rt.quantity, 0)) / si.quantity, 2) AS revenue,
+		       s.created_at
+		FROM sale_items si
+		JOIN sales s ON s.id = si.sale_id
+		JOIN items i ON i.id = si.item_id
+		JOIN racks r ON r.id = COALESCE(si.rack_id, i.rack_id)
+		LEFT JOIN returned rt ON rt.sale_item_id = si.id
+		WHERE s.deleted_at IS NULL AND ($3::int IS NULL OR r.warehouse_id = $3)
+	), sold AS (
+		SELECT item_id,
+		       COALESCE(SUM(quantity) FILTER (WHERE created_at >= $1 AND created_at < $2), 0) AS units,
+		       COALESCE(SUM(revenue) FILTER (WHERE created_at >= $1 AND created_at < $2), 0) AS revenue,
+		       MAX(created_at) FILTER (WHERE quantity > 0) AS last_sold_at
+		FROM sale_lines
+		GROUP BY item_id
+	), lines AS (
+		SELECT i.id, i.sku, i.name, c.id AS category_id, c.name AS category_name,
+		       CASE WHEN $3::int IS NULL THEN i.stock ELSE COALESCE((
+		           SELECT SUM(il.quantity) FROM item_locations il
+		           JOIN racks r ON r.id = il.rack_id
+		           WHERE il.item_id = i.id AND r.warehouse_id = $3
+		       ), 0) END AS stock,
+		       COALESCE(sold.units, 0) AS units, COALESCE(sold.revenue, 0) AS revenue, sold.last_sold_at
+		FROM items i
+		JOIN categories c ON c.id = i.category_id
+		LEFT JOIN sold ON sold.item_id = i.id
+		WHERE $4::int IS NULL OR i.category_id = $4
+	)
+`
+
+// GetItemMovement lists the top sellers (most units or revenue first), the slow
+// movers (items in stock that sold, lowest sell-through first) or the dead
+// stock (items in stock not sold since filter.From, longest unsold first)
+func (r *reportRepository) GetItemMovement(report string, filter model.ItemMovementFilter, page, limit int) ([]model.ItemMovement, int, error) {
+	offset := (page - 1) * limit
+
+	var where, order string
+	switch report {
+	case model.ItemMovementSlowMovers:
+		where, order = "stock > 0 AND units > 0", "units::numeric / (units + stock) ASC, stock DESC"
+	case model.ItemMovementDeadStock:
+		where, order = "stock > 0 AND (last_sold_at IS NULL OR last_sold_at < $1)", "last_sold_at ASC NULLS FIRST, stock DESC"
+	default:
+		where, order = "units > 0", "units DESC, revenue DESC"
+		if filter.SortBy == model.TopSellersByRevenue {
+			order = "revenue DESC, units DESC"
+		}
+	}
+
+	// Get total count
+	var total int
+	countQuery := itemMovementLinesSQL + `SELECT COUNT(*) FROM lines WHERE ` + where
+	err := r.db.QueryRow(context.Background(), countQuery, filter.From, filter.To, filter.WarehouseID, filter.CategoryID).Scan(&total)
+	if err != nil {
+		if r.Logger != nil {
+			r.Logger.Error("error counting item movement rows", zap.Error(err))
+		}
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := itemMovementLinesSQL + `
+		SELECT id, sku, name, category_id, category_name, stock, units, revenue, last_sold_at
+		FROM lines
+		WHERE ` + where + `
+		ORDER BY ` + order + `, id ASC
+		LIMIT $5 OFFSET $6
+	`
+	rows, err := r.db.Query(context.Background(), query, filter.From, filter.To, filter.WarehouseID, filter.CategoryID, limit, offset)
+	if err != nil {
+		if r.Logger != nil {
+			r.Logger.Error("error querying item movement", zap.Error(err))
+		}
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var items []model.ItemMovement
+	for rows.Next() {
+		var item model.ItemMovement
+		err := rows.Scan(
+			&item.ID, &item.SKU, &item.Name, &item.CategoryID, &item.CategoryName,
+			&item.Stock, &item.UnitsSold, &item.Revenue, &item.LastSoldAt,
+		)
+		if err != nil {
+			if r.Logger != nil {
+				r.Logger.Error("error scanning item movement", zap.Error(err))
+			}
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+
+	return items, total, nil
+}
+
+// GetABCValues ranks every item by the revenue, or the cost of the units sold
+// for the consumption basis, of its sale lines between from and to, highest
+// first. Both are net of returns the way the gross margin report counts them.
+// Items without sales are listed with no value.
+func (r *reportRepository) GetABCValues(basis string, from, to time.Time) ([]model.ABCItem, error) {
+	value := "revenue"
+	if basis == model.ABCBasisConsumption {
+		value = "cost"
+	}
+
+	query := grossMarginLinesSQL + `, sold AS (
+			SELECT item_id, SUM(` + value + `) AS value
+			FROM lines
+			GROUP BY item_id
+		)
+		SELECT i.id, i.sku, i.name, COALESCE(sold.value, 0) AS value
+		FROM items i
+		LEFT JOIN sold ON sold.item_id = i.id
+		ORDER BY value DESC, i.id ASC
+	`
+	rows, err := r.db.Query(context.Background(), query, from, to)
+	if err != nil {
+		if r.Logger != nil {
+			r.Logger.Error("error querying abc values", zap.Error(err))
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.ABCItem
+	for rows.Next() {
+		var item model.ABCItem
+		err := rows.Scan(&item.ItemID, &item.SKU, &item.Name, &item.Value)
+		if err != nil {
+			if r.Logger != nil {
+				r.Logger.Error("error scanning abc value", zap.Error(err))
+			}
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
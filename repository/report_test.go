@@ -2,7 +2,10 @@ package repository
 
 import (
 	"errors"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
 	"testing"
+	"time"
 
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/require"
@@ -89,12 +92,52 @@ func TestReportRepository_GetTotalRevenue_Success(t *testing.T) {
 	repo := NewReportRepository(mockDB, zap.NewNop())
 
 	mockDB.
-		ExpectQuery(`SELECT COALESCE\(SUM\(total_amount\), 0\) FROM sales WHERE deleted_at IS NULL`).
-		WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(5000000.0))
+		ExpectQuery(`WITH returned AS (.+) SELECT COALESCE\(SUM\(revenue\), 0\) FROM lines`).
+		WithArgs(nil, nil).
+		WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(money.FromUnits(5000000)))
 
 	total, err := repo.GetTotalRevenue()
 	require.NoError(t, err)
-	require.Equal(t, 5000000.0, total)
+	require.Equal(t, money.FromUnits(5000000), total)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReportRepository_GetTotalRefunds_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReportRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectQuery(`SELECT COALESCE\(SUM\(sr.refund_amount\), 0\) FROM sale_returns sr JOIN sales s`).
+		WillReturnRows(pgxmock.NewRows([]string{"sum"}).AddRow(money.FromUnits(250000)))
+
+	total, err := repo.GetTotalRefunds()
+	require.NoError(t, err)
+	require.Equal(t, money.FromUnits(250000), total)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReportRepository_GetSalesBreakdown_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReportRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectQuery(`SELECT COALESCE\(SUM\(gross_amount\), 0\), (.+) FROM sales WHERE deleted_at IS NULL`).
+		WillReturnRows(pgxmock.NewRows([]string{"gross", "discount", "tax"}).
+			AddRow(money.FromUnits(500000), money.FromUnits(50000), money.FromUnits(49500)))
+
+	gross, discount, tax, err := repo.GetSalesBreakdown()
+	require.NoError(t, err)
+	require.Equal(t, money.FromUnits(500000), gross)
+	require.Equal(t, money.FromUnits(50000), discount)
+	require.Equal(t, money.FromUnits(49500), tax)
 
 	require.NoError(t, mockDB.ExpectationsWereMet())
 }
@@ -152,3 +195,169 @@ func TestReportRepository_GetTotalWarehouses_Success(t *testing.T) {
 
 	require.NoError(t, mockDB.ExpectationsWereMet())
 }
+
+func TestReportRepository_GetGrossMargin_ByItem(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReportRepository(mockDB, zap.NewNop())
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockDB.
+		ExpectQuery(`SELECT COUNT\(DISTINCT item_id\) FROM lines`).
+		WithArgs(&from, (*time.Time)(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(2))
+	mockDB.
+		ExpectQuery(`GROUP BY item_id`).
+		WithArgs(&from, (*time.Time)(nil), 10, 0).
+		WillReturnRows(pgxmock.NewRows([]string{"item_id", "name", "sold_at", "quantity", "revenue", "cost"}).
+			AddRow(3, "Mouse", nil, 5, money.FromUnits(500000), money.FromUnits(300000)).
+			AddRow(4, "Keyboard", nil, 2, money.FromUnits(200000), money.FromUnits(180000)))
+
+	margins, total, err := repo.GetGrossMargin(model.GrossMarginByItem, &from, nil, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, total)
+	require.Len(t, margins, 2)
+	require.Equal(t, "Mouse", margins[0].Name)
+	require.Nil(t, margins[0].SoldAt)
+	require.Equal(t, money.FromUnits(300000), margins[0].Cost)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReportRepository_GetGrossMargin_Error(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReportRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectQuery(`SELECT COUNT\(DISTINCT sale_id\) FROM lines`).
+		WillReturnError(errors.New("database error"))
+
+	margins, total, err := repo.GetGrossMargin(model.GrossMarginBySale, nil, nil, 1, 10)
+	require.Error(t, err)
+	require.Nil(t, margins)
+	require.Equal(t, 0, total)
+}
+
+func TestReportRepository_GetInventoryValuation_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReportRepository(mockDB, zap.NewNop())
+
+	until := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	warehouseID, rackID := 1, 1
+	warehouse, rackCode := "Gudang Utama", "A-01"
+	mockDB.
+		ExpectQuery(`WITH stock AS (.+) FROM item_locations il (.+) transit AS (.+) i.in_transit \+ (.+) GROUP BY w.id(.+) UNION ALL SELECT NULL, NULL, NULL, NULL, (.+) FROM transit t`).
+		WithArgs(&until).
+		WillReturnRows(pgxmock.NewRows([]string{"warehouse_id", "warehouse_name", "rack_id", "rack_code", "category_id", "category_name", "quantity", "value"}).
+			AddRow(&warehouseID, &warehouse, &rackID, &rackCode, 1, "Elektronik", 10, money.FromUnits(500000)).
+			AddRow((*int)(nil), (*string)(nil), (*int)(nil), (*string)(nil), 1, "Elektronik", 2, money.FromUnits(100000)))
+
+	lines, err := repo.GetInventoryValuation(&until)
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	require.Equal(t, "A-01", *lines[0].RackCode)
+	require.Equal(t, money.FromUnits(500000), lines[0].Value)
+	require.Nil(t, lines[1].RackID)
+	require.Equal(t, 2, lines[1].Quantity)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReportRepository_GetSalesByPeriod_ByCashier(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReportRepository(mockDB, zap.NewNop())
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	cashierID := 2
+	cashier := "Budi"
+
+	mockDB.
+		ExpectQuery(`WITH returned AS (.+) SELECT date_trunc\(\$3, l.sold_at AT TIME ZONE \$4\) (.+) SUM\(l.revenue\), COUNT\(DISTINCT l.sale_id\), SUM\(l.quantity\) FROM lines l JOIN users u ON u.id = l.user_id`).
+		WithArgs(from, to, model.SalesIntervalMonth, "Asia/Jakarta").
+		WillReturnRows(pgxmock.NewRows([]string{"period", "group_id", "group_name", "revenue", "sales_count", "units_sold"}).
+			AddRow(from, &cashierID, &cashier, money.FromUnits(750000), 4, 9))
+
+	periods, err := repo.GetSalesByPeriod(model.SalesIntervalMonth, model.SalesGroupByCashier, "Asia/Jakarta", from, to)
+	require.NoError(t, err)
+	require.Len(t, periods, 1)
+	require.Equal(t, 2, *periods[0].GroupID)
+	require.Equal(t, "Budi", *periods[0].GroupName)
+	require.Equal(t, 4, periods[0].SalesCount)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReportRepository_GetItemMovement_DeadStockInWarehouse(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReportRepository(mockDB, zap.NewNop())
+
+	warehouseID := 2
+	filter := model.ItemMovementFilter{
+		From:        time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:          time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		WarehouseID: &warehouseID,
+	}
+
+	mockDB.
+		ExpectQuery(`WITH returned AS (.+) FROM sale_return_items (.+) LEFT JOIN returned rt (.+) MAX\(created_at\) FILTER \(WHERE quantity > 0\) (.+) SELECT COUNT\(\*\) FROM lines WHERE stock > 0 AND \(last_sold_at IS NULL OR last_sold_at < \$1\)`).
+		WithArgs(filter.From, filter.To, filter.WarehouseID, filter.CategoryID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+
+	mockDB.
+		ExpectQuery(`WITH returned AS (.+) ORDER BY last_sold_at ASC NULLS FIRST, stock DESC, id ASC`).
+		WithArgs(filter.From, filter.To, filter.WarehouseID, filter.CategoryID, 10, 0).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "sku", "name", "category_id", "category_name", "stock", "units", "revenue", "last_sold_at"}).
+			AddRow(4, "SKU-004", "Sabun", 1, "Rumah Tangga", 8, 0, money.Amount(0), (*time.Time)(nil)))
+
+	items, total, err := repo.GetItemMovement(model.ItemMovementDeadStock, filter, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Len(t, items, 1)
+	require.Equal(t, 8, items[0].Stock)
+	require.Nil(t, items[0].LastSoldAt)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReportRepository_GetABCValues_Consumption(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReportRepository(mockDB, zap.NewNop())
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	mockDB.
+		ExpectQuery(`WITH returned AS (.+) sold AS \( SELECT item_id, SUM\(cost\) AS value FROM lines (.+) LEFT JOIN sold`).
+		WithArgs(from, to).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "sku", "name", "value"}).
+			AddRow(2, "SKU-002", "Beras", money.FromUnits(900000)).
+			AddRow(1, "SKU-001", "Garam", money.Amount(0)))
+
+	items, err := repo.GetABCValues(model.ABCBasisConsumption, from, to)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, 2, items[0].ItemID)
+	require.Equal(t, money.FromUnits(900000), items[0].Value)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
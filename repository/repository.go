@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"project-app-inventory/database"
 
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
@@ -18,9 +21,25 @@ type Repository struct {
 	WarehouseRepo        WarehouseRepository
 	SaleRepo             SaleRepository
 	ReportRepo           ReportRepository
+	StockMovementRepo    StockMovementRepository
+	ItemLocationRepo     ItemLocationRepository
+	ItemLotRepo          ItemLotRepository
+	ItemSerialRepo       ItemSerialRepository
+	TransferRepo         TransferRepository
+	SupplierRepo         SupplierRepository
+	PurchaseOrderRepo    PurchaseOrderRepository
+	ReceiptRepo          ReceiptRepository
+	CustomerRepo         CustomerRepository
+	SaleReturnRepo       SaleReturnRepository
+	IdempotencyKeyRepo   IdempotencyKeyRepository
+	ReservationRepo      ReservationRepository
+	StocktakeRepo        StocktakeRepository
+	ForecastRepo         ForecastRepository
 }
 
-func NewRepository(db database.PgxIface, log *zap.Logger) Repository {
+// NewRepository builds every repository on db. costingMethod is the method the
+// cost of goods sold of sale lines is taken with, see model.CostingMethodAverage.
+func NewRepository(db database.PgxIface, log *zap.Logger, costingMethod string) Repository {
 	return Repository{
 		AssignmentRepo:       NewAssignmentRepository(db, log),
 		SubmissionRepo:       NewSubmissionRepo(db),
@@ -31,7 +50,31 @@ func NewRepository(db database.PgxIface, log *zap.Logger) Repository {
 		CategoryRepo:         NewCategoryRepository(db, log),
 		RackRepo:             NewRackRepository(db, log),
 		WarehouseRepo:        NewWarehouseRepository(db, log),
-		SaleRepo:             NewSaleRepository(db, log),
+		SaleRepo:             NewSaleRepository(db, log, costingMethod),
 		ReportRepo:           NewReportRepository(db, log),
+		StockMovementRepo:    NewStockMovementRepository(db, log),
+		ItemLocationRepo:     NewItemLocationRepository(db, log),
+		ItemLotRepo:          NewItemLotRepository(db, log),
+		ItemSerialRepo:       NewItemSerialRepository(db, log),
+		TransferRepo:         NewTransferRepository(db, log),
+		SupplierRepo:         NewSupplierRepository(db, log),
+		PurchaseOrderRepo:    NewPurchaseOrderRepository(db, log),
+		ReceiptRepo:          NewReceiptRepository(db, log),
+		CustomerRepo:         NewCustomerRepository(db, log),
+		SaleReturnRepo:       NewSaleReturnRepository(db, log),
+		IdempotencyKeyRepo:   NewIdempotencyKeyRepository(db, log),
+		ReservationRepo:      NewReservationRepository(db, log),
+		StocktakeRepo:        NewStocktakeRepository(db, log),
+		ForecastRepo:         NewForecastRepository(db, log),
 	}
 }
+
+// beginTx starts a transaction when the underlying connection supports it
+// (pgxpool.Pool in production, pgxmock in tests)
+func beginTx(db database.PgxIface) (pgx.Tx, error) {
+	txManager, ok := db.(database.TxManager)
+	if !ok {
+		return nil, errors.New("database connection does not support transactions")
+	}
+	return txManager.Begin(context.Background())
+}
@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInsufficientAvailable = errors.New("insufficient available stock for item")
+	ErrReservationNotActive  = errors.New("reservation is not active")
+)
+
+// reservedQuantitySQL sums what active, unexpired reservations hold of items.id
+const reservedQuantitySQL = `COALESCE((
+			SELECT SUM(sri.quantity)
+			FROM stock_reservation_items sri
+			JOIN stock_reservations sr ON sr.id = sri.reservation_id
+			WHERE sri.item_id = items.id AND sr.status = 'active' AND sr.expires_at > NOW()
+		), 0)`
+
+// reservationStatusSQL reports an active reservation past its expiry as expired
+const reservationStatusSQL = `CASE WHEN status = 'active' AND expires_at <= NOW() THEN 'expired' ELSE status END`
+
+type ReservationRepository interface {
+	Create(reservation *model.Reservation, items []model.ReservationItem) error
+	FindByID(id int) (*model.Reservation, error)
+	FindReservationItems(reservationID int) ([]model.ReservationItem, error)
+	FindAll(status string, page, limit int) ([]model.Reservation, int, error)
+	Extend(id int, expiresAt time.Time) error
+	Release(id int) error
+}
+
+type reservationRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewReservationRepository(db database.PgxIface, log *zap.Logger) ReservationRepository {
+	return &reservationRepository{db: db, Logger: log}
+}
+
+// Create holds the quantities of every line. The item rows are locked while
+// their available stock is checked so two reservations can't promise the same
+// units.
+func (r *reservationRepository) Create(reservation *model.Reservation, items []model.ReservationItem) error {
+	// Begin transaction
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	availableQuery := `
+		SELECT stock - in_transit - ` + reservedQuantitySQL + `
+		FROM items
+		WHERE id = $1
+		FOR UPDATE
+	`
+	for _, item := range items {
+		var available int
+		err = tx.QueryRow(context.Background(), availableQuery, item.ItemID).Scan(&available)
+		if err != nil {
+			r.Logger.Error("error checking available stock", zap.Error(err))
+			return err
+		}
+		if available < item.Quantity {
+			return ErrInsufficientAvailable
+		}
+	}
+
+	// Insert reservation header
+	query := `
+		INSERT INTO stock_reservations (customer_id, status, note, expires_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	reservation.Status = model.ReservationStatusActive
+	err = tx.QueryRow(context.Background(), query,
+		reservation.CustomerID, reservation.Status, reservation.Note, reservation.ExpiresAt, reservation.CreatedBy,
+	).Scan(&reservation.ID, &reservation.CreatedAt, &reservation.UpdatedAt)
+
+	if err != nil {
+		r.Logger.Error("error creating reservation", zap.Error(err))
+		return err
+	}
+
+	// Insert reservation lines
+	itemQuery := `
+		INSERT INTO stock_reservation_items (reservation_id, item_id, quantity)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`
+	for i := range items {
+		items[i].ReservationID = reservation.ID
+		err = tx.QueryRow(context.Background(), itemQuery,
+			items[i].ReservationID, items[i].ItemID, items[i].Quantity,
+		).Scan(&items[i].ID)
+
+		if err != nil {
+			r.Logger.Error("error creating reservation item", zap.Error(err))
+			return err
+		}
+	}
+
+	// Commit transaction
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *reservationRepository) FindByID(id int) (*model.Reservation, error) {
+	query := `
+		SELECT id, customer_id, ` + reservationStatusSQL + `, note, expires_at, sale_id, created_by, created_at, updated_at
+		FROM stock_reservations
+		WHERE id = $1
+	`
+	var reservation model.Reservation
+	err := r.db.QueryRow(context.Background(), query, id).Scan(
+		&reservation.ID, &reservation.CustomerID, &reservation.Status, &reservation.Note, &reservation.ExpiresAt,
+		&reservation.SaleID, &reservation.CreatedBy, &reservation.CreatedAt, &reservation.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.Error("error finding reservation by id", zap.Error(err))
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+func (r *reservationRepository) FindReservationItems(reservationID int) ([]model.ReservationItem, error) {
+	query := `
+		SELECT id, reservation_id, item_id, quantity
+		FROM stock_reservation_items
+		WHERE reservation_id = $1
+		ORDER BY id ASC
+	`
+	rows, err := r.db.Query(context.Background(), query, reservationID)
+	if err != nil {
+		r.Logger.Error("error querying reservation items", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.ReservationItem
+	for rows.Next() {
+		var item model.ReservationItem
+		err := rows.Scan(&item.ID, &item.ReservationID, &item.ItemID, &item.Quantity)
+		if err != nil {
+			r.Logger.Error("error scanning reservation item", zap.Error(err))
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r *reservationRepository) FindAll(status string, page, limit int) ([]model.Reservation, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count, empty status means all reservations
+	var total int
+	countQuery := `SELECT COUNT(*) FROM stock_reservations WHERE ($1 = '' OR ` + reservationStatusSQL + ` = $1)`
+	err := r.db.QueryRow(context.Background(), countQuery, status).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting reservations", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := `
+		SELECT id, customer_id, ` + reservationStatusSQL + `, note, expires_at, sale_id, created_by, created_at, updated_at
+		FROM stock_reservations
+		WHERE ($1 = '' OR ` + reservationStatusSQL + ` = $1)
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(context.Background(), query, status, limit, offset)
+	if err != nil {
+		r.Logger.Error("error querying reservations", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var reservations []model.Reservation
+	for rows.Next() {
+		var reservation model.Reservation
+		err := rows.Scan(
+			&reservation.ID, &reservation.CustomerID, &reservation.Status, &reservation.Note, &reservation.ExpiresAt,
+			&reservation.SaleID, &reservation.CreatedBy, &reservation.CreatedAt, &reservation.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning reservation", zap.Error(err))
+			return nil, 0, err
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, total, nil
+}
+
+// Extend moves the expiry of a reservation that still holds its stock
+func (r *reservationRepository) Extend(id int, expiresAt time.Time) error {
+	query := `
+		UPDATE stock_reservations
+		SET expires_at = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3 AND expires_at > NOW()
+	`
+	result, err := r.db.Exec(context.Background(), query, expiresAt, id, model.ReservationStatusActive)
+	if err != nil {
+		r.Logger.Error("error extending reservation", zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrReservationNotActive
+	}
+	return nil
+}
+
+// Release gives the held stock back, an expired reservation can still be
+// released to close it
+func (r *reservationRepository) Release(id int) error {
+	query := `
+		UPDATE stock_reservations
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+	`
+	result, err := r.db.Exec(context.Background(), query, model.ReservationStatusReleased, id, model.ReservationStatusActive)
+	if err != nil {
+		r.Logger.Error("error releasing reservation", zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return ErrReservationNotActive
+	}
+	return nil
+}
+
+// convertReservation closes the reservation a sale was made from, inside the
+// sale's transaction so the held stock is never counted twice
+func convertReservation(ctx context.Context, tx database.PgxIface, reservationID, saleID int) error {
+	query := `
+		UPDATE stock_reservations
+		SET status = $1, sale_id = $2, updated_at = NOW()
+		WHERE id = $3 AND status = $4 AND expires_at > NOW()
+	`
+	result, err := tx.Exec(ctx, query, model.ReservationStatusConverted, saleID, reservationID, model.ReservationStatusActive)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrReservationNotActive
+	}
+	return nil
+}
+
+// checkReservedStock makes sure what is left of each item outside of transfers
+// in transit still covers what active reservations hold of it. It runs inside
+// the sale's or transfer's transaction after the stock went out, on item rows
+// the movements locked, so a reservation made since the service checked the
+// item can't end up holding units that are gone.
+func checkReservedStock(ctx context.Context, tx database.PgxIface, itemIDs []int) error {
+	query := `SELECT stock - in_transit - ` + reservedQuantitySQL + ` FROM items WHERE id = $1`
+	checked := make(map[int]bool)
+	for _, itemID := range itemIDs {
+		if checked[itemID] {
+			continue
+		}
+		checked[itemID] = true
+
+		var available int
+		if err := tx.QueryRow(ctx, query, itemID).Scan(&available); err != nil {
+			return err
+		}
+		if available < 0 {
+			return ErrInsufficientAvailable
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"project-app-inventory/model"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReservationRepository_Create(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReservationRepository(mockDB, zap.NewNop())
+
+	customerID := 4
+	expiresAt := time.Now().Add(24 * time.Hour)
+	reservation := &model.Reservation{CustomerID: &customerID, ExpiresAt: expiresAt, CreatedBy: 2}
+	items := []model.ReservationItem{{ItemID: 3, Quantity: 2}}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`SELECT stock - in_transit - COALESCE`).
+		WithArgs(3).
+		WillReturnRows(pgxmock.NewRows([]string{"available"}).AddRow(5))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_reservations`).
+		WithArgs(&customerID, model.ReservationStatusActive, reservation.Note, expiresAt, 2).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(9, time.Now(), time.Now()))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_reservation_items`).
+		WithArgs(9, 3, 2).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+	mockDB.ExpectCommit()
+
+	err = repo.Create(reservation, items)
+	require.NoError(t, err)
+	require.Equal(t, 9, reservation.ID)
+	require.Equal(t, model.ReservationStatusActive, reservation.Status)
+	require.Equal(t, 9, items[0].ReservationID)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReservationRepository_Create_InsufficientAvailable(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReservationRepository(mockDB, zap.NewNop())
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`SELECT stock - in_transit - COALESCE`).
+		WithArgs(3).
+		WillReturnRows(pgxmock.NewRows([]string{"available"}).AddRow(1))
+	mockDB.ExpectRollback()
+
+	err = repo.Create(&model.Reservation{CreatedBy: 2}, []model.ReservationItem{{ItemID: 3, Quantity: 2}})
+	require.ErrorIs(t, err, ErrInsufficientAvailable)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestReservationRepository_Extend_NotActive(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewReservationRepository(mockDB, zap.NewNop())
+
+	expiresAt := time.Now().Add(time.Hour)
+	mockDB.
+		ExpectExec(`UPDATE stock_reservations`).
+		WithArgs(expiresAt, 9, model.ReservationStatusActive).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	err = repo.Extend(9, expiresAt)
+	require.ErrorIs(t, err, ErrReservationNotActive)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
@@ -7,7 +7,6 @@ import (
 	"project-app-inventory/model"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
@@ -16,28 +15,25 @@ type SaleRepository interface {
 	FindByID(id int) (*model.Sale, error)
 	FindSaleItems(saleID int) ([]model.SaleItem, error)
 	FindAll(page, limit int) ([]model.Sale, int, error)
-	Update(id int, sale *model.Sale, items []model.SaleItem) error
-	Delete(id int) error
+	FindAfter(afterID, limit int) ([]model.Sale, error)
+	FindByCustomerID(customerID, page, limit int) ([]model.Sale, int, error)
+	Update(id int, userID int, sale *model.Sale, items []model.SaleItem) error
+	Delete(id int, userID int) error
 }
 
 type saleRepository struct {
-	db     database.PgxIface
-	Logger *zap.Logger
+	db            database.PgxIface
+	Logger        *zap.Logger
+	costingMethod string // method the cost of goods sold of new lines is taken with
 }
 
-func NewSaleRepository(db database.PgxIface, log *zap.Logger) SaleRepository {
-	return &saleRepository{db: db, Logger: log}
+func NewSaleRepository(db database.PgxIface, log *zap.Logger, costingMethod string) SaleRepository {
+	return &saleRepository{db: db, Logger: log, costingMethod: costingMethod}
 }
 
 func (r *saleRepository) Create(sale *model.Sale, items []model.SaleItem) error {
-	// Type assert to get pool for transaction
-	pool, ok := r.db.(*pgxpool.Pool)
-	if !ok {
-		return errors.New("database connection does not support transactions")
-	}
-
 	// Begin transaction
-	tx, err := pool.Begin(context.Background())
+	tx, err := beginTx(r.db)
 	if err != nil {
 		r.Logger.Error("error beginning transaction", zap.Error(err))
 		return err
@@ -46,12 +42,12 @@ func (r *saleRepository) Create(sale *model.Sale, items []model.SaleItem) error
 
 	// Insert sale
 	query := `
-		INSERT INTO sales (user_id, total_amount, created_at, updated_at)
-		VALUES ($1, $2, NOW(), NOW())
+		INSERT INTO sales (user_id, customer_id, gross_amount, discount_amount, tax_amount, total_amount, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
 		RETURNING id, created_at, updated_at
 	`
 	err = tx.QueryRow(context.Background(), query,
-		sale.UserID, sale.TotalAmount,
+		sale.UserID, sale.CustomerID, sale.GrossAmount, sale.DiscountAmount, sale.TaxAmount, sale.TotalAmount,
 	).Scan(&sale.ID, &sale.CreatedAt, &sale.UpdatedAt)
 
 	if err != nil {
@@ -59,41 +55,32 @@ func (r *saleRepository) Create(sale *model.Sale, items []model.SaleItem) error
 		return err
 	}
 
-	// Insert sale items
-	itemQuery := `
-		INSERT INTO sale_items (sale_id, item_id, quantity, price_at_sale, subtotal)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
-	`
+	// Take the stock out first, the line is stored with what its units cost
 	for i := range items {
 		items[i].SaleID = sale.ID
-		err = tx.QueryRow(context.Background(), itemQuery,
-			items[i].SaleID, items[i].ItemID, items[i].Quantity,
-			items[i].PriceAtSale, items[i].Subtotal,
-		).Scan(&items[i].ID)
+		movement := saleMovement(sale.ID, sale.UserID, model.MovementTypeSale, items[i], -items[i].Quantity)
+		if err := applyStockMovement(context.Background(), tx, movement); err != nil {
+			r.Logger.Error("error updating item stock", zap.Error(err))
+			return err
+		}
 
-		if err != nil {
+		if err := r.insertSaleItem(tx, &items[i], movement); err != nil {
 			r.Logger.Error("error creating sale item", zap.Error(err))
 			return err
 		}
+	}
 
-		// Update item stock
-		updateStockQuery := `
-			UPDATE items
-			SET stock = stock - $1, updated_at = NOW()
-			WHERE id = $2 AND stock >= $3
-		`
-		result, err := tx.Exec(context.Background(), updateStockQuery,
-			items[i].Quantity, items[i].ItemID, items[i].Quantity,
-		)
-		if err != nil {
-			r.Logger.Error("error updating item stock", zap.Error(err))
+	// The reservation the sale was made from stops holding its stock
+	if sale.ReservationID != nil {
+		if err := convertReservation(context.Background(), tx, *sale.ReservationID, sale.ID); err != nil {
+			r.Logger.Error("error converting reservation", zap.Error(err))
 			return err
 		}
+	}
 
-		if result.RowsAffected() == 0 {
-			return errors.New("insufficient stock for item")
-		}
+	if err := checkReservedStock(context.Background(), tx, saleItemIDs(items)); err != nil {
+		r.Logger.Error("error checking reserved stock", zap.Error(err))
+		return err
 	}
 
 	// Commit transaction
@@ -108,13 +95,13 @@ func (r *saleRepository) Create(sale *model.Sale, items []model.SaleItem) error
 
 func (r *saleRepository) FindByID(id int) (*model.Sale, error) {
 	query := `
-		SELECT s.id, s.user_id, s.total_amount, s.created_at, s.updated_at, s.deleted_at
+		SELECT s.id, s.user_id, s.customer_id, s.gross_amount, s.discount_amount, s.tax_amount, s.total_amount, s.created_at, s.updated_at, s.deleted_at
 		FROM sales s
 		WHERE s.id = $1 AND s.deleted_at IS NULL
 	`
 	var sale model.Sale
 	err := r.db.QueryRow(context.Background(), query, id).Scan(
-		&sale.ID, &sale.UserID, &sale.TotalAmount, &sale.CreatedAt, &sale.UpdatedAt, &sale.DeletedAt,
+		&sale.ID, &sale.UserID, &sale.CustomerID, &sale.GrossAmount, &sale.DiscountAmount, &sale.TaxAmount, &sale.TotalAmount, &sale.CreatedAt, &sale.UpdatedAt, &sale.DeletedAt,
 	)
 
 	if err == pgx.ErrNoRows {
@@ -129,11 +116,16 @@ func (r *saleRepository) FindByID(id int) (*model.Sale, error) {
 
 func (r *saleRepository) FindSaleItems(saleID int) ([]model.SaleItem, error) {
 	query := `
-		SELECT id, sale_id, item_id, quantity, price_at_sale, subtotal
-		FROM sale_items
-		WHERE sale_id = $1
-		ORDER BY id ASC
+		SELECT si.id, si.sale_id, si.item_id, COALESCE(si.rack_id, i.rack_id),
+		       si.quantity, si.price_at_sale, si.gross_amount, si.discount_amount,
+		       si.tax_rate, si.tax_amount, si.subtotal, si.lot_number, si.serial_numbers,
+		       si.unit_cost, si.cost_amount
+		FROM sale_items si
+		JOIN items i ON i.id = si.item_id
+		WHERE si.sale_id = $1
+		ORDER BY si.id ASC
 	`
+	// Lines recorded before per-rack stock have no rack, they fall back to the item's home rack
 	rows, err := r.db.Query(context.Background(), query, saleID)
 	if err != nil {
 		r.Logger.Error("error querying sale items", zap.Error(err))
@@ -145,8 +137,10 @@ func (r *saleRepository) FindSaleItems(saleID int) ([]model.SaleItem, error) {
 	for rows.Next() {
 		var item model.SaleItem
 		err := rows.Scan(
-			&item.ID, &item.SaleID, &item.ItemID,
-			&item.Quantity, &item.PriceAtSale, &item.Subtotal,
+			&item.ID, &item.SaleID, &item.ItemID, &item.RackID,
+			&item.Quantity, &item.PriceAtSale, &item.GrossAmount, &item.DiscountAmount,
+			&item.TaxRate, &item.TaxAmount, &item.Subtotal, &item.LotNumber, &item.SerialNumbers,
+			&item.UnitCost, &item.CostAmount,
 		)
 		if err != nil {
 			r.Logger.Error("error scanning sale item", zap.Error(err))
@@ -172,7 +166,7 @@ func (r *saleRepository) FindAll(page, limit int) ([]model.Sale, int, error) {
 
 	// Get data with pagination
 	query := `
-		SELECT id, user_id, total_amount, created_at, updated_at, deleted_at
+		SELECT id, user_id, customer_id, gross_amount, discount_amount, tax_amount, total_amount, created_at, updated_at, deleted_at
 		FROM sales
 		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -189,7 +183,7 @@ func (r *saleRepository) FindAll(page, limit int) ([]model.Sale, int, error) {
 	for rows.Next() {
 		var sale model.Sale
 		err := rows.Scan(
-			&sale.ID, &sale.UserID, &sale.TotalAmount, &sale.CreatedAt, &sale.UpdatedAt, &sale.DeletedAt,
+			&sale.ID, &sale.UserID, &sale.CustomerID, &sale.GrossAmount, &sale.DiscountAmount, &sale.TaxAmount, &sale.TotalAmount, &sale.CreatedAt, &sale.UpdatedAt, &sale.DeletedAt,
 		)
 		if err != nil {
 			r.Logger.Error("error scanning sale", zap.Error(err))
@@ -201,15 +195,85 @@ func (r *saleRepository) FindAll(page, limit int) ([]model.Sale, int, error) {
 	return sales, total, nil
 }
 
-func (r *saleRepository) Update(id int, sale *model.Sale, items []model.SaleItem) error {
-	// Type assert to get pool for transaction
-	pool, ok := r.db.(*pgxpool.Pool)
-	if !ok {
-		return errors.New("database connection does not support transactions")
+// FindAfter reads up to limit sales with an id above afterID in id order. Exports
+// page with it, a page can't skip or repeat sales added or removed meanwhile.
+func (r *saleRepository) FindAfter(afterID, limit int) ([]model.Sale, error) {
+	query := `
+		SELECT id, user_id, customer_id, gross_amount, discount_amount, tax_amount, total_amount, created_at, updated_at, deleted_at
+		FROM sales
+		WHERE id > $1 AND deleted_at IS NULL
+		ORDER BY id ASC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(context.Background(), query, afterID, limit)
+	if err != nil {
+		r.Logger.Error("error querying sales after id", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sales []model.Sale
+	for rows.Next() {
+		var sale model.Sale
+		err := rows.Scan(
+			&sale.ID, &sale.UserID, &sale.CustomerID, &sale.GrossAmount, &sale.DiscountAmount, &sale.TaxAmount, &sale.TotalAmount, &sale.CreatedAt, &sale.UpdatedAt, &sale.DeletedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning sale", zap.Error(err))
+			return nil, err
+		}
+		sales = append(sales, sale)
+	}
+
+	return sales, nil
+}
+
+func (r *saleRepository) FindByCustomerID(customerID, page, limit int) ([]model.Sale, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count
+	var total int
+	countQuery := `SELECT COUNT(*) FROM sales WHERE customer_id = $1 AND deleted_at IS NULL`
+	err := r.db.QueryRow(context.Background(), countQuery, customerID).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting customer sales", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := `
+		SELECT id, user_id, customer_id, gross_amount, discount_amount, tax_amount, total_amount, created_at, updated_at, deleted_at
+		FROM sales
+		WHERE customer_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(context.Background(), query, customerID, limit, offset)
+	if err != nil {
+		r.Logger.Error("error querying customer sales", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var sales []model.Sale
+	for rows.Next() {
+		var sale model.Sale
+		err := rows.Scan(
+			&sale.ID, &sale.UserID, &sale.CustomerID, &sale.GrossAmount, &sale.DiscountAmount, &sale.TaxAmount, &sale.TotalAmount, &sale.CreatedAt, &sale.UpdatedAt, &sale.DeletedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning customer sale", zap.Error(err))
+			return nil, 0, err
+		}
+		sales = append(sales, sale)
 	}
 
+	return sales, total, nil
+}
+
+func (r *saleRepository) Update(id int, userID int, sale *model.Sale, items []model.SaleItem) error {
 	// Begin transaction
-	tx, err := pool.Begin(context.Background())
+	tx, err := beginTx(r.db)
 	if err != nil {
 		r.Logger.Error("error beginning transaction", zap.Error(err))
 		return err
@@ -225,15 +289,8 @@ func (r *saleRepository) Update(id int, sale *model.Sale, items []model.SaleItem
 
 	// Return stock from old items
 	for _, oldItem := range oldItems {
-		returnStockQuery := `
-			UPDATE items
-			SET stock = stock + $1, updated_at = NOW()
-			WHERE id = $2
-		`
-		_, err := tx.Exec(context.Background(), returnStockQuery,
-			oldItem.Quantity, oldItem.ItemID,
-		)
-		if err != nil {
+		movement := saleMovement(id, userID, model.MovementTypeSaleEdit, oldItem, oldItem.Quantity)
+		if err := applyStockMovement(context.Background(), tx, movement); err != nil {
 			r.Logger.Error("error returning stock", zap.Error(err))
 			return err
 		}
@@ -247,13 +304,15 @@ func (r *saleRepository) Update(id int, sale *model.Sale, items []model.SaleItem
 		return err
 	}
 
-	// Update sale total amount
+	// Update sale customer and amounts
 	updateSaleQuery := `
 		UPDATE sales
-		SET total_amount = $1, updated_at = NOW()
-		WHERE id = $2 AND deleted_at IS NULL
+		SET customer_id = $1, gross_amount = $2, discount_amount = $3, tax_amount = $4, total_amount = $5, updated_at = NOW()
+		WHERE id = $6 AND deleted_at IS NULL
 	`
-	result, err := tx.Exec(context.Background(), updateSaleQuery, sale.TotalAmount, id)
+	result, err := tx.Exec(context.Background(), updateSaleQuery,
+		sale.CustomerID, sale.GrossAmount, sale.DiscountAmount, sale.TaxAmount, sale.TotalAmount, id,
+	)
 	if err != nil {
 		r.Logger.Error("error updating sale", zap.Error(err))
 		return err
@@ -263,41 +322,24 @@ func (r *saleRepository) Update(id int, sale *model.Sale, items []model.SaleItem
 		return errors.New("sale not found")
 	}
 
-	// Insert new sale items
-	itemQuery := `
-		INSERT INTO sale_items (sale_id, item_id, quantity, price_at_sale, subtotal)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id
-	`
+	// Reduce stock for new items and insert them
 	for i := range items {
 		items[i].SaleID = id
-		err = tx.QueryRow(context.Background(), itemQuery,
-			items[i].SaleID, items[i].ItemID, items[i].Quantity,
-			items[i].PriceAtSale, items[i].Subtotal,
-		).Scan(&items[i].ID)
-
-		if err != nil {
-			r.Logger.Error("error creating new sale item", zap.Error(err))
+		movement := saleMovement(id, userID, model.MovementTypeSaleEdit, items[i], -items[i].Quantity)
+		if err := applyStockMovement(context.Background(), tx, movement); err != nil {
+			r.Logger.Error("error updating item stock", zap.Error(err))
 			return err
 		}
 
-		// Reduce stock for new items
-		updateStockQuery := `
-			UPDATE items
-			SET stock = stock - $1, updated_at = NOW()
-			WHERE id = $2 AND stock >= $3
-		`
-		result, err := tx.Exec(context.Background(), updateStockQuery,
-			items[i].Quantity, items[i].ItemID, items[i].Quantity,
-		)
-		if err != nil {
-			r.Logger.Error("error updating item stock", zap.Error(err))
+		if err := r.insertSaleItem(tx, &items[i], movement); err != nil {
+			r.Logger.Error("error creating new sale item", zap.Error(err))
 			return err
 		}
+	}
 
-		if result.RowsAffected() == 0 {
-			return errors.New("insufficient stock for item")
-		}
+	if err := checkReservedStock(context.Background(), tx, saleItemIDs(items)); err != nil {
+		r.Logger.Error("error checking reserved stock", zap.Error(err))
+		return err
 	}
 
 	// Commit transaction
@@ -310,15 +352,9 @@ func (r *saleRepository) Update(id int, sale *model.Sale, items []model.SaleItem
 	return nil
 }
 
-func (r *saleRepository) Delete(id int) error {
-	// Type assert to get pool for transaction
-	pool, ok := r.db.(*pgxpool.Pool)
-	if !ok {
-		return errors.New("database connection does not support transactions")
-	}
-
+func (r *saleRepository) Delete(id int, userID int) error {
 	// Begin transaction
-	tx, err := pool.Begin(context.Background())
+	tx, err := beginTx(r.db)
 	if err != nil {
 		r.Logger.Error("error beginning transaction", zap.Error(err))
 		return err
@@ -334,15 +370,8 @@ func (r *saleRepository) Delete(id int) error {
 
 	// Return stock for each item
 	for _, item := range saleItems {
-		returnStockQuery := `
-			UPDATE items
-			SET stock = stock + $1, updated_at = NOW()
-			WHERE id = $2
-		`
-		_, err := tx.Exec(context.Background(), returnStockQuery,
-			item.Quantity, item.ItemID,
-		)
-		if err != nil {
+		movement := saleMovement(id, userID, model.MovementTypeSaleVoid, item, item.Quantity)
+		if err := applyStockMovement(context.Background(), tx, movement); err != nil {
 			r.Logger.Error("error returning stock", zap.Error(err))
 			return err
 		}
@@ -377,3 +406,54 @@ func (r *saleRepository) Delete(id int) error {
 
 	return nil
 }
+
+// insertSaleItem stores a sale line with the cost of goods sold of the
+// movement that took its stock out
+func (r *saleRepository) insertSaleItem(tx pgx.Tx, line *model.SaleItem, movement *model.StockMovement) error {
+	line.CostAmount = movement.Cost.Amount(r.costingMethod)
+	line.UnitCost = line.CostAmount.Share(1, int64(line.Quantity))
+
+	query := `
+		INSERT INTO sale_items (sale_id, item_id, rack_id, quantity, price_at_sale,
+		                        gross_amount, discount_amount, tax_rate, tax_amount, subtotal, lot_number,
+		                        serial_numbers, unit_cost, cost_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id
+	`
+	return tx.QueryRow(context.Background(), query,
+		line.SaleID, line.ItemID, line.RackID, line.Quantity,
+		line.PriceAtSale, line.GrossAmount, line.DiscountAmount,
+		line.TaxRate, line.TaxAmount, line.Subtotal, line.LotNumber,
+		line.SerialNumbers, line.UnitCost, line.CostAmount,
+	).Scan(&line.ID)
+}
+
+// saleMovement builds the ledger entry for a stock change caused by a sale line
+func saleMovement(saleID, userID int, movementType string, line model.SaleItem, quantity int) *model.StockMovement {
+	referenceType := model.ReferenceTypeSale
+	movement := &model.StockMovement{
+		ItemID:        line.ItemID,
+		UserID:        userID,
+		RackID:        line.RackID,
+		MovementType:  movementType,
+		Quantity:      quantity,
+		ReferenceType: &referenceType,
+		ReferenceID:   &saleID,
+		LotNumber:     line.LotNumber,
+		SerialNumbers: line.SerialNumbers,
+	}
+	// Units coming back from a sale go in at what they cost when sold
+	if quantity > 0 {
+		movement.UnitCost = &line.UnitCost
+	}
+	return movement
+}
+
+// saleItemIDs returns the item of every sale line
+func saleItemIDs(items []model.SaleItem) []int {
+	itemIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ItemID)
+	}
+	return itemIDs
+}
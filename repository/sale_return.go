@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var ErrOverReturn = errors.New("returned quantity exceeds sold quantity")
+
+type SaleReturnRepository interface {
+	Create(saleReturn *model.SaleReturn, items []model.SaleReturnItem) error
+	FindBySaleID(saleID int) ([]model.SaleReturn, error)
+	FindReturnItemsBySaleID(saleID int) ([]model.SaleReturnItem, error)
+}
+
+type saleReturnRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewSaleReturnRepository(db database.PgxIface, log *zap.Logger) SaleReturnRepository {
+	return &saleReturnRepository{db: db, Logger: log}
+}
+
+// Create books the return and puts restocked lines back into their rack in one
+// transaction. The sale row is locked so concurrent returns can't return more
+// than was sold.
+func (r *saleReturnRepository) Create(saleReturn *model.SaleReturn, items []model.SaleReturnItem) error {
+	// Begin transaction
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	// Lock the sale, voided sales can't be returned
+	var saleID int
+	lockQuery := `SELECT id FROM sales WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	err = tx.QueryRow(context.Background(), lockQuery, saleReturn.SaleID).Scan(&saleID)
+	if err == pgx.ErrNoRows {
+		return errors.New("sale not found")
+	}
+	if err != nil {
+		r.Logger.Error("error locking sale", zap.Error(err))
+		return err
+	}
+
+	// Insert return header
+	query := `
+		INSERT INTO sale_returns (sale_id, note, refund_amount, created_by, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(context.Background(), query,
+		saleReturn.SaleID, saleReturn.Note, saleReturn.RefundAmount, saleReturn.CreatedBy,
+	).Scan(&saleReturn.ID, &saleReturn.CreatedAt)
+
+	if err != nil {
+		r.Logger.Error("error creating sale return", zap.Error(err))
+		return err
+	}
+
+	// Sold quantity of the line minus everything returned so far, including
+	// earlier lines of this return, the lot restocked units go back to and the
+	// cost they go back in at
+	remainingQuery := `
+		SELECT si.quantity - COALESCE((
+			SELECT SUM(sri.quantity) FROM sale_return_items sri WHERE sri.sale_item_id = si.id
+		), 0), si.lot_number, si.unit_cost
+		FROM sale_items si
+		WHERE si.id = $1 AND si.sale_id = $2
+	`
+	itemQuery := `
+		INSERT INTO sale_return_items (return_id, sale_item_id, item_id, rack_id, quantity, condition,
+		                               price_at_sale, refund_amount, serial_numbers)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+	for i := range items {
+		var remaining int
+		var lotNumber *string
+		var unitCost money.Amount
+		err = tx.QueryRow(context.Background(), remainingQuery, items[i].SaleItemID, saleReturn.SaleID).Scan(&remaining, &lotNumber, &unitCost)
+		if err == pgx.ErrNoRows {
+			return errors.New("sale item not found")
+		}
+		if err != nil {
+			r.Logger.Error("error checking returned quantity", zap.Error(err))
+			return err
+		}
+		if items[i].Quantity > remaining {
+			return ErrOverReturn
+		}
+
+		items[i].ReturnID = saleReturn.ID
+		err = tx.QueryRow(context.Background(), itemQuery,
+			items[i].ReturnID, items[i].SaleItemID, items[i].ItemID, items[i].RackID, items[i].Quantity,
+			items[i].Condition, items[i].PriceAtSale, items[i].RefundAmount, items[i].SerialNumbers,
+		).Scan(&items[i].ID)
+
+		if err != nil {
+			r.Logger.Error("error creating sale return item", zap.Error(err))
+			return err
+		}
+
+		// Damaged goods are refunded but never go back into stock, their
+		// serials are marked damaged
+		if items[i].Condition != model.ReturnConditionRestock {
+			if len(items[i].SerialNumbers) > 0 {
+				err := damageSerials(context.Background(), tx, items[i].ItemID, items[i].RackID,
+					saleReturn.CreatedBy, saleReturn.ID, items[i].SerialNumbers,
+				)
+				if err != nil {
+					r.Logger.Error("error marking returned serials damaged", zap.Error(err))
+					return err
+				}
+			}
+			continue
+		}
+
+		referenceType := model.ReferenceTypeReturn
+		movement := &model.StockMovement{
+			ItemID:        items[i].ItemID,
+			UserID:        saleReturn.CreatedBy,
+			RackID:        items[i].RackID,
+			MovementType:  model.MovementTypeSaleReturn,
+			Quantity:      items[i].Quantity,
+			ReferenceType: &referenceType,
+			ReferenceID:   &saleReturn.ID,
+			LotNumber:     lotNumber,
+			SerialNumbers: items[i].SerialNumbers,
+			UnitCost:      &unitCost,
+		}
+		if err := applyStockMovement(context.Background(), tx, movement); err != nil {
+			r.Logger.Error("error restocking returned item", zap.Error(err))
+			return err
+		}
+	}
+
+	// Commit transaction
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *saleReturnRepository) FindBySaleID(saleID int) ([]model.SaleReturn, error) {
+	query := `
+		SELECT id, sale_id, note, refund_amount, created_by, created_at
+		FROM sale_returns
+		WHERE sale_id = $1
+		ORDER BY id ASC
+	`
+	rows, err := r.db.Query(context.Background(), query, saleID)
+	if err != nil {
+		r.Logger.Error("error querying sale returns", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var returns []model.SaleReturn
+	for rows.Next() {
+		var saleReturn model.SaleReturn
+		err := rows.Scan(
+			&saleReturn.ID, &saleReturn.SaleID, &saleReturn.Note, &saleReturn.RefundAmount,
+			&saleReturn.CreatedBy, &saleReturn.CreatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning sale return", zap.Error(err))
+			return nil, err
+		}
+		returns = append(returns, saleReturn)
+	}
+
+	return returns, nil
+}
+
+// FindReturnItemsBySaleID returns the lines of every return booked against the sale
+func (r *saleReturnRepository) FindReturnItemsBySaleID(saleID int) ([]model.SaleReturnItem, error) {
+	query := `
+		SELECT sri.id, sri.return_id, sri.sale_item_id, sri.item_id, sri.rack_id, sri.quantity,
+		       sri.condition, sri.price_at_sale, sri.refund_amount, sri.serial_numbers
+		FROM sale_return_items sri
+		JOIN sale_returns sr ON sr.id = sri.return_id
+		WHERE sr.sale_id = $1
+		ORDER BY sri.id ASC
+	`
+	rows, err := r.db.Query(context.Background(), query, saleID)
+	if err != nil {
+		r.Logger.Error("error querying sale return items", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.SaleReturnItem
+	for rows.Next() {
+		var item model.SaleReturnItem
+		err := rows.Scan(
+			&item.ID, &item.ReturnID, &item.SaleItemID, &item.ItemID, &item.RackID, &item.Quantity,
+			&item.Condition, &item.PriceAtSale, &item.RefundAmount, &item.SerialNumbers,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning sale return item", zap.Error(err))
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
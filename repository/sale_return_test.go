@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSaleReturnRepository_Create_RestockAndDamaged(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewSaleReturnRepository(mockDB, zap.NewNop())
+
+	saleReturn := &model.SaleReturn{SaleID: 5, RefundAmount: money.FromUnits(300000), CreatedBy: 2}
+	items := []model.SaleReturnItem{
+		{SaleItemID: 7, ItemID: 3, RackID: 1, Quantity: 2, Condition: model.ReturnConditionRestock, PriceAtSale: money.FromUnits(100000), RefundAmount: money.FromUnits(200000)},
+		{SaleItemID: 8, ItemID: 4, RackID: 1, Quantity: 1, Condition: model.ReturnConditionDamaged, PriceAtSale: money.FromUnits(100000), RefundAmount: money.FromUnits(100000)},
+	}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`SELECT id FROM sales WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(5).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(5))
+	mockDB.
+		ExpectQuery(`INSERT INTO sale_returns`).
+		WithArgs(5, saleReturn.Note, money.FromUnits(300000), 2).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(11, time.Now()))
+
+	// Restocked line goes back into its rack
+	mockDB.
+		ExpectQuery(`SELECT si.quantity - COALESCE`).
+		WithArgs(7, 5).
+		WillReturnRows(pgxmock.NewRows([]string{"remaining", "lot_number", "unit_cost"}).AddRow(2, nil, money.FromUnits(60000)))
+	mockDB.
+		ExpectQuery(`INSERT INTO sale_return_items`).
+		WithArgs(11, 7, 3, 1, 2, model.ReturnConditionRestock, money.FromUnits(100000), money.FromUnits(200000), []string(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(1))
+	mockDB.
+		ExpectExec(`INSERT INTO item_locations`).
+		WithArgs(3, 1, 2).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockDB.
+		ExpectQuery(`UPDATE items`).
+		WithArgs(2, 3).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(12))
+	soldCost := money.FromUnits(60000)
+	mockDB.
+		ExpectQuery(`INSERT INTO cost_layers`).
+		WithArgs(3, 2, &soldCost, pgxmock.AnyArg(), pgxmock.AnyArg()). // back in at what it cost when sold
+		WillReturnRows(pgxmock.NewRows([]string{"unit_cost"}).AddRow(soldCost))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(3, 2, 1, model.MovementTypeSaleReturn, 2, 12,
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+
+	// Damaged line is only recorded
+	mockDB.
+		ExpectQuery(`SELECT si.quantity - COALESCE`).
+		WithArgs(8, 5).
+		WillReturnRows(pgxmock.NewRows([]string{"remaining", "lot_number", "unit_cost"}).AddRow(3, nil, money.FromUnits(60000)))
+	mockDB.
+		ExpectQuery(`INSERT INTO sale_return_items`).
+		WithArgs(11, 8, 4, 1, 1, model.ReturnConditionDamaged, money.FromUnits(100000), money.FromUnits(100000), []string(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(2))
+	mockDB.ExpectCommit()
+
+	err = repo.Create(saleReturn, items)
+	require.NoError(t, err)
+	require.Equal(t, 11, saleReturn.ID)
+	require.Equal(t, 11, items[1].ReturnID)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestSaleReturnRepository_Create_OverReturn(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewSaleReturnRepository(mockDB, zap.NewNop())
+
+	saleReturn := &model.SaleReturn{SaleID: 5, RefundAmount: money.FromUnits(300000), CreatedBy: 2}
+	items := []model.SaleReturnItem{
+		{SaleItemID: 7, ItemID: 3, RackID: 1, Quantity: 3, Condition: model.ReturnConditionRestock, PriceAtSale: money.FromUnits(100000), RefundAmount: money.FromUnits(300000)},
+	}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`SELECT id FROM sales`).
+		WithArgs(5).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(5))
+	mockDB.
+		ExpectQuery(`INSERT INTO sale_returns`).
+		WithArgs(5, saleReturn.Note, money.FromUnits(300000), 2).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(11, time.Now()))
+	mockDB.
+		ExpectQuery(`SELECT si.quantity - COALESCE`).
+		WithArgs(7, 5).
+		WillReturnRows(pgxmock.NewRows([]string{"remaining", "lot_number", "unit_cost"}).AddRow(1, nil, money.FromUnits(60000))) // 2 of 3 already returned
+	mockDB.ExpectRollback()
+
+	err = repo.Create(saleReturn, items)
+	require.ErrorIs(t, err, ErrOverReturn)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestSaleReturnRepository_Create_SaleVoided(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewSaleReturnRepository(mockDB, zap.NewNop())
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`SELECT id FROM sales`).
+		WithArgs(5).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}))
+	mockDB.ExpectRollback()
+
+	err = repo.Create(&model.SaleReturn{SaleID: 5, CreatedBy: 2}, nil)
+	require.Error(t, err)
+	require.Equal(t, "sale not found", err.Error())
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"project-app-inventory/model"
+	"project-app-inventory/money"
 	"testing"
 	"time"
 
@@ -17,19 +19,20 @@ func TestSaleRepository_FindByID_Success(t *testing.T) {
 	require.NoError(t, err)
 	defer mockDB.Close()
 
-	repo := NewSaleRepository(mockDB, zap.NewNop())
+	repo := NewSaleRepository(mockDB, zap.NewNop(), model.CostingMethodAverage)
 
 	mockDB.
 		ExpectQuery(`SELECT (.+) FROM sales`).
 		WithArgs(1).
-		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "total_amount", "created_at", "updated_at", "deleted_at"}).
-			AddRow(1, 1, 150000.0, time.Now(), time.Now(), nil))
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "customer_id", "gross_amount", "discount_amount", "tax_amount", "total_amount", "created_at", "updated_at", "deleted_at"}).
+			AddRow(1, 1, nil, money.FromUnits(150000), money.FromUnits(15000), money.FromUnits(14850), money.FromUnits(149850), time.Now(), time.Now(), nil))
 
 	sale, err := repo.FindByID(1)
 	require.NoError(t, err)
 	require.NotNil(t, sale)
 	require.Equal(t, 1, sale.ID)
-	require.Equal(t, 150000.0, sale.TotalAmount)
+	require.Equal(t, money.FromUnits(15000), sale.DiscountAmount)
+	require.Equal(t, money.FromUnits(149850), sale.TotalAmount)
 
 	require.NoError(t, mockDB.ExpectationsWereMet())
 }
@@ -39,14 +42,18 @@ func TestSaleRepository_FindSaleItems_Success(t *testing.T) {
 	require.NoError(t, err)
 	defer mockDB.Close()
 
-	repo := NewSaleRepository(mockDB, zap.NewNop())
+	repo := NewSaleRepository(mockDB, zap.NewNop(), model.CostingMethodAverage)
 
 	mockDB.
-		ExpectQuery(`SELECT (.+) FROM sale_items WHERE sale_id`).
+		ExpectQuery(`SELECT (.+) FROM sale_items si (.+) WHERE si.sale_id`).
 		WithArgs(1).
-		WillReturnRows(pgxmock.NewRows([]string{"id", "sale_id", "item_id", "quantity", "price_at_sale", "subtotal"}).
-			AddRow(1, 1, 1, 2, 75000.0, 150000.0).
-			AddRow(2, 1, 2, 1, 50000.0, 50000.0))
+		WillReturnRows(pgxmock.NewRows([]string{"id", "sale_id", "item_id", "rack_id", "quantity", "price_at_sale",
+			"gross_amount", "discount_amount", "tax_rate", "tax_amount", "subtotal", "lot_number", "serial_numbers",
+			"unit_cost", "cost_amount"}).
+			AddRow(1, 1, 1, 1, 2, money.FromUnits(75000), money.FromUnits(150000), money.FromUnits(0), money.Rate(1100), money.FromUnits(16500), money.FromUnits(166500), nil, nil,
+				money.FromUnits(40000), money.FromUnits(80000)).
+			AddRow(2, 1, 2, 3, 1, money.FromUnits(50000), money.FromUnits(50000), money.FromUnits(5000), money.Rate(0), money.FromUnits(0), money.FromUnits(45000), nil, nil,
+				money.FromUnits(30000), money.FromUnits(30000)))
 
 	items, err := repo.FindSaleItems(1)
 	require.NoError(t, err)
@@ -54,6 +61,199 @@ func TestSaleRepository_FindSaleItems_Success(t *testing.T) {
 	require.Len(t, items, 2)
 	require.Equal(t, 1, items[0].ID)
 	require.Equal(t, 2, items[0].Quantity)
+	require.Equal(t, 3, items[1].RackID)
+	require.Equal(t, money.Rate(1100), items[0].TaxRate)
+	require.Equal(t, money.FromUnits(45000), items[1].Subtotal)
+	require.Equal(t, money.FromUnits(80000), items[0].CostAmount)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestSaleRepository_FindByCustomerID_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewSaleRepository(mockDB, zap.NewNop(), model.CostingMethodAverage)
+
+	customerID := 4
+	mockDB.
+		ExpectQuery(`SELECT COUNT`).
+		WithArgs(customerID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM sales WHERE customer_id`).
+		WithArgs(customerID, 10, 0).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "customer_id", "gross_amount", "discount_amount", "tax_amount", "total_amount", "created_at", "updated_at", "deleted_at"}).
+			AddRow(7, 2, &customerID, money.FromUnits(250000), money.FromUnits(0), money.FromUnits(0), money.FromUnits(250000), time.Now(), time.Now(), nil))
+
+	sales, total, err := repo.FindByCustomerID(customerID, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Len(t, sales, 1)
+	require.Equal(t, customerID, *sales[0].CustomerID)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestSaleRepository_Create_StoresBreakdown(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewSaleRepository(mockDB, zap.NewNop(), model.CostingMethodAverage)
+
+	sale := &model.Sale{
+		UserID:         1,
+		GrossAmount:    money.FromUnits(100000),
+		DiscountAmount: money.FromUnits(10000),
+		TaxAmount:      money.FromUnits(9900),
+		TotalAmount:    money.FromUnits(99900),
+	}
+	items := []model.SaleItem{{
+		ItemID: 3, RackID: 2, Quantity: 2,
+		PriceAtSale:    money.FromUnits(50000),
+		GrossAmount:    money.FromUnits(100000),
+		DiscountAmount: money.FromUnits(10000),
+		TaxRate:        money.Rate(1100),
+		TaxAmount:      money.FromUnits(9900),
+		Subtotal:       money.FromUnits(99900),
+	}}
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectQuery(`INSERT INTO sales`).
+		WithArgs(1, sale.CustomerID, money.FromUnits(100000), money.FromUnits(10000), money.FromUnits(9900), money.FromUnits(99900)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(5, time.Now(), time.Now()))
+	mockDB.ExpectExec(`UPDATE item_locations`).
+		WithArgs(3, 2, -2).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockDB.ExpectQuery(`UPDATE items`).
+		WithArgs(-2, 3).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(8))
+	mockDB.ExpectQuery(`UPDATE cost_layers`).
+		WithArgs(3, 2, pgxmock.AnyArg(), pgxmock.AnyArg(), (*money.Amount)(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"average_cost", "layered_quantity", "layered_cost"}).
+			AddRow(money.FromUnits(30000), 2, money.FromUnits(50000)))
+	mockDB.ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(3, 1, 2, model.MovementTypeSale, -2, 8,
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	mockDB.ExpectQuery(`INSERT INTO sale_items`).
+		WithArgs(5, 3, 2, 2, money.FromUnits(50000), money.FromUnits(100000), money.FromUnits(10000),
+			money.Rate(1100), money.FromUnits(9900), money.FromUnits(99900), (*string)(nil), []string(nil),
+			money.FromUnits(30000), money.FromUnits(60000)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(11))
+	mockDB.ExpectQuery(`SELECT stock - in_transit - COALESCE`).
+		WithArgs(3).
+		WillReturnRows(pgxmock.NewRows([]string{"available"}).AddRow(8))
+	mockDB.ExpectCommit()
+
+	err = repo.Create(sale, items)
+	require.NoError(t, err)
+	require.Equal(t, 5, sale.ID)
+	require.Equal(t, 11, items[0].ID)
+	require.Equal(t, money.FromUnits(60000), items[0].CostAmount) // two units at the average cost
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestSaleRepository_Create_FIFOCost(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewSaleRepository(mockDB, zap.NewNop(), model.CostingMethodFIFO)
+
+	sale := &model.Sale{UserID: 1, GrossAmount: money.FromUnits(150000), TotalAmount: money.FromUnits(150000)}
+	items := []model.SaleItem{{
+		ItemID: 3, RackID: 2, Quantity: 3,
+		PriceAtSale: money.FromUnits(50000),
+		GrossAmount: money.FromUnits(150000),
+		Subtotal:    money.FromUnits(150000),
+	}}
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectQuery(`INSERT INTO sales`).
+		WithArgs(1, sale.CustomerID, money.FromUnits(150000), money.Amount(0), money.Amount(0), money.FromUnits(150000)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(5, time.Now(), time.Now()))
+	mockDB.ExpectExec(`UPDATE item_locations`).
+		WithArgs(3, 2, -3).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockDB.ExpectQuery(`UPDATE items`).
+		WithArgs(-3, 3).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(7))
+	mockDB.ExpectQuery(`UPDATE cost_layers`).
+		WithArgs(3, 3, pgxmock.AnyArg(), pgxmock.AnyArg(), (*money.Amount)(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"average_cost", "layered_quantity", "layered_cost"}).
+			AddRow(money.FromUnits(30000), 3, money.FromUnits(70000)))
+	mockDB.ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(3, 1, 2, model.MovementTypeSale, -3, 7,
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	mockDB.ExpectQuery(`INSERT INTO sale_items`).
+		WithArgs(5, 3, 2, 3, money.FromUnits(50000), money.FromUnits(150000), money.Amount(0),
+			money.Rate(0), money.Amount(0), money.FromUnits(150000), (*string)(nil), []string(nil),
+			money.Amount(2333333), money.FromUnits(70000)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(11))
+	mockDB.ExpectQuery(`SELECT stock - in_transit - COALESCE`).
+		WithArgs(3).
+		WillReturnRows(pgxmock.NewRows([]string{"available"}).AddRow(7))
+	mockDB.ExpectCommit()
+
+	err = repo.Create(sale, items)
+	require.NoError(t, err)
+	require.Equal(t, money.FromUnits(70000), items[0].CostAmount) // the layers taken, not the average
+	require.Equal(t, money.Amount(2333333), items[0].UnitCost)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestSaleRepository_Create_ReservedMeanwhile(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewSaleRepository(mockDB, zap.NewNop(), model.CostingMethodAverage)
+
+	sale := &model.Sale{UserID: 1, GrossAmount: money.FromUnits(100000), TotalAmount: money.FromUnits(100000)}
+	items := []model.SaleItem{{
+		ItemID: 3, RackID: 2, Quantity: 2,
+		PriceAtSale: money.FromUnits(50000),
+		GrossAmount: money.FromUnits(100000),
+		Subtotal:    money.FromUnits(100000),
+	}}
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectQuery(`INSERT INTO sales`).
+		WithArgs(1, sale.CustomerID, money.FromUnits(100000), money.Amount(0), money.Amount(0), money.FromUnits(100000)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(5, time.Now(), time.Now()))
+	mockDB.ExpectExec(`UPDATE item_locations`).
+		WithArgs(3, 2, -2).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockDB.ExpectQuery(`UPDATE items`).
+		WithArgs(-2, 3).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(8))
+	mockDB.ExpectQuery(`UPDATE cost_layers`).
+		WithArgs(3, 2, pgxmock.AnyArg(), pgxmock.AnyArg(), (*money.Amount)(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"average_cost", "layered_quantity", "layered_cost"}).
+			AddRow(money.FromUnits(30000), 2, money.FromUnits(50000)))
+	mockDB.ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(3, 1, 2, model.MovementTypeSale, -2, 8,
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	mockDB.ExpectQuery(`INSERT INTO sale_items`).
+		WithArgs(5, 3, 2, 2, money.FromUnits(50000), money.FromUnits(100000), money.Amount(0),
+			money.Rate(0), money.Amount(0), money.FromUnits(100000), (*string)(nil), []string(nil),
+			money.FromUnits(30000), money.FromUnits(60000)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(11))
+	// Of the 8 units left a reservation made in the meantime holds 9
+	mockDB.ExpectQuery(`SELECT stock - in_transit - COALESCE`).
+		WithArgs(3).
+		WillReturnRows(pgxmock.NewRows([]string{"available"}).AddRow(-1))
+	mockDB.ExpectRollback()
+
+	err = repo.Create(sale, items)
+	require.ErrorIs(t, err, ErrInsufficientAvailable)
 
 	require.NoError(t, mockDB.ExpectationsWereMet())
 }
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInsufficientStock    = errors.New("insufficient stock for item")
+	ErrInsufficientLotStock = errors.New("insufficient stock in lot")
+)
+
+type StockMovementRepository interface {
+	Record(movement *model.StockMovement) error
+	FindByItemID(itemID int, from, to *time.Time, page, limit int) ([]model.StockMovement, int, error)
+}
+
+type stockMovementRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewStockMovementRepository(db database.PgxIface, log *zap.Logger) StockMovementRepository {
+	return &stockMovementRepository{db: db, Logger: log}
+}
+
+// Record applies a single movement in its own transaction
+func (r *stockMovementRepository) Record(movement *model.StockMovement) error {
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	if err := applyStockMovement(context.Background(), tx, movement); err != nil {
+		r.Logger.Error("error recording stock movement", zap.Error(err))
+		return err
+	}
+
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *stockMovementRepository) FindByItemID(itemID int, from, to *time.Time, page, limit int) ([]model.StockMovement, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count
+	var total int
+	countQuery := `
+		SELECT COUNT(*) FROM stock_movements
+		WHERE item_id = $1
+		  AND ($2::timestamptz IS NULL OR created_at >= $2)
+		  AND ($3::timestamptz IS NULL OR created_at < $3)
+	`
+	err := r.db.QueryRow(context.Background(), countQuery, itemID, from, to).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting stock movements", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Oldest first so the history can be replayed in order
+	query := `
+		SELECT id, item_id, user_id, rack_id, movement_type, quantity, balance_after,
+		       reason_code, reason, reference_type, reference_id, lot_number, created_at
+		FROM stock_movements
+		WHERE item_id = $1
+		  AND ($2::timestamptz IS NULL OR created_at >= $2)
+		  AND ($3::timestamptz IS NULL OR created_at < $3)
+		ORDER BY created_at ASC, id ASC
+		LIMIT $4 OFFSET $5
+	`
+	rows, err := r.db.Query(context.Background(), query, itemID, from, to, limit, offset)
+	if err != nil {
+		r.Logger.Error("error querying stock movements", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var movements []model.StockMovement
+	for rows.Next() {
+		var m model.StockMovement
+		err := rows.Scan(
+			&m.ID, &m.ItemID, &m.UserID, &m.RackID, &m.MovementType, &m.Quantity, &m.BalanceAfter,
+			&m.ReasonCode, &m.Reason, &m.ReferenceType, &m.ReferenceID, &m.LotNumber, &m.CreatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning stock movement", zap.Error(err))
+			return nil, 0, err
+		}
+		movements = append(movements, m)
+	}
+
+	return movements, total, nil
+}
+
+// applyStockMovement changes the stock of one rack location by movement.Quantity,
+// keeps items.stock in sync as the aggregate of all locations plus the units in
+// transit and writes the matching ledger row with the resulting balance. A
+// transfer movement moves its units between the rack and items.in_transit, so
+// the item's stock stays the same while the goods are on the way. A movement with a lot number
+// also changes that lot in the rack. The lot must already exist; stock coming
+// in may open it in another rack, with the same expiry date. A movement with
+// serial numbers moves exactly those units. The item's cost follows along, see
+// applyCost. Callers must run it inside a transaction so the stock change and
+// its ledger entry commit together.
+func applyStockMovement(ctx context.Context, tx database.PgxIface, movement *model.StockMovement) error {
+	var locationQuery string
+	if movement.Quantity > 0 {
+		locationQuery = `
+			INSERT INTO item_locations (item_id, rack_id, quantity, created_at, updated_at)
+			VALUES ($1, $2, $3, NOW(), NOW())
+			ON CONFLICT (item_id, rack_id)
+			DO UPDATE SET quantity = item_locations.quantity + EXCLUDED.quantity, updated_at = NOW()
+		`
+	} else {
+		locationQuery = `
+			UPDATE item_locations
+			SET quantity = quantity + $3, updated_at = NOW()
+			WHERE item_id = $1 AND rack_id = $2 AND quantity + $3 >= 0
+		`
+	}
+	result, err := tx.Exec(ctx, locationQuery, movement.ItemID, movement.RackID, movement.Quantity)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrInsufficientStock
+	}
+
+	transfer := movement.MovementType == model.MovementTypeTransferOut || movement.MovementType == model.MovementTypeTransferIn
+	updateStockQuery := `
+		UPDATE items
+		SET stock = stock + $1, updated_at = NOW()
+		WHERE id = $2 AND stock + $1 >= 0
+		RETURNING stock
+	`
+	if transfer {
+		updateStockQuery = `
+			UPDATE items
+			SET in_transit = in_transit - $1, updated_at = NOW()
+			WHERE id = $2 AND in_transit - $1 >= 0
+			RETURNING stock
+		`
+	}
+	err = tx.QueryRow(ctx, updateStockQuery, movement.Quantity, movement.ItemID).Scan(&movement.BalanceAfter)
+	if err == pgx.ErrNoRows {
+		return ErrInsufficientStock
+	}
+	if err != nil {
+		return err
+	}
+
+	if movement.LotNumber != nil {
+		var lotQuery string
+		if movement.Quantity > 0 {
+			lotQuery = `
+				INSERT INTO item_lots (item_id, rack_id, lot_number, expiry_date, quantity, created_at, updated_at)
+				SELECT $1, $2, $3, MIN(expiry_date), $4, NOW(), NOW()
+				FROM item_lots
+				WHERE item_id = $1 AND lot_number = $3
+				HAVING COUNT(*) > 0
+				ON CONFLICT (item_id, rack_id, lot_number)
+				DO UPDATE SET quantity = item_lots.quantity + EXCLUDED.quantity, updated_at = NOW()
+			`
+		} else {
+			lotQuery = `
+				UPDATE item_lots
+				SET quantity = quantity + $4, updated_at = NOW()
+				WHERE item_id = $1 AND rack_id = $2 AND lot_number = $3 AND quantity + $4 >= 0
+			`
+		}
+		result, err := tx.Exec(ctx, lotQuery, movement.ItemID, movement.RackID, *movement.LotNumber, movement.Quantity)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrInsufficientLotStock
+		}
+	}
+
+	if len(movement.SerialNumbers) > 0 {
+		if err := moveSerials(ctx, tx, movement); err != nil {
+			return err
+		}
+	}
+
+	// Transfers only move stock between racks, its cost stays the same
+	if !transfer {
+		if err := applyCost(ctx, tx, movement); err != nil {
+			return err
+		}
+	}
+
+	insertQuery := `
+		INSERT INTO stock_movements (item_id, user_id, rack_id, movement_type, quantity, balance_after,
+		                             reason_code, reason, reference_type, reference_id, lot_number,
+		                             average_cost, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11,
+		        (SELECT average_cost FROM items WHERE id = $1), NOW())
+		RETURNING id, created_at
+	`
+	return tx.QueryRow(ctx, insertQuery,
+		movement.ItemID, movement.UserID, movement.RackID, movement.MovementType, movement.Quantity, movement.BalanceAfter,
+		movement.ReasonCode, movement.Reason, movement.ReferenceType, movement.ReferenceID, movement.LotNumber,
+	).Scan(&movement.ID, &movement.CreatedAt)
+}
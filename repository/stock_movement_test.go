@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"errors"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStockMovementRepository_Record_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewStockMovementRepository(mockDB, zap.NewNop())
+
+	movement := &model.StockMovement{ItemID: 1, UserID: 2, RackID: 4, MovementType: model.MovementTypeAdjustment, Quantity: -3}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectExec(`UPDATE item_locations`).
+		WithArgs(1, 4, -3).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockDB.
+		ExpectQuery(`UPDATE items`).
+		WithArgs(-3, 1).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(7))
+	mockDB.
+		ExpectQuery(`UPDATE cost_layers`).
+		WithArgs(1, 3, movement.ReferenceType, movement.ReferenceID, movement.UnitCost).
+		WillReturnRows(pgxmock.NewRows([]string{"average_cost", "layered_quantity", "layered_cost"}).
+			AddRow(money.FromUnits(1500), 2, money.FromUnits(2800)))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(1, 2, 4, model.MovementTypeAdjustment, -3, 7, movement.ReasonCode, movement.Reason, movement.ReferenceType, movement.ReferenceID, movement.LotNumber).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(5, time.Now()))
+	mockDB.ExpectCommit()
+
+	err = repo.Record(movement)
+	require.NoError(t, err)
+	require.Equal(t, 5, movement.ID)
+	require.Equal(t, 7, movement.BalanceAfter)
+	require.Equal(t, money.FromUnits(4500), movement.Cost.Average)
+	require.Equal(t, money.FromUnits(4300), movement.Cost.FIFO) // 2 layered units, 1 older unit at the average
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStockMovementRepository_Record_InsufficientStock(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewStockMovementRepository(mockDB, zap.NewNop())
+
+	movement := &model.StockMovement{ItemID: 1, UserID: 2, RackID: 4, MovementType: model.MovementTypeAdjustment, Quantity: -30}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectExec(`UPDATE item_locations`).
+		WithArgs(1, 4, -30).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mockDB.ExpectRollback()
+
+	err = repo.Record(movement)
+	require.ErrorIs(t, err, ErrInsufficientStock)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStockMovementRepository_FindByItemID_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewStockMovementRepository(mockDB, zap.NewNop())
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	reference := model.ReferenceTypeSale
+	saleID := 4
+
+	mockDB.
+		ExpectQuery(`SELECT COUNT\(\*\) FROM stock_movements`).
+		WithArgs(1, &from, (*time.Time)(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM stock_movements`).
+		WithArgs(1, &from, (*time.Time)(nil), 10, 0).
+		WillReturnRows(pgxmock.NewRows([]string{
+			"id", "item_id", "user_id", "rack_id", "movement_type", "quantity", "balance_after",
+			"reason_code", "reason", "reference_type", "reference_id", "lot_number", "created_at",
+		}).AddRow(1, 1, 2, 4, model.MovementTypeSale, -2, 8, nil, nil, &reference, &saleID, nil, time.Now()))
+
+	movements, total, err := repo.FindByItemID(1, &from, nil, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Len(t, movements, 1)
+	require.Equal(t, -2, movements[0].Quantity)
+	require.Equal(t, 4, *movements[0].ReferenceID)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStockMovementRepository_FindByItemID_Error(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewStockMovementRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectQuery(`SELECT COUNT\(\*\) FROM stock_movements`).
+		WillReturnError(errors.New("database error"))
+
+	movements, total, err := repo.FindByItemID(1, nil, nil, 1, 10)
+	require.Error(t, err)
+	require.Nil(t, movements)
+	require.Equal(t, 0, total)
+}
+
+func TestStockMovementRepository_Record_IncomingCreatesLocation(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewStockMovementRepository(mockDB, zap.NewNop())
+
+	movement := &model.StockMovement{ItemID: 1, UserID: 2, RackID: 5, MovementType: model.MovementTypeAdjustment, Quantity: 4}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectExec(`INSERT INTO item_locations (.+) ON CONFLICT`).
+		WithArgs(1, 5, 4).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockDB.
+		ExpectQuery(`UPDATE items`).
+		WithArgs(4, 1).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(14))
+	mockDB.
+		ExpectQuery(`INSERT INTO cost_layers`).
+		WithArgs(1, 4, (*money.Amount)(nil), movement.ReferenceType, movement.ReferenceID).
+		WillReturnRows(pgxmock.NewRows([]string{"unit_cost"}).AddRow(money.FromUnits(1500)))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(1, 2, 5, model.MovementTypeAdjustment, 4, 14, movement.ReasonCode, movement.Reason, movement.ReferenceType, movement.ReferenceID, movement.LotNumber).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(6, time.Now()))
+	mockDB.ExpectCommit()
+
+	err = repo.Record(movement)
+	require.NoError(t, err)
+	require.Equal(t, 14, movement.BalanceAfter)
+	require.Equal(t, money.FromUnits(1500), *movement.UnitCost) // found stock comes in at the average cost
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStockMovementRepository_Record_LotInsufficient(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewStockMovementRepository(mockDB, zap.NewNop())
+
+	lotNumber := "LOT-A"
+	movement := &model.StockMovement{ItemID: 1, UserID: 2, RackID: 4, MovementType: model.MovementTypeAdjustment, Quantity: -3, LotNumber: &lotNumber}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectExec(`UPDATE item_locations`).
+		WithArgs(1, 4, -3).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockDB.
+		ExpectQuery(`UPDATE items`).
+		WithArgs(-3, 1).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(7))
+	mockDB.
+		ExpectExec(`UPDATE item_lots`).
+		WithArgs(1, 4, "LOT-A", -3).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mockDB.ExpectRollback()
+
+	err = repo.Record(movement)
+	require.ErrorIs(t, err, ErrInsufficientLotStock)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStockMovementRepository_Record_SerialsOut(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewStockMovementRepository(mockDB, zap.NewNop())
+
+	serials := []string{"SN-1", "SN-2"}
+	movement := &model.StockMovement{ItemID: 1, UserID: 2, RackID: 4, MovementType: model.MovementTypeAdjustment, Quantity: -2, SerialNumbers: serials}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectExec(`UPDATE item_locations`).
+		WithArgs(1, 4, -2).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockDB.
+		ExpectQuery(`UPDATE items`).
+		WithArgs(-2, 1).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(3))
+	mockDB.
+		ExpectExec(`UPDATE item_serials`).
+		WithArgs(1, 4, serials, model.SerialStatusRemoved, model.SerialStatusInStock).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	mockDB.
+		ExpectExec(`INSERT INTO serial_events`).
+		WithArgs(1, serials, model.MovementTypeAdjustment, 4, 2, movement.ReferenceType, movement.ReferenceID).
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mockDB.
+		ExpectQuery(`UPDATE cost_layers`).
+		WithArgs(1, 2, movement.ReferenceType, movement.ReferenceID, movement.UnitCost).
+		WillReturnRows(pgxmock.NewRows([]string{"average_cost", "layered_quantity", "layered_cost"}).
+			AddRow(money.FromUnits(1500), 2, money.FromUnits(3000)))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(1, 2, 4, model.MovementTypeAdjustment, -2, 3,
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(6, time.Now()))
+	mockDB.ExpectCommit()
+
+	err = repo.Record(movement)
+	require.NoError(t, err)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStockMovementRepository_Record_SerialNotInStock(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewStockMovementRepository(mockDB, zap.NewNop())
+
+	serials := []string{"SN-1", "SN-9"}
+	movement := &model.StockMovement{ItemID: 1, UserID: 2, RackID: 4, MovementType: model.MovementTypeAdjustment, Quantity: -2, SerialNumbers: serials}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectExec(`UPDATE item_locations`).
+		WithArgs(1, 4, -2).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockDB.
+		ExpectQuery(`UPDATE items`).
+		WithArgs(-2, 1).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(3))
+	mockDB.
+		ExpectExec(`UPDATE item_serials`).
+		WithArgs(1, 4, serials, model.SerialStatusRemoved, model.SerialStatusInStock).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1)) // SN-9 sits in another rack
+	mockDB.ExpectRollback()
+
+	err = repo.Record(movement)
+	require.ErrorIs(t, err, ErrSerialNotInStock)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
@@ -0,0 +1,406 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+var ErrStocktakeUncounted = errors.New("stocktake has uncounted lines")
+
+type StocktakeRepository interface {
+	Create(stocktake *model.Stocktake) error
+	FindByID(id int) (*model.Stocktake, error)
+	FindAll(status string, page, limit int) ([]model.Stocktake, int, error)
+	FindItems(stocktakeID int) ([]model.StocktakeItem, error)
+	Count(id, userID int, counts []model.StocktakeItem) error
+	Approve(id, userID int, excludeIDs []int) error
+	Cancel(id int) error
+	FindFrozenRacks(itemID int) ([]int, error)
+}
+
+type stocktakeRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewStocktakeRepository(db database.PgxIface, log *zap.Logger) StocktakeRepository {
+	return &stocktakeRepository{db: db, Logger: log}
+}
+
+// Create opens the count and snapshots the stock of every item in its racks as
+// the expected quantity. Without rack ids all racks of the warehouse are counted.
+func (r *stocktakeRepository) Create(stocktake *model.Stocktake) error {
+	// Begin transaction
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	if len(stocktake.RackIDs) == 0 && stocktake.WarehouseID != nil {
+		racksQuery := `SELECT COALESCE(array_agg(id ORDER BY id), '{}') FROM racks WHERE warehouse_id = $1`
+		err = tx.QueryRow(context.Background(), racksQuery, *stocktake.WarehouseID).Scan(&stocktake.RackIDs)
+		if err != nil {
+			r.Logger.Error("error finding warehouse racks", zap.Error(err))
+			return err
+		}
+	}
+	if len(stocktake.RackIDs) == 0 {
+		return errors.New("stocktake has no racks to count")
+	}
+
+	// An item on a rack is counted by one open stocktake at a time, otherwise
+	// both would post its variances. Counts of different ABC classes don't meet.
+	var overlapping bool
+	overlapQuery := `
+		SELECT EXISTS (
+			SELECT 1 FROM stocktakes
+			WHERE status = $1 AND rack_ids && $2
+			  AND (abc_classes IS NULL OR $3::text[] IS NULL OR abc_classes && $3)
+		)
+	`
+	err = tx.QueryRow(context.Background(), overlapQuery, model.StocktakeStatusOpen, stocktake.RackIDs, stocktake.ABCClasses).Scan(&overlapping)
+	if err != nil {
+		r.Logger.Error("error checking open stocktakes", zap.Error(err))
+		return err
+	}
+	if overlapping {
+		return errors.New("racks are already counted by an open stocktake")
+	}
+
+	// Insert stocktake header
+	query := `
+		INSERT INTO stocktakes (warehouse_id, rack_ids, status, freeze_sales, abc_classes, note, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	stocktake.Status = model.StocktakeStatusOpen
+	err = tx.QueryRow(context.Background(), query,
+		stocktake.WarehouseID, stocktake.RackIDs, stocktake.Status, stocktake.FreezeSales,
+		stocktake.ABCClasses, stocktake.Note, stocktake.CreatedBy,
+	).Scan(&stocktake.ID, &stocktake.CreatedAt, &stocktake.UpdatedAt)
+
+	if err != nil {
+		r.Logger.Error("error creating stocktake", zap.Error(err))
+		return err
+	}
+
+	// Snapshot the expected quantities
+	snapshotQuery := `
+		INSERT INTO stocktake_items (stocktake_id, item_id, rack_id, expected_quantity)
+		SELECT $1, il.item_id, il.rack_id, il.quantity
+		FROM item_locations il
+		JOIN items i ON i.id = il.item_id
+		WHERE il.rack_id = ANY($2) AND ($3::text[] IS NULL OR i.abc_class = ANY($3))
+	`
+	_, err = tx.Exec(context.Background(), snapshotQuery, stocktake.ID, stocktake.RackIDs, stocktake.ABCClasses)
+	if err != nil {
+		r.Logger.Error("error snapshotting stocktake items", zap.Error(err))
+		return err
+	}
+
+	// Commit transaction
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *stocktakeRepository) FindByID(id int) (*model.Stocktake, error) {
+	query := `
+		SELECT id, warehouse_id, rack_ids, status, freeze_sales, abc_classes, note, created_by,
+		       approved_by, approved_at, created_at, updated_at
+		FROM stocktakes
+		WHERE id = $1
+	`
+	var stocktake model.Stocktake
+	err := r.db.QueryRow(context.Background(), query, id).Scan(
+		&stocktake.ID, &stocktake.WarehouseID, &stocktake.RackIDs, &stocktake.Status, &stocktake.FreezeSales,
+		&stocktake.ABCClasses, &stocktake.Note, &stocktake.CreatedBy, &stocktake.ApprovedBy, &stocktake.ApprovedAt,
+		&stocktake.CreatedAt, &stocktake.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.Error("error finding stocktake by id", zap.Error(err))
+		return nil, err
+	}
+	return &stocktake, nil
+}
+
+func (r *stocktakeRepository) FindAll(status string, page, limit int) ([]model.Stocktake, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count, empty status means all stocktakes
+	var total int
+	countQuery := `SELECT COUNT(*) FROM stocktakes WHERE ($1 = '' OR status = $1)`
+	err := r.db.QueryRow(context.Background(), countQuery, status).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting stocktakes", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := `
+		SELECT id, warehouse_id, rack_ids, status, freeze_sales, abc_classes, note, created_by,
+		       approved_by, approved_at, created_at, updated_at
+		FROM stocktakes
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(context.Background(), query, status, limit, offset)
+	if err != nil {
+		r.Logger.Error("error querying stocktakes", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var stocktakes []model.Stocktake
+	for rows.Next() {
+		var stocktake model.Stocktake
+		err := rows.Scan(
+			&stocktake.ID, &stocktake.WarehouseID, &stocktake.RackIDs, &stocktake.Status, &stocktake.FreezeSales,
+			&stocktake.ABCClasses, &stocktake.Note, &stocktake.CreatedBy, &stocktake.ApprovedBy, &stocktake.ApprovedAt,
+			&stocktake.CreatedAt, &stocktake.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning stocktake", zap.Error(err))
+			return nil, 0, err
+		}
+		stocktakes = append(stocktakes, stocktake)
+	}
+
+	return stocktakes, total, nil
+}
+
+func (r *stocktakeRepository) FindItems(stocktakeID int) ([]model.StocktakeItem, error) {
+	return r.findItems(r.db, stocktakeID)
+}
+
+// findItems loads the lines with the net ledger movement of each item and rack
+// between the snapshot and the count of the line (until now while uncounted),
+// stock the counter no longer finds or finds on top of the snapshot.
+func (r *stocktakeRepository) findItems(db database.PgxIface, stocktakeID int) ([]model.StocktakeItem, error) {
+	query := `
+		SELECT si.id, si.stocktake_id, si.item_id, i.sku, i.name, si.rack_id, si.expected_quantity,
+		       COALESCE((
+		           SELECT SUM(sm.quantity)
+		           FROM stock_movements sm
+		           WHERE sm.item_id = si.item_id AND sm.rack_id = si.rack_id
+		             AND sm.created_at >= s.created_at AND sm.created_at < COALESCE(si.counted_at, NOW())
+		       ), 0) AS moved_quantity,
+		       si.counted_quantity, si.counted_by, si.counted_at, si.excluded
+		FROM stocktake_items si
+		JOIN stocktakes s ON s.id = si.stocktake_id
+		JOIN items i ON i.id = si.item_id
+		WHERE si.stocktake_id = $1
+		ORDER BY si.rack_id ASC, i.sku ASC
+	`
+	rows, err := db.Query(context.Background(), query, stocktakeID)
+	if err != nil {
+		r.Logger.Error("error querying stocktake items", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.StocktakeItem
+	for rows.Next() {
+		var item model.StocktakeItem
+		err := rows.Scan(
+			&item.ID, &item.StocktakeID, &item.ItemID, &item.SKU, &item.ItemName, &item.RackID, &item.ExpectedQuantity,
+			&item.MovedQuantity, &item.CountedQuantity, &item.CountedBy, &item.CountedAt, &item.Excluded,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning stocktake item", zap.Error(err))
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// Count records counted quantities, a later count of the same line replaces the
+// earlier one. Items found in a rack without expected stock get a line of their own.
+func (r *stocktakeRepository) Count(id, userID int, counts []model.StocktakeItem) error {
+	// Begin transaction
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	// Lock the stocktake so counts can't land after approval
+	var status string
+	lockQuery := `SELECT status FROM stocktakes WHERE id = $1 FOR UPDATE`
+	err = tx.QueryRow(context.Background(), lockQuery, id).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return errors.New("stocktake not found")
+	}
+	if err != nil {
+		r.Logger.Error("error locking stocktake", zap.Error(err))
+		return err
+	}
+	if status != model.StocktakeStatusOpen {
+		return errors.New("stocktake is not open for counting")
+	}
+
+	query := `
+		INSERT INTO stocktake_items (stocktake_id, item_id, rack_id, expected_quantity,
+		                             counted_quantity, counted_by, counted_at)
+		VALUES ($1, $2, $3, 0, $4, $5, NOW())
+		ON CONFLICT (stocktake_id, item_id, rack_id) DO UPDATE
+		SET counted_quantity = EXCLUDED.counted_quantity, counted_by = EXCLUDED.counted_by, counted_at = NOW()
+	`
+	for _, count := range counts {
+		_, err = tx.Exec(context.Background(), query, id, count.ItemID, count.RackID, count.CountedQuantity, userID)
+		if err != nil {
+			r.Logger.Error("error recording stocktake count", zap.Error(err))
+			return err
+		}
+	}
+
+	// Commit transaction
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// Approve closes the count and posts every variance to the stock ledger in one
+// transaction. Expected is rebased by the stock moved in the rack between the
+// snapshot and the count, so those movements are neither undone nor posted
+// twice. Lines in excludeIDs are marked excluded and nothing is posted for them.
+func (r *stocktakeRepository) Approve(id, userID int, excludeIDs []int) error {
+	// Begin transaction
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	// Move status first, this also locks the stocktake row
+	var stocktakeID int
+	query := `
+		UPDATE stocktakes
+		SET status = $1, approved_by = $2, approved_at = NOW(), updated_at = NOW()
+		WHERE id = $3 AND status = $4
+		RETURNING id
+	`
+	err = tx.QueryRow(context.Background(), query,
+		model.StocktakeStatusApproved, userID, id, model.StocktakeStatusOpen,
+	).Scan(&stocktakeID)
+	if err == pgx.ErrNoRows {
+		return errors.New("only open stocktakes can be approved")
+	}
+	if err != nil {
+		r.Logger.Error("error approving stocktake", zap.Error(err))
+		return err
+	}
+
+	if len(excludeIDs) > 0 {
+		excludeQuery := `UPDATE stocktake_items SET excluded = TRUE WHERE stocktake_id = $1 AND id = ANY($2)`
+		_, err = tx.Exec(context.Background(), excludeQuery, id, excludeIDs)
+		if err != nil {
+			r.Logger.Error("error excluding stocktake items", zap.Error(err))
+			return err
+		}
+	}
+
+	items, err := r.findItems(tx, id)
+	if err != nil {
+		return err
+	}
+
+	referenceType := model.ReferenceTypeStocktake
+	for _, item := range items {
+		if item.Excluded {
+			continue
+		}
+		variance := item.Variance()
+		if variance == nil {
+			return ErrStocktakeUncounted
+		}
+		if *variance == 0 {
+			continue
+		}
+
+		movement := &model.StockMovement{
+			ItemID:        item.ItemID,
+			UserID:        userID,
+			RackID:        item.RackID,
+			MovementType:  model.MovementTypeStocktake,
+			Quantity:      *variance,
+			ReferenceType: &referenceType,
+			ReferenceID:   &stocktakeID,
+		}
+		if err := applyStockMovement(context.Background(), tx, movement); err != nil {
+			r.Logger.Error("error posting stocktake variance", zap.Error(err))
+			return err
+		}
+	}
+
+	// Commit transaction
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *stocktakeRepository) Cancel(id int) error {
+	query := `
+		UPDATE stocktakes
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+	`
+	result, err := r.db.Exec(context.Background(), query, model.StocktakeStatusCancelled, id, model.StocktakeStatusOpen)
+	if err != nil {
+		r.Logger.Error("error cancelling stocktake", zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("only open stocktakes can be cancelled")
+	}
+	return nil
+}
+
+// FindFrozenRacks returns the racks in which open stocktakes that freeze sales
+// count the item, it can still be sold from its other racks
+func (r *stocktakeRepository) FindFrozenRacks(itemID int) ([]int, error) {
+	query := `
+		SELECT COALESCE(array_agg(DISTINCT r.rack_id ORDER BY r.rack_id), '{}')
+		FROM stocktakes s
+		JOIN items i ON i.id = $1
+		CROSS JOIN LATERAL unnest(s.rack_ids) AS r(rack_id)
+		WHERE s.status = $2 AND s.freeze_sales
+		  AND (s.abc_classes IS NULL OR i.abc_class = ANY(s.abc_classes))
+	`
+	var rackIDs []int
+	err := r.db.QueryRow(context.Background(), query, itemID, model.StocktakeStatusOpen).Scan(&rackIDs)
+	if err != nil {
+		r.Logger.Error("error finding frozen racks", zap.Error(err))
+		return nil, err
+	}
+	return rackIDs, nil
+}
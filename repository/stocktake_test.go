@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStocktakeRepository_Create_WarehouseSnapshot(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewStocktakeRepository(mockDB, zap.NewNop())
+
+	warehouseID := 2
+	stocktake := &model.Stocktake{WarehouseID: &warehouseID, FreezeSales: true, CreatedBy: 1}
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM racks WHERE warehouse_id`).
+		WithArgs(2).
+		WillReturnRows(pgxmock.NewRows([]string{"rack_ids"}).AddRow([]int{3, 4}))
+	mockDB.
+		ExpectQuery(`SELECT EXISTS (.+) FROM stocktakes`).
+		WithArgs(model.StocktakeStatusOpen, []int{3, 4}, []string(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+	mockDB.
+		ExpectQuery(`INSERT INTO stocktakes`).
+		WithArgs(&warehouseID, []int{3, 4}, model.StocktakeStatusOpen, true, []string(nil), (*string)(nil), 1).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow(5, time.Now(), time.Now()))
+	mockDB.
+		ExpectExec(`INSERT INTO stocktake_items (.+) FROM item_locations`).
+		WithArgs(5, []int{3, 4}, []string(nil)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 12))
+	mockDB.ExpectCommit()
+
+	err = repo.Create(stocktake)
+	require.NoError(t, err)
+	require.Equal(t, 5, stocktake.ID)
+	require.Equal(t, []int{3, 4}, stocktake.RackIDs)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStocktakeRepository_Approve_PostsVariances(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewStocktakeRepository(mockDB, zap.NewNop())
+
+	counted := func(n int) *int { return &n }
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`UPDATE stocktakes`).
+		WithArgs(model.StocktakeStatusApproved, 1, 5, model.StocktakeStatusOpen).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(5))
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM stocktake_items si`).
+		WithArgs(5).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "stocktake_id", "item_id", "sku", "name", "rack_id", "expected_quantity",
+			"moved_quantity", "counted_quantity", "counted_by", "counted_at", "excluded"}).
+			AddRow(1, 5, 7, "MOU-001", "Mouse", 3, 10, 0, counted(8), counted(2), nil, false).
+			AddRow(2, 5, 8, "KEY-001", "Keyboard", 3, 4, 0, counted(4), counted(2), nil, false))
+	mockDB.
+		ExpectExec(`UPDATE item_locations`).
+		WithArgs(7, 3, -2).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockDB.
+		ExpectQuery(`UPDATE items`).
+		WithArgs(-2, 7).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(8))
+	mockDB.
+		ExpectQuery(`UPDATE cost_layers`).
+		WithArgs(7, 2, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"average_cost", "layered_quantity", "layered_cost"}).
+			AddRow(money.FromUnits(1000), 2, money.FromUnits(2000)))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(7, 1, 3, model.MovementTypeStocktake, -2, 8,
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	mockDB.ExpectCommit()
+
+	err = repo.Approve(5, 1, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStocktakeRepository_Approve_Uncounted(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewStocktakeRepository(mockDB, zap.NewNop())
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`UPDATE stocktakes`).
+		WithArgs(model.StocktakeStatusApproved, 1, 5, model.StocktakeStatusOpen).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(5))
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM stocktake_items si`).
+		WithArgs(5).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "stocktake_id", "item_id", "sku", "name", "rack_id", "expected_quantity",
+			"moved_quantity", "counted_quantity", "counted_by", "counted_at", "excluded"}).
+			AddRow(1, 5, 7, "MOU-001", "Mouse", 3, 10, 0, nil, nil, nil, false))
+	mockDB.ExpectRollback()
+
+	err = repo.Approve(5, 1, nil)
+	require.ErrorIs(t, err, ErrStocktakeUncounted)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+// TestStocktakeRepository_Approve_MovedAndExcluded tests stock moved since the
+// snapshot is not posted again and excluded lines post nothing
+func TestStocktakeRepository_Approve_MovedAndExcluded(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewStocktakeRepository(mockDB, zap.NewNop())
+
+	counted := func(n int) *int { return &n }
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`UPDATE stocktakes`).
+		WithArgs(model.StocktakeStatusApproved, 1, 5, model.StocktakeStatusOpen).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(5))
+	mockDB.
+		ExpectExec(`UPDATE stocktake_items SET excluded = TRUE`).
+		WithArgs(5, []int{2}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM stock_movements sm (.+) FROM stocktake_items si`).
+		WithArgs(5).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "stocktake_id", "item_id", "sku", "name", "rack_id", "expected_quantity",
+			"moved_quantity", "counted_quantity", "counted_by", "counted_at", "excluded"}).
+			AddRow(1, 5, 7, "MOU-001", "Mouse", 3, 10, -3, counted(7), counted(2), nil, false).
+			AddRow(2, 5, 8, "MED-001", "Medicine", 3, 4, 0, counted(1), counted(2), nil, true))
+	mockDB.ExpectCommit()
+
+	err = repo.Approve(5, 1, []int{2})
+	require.NoError(t, err)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestStocktakeRepository_FindFrozenRacks(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewStocktakeRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM stocktakes s (.+) unnest\(s.rack_ids\)`).
+		WithArgs(7, model.StocktakeStatusOpen).
+		WillReturnRows(pgxmock.NewRows([]string{"rack_ids"}).AddRow([]int{3, 4}))
+
+	rackIDs, err := repo.FindFrozenRacks(7)
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 4}, rackIDs)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type SupplierRepository interface {
+	Create(supplier *model.Supplier) error
+	FindByID(id int) (*model.Supplier, error)
+	FindByName(name string) (*model.Supplier, error)
+	FindAll(page, limit int) ([]model.Supplier, int, error)
+	Update(id int, data *model.Supplier) error
+	Delete(id int) error
+}
+
+type supplierRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewSupplierRepository(db database.PgxIface, log *zap.Logger) SupplierRepository {
+	return &supplierRepository{db: db, Logger: log}
+}
+
+func (r *supplierRepository) Create(supplier *model.Supplier) error {
+	query := `
+		INSERT INTO suppliers (name, contact_name, phone, email, address, lead_time_days, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRow(context.Background(), query,
+		supplier.Name, supplier.ContactName, supplier.Phone, supplier.Email, supplier.Address, supplier.LeadTimeDays,
+	).Scan(&supplier.ID, &supplier.CreatedAt, &supplier.UpdatedAt)
+
+	if err != nil {
+		r.Logger.Error("error creating supplier", zap.Error(err))
+	}
+	return err
+}
+
+func (r *supplierRepository) FindByID(id int) (*model.Supplier, error) {
+	query := `
+		SELECT id, name, contact_name, phone, email, address, lead_time_days, created_at, updated_at
+		FROM suppliers
+		WHERE id = $1
+	`
+	var supplier model.Supplier
+	err := r.db.QueryRow(context.Background(), query, id).Scan(
+		&supplier.ID, &supplier.Name, &supplier.ContactName, &supplier.Phone,
+		&supplier.Email, &supplier.Address, &supplier.LeadTimeDays, &supplier.CreatedAt, &supplier.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.Error("error finding supplier by id", zap.Error(err))
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+func (r *supplierRepository) FindByName(name string) (*model.Supplier, error) {
+	query := `
+		SELECT id, name, contact_name, phone, email, address, lead_time_days, created_at, updated_at
+		FROM suppliers
+		WHERE name = $1
+	`
+	var supplier model.Supplier
+	err := r.db.QueryRow(context.Background(), query, name).Scan(
+		&supplier.ID, &supplier.Name, &supplier.ContactName, &supplier.Phone,
+		&supplier.Email, &supplier.Address, &supplier.LeadTimeDays, &supplier.CreatedAt, &supplier.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.Error("error finding supplier by name", zap.Error(err))
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+func (r *supplierRepository) FindAll(page, limit int) ([]model.Supplier, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count
+	var total int
+	countQuery := `SELECT COUNT(*) FROM suppliers`
+	err := r.db.QueryRow(context.Background(), countQuery).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting suppliers", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := `
+		SELECT id, name, contact_name, phone, email, address, lead_time_days, created_at, updated_at
+		FROM suppliers
+		ORDER BY name ASC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.db.Query(context.Background(), query, limit, offset)
+	if err != nil {
+		r.Logger.Error("error querying suppliers", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var suppliers []model.Supplier
+	for rows.Next() {
+		var supplier model.Supplier
+		err := rows.Scan(
+			&supplier.ID, &supplier.Name, &supplier.ContactName, &supplier.Phone,
+			&supplier.Email, &supplier.Address, &supplier.LeadTimeDays, &supplier.CreatedAt, &supplier.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning supplier", zap.Error(err))
+			return nil, 0, err
+		}
+		suppliers = append(suppliers, supplier)
+	}
+
+	return suppliers, total, nil
+}
+
+func (r *supplierRepository) Update(id int, data *model.Supplier) error {
+	query := `
+		UPDATE suppliers
+		SET name = $1, contact_name = $2, phone = $3, email = $4, address = $5, lead_time_days = $6, updated_at = NOW()
+		WHERE id = $7
+	`
+	result, err := r.db.Exec(context.Background(), query,
+		data.Name, data.ContactName, data.Phone, data.Email, data.Address, data.LeadTimeDays, id,
+	)
+	if err != nil {
+		r.Logger.Error("error updating supplier", zap.Error(err))
+		return err
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.New("supplier not found")
+	}
+	return nil
+}
+
+func (r *supplierRepository) Delete(id int) error {
+	query := `
+		DELETE FROM suppliers
+		WHERE id = $1
+	`
+	result, err := r.db.Exec(context.Background(), query, id)
+	if err != nil {
+		r.Logger.Error("error deleting supplier", zap.Error(err))
+		return err
+	}
+
+	rowsAffected := result.RowsAffected()
+	if rowsAffected == 0 {
+		return errors.New("supplier not found")
+	}
+	return nil
+}
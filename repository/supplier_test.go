@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"project-app-inventory/model"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSupplierRepository_Create_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewSupplierRepository(mockDB, zap.NewNop())
+
+	phone := "021-5550101"
+	supplier := &model.Supplier{
+		Name:  "PT Sinar Elektronik",
+		Phone: &phone,
+	}
+
+	mockDB.
+		ExpectQuery(`INSERT INTO suppliers`).
+		WithArgs(supplier.Name, supplier.ContactName, supplier.Phone, supplier.Email, supplier.Address, supplier.LeadTimeDays).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at", "updated_at"}).
+			AddRow(3, time.Now(), time.Now()))
+
+	err = repo.Create(supplier)
+	require.NoError(t, err)
+	require.Equal(t, 3, supplier.ID)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestSupplierRepository_FindByID_NotFound(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewSupplierRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM suppliers WHERE id`).
+		WithArgs(99).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "contact_name", "phone", "email", "address", "lead_time_days", "created_at", "updated_at"}))
+
+	supplier, err := repo.FindByID(99)
+	require.NoError(t, err)
+	require.Nil(t, supplier)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestSupplierRepository_Delete_NotFound(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewSupplierRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectExec(`DELETE FROM suppliers`).
+		WithArgs(99).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	err = repo.Delete(99)
+	require.Error(t, err)
+	require.Equal(t, "supplier not found", err.Error())
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
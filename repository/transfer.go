@@ -0,0 +1,414 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"project-app-inventory/database"
+	"project-app-inventory/model"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type TransferRepository interface {
+	Create(transfer *model.Transfer, items []model.TransferItem) error
+	FindByID(id int) (*model.Transfer, error)
+	FindTransferItems(transferID int) ([]model.TransferItem, error)
+	FindAll(status string, page, limit int) ([]model.Transfer, int, error)
+	Dispatch(id int, userID int) error
+	Receive(id int, userID int) error
+	Cancel(id int) error
+}
+
+type transferRepository struct {
+	db     database.PgxIface
+	Logger *zap.Logger
+}
+
+func NewTransferRepository(db database.PgxIface, log *zap.Logger) TransferRepository {
+	return &transferRepository{db: db, Logger: log}
+}
+
+func (r *transferRepository) Create(transfer *model.Transfer, items []model.TransferItem) error {
+	// Begin transaction
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	// Insert transfer header
+	query := `
+		INSERT INTO stock_transfers (source_rack_id, destination_rack_id, status, note, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, created_at, updated_at
+	`
+	transfer.Status = model.TransferStatusDraft
+	err = tx.QueryRow(context.Background(), query,
+		transfer.SourceRackID, transfer.DestinationRackID, transfer.Status, transfer.Note, transfer.CreatedBy,
+	).Scan(&transfer.ID, &transfer.CreatedAt, &transfer.UpdatedAt)
+
+	if err != nil {
+		r.Logger.Error("error creating transfer", zap.Error(err))
+		return err
+	}
+
+	// Insert transfer lines
+	itemQuery := `
+		INSERT INTO stock_transfer_items (transfer_id, item_id, quantity, lot_number, serial_numbers)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	for i := range items {
+		items[i].TransferID = transfer.ID
+		err = tx.QueryRow(context.Background(), itemQuery,
+			items[i].TransferID, items[i].ItemID, items[i].Quantity, items[i].LotNumber, items[i].SerialNumbers,
+		).Scan(&items[i].ID)
+
+		if err != nil {
+			r.Logger.Error("error creating transfer item", zap.Error(err))
+			return err
+		}
+	}
+
+	// Commit transaction
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *transferRepository) FindByID(id int) (*model.Transfer, error) {
+	query := `
+		SELECT id, source_rack_id, destination_rack_id, status, note, created_by,
+		       dispatched_by, dispatched_at, received_by, received_at, created_at, updated_at
+		FROM stock_transfers
+		WHERE id = $1
+	`
+	var transfer model.Transfer
+	err := r.db.QueryRow(context.Background(), query, id).Scan(
+		&transfer.ID, &transfer.SourceRackID, &transfer.DestinationRackID, &transfer.Status,
+		&transfer.Note, &transfer.CreatedBy, &transfer.DispatchedBy, &transfer.DispatchedAt,
+		&transfer.ReceivedBy, &transfer.ReceivedAt, &transfer.CreatedAt, &transfer.UpdatedAt,
+	)
+
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		r.Logger.Error("error finding transfer by id", zap.Error(err))
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *transferRepository) FindTransferItems(transferID int) ([]model.TransferItem, error) {
+	return r.findTransferItems(r.db, transferID)
+}
+
+func (r *transferRepository) findTransferItems(db database.PgxIface, transferID int) ([]model.TransferItem, error) {
+	query := `
+		SELECT id, transfer_id, item_id, quantity, lot_number, serial_numbers
+		FROM stock_transfer_items
+		WHERE transfer_id = $1
+		ORDER BY id ASC
+	`
+	rows, err := db.Query(context.Background(), query, transferID)
+	if err != nil {
+		r.Logger.Error("error querying transfer items", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.TransferItem
+	for rows.Next() {
+		var item model.TransferItem
+		err := rows.Scan(&item.ID, &item.TransferID, &item.ItemID, &item.Quantity, &item.LotNumber, &item.SerialNumbers)
+		if err != nil {
+			r.Logger.Error("error scanning transfer item", zap.Error(err))
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (r *transferRepository) FindAll(status string, page, limit int) ([]model.Transfer, int, error) {
+	offset := (page - 1) * limit
+
+	// Get total count, empty status means all transfers
+	var total int
+	countQuery := `SELECT COUNT(*) FROM stock_transfers WHERE ($1 = '' OR status = $1)`
+	err := r.db.QueryRow(context.Background(), countQuery, status).Scan(&total)
+	if err != nil {
+		r.Logger.Error("error counting transfers", zap.Error(err))
+		return nil, 0, err
+	}
+
+	// Get data with pagination
+	query := `
+		SELECT id, source_rack_id, destination_rack_id, status, note, created_by,
+		       dispatched_by, dispatched_at, received_by, received_at, created_at, updated_at
+		FROM stock_transfers
+		WHERE ($1 = '' OR status = $1)
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Query(context.Background(), query, status, limit, offset)
+	if err != nil {
+		r.Logger.Error("error querying transfers", zap.Error(err))
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var transfers []model.Transfer
+	for rows.Next() {
+		var transfer model.Transfer
+		err := rows.Scan(
+			&transfer.ID, &transfer.SourceRackID, &transfer.DestinationRackID, &transfer.Status,
+			&transfer.Note, &transfer.CreatedBy, &transfer.DispatchedBy, &transfer.DispatchedAt,
+			&transfer.ReceivedBy, &transfer.ReceivedAt, &transfer.CreatedAt, &transfer.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning transfer", zap.Error(err))
+			return nil, 0, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, total, nil
+}
+
+// Dispatch takes the goods out of the source rack and puts the transfer in
+// transit. Lots are picked from what the rack holds now, and the units left
+// outside of transit must still cover the item's reservations.
+func (r *transferRepository) Dispatch(id int, userID int) error {
+	// Begin transaction
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	// Move status first, this also locks the transfer row
+	var rackID int
+	query := `
+		UPDATE stock_transfers
+		SET status = $1, dispatched_by = $2, dispatched_at = NOW(), updated_at = NOW()
+		WHERE id = $3 AND status = $4
+		RETURNING source_rack_id
+	`
+	err = tx.QueryRow(context.Background(), query,
+		model.TransferStatusInTransit, userID, id, model.TransferStatusDraft,
+	).Scan(&rackID)
+	if err == pgx.ErrNoRows {
+		return errors.New("only draft transfers can be dispatched")
+	}
+	if err != nil {
+		r.Logger.Error("error dispatching transfer", zap.Error(err))
+		return err
+	}
+
+	items, err := r.findTransferItems(tx, id)
+	if err != nil {
+		return err
+	}
+	items, err = r.pickTransferLots(tx, id, rackID, items)
+	if err != nil {
+		r.Logger.Error("error picking transfer lots", zap.Error(err))
+		return err
+	}
+
+	if err := r.moveTransferStock(tx, id, userID, rackID, items, model.MovementTypeTransferOut, -1); err != nil {
+		return err
+	}
+
+	itemIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ItemID)
+	}
+	if err := checkReservedStock(context.Background(), tx, itemIDs); err != nil {
+		r.Logger.Error("error checking reserved stock", zap.Error(err))
+		return err
+	}
+
+	// Commit transaction
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// Receive books the goods in transit into the destination rack
+func (r *transferRepository) Receive(id int, userID int) error {
+	// Begin transaction
+	tx, err := beginTx(r.db)
+	if err != nil {
+		r.Logger.Error("error beginning transaction", zap.Error(err))
+		return err
+	}
+	defer tx.Rollback(context.Background())
+
+	var rackID int
+	query := `
+		UPDATE stock_transfers
+		SET status = $1, received_by = $2, received_at = NOW(), updated_at = NOW()
+		WHERE id = $3 AND status = $4
+		RETURNING destination_rack_id
+	`
+	err = tx.QueryRow(context.Background(), query,
+		model.TransferStatusReceived, userID, id, model.TransferStatusInTransit,
+	).Scan(&rackID)
+	if err == pgx.ErrNoRows {
+		return errors.New("only transfers in transit can be received")
+	}
+	if err != nil {
+		r.Logger.Error("error receiving transfer", zap.Error(err))
+		return err
+	}
+
+	items, err := r.findTransferItems(tx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.moveTransferStock(tx, id, userID, rackID, items, model.MovementTypeTransferIn, 1); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	err = tx.Commit(context.Background())
+	if err != nil {
+		r.Logger.Error("error committing transaction", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (r *transferRepository) Cancel(id int) error {
+	query := `
+		UPDATE stock_transfers
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+	`
+	result, err := r.db.Exec(context.Background(), query, model.TransferStatusCancelled, id, model.TransferStatusDraft)
+	if err != nil {
+		r.Logger.Error("error cancelling transfer", zap.Error(err))
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New("only draft transfers can be cancelled")
+	}
+	return nil
+}
+
+// pickTransferLots replaces the line of a lot tracked item by a line per lot
+// of the rack, first-expiry-first-out. Expired lots move like any other. The
+// lots are locked until the stock moves, so a sale can't take them meanwhile.
+func (r *transferRepository) pickTransferLots(tx pgx.Tx, transferID, rackID int, items []model.TransferItem) ([]model.TransferItem, error) {
+	var lines []model.TransferItem
+	for _, item := range items {
+		if item.LotNumber != nil || len(item.SerialNumbers) > 0 {
+			lines = append(lines, item)
+			continue
+		}
+
+		var trackLots bool
+		err := tx.QueryRow(context.Background(), `SELECT track_lots FROM items WHERE id = $1`, item.ItemID).Scan(&trackLots)
+		if err != nil {
+			return nil, err
+		}
+		if !trackLots {
+			lines = append(lines, item)
+			continue
+		}
+
+		lotQuery := `
+			SELECT lot_number, quantity
+			FROM item_lots
+			WHERE item_id = $1 AND rack_id = $2 AND quantity > 0
+			ORDER BY expiry_date ASC NULLS LAST, id ASC
+			FOR UPDATE
+		`
+		rows, err := tx.Query(context.Background(), lotQuery, item.ItemID, rackID)
+		if err != nil {
+			return nil, err
+		}
+		var picked []model.TransferItem
+		remaining := item.Quantity
+		for rows.Next() && remaining > 0 {
+			var lotNumber string
+			var quantity int
+			if err := rows.Scan(&lotNumber, &quantity); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			take := min(quantity, remaining)
+			picked = append(picked, model.TransferItem{TransferID: transferID, ItemID: item.ItemID, Quantity: take, LotNumber: &lotNumber})
+			remaining -= take
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		if remaining > 0 {
+			return nil, ErrInsufficientLotStock
+		}
+
+		_, err = tx.Exec(context.Background(), `DELETE FROM stock_transfer_items WHERE id = $1`, item.ID)
+		if err != nil {
+			return nil, err
+		}
+		itemQuery := `
+			INSERT INTO stock_transfer_items (transfer_id, item_id, quantity, lot_number, serial_numbers)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id
+		`
+		for i := range picked {
+			err = tx.QueryRow(context.Background(), itemQuery,
+				picked[i].TransferID, picked[i].ItemID, picked[i].Quantity, picked[i].LotNumber, picked[i].SerialNumbers,
+			).Scan(&picked[i].ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+		lines = append(lines, picked...)
+	}
+
+	return lines, nil
+}
+
+// moveTransferStock applies every line of the transfer to rackID, sign is -1
+// when stock leaves the rack and 1 when it arrives
+func (r *transferRepository) moveTransferStock(tx pgx.Tx, transferID, userID, rackID int, items []model.TransferItem, movementType string, sign int) error {
+	referenceType := model.ReferenceTypeTransfer
+	for _, item := range items {
+		movement := &model.StockMovement{
+			ItemID:        item.ItemID,
+			UserID:        userID,
+			RackID:        rackID,
+			MovementType:  movementType,
+			Quantity:      sign * item.Quantity,
+			ReferenceType: &referenceType,
+			ReferenceID:   &transferID,
+			LotNumber:     item.LotNumber,
+			SerialNumbers: item.SerialNumbers,
+		}
+		if err := applyStockMovement(context.Background(), tx, movement); err != nil {
+			r.Logger.Error("error moving transfer stock", zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
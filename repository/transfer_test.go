@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"project-app-inventory/model"
+	"testing"
+	"time"
+
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTransferRepository_Dispatch_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewTransferRepository(mockDB, zap.NewNop())
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`UPDATE stock_transfers`).
+		WithArgs(model.TransferStatusInTransit, 2, 1, model.TransferStatusDraft).
+		WillReturnRows(pgxmock.NewRows([]string{"source_rack_id"}).AddRow(3))
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM stock_transfer_items`).
+		WithArgs(1).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "transfer_id", "item_id", "quantity", "lot_number", "serial_numbers"}).AddRow(1, 1, 7, 4, (*string)(nil), nil))
+	mockDB.
+		ExpectQuery(`SELECT track_lots FROM items`).
+		WithArgs(7).
+		WillReturnRows(pgxmock.NewRows([]string{"track_lots"}).AddRow(false))
+	mockDB.
+		ExpectExec(`UPDATE item_locations`).
+		WithArgs(7, 3, -4).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	// The units go in transit, the item's stock stays the same
+	mockDB.
+		ExpectQuery(`UPDATE items SET in_transit = in_transit - \$1`).
+		WithArgs(-4, 7).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(10))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(7, 2, 3, model.MovementTypeTransferOut, -4, 10,
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	mockDB.
+		ExpectQuery(`SELECT stock - in_transit - COALESCE`).
+		WithArgs(7).
+		WillReturnRows(pgxmock.NewRows([]string{"available"}).AddRow(6))
+	mockDB.ExpectCommit()
+
+	err = repo.Dispatch(1, 2)
+	require.NoError(t, err)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestTransferRepository_Dispatch_PicksLots(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewTransferRepository(mockDB, zap.NewNop())
+
+	lotA, lotB := "LOT-A", "LOT-B"
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`UPDATE stock_transfers`).
+		WithArgs(model.TransferStatusInTransit, 2, 1, model.TransferStatusDraft).
+		WillReturnRows(pgxmock.NewRows([]string{"source_rack_id"}).AddRow(3))
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM stock_transfer_items`).
+		WithArgs(1).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "transfer_id", "item_id", "quantity", "lot_number", "serial_numbers"}).AddRow(1, 1, 7, 4, (*string)(nil), nil))
+	mockDB.
+		ExpectQuery(`SELECT track_lots FROM items`).
+		WithArgs(7).
+		WillReturnRows(pgxmock.NewRows([]string{"track_lots"}).AddRow(true))
+	// Lots of the rack as they are at dispatch, first expiry first
+	mockDB.
+		ExpectQuery(`SELECT lot_number, quantity FROM item_lots (.+) FOR UPDATE`).
+		WithArgs(7, 3).
+		WillReturnRows(pgxmock.NewRows([]string{"lot_number", "quantity"}).AddRow("LOT-A", 3).AddRow("LOT-B", 2))
+	mockDB.
+		ExpectExec(`DELETE FROM stock_transfer_items`).
+		WithArgs(1).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_transfer_items`).
+		WithArgs(1, 7, 3, &lotA, []string(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(2))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_transfer_items`).
+		WithArgs(1, 7, 1, &lotB, []string(nil)).
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(3))
+	for _, line := range []struct {
+		lotNumber *string
+		quantity  int
+	}{{&lotA, -3}, {&lotB, -1}} {
+		mockDB.
+			ExpectExec(`UPDATE item_locations`).
+			WithArgs(7, 3, line.quantity).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mockDB.
+			ExpectQuery(`UPDATE items SET in_transit`).
+			WithArgs(line.quantity, 7).
+			WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(10))
+		mockDB.
+			ExpectExec(`UPDATE item_lots`).
+			WithArgs(7, 3, *line.lotNumber, line.quantity).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mockDB.
+			ExpectQuery(`INSERT INTO stock_movements`).
+			WithArgs(7, 2, 3, model.MovementTypeTransferOut, line.quantity, 10,
+				pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), line.lotNumber).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	}
+	mockDB.
+		ExpectQuery(`SELECT stock - in_transit - COALESCE`).
+		WithArgs(7).
+		WillReturnRows(pgxmock.NewRows([]string{"available"}).AddRow(6))
+	mockDB.ExpectCommit()
+
+	err = repo.Dispatch(1, 2)
+	require.NoError(t, err)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestTransferRepository_Dispatch_Reserved(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewTransferRepository(mockDB, zap.NewNop())
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`UPDATE stock_transfers`).
+		WithArgs(model.TransferStatusInTransit, 2, 1, model.TransferStatusDraft).
+		WillReturnRows(pgxmock.NewRows([]string{"source_rack_id"}).AddRow(3))
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM stock_transfer_items`).
+		WithArgs(1).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "transfer_id", "item_id", "quantity", "lot_number", "serial_numbers"}).AddRow(1, 1, 7, 4, (*string)(nil), nil))
+	mockDB.
+		ExpectQuery(`SELECT track_lots FROM items`).
+		WithArgs(7).
+		WillReturnRows(pgxmock.NewRows([]string{"track_lots"}).AddRow(false))
+	mockDB.
+		ExpectExec(`UPDATE item_locations`).
+		WithArgs(7, 3, -4).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockDB.
+		ExpectQuery(`UPDATE items SET in_transit`).
+		WithArgs(-4, 7).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(10))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(7, 2, 3, model.MovementTypeTransferOut, -4, 10,
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	// 8 of the 10 units are reserved, only 6 stay outside of transit
+	mockDB.
+		ExpectQuery(`SELECT stock - in_transit - COALESCE`).
+		WithArgs(7).
+		WillReturnRows(pgxmock.NewRows([]string{"available"}).AddRow(-2))
+	mockDB.ExpectRollback()
+
+	err = repo.Dispatch(1, 2)
+	require.ErrorIs(t, err, ErrInsufficientAvailable)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestTransferRepository_Dispatch_InsufficientStock(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewTransferRepository(mockDB, zap.NewNop())
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`UPDATE stock_transfers`).
+		WithArgs(model.TransferStatusInTransit, 2, 1, model.TransferStatusDraft).
+		WillReturnRows(pgxmock.NewRows([]string{"source_rack_id"}).AddRow(3))
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM stock_transfer_items`).
+		WithArgs(1).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "transfer_id", "item_id", "quantity", "lot_number", "serial_numbers"}).AddRow(1, 1, 7, 40, (*string)(nil), nil))
+	mockDB.
+		ExpectQuery(`SELECT track_lots FROM items`).
+		WithArgs(7).
+		WillReturnRows(pgxmock.NewRows([]string{"track_lots"}).AddRow(false))
+	mockDB.
+		ExpectExec(`UPDATE item_locations`).
+		WithArgs(7, 3, -40).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mockDB.ExpectRollback()
+
+	err = repo.Dispatch(1, 2)
+	require.ErrorIs(t, err, ErrInsufficientStock)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestTransferRepository_Receive_NotInTransit(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewTransferRepository(mockDB, zap.NewNop())
+
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`UPDATE stock_transfers`).
+		WithArgs(model.TransferStatusReceived, 2, 1, model.TransferStatusInTransit).
+		WillReturnRows(pgxmock.NewRows([]string{"destination_rack_id"}))
+	mockDB.ExpectRollback()
+
+	err = repo.Receive(1, 2)
+	require.Error(t, err)
+	require.Equal(t, "only transfers in transit can be received", err.Error())
+}
+
+func TestTransferRepository_Receive_LotIntoRack(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewTransferRepository(mockDB, zap.NewNop())
+
+	lotNumber := "LOT-A"
+	mockDB.ExpectBegin()
+	mockDB.
+		ExpectQuery(`UPDATE stock_transfers`).
+		WithArgs(model.TransferStatusReceived, 2, 1, model.TransferStatusInTransit).
+		WillReturnRows(pgxmock.NewRows([]string{"destination_rack_id"}).AddRow(5))
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM stock_transfer_items`).
+		WithArgs(1).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "transfer_id", "item_id", "quantity", "lot_number", "serial_numbers"}).AddRow(1, 1, 7, 4, &lotNumber, nil))
+	mockDB.
+		ExpectExec(`INSERT INTO item_locations`).
+		WithArgs(7, 5, 4).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockDB.
+		ExpectQuery(`UPDATE items`).
+		WithArgs(4, 7).
+		WillReturnRows(pgxmock.NewRows([]string{"stock"}).AddRow(10))
+	// The lot is opened in the destination rack with the expiry it has elsewhere
+	mockDB.
+		ExpectExec(`INSERT INTO item_lots (.+) SELECT (.+) MIN\(expiry_date\)(.+) ON CONFLICT \(item_id, rack_id, lot_number\)`).
+		WithArgs(7, 5, "LOT-A", 4).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockDB.
+		ExpectQuery(`INSERT INTO stock_movements`).
+		WithArgs(7, 2, 5, model.MovementTypeTransferIn, 4, 10,
+			pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), &lotNumber).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+	mockDB.ExpectCommit()
+
+	err = repo.Receive(1, 2)
+	require.NoError(t, err)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestTransferRepository_Cancel_NotDraft(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewTransferRepository(mockDB, zap.NewNop())
+
+	mockDB.
+		ExpectExec(`UPDATE stock_transfers`).
+		WithArgs(model.TransferStatusCancelled, 1, model.TransferStatusDraft).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	err = repo.Cancel(1)
+	require.Error(t, err)
+
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
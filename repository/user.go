@@ -15,6 +15,7 @@ type UserRepository interface {
 	FindByEmail(email string) (*model.User, error)
 	FindByID(id int) (*model.User, error)
 	FindAll(page, limit int) ([]model.User, int, error)
+	FindAfter(afterID, limit int) ([]model.User, error)
 	Update(id int, data *model.User) error
 	Delete(id int) error
 	FindAllStudents() ([]model.User, error)
@@ -143,6 +144,46 @@ func (r *userRepositoryImpl) FindAll(page, limit int) ([]model.User, int, error)
 	return users, total, nil
 }
 
+// FindAfter reads up to limit users with an id above afterID in id order. Exports
+// page with it, a page can't skip or repeat users added or removed meanwhile.
+func (r *userRepositoryImpl) FindAfter(afterID, limit int) ([]model.User, error) {
+	query := `
+		SELECT u.id, u.name, u.email, u.password_hash, u.role_id, r.name as role_name, u.is_active, u.created_at, u.updated_at
+		FROM users u
+		LEFT JOIN roles r ON u.role_id = r.id
+		WHERE u.id > $1
+		ORDER BY u.id ASC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(context.Background(), query, afterID, limit)
+	if err != nil {
+		if r.Logger != nil {
+			r.Logger.Error("error querying users after id", zap.Error(err))
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		var user model.User
+		err := rows.Scan(
+			&user.ID, &user.Name, &user.Email, &user.PasswordHash,
+			&user.RoleID, &user.RoleName, &user.IsActive,
+			&user.CreatedAt, &user.UpdatedAt,
+		)
+		if err != nil {
+			if r.Logger != nil {
+				r.Logger.Error("error scanning user", zap.Error(err))
+			}
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
 func (r *userRepositoryImpl) Update(id int, data *model.User) error {
 	query := `
 		UPDATE users
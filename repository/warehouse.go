@@ -15,6 +15,7 @@ type WarehouseRepository interface {
 	FindByID(id int) (*model.Warehouse, error)
 	FindByName(name string) (*model.Warehouse, error)
 	FindAll(page, limit int) ([]model.Warehouse, int, error)
+	FindAfter(afterID, limit int) ([]model.Warehouse, error)
 	Update(id int, data *model.Warehouse) error
 	Delete(id int) error
 }
@@ -131,6 +132,40 @@ func (r *warehouseRepository) FindAll(page, limit int) ([]model.Warehouse, int,
 	return warehouses, total, nil
 }
 
+// FindAfter reads up to limit warehouses with an id above afterID in id order. Exports
+// page with it, a page can't skip or repeat warehouses added or removed meanwhile.
+func (r *warehouseRepository) FindAfter(afterID, limit int) ([]model.Warehouse, error) {
+	query := `
+		SELECT id, name, location, created_at, updated_at
+		FROM warehouses
+		WHERE id > $1
+		ORDER BY id ASC
+		LIMIT $2
+	`
+	rows, err := r.db.Query(context.Background(), query, afterID, limit)
+	if err != nil {
+		r.Logger.Error("error querying warehouses after id", zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warehouses []model.Warehouse
+	for rows.Next() {
+		var warehouse model.Warehouse
+		err := rows.Scan(
+			&warehouse.ID, &warehouse.Name, &warehouse.Location,
+			&warehouse.CreatedAt, &warehouse.UpdatedAt,
+		)
+		if err != nil {
+			r.Logger.Error("error scanning warehouse", zap.Error(err))
+			return nil, err
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+
+	return warehouses, nil
+}
+
 func (r *warehouseRepository) Update(id int, data *model.Warehouse) error {
 	query := `
 		UPDATE warehouses
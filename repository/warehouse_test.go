@@ -238,6 +238,33 @@ func TestWarehouseRepository_FindAll_Success(t *testing.T) {
 	require.NoError(t, mockDB.ExpectationsWereMet())
 }
 
+// TestWarehouseRepository_FindAfter_Success tests reading the warehouses after an id
+func TestWarehouseRepository_FindAfter_Success(t *testing.T) {
+	mockDB, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	repo := NewWarehouseRepository(mockDB, zap.NewNop())
+
+	rows := pgxmock.NewRows([]string{
+		"id", "name", "location", "created_at", "updated_at",
+	}).
+		AddRow(6, "Warehouse 6", "Jakarta", time.Now(), time.Now()).
+		AddRow(9, "Warehouse 9", "Bandung", time.Now(), time.Now())
+
+	mockDB.
+		ExpectQuery(`SELECT (.+) FROM warehouses WHERE id > \$1 ORDER BY id ASC LIMIT \$2`).
+		WithArgs(5, 500).
+		WillReturnRows(rows)
+
+	warehouses, err := repo.FindAfter(5, 500)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, len(warehouses))
+	require.Equal(t, 9, warehouses[1].ID)
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
 // TestWarehouseRepository_FindAll_Error tests error handling
 func TestWarehouseRepository_FindAll_Error(t *testing.T) {
 	mockDB, err := pgxmock.NewPool()
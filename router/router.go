@@ -5,17 +5,18 @@ import (
 	"project-app-inventory/handler"
 	mCostume "project-app-inventory/middleware"
 	"project-app-inventory/service"
+	"project-app-inventory/utils"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"go.uber.org/zap"
 )
 
-func NewRouter(handler handler.Handler, service service.Service, log *zap.Logger) *chi.Mux {
+func NewRouter(handler handler.Handler, service service.Service, log *zap.Logger, config utils.Configuration) *chi.Mux {
 	r := chi.NewRouter()
 
 	// middleware
-	mw := mCostume.NewMiddlewareCustome(service, log)
+	mw := mCostume.NewMiddlewareCustome(service, log, config)
 
 	r.Mount("/api/v1", Apiv1(handler, mw))
 	r.Mount("/api/v2", Apiv2(handler))
@@ -38,33 +39,52 @@ func Apiv1(handler handler.Handler, mw mCostume.MiddlewareCostume) *chi.Mux {
 	r.Group(func(r chi.Router) {
 		r.Use(mw.AuthMiddleware)
 
+		// Mutating requests sent with an Idempotency-Key header run only once
+		r.Use(mw.Idempotency)
+
 		// Logout endpoint
 		r.Post("/logout", handler.HandlerAuth.Logout)
 
 		// Items routes - CRUD for inventory items
 		r.Route("/items", func(r chi.Router) {
-			// All authenticated users can read items and check low stock
+			// All authenticated users can read items and check low stock and expiring lots
 			r.Get("/", handler.ItemHandler.List)
 			r.Get("/low-stock", handler.ItemHandler.GetLowStock)
+			r.Get("/reorder-suggestions", handler.ItemHandler.GetReorderSuggestions)
+			r.Get("/forecast", handler.ForecastHandler.List)
+			r.Get("/expiring", handler.ItemLotHandler.ListExpiring)
 
 			// Only super_admin and admin can create, update, delete
 			r.Group(func(r chi.Router) {
 				r.Use(mw.RoleMiddleware("super_admin", "admin"))
 				r.Post("/", handler.ItemHandler.Create)
+				r.Post("/import", handler.ItemHandler.Import)
+				r.Post("/reorder-suggestions/accept", handler.ItemHandler.AcceptReorderSuggestions)
 			})
 
 			r.Route("/{item_id}", func(r chi.Router) {
 				r.Get("/", handler.ItemHandler.GetByID)
+				r.Get("/locations", handler.ItemLocationHandler.ListByItem)
+				r.Get("/lots", handler.ItemLotHandler.ListByItem)
+				r.Get("/serials", handler.ItemSerialHandler.ListByItem)
+				r.Get("/forecast", handler.ForecastHandler.GetByItem)
 
 				// Only super_admin and admin can update and delete
 				r.Group(func(r chi.Router) {
 					r.Use(mw.RoleMiddleware("super_admin", "admin"))
 					r.Put("/", handler.ItemHandler.Update)
 					r.Delete("/", handler.ItemHandler.Delete)
+
+					// Stock ledger of the item, stock only changes through adjustments
+					r.Get("/movements", handler.StockMovementHandler.ListByItem)
+					r.Post("/adjustments", handler.StockMovementHandler.Adjust)
 				})
 			})
 		})
 
+		// Serial lookup - All authenticated users can trace a serial number
+		r.Get("/serials/{serial}", handler.ItemSerialHandler.GetBySerial)
+
 		// Categories routes - CRUD for item categories
 		r.Route("/categories", func(r chi.Router) {
 			// All authenticated users can read
@@ -101,6 +121,7 @@ func Apiv1(handler handler.Handler, mw mCostume.MiddlewareCostume) *chi.Mux {
 
 			r.Route("/{rack_id}", func(r chi.Router) {
 				r.Get("/", handler.RackHandler.GetByID)
+				r.Get("/stock", handler.ItemLocationHandler.ListByRack)
 
 				// Only super_admin and admin can update and delete
 				r.Group(func(r chi.Router) {
@@ -124,6 +145,7 @@ func Apiv1(handler handler.Handler, mw mCostume.MiddlewareCostume) *chi.Mux {
 
 			r.Route("/{warehouse_id}", func(r chi.Router) {
 				r.Get("/", handler.WarehouseHandler.GetByID)
+				r.Get("/stock", handler.ItemLocationHandler.ListByWarehouse)
 
 				// Only super_admin and admin can update and delete
 				r.Group(func(r chi.Router) {
@@ -134,18 +156,158 @@ func Apiv1(handler handler.Handler, mw mCostume.MiddlewareCostume) *chi.Mux {
 			})
 		})
 
+		// Reservations routes - All authenticated users can hold stock for customers
+		r.Route("/reservations", func(r chi.Router) {
+			r.Get("/", handler.ReservationHandler.List)
+			r.Post("/", handler.ReservationHandler.Create)
+			r.Route("/{reservation_id}", func(r chi.Router) {
+				r.Get("/", handler.ReservationHandler.GetByID)
+				r.Post("/extend", handler.ReservationHandler.Extend)
+				r.Post("/release", handler.ReservationHandler.Release)
+				r.Post("/convert", handler.ReservationHandler.Convert)
+			})
+		})
+
 		// Sales routes - All authenticated users can create and read
 		r.Route("/sales", func(r chi.Router) {
 			r.Get("/", handler.SaleHandler.List)
 			r.Post("/", handler.SaleHandler.Create)
 			r.Route("/{sale_id}", func(r chi.Router) {
 				r.Get("/", handler.SaleHandler.GetByID)
+				r.Get("/returns", handler.SaleReturnHandler.List)
 
-				// Only super_admin and admin can update and delete sales
+				// Only super_admin and admin can update and delete sales and refund returns
 				r.Group(func(r chi.Router) {
 					r.Use(mw.RoleMiddleware("super_admin", "admin"))
 					r.Put("/", handler.SaleHandler.Update)
 					r.Delete("/", handler.SaleHandler.Delete)
+					r.Post("/returns", handler.SaleReturnHandler.Create)
+				})
+			})
+		})
+
+		// Customers routes - All authenticated users can register and look up customers at the counter
+		r.Route("/customers", func(r chi.Router) {
+			r.Get("/", handler.CustomerHandler.List)
+			r.Post("/", handler.CustomerHandler.Create)
+			r.Route("/{customer_id}", func(r chi.Router) {
+				r.Get("/", handler.CustomerHandler.GetByID)
+
+				// Only super_admin and admin can see revenue, update and delete
+				r.Group(func(r chi.Router) {
+					r.Use(mw.RoleMiddleware("super_admin", "admin"))
+					r.Get("/sales", handler.CustomerHandler.ListSales)
+					r.Put("/", handler.CustomerHandler.Update)
+					r.Delete("/", handler.CustomerHandler.Delete)
+				})
+			})
+		})
+
+		// Transfers routes - move stock between racks and warehouses
+		r.Route("/transfers", func(r chi.Router) {
+			// All authenticated users can read
+			r.Get("/", handler.TransferHandler.List)
+
+			// Only super_admin and admin can create
+			r.Group(func(r chi.Router) {
+				r.Use(mw.RoleMiddleware("super_admin", "admin"))
+				r.Post("/", handler.TransferHandler.Create)
+			})
+
+			r.Route("/{transfer_id}", func(r chi.Router) {
+				r.Get("/", handler.TransferHandler.GetByID)
+
+				// Only super_admin and admin can move a transfer through its lifecycle
+				r.Group(func(r chi.Router) {
+					r.Use(mw.RoleMiddleware("super_admin", "admin"))
+					r.Post("/dispatch", handler.TransferHandler.Dispatch)
+					r.Post("/receive", handler.TransferHandler.Receive)
+					r.Post("/cancel", handler.TransferHandler.Cancel)
+				})
+			})
+		})
+
+		// Stocktakes routes - physical counts of a warehouse or a set of racks
+		r.Route("/stocktakes", func(r chi.Router) {
+			// All authenticated users can read
+			r.Get("/", handler.StocktakeHandler.List)
+
+			// Only super_admin and admin can open a count
+			r.Group(func(r chi.Router) {
+				r.Use(mw.RoleMiddleware("super_admin", "admin"))
+				r.Post("/", handler.StocktakeHandler.Create)
+			})
+
+			r.Route("/{stocktake_id}", func(r chi.Router) {
+				r.Get("/", handler.StocktakeHandler.GetByID)
+
+				// All authenticated users can submit counted quantities
+				r.Put("/counts", handler.StocktakeHandler.SubmitCounts)
+
+				// Only super_admin and admin can approve or cancel a count
+				r.Group(func(r chi.Router) {
+					r.Use(mw.RoleMiddleware("super_admin", "admin"))
+					r.Post("/approve", handler.StocktakeHandler.Approve)
+					r.Post("/cancel", handler.StocktakeHandler.Cancel)
+				})
+			})
+		})
+
+		// Suppliers routes - CRUD for suppliers
+		r.Route("/suppliers", func(r chi.Router) {
+			// All authenticated users can read
+			r.Get("/", handler.SupplierHandler.List)
+
+			// Only super_admin and admin can create
+			r.Group(func(r chi.Router) {
+				r.Use(mw.RoleMiddleware("super_admin", "admin"))
+				r.Post("/", handler.SupplierHandler.Create)
+			})
+
+			r.Route("/{supplier_id}", func(r chi.Router) {
+				r.Get("/", handler.SupplierHandler.GetByID)
+
+				// Only super_admin and admin can update and delete
+				r.Group(func(r chi.Router) {
+					r.Use(mw.RoleMiddleware("super_admin", "admin"))
+					r.Put("/", handler.SupplierHandler.Update)
+					r.Delete("/", handler.SupplierHandler.Delete)
+				})
+			})
+		})
+
+		// Purchase orders routes - Only super_admin and admin handle purchasing
+		r.Route("/purchase-orders", func(r chi.Router) {
+			r.Use(mw.RoleMiddleware("super_admin", "admin"))
+			r.Get("/", handler.PurchaseOrderHandler.List)
+			r.Post("/", handler.PurchaseOrderHandler.Create)
+			r.Post("/from-low-stock", handler.PurchaseOrderHandler.CreateFromLowStock)
+			r.Route("/{purchase_order_id}", func(r chi.Router) {
+				r.Get("/", handler.PurchaseOrderHandler.GetByID)
+				r.Put("/", handler.PurchaseOrderHandler.Update)
+				r.Post("/approve", handler.PurchaseOrderHandler.Approve)
+				r.Post("/cancel", handler.PurchaseOrderHandler.Cancel)
+			})
+		})
+
+		// Receipts routes - goods received notes that put delivered stock into racks
+		r.Route("/receipts", func(r chi.Router) {
+			// All authenticated users can read
+			r.Get("/", handler.ReceiptHandler.List)
+
+			// Only super_admin and admin can book receipts
+			r.Group(func(r chi.Router) {
+				r.Use(mw.RoleMiddleware("super_admin", "admin"))
+				r.Post("/", handler.ReceiptHandler.Create)
+			})
+
+			r.Route("/{receipt_id}", func(r chi.Router) {
+				r.Get("/", handler.ReceiptHandler.GetByID)
+
+				// Only super_admin and admin can void receipts
+				r.Group(func(r chi.Router) {
+					r.Use(mw.RoleMiddleware("super_admin", "admin"))
+					r.Post("/void", handler.ReceiptHandler.Void)
 				})
 			})
 		})
@@ -166,6 +328,14 @@ func Apiv1(handler handler.Handler, mw mCostume.MiddlewareCostume) *chi.Mux {
 		r.Route("/reports", func(r chi.Router) {
 			r.Use(mw.RoleMiddleware("super_admin", "admin"))
 			r.Get("/summary", handler.ReportHandler.GetSummary)
+			r.Get("/gross-margin", handler.ReportHandler.GetGrossMargin)
+			r.Get("/inventory-valuation", handler.ReportHandler.GetInventoryValuation)
+			r.Get("/sales", handler.ReportHandler.GetSalesByPeriod)
+			r.Get("/top-sellers", handler.ReportHandler.GetTopSellers)
+			r.Get("/slow-movers", handler.ReportHandler.GetSlowMovers)
+			r.Get("/dead-stock", handler.ReportHandler.GetDeadStock)
+			r.Get("/abc-classification", handler.ReportHandler.GetABCClassification)
+			r.Post("/abc-classification", handler.ReportHandler.ApplyABCClassification)
 		})
 
 		// Assignment routes (example - will be replaced with inventory routes later)
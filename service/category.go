@@ -4,6 +4,7 @@ import (
 	"errors"
 	"project-app-inventory/dto"
 	"project-app-inventory/model"
+	"project-app-inventory/money"
 	"project-app-inventory/repository"
 	"project-app-inventory/utils"
 )
@@ -11,6 +12,7 @@ import (
 type CategoryService interface {
 	Create(category *model.Category) error
 	GetAllCategories(page, limit int) (*[]model.Category, *dto.Pagination, error)
+	GetCategoriesAfter(afterID, limit int) ([]model.Category, error)
 	GetCategoryByID(id int) (*model.Category, error)
 	Update(id int, data *model.Category) error
 	Delete(id int) error
@@ -25,6 +27,14 @@ func NewCategoryService(repo repository.Repository) CategoryService {
 }
 
 func (s *categoryService) Create(category *model.Category) error {
+	if err := checkTaxRate(category.TaxRate); err != nil {
+		return err
+	}
+	if category.TaxRate == nil {
+		var noTax money.Rate
+		category.TaxRate = &noTax
+	}
+
 	// Check if name already exists
 	existingCategory, err := s.Repo.CategoryRepo.FindByName(category.Name)
 	if err != nil {
@@ -52,6 +62,11 @@ func (s *categoryService) GetAllCategories(page, limit int) (*[]model.Category,
 	return &categories, &pagination, nil
 }
 
+// GetCategoriesAfter reads the categories after afterID in id order, a page of an export
+func (s *categoryService) GetCategoriesAfter(afterID, limit int) ([]model.Category, error) {
+	return s.Repo.CategoryRepo.FindAfter(afterID, limit)
+}
+
 func (s *categoryService) GetCategoryByID(id int) (*model.Category, error) {
 	category, err := s.Repo.CategoryRepo.FindByID(id)
 	if err != nil {
@@ -83,6 +98,14 @@ func (s *categoryService) Update(id int, data *model.Category) error {
 		data.Description = existingCategory.Description
 	}
 
+	// If tax rate is nil, keep existing tax rate
+	if data.TaxRate == nil {
+		data.TaxRate = existingCategory.TaxRate
+	}
+	if err := checkTaxRate(data.TaxRate); err != nil {
+		return err
+	}
+
 	// Check if name is being changed and if new name already exists
 	if data.Name != existingCategory.Name {
 		nameExists, err := s.Repo.CategoryRepo.FindByName(data.Name)
@@ -109,3 +132,11 @@ func (s *categoryService) Delete(id int) error {
 
 	return s.Repo.CategoryRepo.Delete(id)
 }
+
+// checkTaxRate accepts an empty rate or a percentage between 0 and 100
+func checkTaxRate(rate *money.Rate) error {
+	if rate != nil && (*rate < 0 || *rate > money.MaxRate) {
+		return errors.New("tax rate must be between 0 and 100")
+	}
+	return nil
+}
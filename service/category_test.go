@@ -41,6 +41,11 @@ func (m *MockCategoryRepository) FindAll(page, limit int) ([]model.Category, int
 	return args.Get(0).([]model.Category), args.Int(1), args.Error(2)
 }
 
+func (m *MockCategoryRepository) FindAfter(afterID, limit int) ([]model.Category, error) {
+	args := m.Called(afterID, limit)
+	return args.Get(0).([]model.Category), args.Error(1)
+}
+
 func (m *MockCategoryRepository) Update(id int, category *model.Category) error {
 	args := m.Called(id, category)
 	return args.Error(0)
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+)
+
+type CustomerService interface {
+	Create(customer *model.Customer) error
+	GetAllCustomers(page, limit int) (*[]model.Customer, *dto.Pagination, error)
+	GetCustomerByID(id int) (*model.Customer, error)
+	GetCustomerSales(id, page, limit int) (*model.Customer, *model.CustomerSalesSummary, []model.Sale, *dto.Pagination, error)
+	Update(id int, data *model.Customer) error
+	Delete(id int) error
+}
+
+type customerService struct {
+	Repo repository.Repository
+}
+
+func NewCustomerService(repo repository.Repository) CustomerService {
+	return &customerService{Repo: repo}
+}
+
+func (s *customerService) Create(customer *model.Customer) error {
+	// Check if email already exists, customers without email are allowed
+	if customer.Email != nil {
+		existingCustomer, err := s.Repo.CustomerRepo.FindByEmail(*customer.Email)
+		if err != nil {
+			return errors.New("failed to check customer email")
+		}
+		if existingCustomer != nil {
+			return errors.New("customer email already exists")
+		}
+	}
+
+	return s.Repo.CustomerRepo.Create(customer)
+}
+
+func (s *customerService) GetAllCustomers(page, limit int) (*[]model.Customer, *dto.Pagination, error) {
+	customers, total, err := s.Repo.CustomerRepo.FindAll(page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return &customers, &pagination, nil
+}
+
+func (s *customerService) GetCustomerByID(id int) (*model.Customer, error) {
+	customer, err := s.Repo.CustomerRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, errors.New("customer not found")
+	}
+	return customer, nil
+}
+
+// GetCustomerSales returns a page of the customer's sales together with the
+// lifetime summary over all of them
+func (s *customerService) GetCustomerSales(id, page, limit int) (*model.Customer, *model.CustomerSalesSummary, []model.Sale, *dto.Pagination, error) {
+	customer, err := s.GetCustomerByID(id)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	summary, err := s.Repo.CustomerRepo.GetSalesSummary(id)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	sales, total, err := s.Repo.SaleRepo.FindByCustomerID(id, page, limit)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return customer, summary, sales, &pagination, nil
+}
+
+func (s *customerService) Update(id int, data *model.Customer) error {
+	// Check if customer exists
+	existingCustomer, err := s.Repo.CustomerRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if existingCustomer == nil {
+		return errors.New("customer not found")
+	}
+
+	// Empty fields keep their existing values
+	if data.Name == "" {
+		data.Name = existingCustomer.Name
+	}
+	if data.Email == nil {
+		data.Email = existingCustomer.Email
+	}
+	if data.Phone == nil {
+		data.Phone = existingCustomer.Phone
+	}
+	if data.Address == nil {
+		data.Address = existingCustomer.Address
+	}
+
+	// Check if email is being changed and if new email already exists
+	if data.Email != nil && (existingCustomer.Email == nil || *data.Email != *existingCustomer.Email) {
+		emailExists, err := s.Repo.CustomerRepo.FindByEmail(*data.Email)
+		if err != nil {
+			return errors.New("failed to check customer email")
+		}
+		if emailExists != nil {
+			return errors.New("customer email already exists")
+		}
+	}
+
+	return s.Repo.CustomerRepo.Update(id, data)
+}
+
+func (s *customerService) Delete(id int) error {
+	// Check if customer exists
+	existingCustomer, err := s.Repo.CustomerRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if existingCustomer == nil {
+		return errors.New("customer not found")
+	}
+
+	// Keep the sales history attributable
+	summary, err := s.Repo.CustomerRepo.GetSalesSummary(id)
+	if err != nil {
+		return err
+	}
+	if summary.TotalSales > 0 {
+		return errors.New("customer has sales and cannot be deleted")
+	}
+
+	return s.Repo.CustomerRepo.Delete(id)
+}
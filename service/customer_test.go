@@ -0,0 +1,182 @@
+package service
+
+import (
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"project-app-inventory/repository"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockCustomerRepository mocks CustomerRepository interface
+type MockCustomerRepository struct {
+	mock.Mock
+}
+
+func (m *MockCustomerRepository) Create(customer *model.Customer) error {
+	args := m.Called(customer)
+	return args.Error(0)
+}
+
+func (m *MockCustomerRepository) FindByID(id int) (*model.Customer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), args.Error(1)
+}
+
+func (m *MockCustomerRepository) FindByEmail(email string) (*model.Customer, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Customer), args.Error(1)
+}
+
+func (m *MockCustomerRepository) FindAll(page, limit int) ([]model.Customer, int, error) {
+	args := m.Called(page, limit)
+	return args.Get(0).([]model.Customer), args.Int(1), args.Error(2)
+}
+
+func (m *MockCustomerRepository) GetSalesSummary(customerID int) (*model.CustomerSalesSummary, error) {
+	args := m.Called(customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CustomerSalesSummary), args.Error(1)
+}
+
+func (m *MockCustomerRepository) Update(id int, customer *model.Customer) error {
+	args := m.Called(id, customer)
+	return args.Error(0)
+}
+
+func (m *MockCustomerRepository) Delete(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// MockSaleRepository mocks SaleRepository interface
+type MockSaleRepository struct {
+	mock.Mock
+}
+
+func (m *MockSaleRepository) Create(sale *model.Sale, items []model.SaleItem) error {
+	args := m.Called(sale, items)
+	return args.Error(0)
+}
+
+func (m *MockSaleRepository) FindByID(id int) (*model.Sale, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Sale), args.Error(1)
+}
+
+func (m *MockSaleRepository) FindSaleItems(saleID int) ([]model.SaleItem, error) {
+	args := m.Called(saleID)
+	return args.Get(0).([]model.SaleItem), args.Error(1)
+}
+
+func (m *MockSaleRepository) FindAll(page, limit int) ([]model.Sale, int, error) {
+	args := m.Called(page, limit)
+	return args.Get(0).([]model.Sale), args.Int(1), args.Error(2)
+}
+
+func (m *MockSaleRepository) FindAfter(afterID, limit int) ([]model.Sale, error) {
+	args := m.Called(afterID, limit)
+	return args.Get(0).([]model.Sale), args.Error(1)
+}
+
+func (m *MockSaleRepository) FindByCustomerID(customerID, page, limit int) ([]model.Sale, int, error) {
+	args := m.Called(customerID, page, limit)
+	return args.Get(0).([]model.Sale), args.Int(1), args.Error(2)
+}
+
+func (m *MockSaleRepository) Update(id int, userID int, sale *model.Sale, items []model.SaleItem) error {
+	args := m.Called(id, userID, sale, items)
+	return args.Error(0)
+}
+
+func (m *MockSaleRepository) Delete(id int, userID int) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+// TestCustomerService_Create_EmailExists tests creation with existing email
+func TestCustomerService_Create_EmailExists(t *testing.T) {
+	mockCustomerRepo := new(MockCustomerRepository)
+	repo := repository.Repository{CustomerRepo: mockCustomerRepo}
+	service := NewCustomerService(repo)
+
+	email := "budi@example.com"
+	customer := &model.Customer{Name: "Budi Santoso", Email: &email}
+
+	mockCustomerRepo.On("FindByEmail", email).Return(&model.Customer{ID: 1, Email: &email}, nil)
+
+	err := service.Create(customer)
+
+	require.Error(t, err)
+	require.Equal(t, "customer email already exists", err.Error())
+	mockCustomerRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestCustomerService_Delete_HasSales tests that customers with sales are kept
+func TestCustomerService_Delete_HasSales(t *testing.T) {
+	mockCustomerRepo := new(MockCustomerRepository)
+	repo := repository.Repository{CustomerRepo: mockCustomerRepo}
+	service := NewCustomerService(repo)
+
+	mockCustomerRepo.On("FindByID", 1).Return(&model.Customer{ID: 1, Name: "Budi Santoso"}, nil)
+	mockCustomerRepo.On("GetSalesSummary", 1).Return(&model.CustomerSalesSummary{CustomerID: 1, TotalSales: 2}, nil)
+
+	err := service.Delete(1)
+
+	require.Error(t, err)
+	require.Equal(t, "customer has sales and cannot be deleted", err.Error())
+	mockCustomerRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+// TestCustomerService_GetCustomerSales_Success tests sales history with lifetime summary
+func TestCustomerService_GetCustomerSales_Success(t *testing.T) {
+	mockCustomerRepo := new(MockCustomerRepository)
+	mockSaleRepo := new(MockSaleRepository)
+	repo := repository.Repository{CustomerRepo: mockCustomerRepo, SaleRepo: mockSaleRepo}
+	service := NewCustomerService(repo)
+
+	customerID := 1
+	sales := []model.Sale{{ID: 4, UserID: 2, CustomerID: &customerID, TotalAmount: money.FromUnits(500000)}}
+
+	mockCustomerRepo.On("FindByID", 1).Return(&model.Customer{ID: 1, Name: "Budi Santoso"}, nil)
+	mockCustomerRepo.On("GetSalesSummary", 1).Return(&model.CustomerSalesSummary{CustomerID: 1, TotalSales: 3, LifetimeRevenue: money.FromUnits(1500000)}, nil)
+	mockSaleRepo.On("FindByCustomerID", 1, 1, 1).Return(sales, 3, nil)
+
+	customer, summary, result, pagination, err := service.GetCustomerSales(1, 1, 1)
+
+	require.NoError(t, err)
+	require.Equal(t, "Budi Santoso", customer.Name)
+	require.Equal(t, money.FromUnits(1500000), summary.LifetimeRevenue)
+	require.Equal(t, sales, result)
+	require.Equal(t, 3, pagination.TotalPages)
+}
+
+// TestSaleService_Create_UnknownCustomer tests a sale for a customer that does not exist
+func TestSaleService_Create_UnknownCustomer(t *testing.T) {
+	mockCustomerRepo := new(MockCustomerRepository)
+	mockSaleRepo := new(MockSaleRepository)
+	repo := repository.Repository{CustomerRepo: mockCustomerRepo, SaleRepo: mockSaleRepo}
+	service := NewSaleService(repo)
+
+	mockCustomerRepo.On("FindByID", 9).Return(nil, nil)
+
+	_, err := service.Create(1, dto.SaleRequest{CustomerID: 9, Items: []dto.SaleItemRequest{{ItemID: 1, Quantity: 1}}})
+
+	require.Error(t, err)
+	require.Equal(t, "customer not found", err.Error())
+	mockSaleRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
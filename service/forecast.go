@@ -0,0 +1,307 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+	"strings"
+	"time"
+)
+
+type ForecastService interface {
+	ForecastItem(itemID int, req dto.ForecastParamsRequest) (*dto.ItemForecastResponse, error)
+	ForecastItems(req dto.ForecastParamsRequest, page, limit int) ([]dto.ItemForecastResponse, *dto.Pagination, error)
+}
+
+type forecastService struct {
+	Repo repository.Repository
+}
+
+func NewForecastService(repo repository.Repository) ForecastService {
+	return &forecastService{Repo: repo}
+}
+
+// Defaults and limits of the forecast parameters
+const (
+	forecastDays        = 14
+	forecastHistoryDays = 84 // twelve weeks, every weekday is seen twelve times
+	forecastWindow      = 28
+	forecastAlpha       = 0.3
+
+	forecastMaxDays = 365
+	// stockOutHorizonDays is how far ahead a stock-out date is looked for
+	stockOutHorizonDays = 365
+)
+
+// ForecastItem forecasts the daily demand of one item and lists it day by day
+func (s *forecastService) ForecastItem(itemID int, req dto.ForecastParamsRequest) (*dto.ItemForecastResponse, error) {
+	params, err := forecastParams(req)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.Repo.ItemRepo.FindByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, errors.New("item not found")
+	}
+
+	today := forecastToday()
+	from := today.AddDate(0, 0, -params.HistoryDays)
+	sales, err := s.Repo.ForecastRepo.FindDailySales([]int{item.ID}, from, today)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast := fitForecast(dailyHistory(sales, from, params.HistoryDays), from, params)
+	response := forecastResponse(*item, forecast, params, today)
+	for i := 0; i < params.Days; i++ {
+		day := today.AddDate(0, 0, i)
+		response.Days = append(response.Days, dto.ForecastDayResponse{
+			Date:   day.Format("2006-01-02"),
+			Demand: round2(forecast.demand(day)),
+		})
+	}
+	return &response, nil
+}
+
+// ForecastItems forecasts the demand of a page of items, without the daily
+// breakdown
+func (s *forecastService) ForecastItems(req dto.ForecastParamsRequest, page, limit int) ([]dto.ItemForecastResponse, *dto.Pagination, error) {
+	params, err := forecastParams(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, total, err := s.Repo.ItemRepo.FindAll(page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	today := forecastToday()
+	from := today.AddDate(0, 0, -params.HistoryDays)
+
+	itemIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ID)
+	}
+	sales, err := s.Repo.ForecastRepo.FindDailySales(itemIDs, from, today)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	salesByItem := make(map[int][]model.DailySales)
+	for _, day := range sales {
+		salesByItem[day.ItemID] = append(salesByItem[day.ItemID], day)
+	}
+
+	forecasts := []dto.ItemForecastResponse{}
+	for _, item := range items {
+		forecast := fitForecast(dailyHistory(salesByItem[item.ID], from, params.HistoryDays), from, params)
+		forecasts = append(forecasts, forecastResponse(item, forecast, params, today))
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return forecasts, &pagination, nil
+}
+
+// forecastParams fills in the defaults of the params not given, the ones given
+// are checked as they are
+func forecastParams(req dto.ForecastParamsRequest) (model.ForecastParams, error) {
+	params := model.ForecastParams{
+		Method:      req.Method,
+		Days:        forecastDays,
+		HistoryDays: forecastHistoryDays,
+		Window:      forecastWindow,
+		Alpha:       forecastAlpha,
+	}
+	switch params.Method {
+	case "":
+		params.Method = model.ForecastMovingAverage
+	case model.ForecastMovingAverage, model.ForecastExponentialSmoothing:
+	default:
+		return params, errors.New("method must be moving_average or exponential_smoothing")
+	}
+
+	if req.Days != nil {
+		params.Days = *req.Days
+	}
+	if req.HistoryDays != nil {
+		params.HistoryDays = *req.HistoryDays
+	}
+	if req.Window != nil {
+		params.Window = *req.Window
+	}
+	if req.Alpha != nil {
+		params.Alpha = *req.Alpha
+	}
+
+	if params.Days < 1 || params.Days > forecastMaxDays {
+		return params, errors.New("days must be between 1 and 365")
+	}
+	if params.HistoryDays < 7 {
+		return params, errors.New("history_days must be at least 7")
+	}
+	if params.Window < 1 {
+		return params, errors.New("window must be at least 1")
+	}
+	if params.Alpha <= 0 || params.Alpha > 1 {
+		return params, errors.New("alpha must be greater than 0 and at most 1")
+	}
+	return params, nil
+}
+
+// forecastToday is the first day forecast, sales of today so far are not
+// part of the history
+func forecastToday() time.Time {
+	year, month, day := time.Now().UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// dailyHistory lays the sales out as one value per day from from on, days
+// without sales are zero
+func dailyHistory(sales []model.DailySales, from time.Time, days int) []float64 {
+	history := make([]float64, days)
+	for _, day := range sales {
+		index := int(day.Day.Sub(from).Hours() / 24)
+		if index >= 0 && index < days {
+			history[index] += float64(day.Units)
+		}
+	}
+	return history
+}
+
+// demandForecast is a level of daily demand with the weekdays seasonality
+// taken out, and the factor each weekday sells at against the average day
+type demandForecast struct {
+	level       float64
+	seasonality [7]float64 // indexed by time.Weekday
+}
+
+func (f demandForecast) demand(day time.Time) float64 {
+	return f.level * f.seasonality[day.Weekday()]
+}
+
+// fitForecast fits the forecast on a daily history starting on start. The
+// weekday factors are the average of each weekday over the average day; the
+// level is the moving average or the exponential smoothing of the history
+// with those factors divided out.
+func fitForecast(history []float64, start time.Time, params model.ForecastParams) demandForecast {
+	var forecast demandForecast
+	for i := range forecast.seasonality {
+		forecast.seasonality[i] = 1
+	}
+
+	var total float64
+	var sums, counts [7]float64
+	for i, units := range history {
+		weekday := start.AddDate(0, 0, i).Weekday()
+		sums[weekday] += units
+		counts[weekday]++
+		total += units
+	}
+	if total == 0 {
+		return forecast
+	}
+
+	// A weekday needs to be seen at least twice before it gets its own factor
+	mean := total / float64(len(history))
+	if len(history) >= 14 {
+		for weekday := range forecast.seasonality {
+			if counts[weekday] > 0 {
+				forecast.seasonality[weekday] = sums[weekday] / counts[weekday] / mean
+			}
+		}
+	}
+
+	// Weekdays that never sell carry no level
+	var adjusted []float64
+	for i, units := range history {
+		factor := forecast.seasonality[start.AddDate(0, 0, i).Weekday()]
+		if factor > 0 {
+			adjusted = append(adjusted, units/factor)
+		}
+	}
+
+	switch params.Method {
+	case model.ForecastExponentialSmoothing:
+		forecast.level = adjusted[0]
+		for _, units := range adjusted[1:] {
+			forecast.level = params.Alpha*units + (1-params.Alpha)*forecast.level
+		}
+	default:
+		window := adjusted
+		if len(window) > params.Window {
+			window = window[len(window)-params.Window:]
+		}
+		var sum float64
+		for _, units := range window {
+			sum += units
+		}
+		forecast.level = sum / float64(len(window))
+	}
+
+	return forecast
+}
+
+// stockOutDate is the first day the forecast demand from today on uses up the
+// available stock, nil when that is more than a year away
+func stockOutDate(forecast demandForecast, available int, today time.Time) *time.Time {
+	if available <= 0 {
+		return &today
+	}
+
+	var demand float64
+	for i := 0; i < stockOutHorizonDays; i++ {
+		day := today.AddDate(0, 0, i)
+		demand += forecast.demand(day)
+		if demand >= float64(available) {
+			return &day
+		}
+	}
+	return nil
+}
+
+func forecastResponse(item model.Item, forecast demandForecast, params model.ForecastParams, today time.Time) dto.ItemForecastResponse {
+	response := dto.ItemForecastResponse{
+		ItemID:             item.ID,
+		SKU:                item.SKU,
+		Name:               item.Name,
+		Available:          item.Available,
+		Method:             params.Method,
+		HistoryDays:        params.HistoryDays,
+		AverageDailyDemand: round2(forecast.level),
+		WeekdayFactors:     make(map[string]float64),
+		ForecastDays:       params.Days,
+	}
+
+	for weekday, factor := range forecast.seasonality {
+		response.WeekdayFactors[strings.ToLower(time.Weekday(weekday).String())] = round2(factor)
+	}
+
+	var expected float64
+	for i := 0; i < params.Days; i++ {
+		expected += forecast.demand(today.AddDate(0, 0, i))
+	}
+	response.ExpectedDemand = round2(expected)
+
+	if date := stockOutDate(forecast, item.Available, today); date != nil {
+		dateStr := date.Format("2006-01-02")
+		response.StockOutDate = &dateStr
+	}
+	return response
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockForecastRepository is a mock implementation of ForecastRepository
+type MockForecastRepository struct {
+	mock.Mock
+}
+
+func (m *MockForecastRepository) FindDailySales(itemIDs []int, from, to time.Time) ([]model.DailySales, error) {
+	args := m.Called(itemIDs, from, to)
+	return args.Get(0).([]model.DailySales), args.Error(1)
+}
+
+// twoWeeks is 10 units a day on weekdays and 20 on weekends, from a Monday
+func twoWeeks() ([]float64, time.Time) {
+	start := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	var history []float64
+	for i := 0; i < 14; i++ {
+		switch start.AddDate(0, 0, i).Weekday() {
+		case time.Saturday, time.Sunday:
+			history = append(history, 20)
+		default:
+			history = append(history, 10)
+		}
+	}
+	return history, start
+}
+
+// TestFitForecast_WeekdaySeasonality tests weekends are forecast at their own factor
+func TestFitForecast_WeekdaySeasonality(t *testing.T) {
+	history, start := twoWeeks()
+
+	forecast := fitForecast(history, start, model.ForecastParams{Method: model.ForecastMovingAverage, Window: 28})
+
+	require.InDelta(t, 180.0/14, forecast.level, 0.0001)
+	require.InDelta(t, 20, forecast.demand(time.Date(2025, 3, 22, 0, 0, 0, 0, time.UTC)), 0.0001) // Saturday
+	require.InDelta(t, 10, forecast.demand(time.Date(2025, 3, 18, 0, 0, 0, 0, time.UTC)), 0.0001) // Tuesday
+}
+
+// TestFitForecast_ExponentialSmoothing tests the level follows the latest days
+func TestFitForecast_ExponentialSmoothing(t *testing.T) {
+	start := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	history := []float64{4, 4, 4, 4, 4, 4, 8}
+
+	forecast := fitForecast(history, start, model.ForecastParams{Method: model.ForecastExponentialSmoothing, Alpha: 0.5})
+
+	// Under two weeks of history every weekday counts the same
+	require.Equal(t, 1.0, forecast.seasonality[time.Sunday])
+	require.InDelta(t, 6, forecast.level, 0.0001)
+}
+
+// TestFitForecast_NoSales tests items that never sold forecast no demand and never run out
+func TestFitForecast_NoSales(t *testing.T) {
+	today := time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)
+
+	forecast := fitForecast(make([]float64, 28), today.AddDate(0, 0, -28), model.ForecastParams{Method: model.ForecastMovingAverage, Window: 28})
+
+	require.Equal(t, 0.0, forecast.level)
+	require.Nil(t, stockOutDate(forecast, 10, today))
+}
+
+// TestStockOutDate tests the stock runs out on the day the cumulative demand reaches it
+func TestStockOutDate(t *testing.T) {
+	history, start := twoWeeks()
+	forecast := fitForecast(history, start, model.ForecastParams{Method: model.ForecastMovingAverage, Window: 28})
+	today := time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC) // Monday
+
+	// 10 a day Monday to Friday uses up 45 units on Friday
+	require.Equal(t, time.Date(2025, 3, 21, 0, 0, 0, 0, time.UTC), *stockOutDate(forecast, 45, today))
+	require.Equal(t, today, *stockOutDate(forecast, 0, today))
+}
+
+// TestForecastService_ForecastItem tests the forecast is fitted on the history before today
+func TestForecastService_ForecastItem(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockForecastRepo := new(MockForecastRepository)
+	service := NewForecastService(repository.Repository{ItemRepo: mockItemRepo, ForecastRepo: mockForecastRepo})
+
+	today := forecastToday()
+	from := today.AddDate(0, 0, -28)
+
+	var sales []model.DailySales
+	for i := 0; i < 28; i++ {
+		sales = append(sales, model.DailySales{ItemID: 3, Day: from.AddDate(0, 0, i), Units: 5})
+	}
+
+	mockItemRepo.On("FindByID", 3).Return(&model.Item{ID: 3, SKU: "TEA-01", Stock: 60, Available: 50}, nil)
+	mockForecastRepo.On("FindDailySales", []int{3}, from, today).Return(sales, nil)
+
+	days, historyDays := 7, 28
+	forecast, err := service.ForecastItem(3, dto.ForecastParamsRequest{Days: &days, HistoryDays: &historyDays})
+
+	require.NoError(t, err)
+	require.Equal(t, model.ForecastMovingAverage, forecast.Method)
+	require.Equal(t, 5.0, forecast.AverageDailyDemand)
+	require.Equal(t, 35.0, forecast.ExpectedDemand)
+	require.Len(t, forecast.Days, 7)
+	require.Equal(t, today.AddDate(0, 0, 9).Format("2006-01-02"), *forecast.StockOutDate)
+	mockItemRepo.AssertExpectations(t)
+	mockForecastRepo.AssertExpectations(t)
+}
+
+// TestForecastService_ForecastItem_InvalidMethod tests an unknown method is rejected
+func TestForecastService_ForecastItem_InvalidMethod(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	service := NewForecastService(repository.Repository{ItemRepo: mockItemRepo})
+
+	forecast, err := service.ForecastItem(3, dto.ForecastParamsRequest{Method: "arima"})
+
+	require.Error(t, err)
+	require.Nil(t, forecast)
+	mockItemRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+}
+
+// TestForecastParams tests params left out get their default and a given 0 is checked, not defaulted
+func TestForecastParams(t *testing.T) {
+	params, err := forecastParams(dto.ForecastParamsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, model.ForecastParams{
+		Method:      model.ForecastMovingAverage,
+		Days:        forecastDays,
+		HistoryDays: forecastHistoryDays,
+		Window:      forecastWindow,
+		Alpha:       forecastAlpha,
+	}, params)
+
+	zero, zeroAlpha := 0, 0.0
+	_, err = forecastParams(dto.ForecastParamsRequest{Days: &zero})
+	require.EqualError(t, err, "days must be between 1 and 365")
+	_, err = forecastParams(dto.ForecastParamsRequest{Window: &zero})
+	require.EqualError(t, err, "window must be at least 1")
+	_, err = forecastParams(dto.ForecastParamsRequest{Alpha: &zeroAlpha})
+	require.EqualError(t, err, "alpha must be greater than 0 and at most 1")
+}
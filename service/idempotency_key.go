@@ -0,0 +1,102 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+	"time"
+)
+
+// idempotencyKeyLease is how long a request may run before its key is taken to
+// be abandoned, by a server that went down before it could answer
+const idempotencyKeyLease = 5 * time.Minute
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+)
+
+type IdempotencyKeyService interface {
+	Begin(userID int, key, method, target string, body []byte, ttl time.Duration) (*model.IdempotencyKey, bool, error)
+	Complete(record *model.IdempotencyKey, statusCode int, header map[string][]string, body []byte) error
+	Release(record *model.IdempotencyKey) error
+	PurgeExpired() (int64, error)
+}
+
+type idempotencyKeyService struct {
+	Repo repository.Repository
+}
+
+func NewIdempotencyKeyService(repo repository.Repository) IdempotencyKeyService {
+	return &idempotencyKeyService{Repo: repo}
+}
+
+// Begin claims key for the request, target being its path and query. The
+// returned flag is true when the key already holds a finished response of the
+// same request that must be replayed, otherwise the caller runs the request and
+// reports back with Complete or Release.
+func (s *idempotencyKeyService) Begin(userID int, key, method, target string, body []byte, ttl time.Duration) (*model.IdempotencyKey, bool, error) {
+	record := &model.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		Method:      method,
+		Path:        target,
+		RequestHash: requestHash(method, target, body),
+		Token:       utils.GenerateUUIDToken(),
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+
+	reserved, err := s.Repo.IdempotencyKeyRepo.Reserve(record, idempotencyKeyLease)
+	if err != nil {
+		return nil, false, err
+	}
+	if reserved {
+		return record, false, nil
+	}
+
+	existing, err := s.Repo.IdempotencyKeyRepo.FindByKey(userID, key)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing == nil {
+		// Released by a failed request in the meantime
+		return nil, false, ErrIdempotencyKeyInProgress
+	}
+	if existing.RequestHash != record.RequestHash {
+		return nil, false, ErrIdempotencyKeyReused
+	}
+	if existing.StatusCode == nil {
+		return nil, false, ErrIdempotencyKeyInProgress
+	}
+
+	return existing, true, nil
+}
+
+// Complete stores the response headers and body of the request that reserved
+// record, to be replayed to its retries
+func (s *idempotencyKeyService) Complete(record *model.IdempotencyKey, statusCode int, header map[string][]string, body []byte) error {
+	return s.Repo.IdempotencyKeyRepo.SaveResponse(record.ID, record.Token, statusCode, header, body)
+}
+
+// Release frees the key of a request that failed on the server side so the
+// client can retry it
+func (s *idempotencyKeyService) Release(record *model.IdempotencyKey) error {
+	return s.Repo.IdempotencyKeyRepo.Delete(record.ID, record.Token)
+}
+
+// PurgeExpired removes the keys past their TTL, Reserve already treats them as
+// free so this only keeps the table from growing
+func (s *idempotencyKeyService) PurgeExpired() (int64, error) {
+	return s.Repo.IdempotencyKeyRepo.DeleteExpired()
+}
+
+// requestHash fingerprints a request so a key can't be reused for another one
+func requestHash(method, target string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + target + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockIdempotencyKeyRepository is a mock implementation of IdempotencyKeyRepository
+type MockIdempotencyKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyKeyRepository) Reserve(key *model.IdempotencyKey, lease time.Duration) (bool, error) {
+	args := m.Called(key, lease)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockIdempotencyKeyRepository) FindByKey(userID int, key string) (*model.IdempotencyKey, error) {
+	args := m.Called(userID, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.IdempotencyKey), args.Error(1)
+}
+
+func (m *MockIdempotencyKeyRepository) SaveResponse(id int, token string, statusCode int, header map[string][]string, body []byte) error {
+	args := m.Called(id, token, statusCode, header, body)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyKeyRepository) Delete(id int, token string) error {
+	args := m.Called(id, token)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyKeyRepository) DeleteExpired() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestIdempotencyKeyService_Begin_NewKey(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	service := NewIdempotencyKeyService(repository.Repository{IdempotencyKeyRepo: mockRepo})
+
+	mockRepo.On("Reserve", mock.MatchedBy(func(key *model.IdempotencyKey) bool {
+		return key.UserID == 3 && key.Key == "abc" && len(key.RequestHash) == 64 && key.Token != "" && key.ExpiresAt.After(time.Now())
+	}), idempotencyKeyLease).Return(true, nil)
+
+	record, replay, err := service.Begin(3, "abc", "POST", "/api/v1/sales", []byte(`{"items":[]}`), time.Hour)
+
+	require.NoError(t, err)
+	require.False(t, replay)
+	require.Equal(t, "abc", record.Key)
+	mockRepo.AssertNotCalled(t, "FindByKey", mock.Anything, mock.Anything)
+}
+
+func TestIdempotencyKeyService_Begin_ReplaysStoredResponse(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	service := NewIdempotencyKeyService(repository.Repository{IdempotencyKeyRepo: mockRepo})
+
+	body := []byte(`{"items":[]}`)
+	statusCode := 201
+	mockRepo.On("Reserve", mock.Anything, idempotencyKeyLease).Return(false, nil)
+	mockRepo.On("FindByKey", 3, "abc").Return(&model.IdempotencyKey{
+		ID:           9,
+		RequestHash:  requestHash("POST", "/api/v1/sales", body),
+		StatusCode:   &statusCode,
+		ResponseBody: []byte(`{"status":true}`),
+	}, nil)
+
+	record, replay, err := service.Begin(3, "abc", "POST", "/api/v1/sales", body, time.Hour)
+
+	require.NoError(t, err)
+	require.True(t, replay)
+	require.Equal(t, 201, *record.StatusCode)
+	require.Equal(t, `{"status":true}`, string(record.ResponseBody))
+}
+
+func TestIdempotencyKeyService_Begin_DifferentBody(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	service := NewIdempotencyKeyService(repository.Repository{IdempotencyKeyRepo: mockRepo})
+
+	statusCode := 201
+	mockRepo.On("Reserve", mock.Anything, idempotencyKeyLease).Return(false, nil)
+	mockRepo.On("FindByKey", 3, "abc").Return(&model.IdempotencyKey{
+		ID:          9,
+		RequestHash: requestHash("POST", "/api/v1/sales", []byte(`{"items":[1]}`)),
+		StatusCode:  &statusCode,
+	}, nil)
+
+	record, _, err := service.Begin(3, "abc", "POST", "/api/v1/sales", []byte(`{"items":[2]}`), time.Hour)
+
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+	require.Nil(t, record)
+}
+
+func TestIdempotencyKeyService_Begin_DifferentQuery(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	service := NewIdempotencyKeyService(repository.Repository{IdempotencyKeyRepo: mockRepo})
+
+	body := []byte("sku,name\n")
+	statusCode := 200
+	mockRepo.On("Reserve", mock.Anything, idempotencyKeyLease).Return(false, nil)
+	mockRepo.On("FindByKey", 3, "abc").Return(&model.IdempotencyKey{
+		ID:          9,
+		RequestHash: requestHash("POST", "/api/v1/items/import?dry_run=true", body),
+		StatusCode:  &statusCode,
+	}, nil)
+
+	_, _, err := service.Begin(3, "abc", "POST", "/api/v1/items/import?dry_run=false", body, time.Hour)
+
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestIdempotencyKeyService_Begin_InProgress(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	service := NewIdempotencyKeyService(repository.Repository{IdempotencyKeyRepo: mockRepo})
+
+	body := []byte(`{"items":[]}`)
+	mockRepo.On("Reserve", mock.Anything, idempotencyKeyLease).Return(false, nil)
+	mockRepo.On("FindByKey", 3, "abc").Return(&model.IdempotencyKey{
+		ID:          9,
+		RequestHash: requestHash("POST", "/api/v1/sales", body),
+	}, nil)
+
+	_, _, err := service.Begin(3, "abc", "POST", "/api/v1/sales", body, time.Hour)
+
+	require.ErrorIs(t, err, ErrIdempotencyKeyInProgress)
+}
+
+// TestIdempotencyKeyService_Begin_NewTokenPerReservation tests every reservation
+// gets its own token, so a request whose key was taken over can't store or free it
+func TestIdempotencyKeyService_Begin_NewTokenPerReservation(t *testing.T) {
+	mockRepo := new(MockIdempotencyKeyRepository)
+	service := NewIdempotencyKeyService(repository.Repository{IdempotencyKeyRepo: mockRepo})
+
+	mockRepo.On("Reserve", mock.Anything, idempotencyKeyLease).Return(true, nil)
+
+	first, _, err := service.Begin(3, "abc", "POST", "/api/v1/sales", nil, time.Hour)
+	require.NoError(t, err)
+	second, _, err := service.Begin(3, "abc", "POST", "/api/v1/sales", nil, time.Hour)
+	require.NoError(t, err)
+	require.NotEqual(t, first.Token, second.Token)
+
+	header := map[string][]string{"Content-Type": {"text/csv"}}
+	mockRepo.On("SaveResponse", 9, second.Token, 200, header, []byte("sku\n")).Return(nil)
+	mockRepo.On("Delete", 9, first.Token).Return(nil)
+
+	second.ID = 9
+	require.NoError(t, service.Complete(second, 200, header, []byte("sku\n")))
+	first.ID = 9
+	require.NoError(t, service.Release(first))
+	mockRepo.AssertExpectations(t)
+}
@@ -9,9 +9,13 @@ import (
 )
 
 type ItemService interface {
-	Create(item *model.Item) error
+	Create(item *model.Item, userID int) error
 	GetAllItems(page, limit int) (*[]model.Item, *dto.Pagination, error)
-	GetLowStockItems(page, limit int) (*[]model.Item, *dto.Pagination, error)
+	GetItemsAfter(afterID, limit int) ([]model.Item, error)
+	GetLowStockItems(page, limit int, useAvailable bool) (*[]model.Item, *dto.Pagination, error)
+	GetReorderSuggestions(req dto.ReorderParamsRequest, page, limit int) (*[]model.ReorderSuggestion, *dto.Pagination, error)
+	AcceptReorderSuggestions(req dto.ReorderParamsRequest, itemIDs []int) ([]model.ReorderSuggestion, error)
+	ImportItems(rows []dto.ItemImportRow, warehouseID, userID int, partial, dryRun bool) (*dto.ItemImportResponse, error)
 	GetItemByID(id int) (*model.Item, error)
 	Update(id int, data *model.Item) error
 	Delete(id int) error
@@ -25,7 +29,23 @@ func NewItemService(repo repository.Repository) ItemService {
 	return &itemService{Repo: repo}
 }
 
-func (s *itemService) Create(item *model.Item) error {
+func (s *itemService) Create(item *model.Item, userID int) error {
+	if err := checkTaxRate(item.TaxRate); err != nil {
+		return err
+	}
+
+	// Opening stock has no lot, lot tracked stock only arrives through receipts
+	if item.TrackLots && item.Stock > 0 {
+		return errors.New("lot tracked items must start with zero stock")
+	}
+	// Likewise serialized stock only arrives with its serial numbers
+	if item.Serialized && item.Stock > 0 {
+		return errors.New("serialized items must start with zero stock")
+	}
+	if item.Serialized && item.TrackLots {
+		return errors.New("items can't be both lot tracked and serialized")
+	}
+
 	// Check if SKU already exists
 	existingItem, err := s.Repo.ItemRepo.FindBySKU(item.SKU)
 	if err != nil {
@@ -35,7 +55,7 @@ func (s *itemService) Create(item *model.Item) error {
 		return errors.New("SKU already exists")
 	}
 
-	return s.Repo.ItemRepo.Create(item)
+	return s.Repo.ItemRepo.Create(item, userID)
 }
 
 func (s *itemService) GetAllItems(page, limit int) (*[]model.Item, *dto.Pagination, error) {
@@ -53,8 +73,15 @@ func (s *itemService) GetAllItems(page, limit int) (*[]model.Item, *dto.Paginati
 	return &items, &pagination, nil
 }
 
-func (s *itemService) GetLowStockItems(page, limit int) (*[]model.Item, *dto.Pagination, error) {
-	items, total, err := s.Repo.ItemRepo.FindLowStock(page, limit)
+// GetItemsAfter reads the items after afterID in id order, a page of an export
+func (s *itemService) GetItemsAfter(afterID, limit int) ([]model.Item, error) {
+	return s.Repo.ItemRepo.FindAfter(afterID, limit)
+}
+
+// GetLowStockItems compares on-hand stock with the minimum, or the stock not held
+// by reservations when useAvailable is set
+func (s *itemService) GetLowStockItems(page, limit int, useAvailable bool) (*[]model.Item, *dto.Pagination, error) {
+	items, total, err := s.Repo.ItemRepo.FindLowStock(page, limit, useAvailable)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -68,6 +95,98 @@ func (s *itemService) GetLowStockItems(page, limit int) (*[]model.Item, *dto.Pag
 	return &items, &pagination, nil
 }
 
+// Defaults of the reorder suggestions, in days
+const (
+	reorderSalesDays    = 90
+	reorderSafetyDays   = 7
+	reorderCoverDays    = 30
+	reorderLeadTimeDays = 7
+)
+
+// GetReorderSuggestions lists the items whose sales call for another minimum
+// stock, with the reorder point and order quantity suggested for them
+func (s *itemService) GetReorderSuggestions(req dto.ReorderParamsRequest, page, limit int) (*[]model.ReorderSuggestion, *dto.Pagination, error) {
+	params, err := reorderParams(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	suggestions, total, err := s.Repo.ItemRepo.FindReorderSuggestions(params, nil, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	averageDailySales(suggestions, params.SalesDays)
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return &suggestions, &pagination, nil
+}
+
+// AcceptReorderSuggestions sets the minimum stock of the items to the reorder
+// point suggested with params. Items already at their suggestion are left out.
+func (s *itemService) AcceptReorderSuggestions(req dto.ReorderParamsRequest, itemIDs []int) ([]model.ReorderSuggestion, error) {
+	params, err := reorderParams(req)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions, _, err := s.Repo.ItemRepo.FindReorderSuggestions(params, itemIDs, 1, len(itemIDs))
+	if err != nil {
+		return nil, err
+	}
+	if len(suggestions) == 0 {
+		return []model.ReorderSuggestion{}, nil
+	}
+	averageDailySales(suggestions, params.SalesDays)
+
+	err = s.Repo.ItemRepo.UpdateMinimumStocks(suggestions)
+	if err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// reorderParams fills in the defaults of the params not given. Sales are
+// averaged over days so it must be at least 1, the others may be 0.
+func reorderParams(req dto.ReorderParamsRequest) (model.ReorderParams, error) {
+	params := model.ReorderParams{
+		SalesDays:    reorderSalesDays,
+		SafetyDays:   reorderSafetyDays,
+		CoverDays:    reorderCoverDays,
+		LeadTimeDays: reorderLeadTimeDays,
+	}
+	if req.SalesDays != nil {
+		params.SalesDays = *req.SalesDays
+	}
+	if req.SafetyDays != nil {
+		params.SafetyDays = *req.SafetyDays
+	}
+	if req.CoverDays != nil {
+		params.CoverDays = *req.CoverDays
+	}
+	if req.LeadTimeDays != nil {
+		params.LeadTimeDays = *req.LeadTimeDays
+	}
+
+	if params.SalesDays < 1 {
+		return params, errors.New("days must be at least 1")
+	}
+	if params.SafetyDays < 0 || params.CoverDays < 0 || params.LeadTimeDays < 0 {
+		return params, errors.New("safety_days, cover_days and lead_time_days must not be negative")
+	}
+	return params, nil
+}
+
+func averageDailySales(suggestions []model.ReorderSuggestion, salesDays int) {
+	for i := range suggestions {
+		suggestions[i].AverageDailySales = round2(float64(suggestions[i].UnitsSold) / float64(salesDays))
+	}
+}
+
 func (s *itemService) GetItemByID(id int) (*model.Item, error) {
 	item, err := s.Repo.ItemRepo.FindByID(id)
 	if err != nil {
@@ -102,11 +221,19 @@ func (s *itemService) Update(id int, data *model.Item) error {
 	if data.RackID == 0 {
 		data.RackID = existingItem.RackID
 	}
-	// Note: Stock, MinimumStock, and Price can be 0, so we don't check for zero values
+	// Stock is never taken from an update, it only moves through adjustments and sales
+	data.Stock = existingItem.Stock
+	data.TrackLots = existingItem.TrackLots
+	data.Serialized = existingItem.Serialized
+	// A tax rate override is kept unless a new one is sent
+	if data.TaxRate == nil {
+		data.TaxRate = existingItem.TaxRate
+	}
+	if err := checkTaxRate(data.TaxRate); err != nil {
+		return err
+	}
+	// Note: MinimumStock and Price can be 0, so we don't check for zero values
 	// If you want to keep existing values when 0 is sent, uncomment below:
-	// if data.Stock == 0 {
-	// 	data.Stock = existingItem.Stock
-	// }
 	// if data.MinimumStock == 0 {
 	// 	data.MinimumStock = existingItem.MinimumStock
 	// }
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+	"strconv"
+	"strings"
+)
+
+// ImportItems creates the rows whose SKU is new and updates the others. Every
+// row is checked like a POST /items request after its category and rack are
+// looked up; rack codes are looked up in warehouseID when it is set.
+//
+// Unless partial, any failed row leaves every item as it was. A dry run
+// reports what the import would do without writing anything.
+func (s *itemService) ImportItems(rows []dto.ItemImportRow, warehouseID, userID int, partial, dryRun bool) (*dto.ItemImportResponse, error) {
+	resolver := itemImportResolver{
+		repo:        s.Repo,
+		warehouseID: warehouseID,
+		categories:  make(map[string]int),
+		racks:       make(map[string]itemImportRack),
+	}
+
+	results := make([]dto.ItemImportRowResult, len(rows))
+	var imports []model.ItemImport
+	var importIndexes []int // index in results of each of imports
+	skuLines := make(map[string]int)
+	failed := 0
+
+	for i, row := range rows {
+		results[i] = dto.ItemImportRowResult{Line: row.Line, SKU: strings.TrimSpace(row.SKU)}
+
+		item, messages, err := resolver.item(row)
+		if err != nil {
+			return nil, err
+		}
+
+		if line, ok := skuLines[item.SKU]; ok && item.SKU != "" {
+			messages = append(messages, fmt.Sprintf("SKU %s is already on line %d", item.SKU, line))
+		} else {
+			skuLines[item.SKU] = row.Line
+		}
+
+		if len(messages) == 0 {
+			existing, err := s.Repo.ItemRepo.FindBySKU(item.SKU)
+			if err != nil {
+				return nil, errors.New("failed to check SKU")
+			}
+			if existing != nil {
+				item.ID = existing.ID
+				// Their stock only moves together with its lots or serial numbers
+				if (existing.TrackLots || existing.Serialized) && item.Stock != existing.Stock {
+					messages = append(messages, "stock of lot tracked and serialized items can't be changed by an import")
+				}
+			}
+		}
+
+		if len(messages) > 0 {
+			results[i].Status = model.ItemImportFailed
+			results[i].Errors = messages
+			failed++
+			continue
+		}
+
+		imports = append(imports, model.ItemImport{Line: row.Line, Item: item})
+		importIndexes = append(importIndexes, i)
+	}
+
+	response := &dto.ItemImportResponse{DryRun: dryRun, Partial: partial, Total: len(rows)}
+
+	if len(imports) > 0 && (partial || failed == 0) {
+		err := s.Repo.ItemRepo.Import(imports, userID, partial, dryRun)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, imported := range imports {
+		result := &results[importIndexes[i]]
+		switch imported.Status {
+		case model.ItemImportCreated, model.ItemImportUpdated:
+			result.Status = imported.Status
+			if !dryRun || imported.Status == model.ItemImportUpdated {
+				itemID := imported.Item.ID
+				result.ItemID = &itemID
+			}
+		case model.ItemImportFailed:
+			result.Status = model.ItemImportFailed
+			result.Errors = []string{itemImportError(imported.Err)}
+			failed++
+		default:
+			// The import stopped at a failure before this row
+			result.Status = model.ItemImportSkipped
+		}
+	}
+
+	if failed > 0 && !partial {
+		// Nothing was kept, the rows that went through are rolled back with the rest
+		for i := range results {
+			if results[i].Status != model.ItemImportFailed {
+				results[i].Status = model.ItemImportSkipped
+				results[i].ItemID = nil
+			}
+		}
+	}
+
+	for _, result := range results {
+		switch result.Status {
+		case model.ItemImportCreated:
+			response.Created++
+		case model.ItemImportUpdated:
+			response.Updated++
+		case model.ItemImportFailed:
+			response.Failed++
+		case model.ItemImportSkipped:
+			response.Skipped++
+		}
+	}
+
+	response.Imported = !dryRun && response.Created+response.Updated > 0
+	response.Rows = results
+	return response, nil
+}
+
+func itemImportError(err error) string {
+	if errors.Is(err, repository.ErrInsufficientStock) {
+		return "stock can't be lowered below what the item's rack holds"
+	}
+	return err.Error()
+}
+
+// itemImportResolver turns import rows into items, looking up each category
+// and rack once
+type itemImportResolver struct {
+	repo        repository.Repository
+	warehouseID int
+	categories  map[string]int // category name or id to id, 0 when not found
+	racks       map[string]itemImportRack
+}
+
+// itemImportRack is a looked up rack code, with the message for the rows when
+// it is not found
+type itemImportRack struct {
+	id      int
+	message string
+}
+
+// item parses the row and checks it with the rules of dto.ItemRequest. The
+// messages say what is wrong with the row, err is a failed lookup.
+func (r *itemImportResolver) item(row dto.ItemImportRow) (model.Item, []string, error) {
+	var messages []string
+	reported := make(map[string]bool) // fields with a message already
+
+	req := dto.ItemRequest{
+		SKU:  strings.TrimSpace(row.SKU),
+		Name: strings.TrimSpace(row.Name),
+	}
+
+	if category := strings.TrimSpace(row.Category); category != "" {
+		categoryID, err := r.category(category)
+		if err != nil {
+			return model.Item{}, nil, err
+		}
+		if categoryID == 0 {
+			messages = append(messages, fmt.Sprintf("category %s not found", category))
+			reported["CategoryID"] = true
+		}
+		req.CategoryID = categoryID
+	}
+
+	if code := strings.TrimSpace(row.Rack); code != "" {
+		rackID, message, err := r.rack(code)
+		if err != nil {
+			return model.Item{}, nil, err
+		}
+		if message != "" {
+			messages = append(messages, message)
+			reported["RackID"] = true
+		}
+		req.RackID = rackID
+	}
+
+	parseInt := func(field, value string) int {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return 0
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			messages = append(messages, field+" must be a whole number")
+			reported[field] = true
+		}
+		return n
+	}
+	req.Stock = parseInt("Stock", row.Stock)
+	req.MinimumStock = parseInt("MinimumStock", row.MinimumStock)
+
+	if price := strings.TrimSpace(row.Price); price != "" {
+		amount, err := money.Parse(price)
+		if err != nil {
+			messages = append(messages, "Price must be a decimal number")
+			reported["Price"] = true
+		}
+		req.Price = amount
+	}
+
+	fieldErrors, _ := utils.ValidateErrors(req)
+	for _, fieldError := range fieldErrors {
+		if !reported[fieldError.Field] {
+			messages = append(messages, fieldError.Message)
+		}
+	}
+
+	item := model.Item{
+		SKU:          req.SKU,
+		Name:         req.Name,
+		CategoryID:   req.CategoryID,
+		RackID:       req.RackID,
+		Stock:        req.Stock,
+		MinimumStock: req.MinimumStock,
+		Price:        req.Price,
+	}
+	return item, messages, nil
+}
+
+// category finds a category by id when value is a number, by name otherwise
+func (r *itemImportResolver) category(value string) (int, error) {
+	if categoryID, ok := r.categories[value]; ok {
+		return categoryID, nil
+	}
+
+	var category *model.Category
+	var err error
+	if id, convErr := strconv.Atoi(value); convErr == nil {
+		category, err = r.repo.CategoryRepo.FindByID(id)
+	} else {
+		category, err = r.repo.CategoryRepo.FindByName(value)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	categoryID := 0
+	if category != nil {
+		categoryID = category.ID
+	}
+	r.categories[value] = categoryID
+	return categoryID, nil
+}
+
+// rack finds a rack by code, in the import's warehouse when it has one. A code
+// used in several warehouses needs the warehouse to tell them apart.
+func (r *itemImportResolver) rack(code string) (int, string, error) {
+	if rack, ok := r.racks[code]; ok {
+		return rack.id, rack.message, nil
+	}
+
+	var racks []model.Rack
+	if r.warehouseID != 0 {
+		rack, err := r.repo.RackRepo.FindByWarehouseAndCode(r.warehouseID, code)
+		if err != nil {
+			return 0, "", err
+		}
+		if rack != nil {
+			racks = append(racks, *rack)
+		}
+	} else {
+		found, err := r.repo.RackRepo.FindByCode(code)
+		if err != nil {
+			return 0, "", err
+		}
+		racks = found
+	}
+
+	var rack itemImportRack
+	switch {
+	case len(racks) == 1:
+		rack.id = racks[0].ID
+	case len(racks) > 1:
+		rack.message = fmt.Sprintf("rack %s is in more than one warehouse, import with warehouse_id", code)
+	case r.warehouseID != 0:
+		rack.message = fmt.Sprintf("rack %s not found in warehouse %d", code, r.warehouseID)
+	default:
+		rack.message = fmt.Sprintf("rack %s not found", code)
+	}
+	r.racks[code] = rack
+	return rack.id, rack.message, nil
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"project-app-inventory/repository"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestItemService_ImportItems_CreatesAndUpdates tests new SKUs are created and known ones updated
+func TestItemService_ImportItems_CreatesAndUpdates(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockRackRepo := new(MockRackRepository)
+	service := NewItemService(repository.Repository{ItemRepo: mockItemRepo, CategoryRepo: mockCategoryRepo, RackRepo: mockRackRepo})
+
+	rows := []dto.ItemImportRow{
+		{Line: 2, SKU: "MOU-001", Name: "Mouse", Category: "Accessories", Rack: "A1", Stock: "10", MinimumStock: "2", Price: "150000"},
+		{Line: 3, SKU: "KEY-001", Name: "Keyboard", Category: "2", Rack: "A1", Stock: "4", MinimumStock: "1", Price: "250000.50"},
+	}
+
+	mockCategoryRepo.On("FindByName", "Accessories").Return(&model.Category{ID: 2}, nil)
+	mockCategoryRepo.On("FindByID", 2).Return(&model.Category{ID: 2}, nil)
+	mockRackRepo.On("FindByCode", "A1").Return([]model.Rack{{ID: 3, WarehouseID: 1, Code: "A1"}}, nil).Once()
+	mockItemRepo.On("FindBySKU", "MOU-001").Return((*model.Item)(nil), nil)
+	mockItemRepo.On("FindBySKU", "KEY-001").Return(&model.Item{ID: 8, SKU: "KEY-001", Stock: 6}, nil)
+	mockItemRepo.On("Import", mock.Anything, 1, false, false).
+		Run(func(args mock.Arguments) {
+			imports := args.Get(0).([]model.ItemImport)
+			require.Len(t, imports, 2)
+			require.Equal(t, model.Item{SKU: "MOU-001", Name: "Mouse", CategoryID: 2, RackID: 3, Stock: 10, MinimumStock: 2,
+				Price: money.FromUnits(150000)}, imports[0].Item)
+			require.Equal(t, 8, imports[1].Item.ID)
+			require.Equal(t, money.Amount(25000050), imports[1].Item.Price)
+
+			imports[0].Item.ID = 9
+			imports[0].Status = model.ItemImportCreated
+			imports[1].Status = model.ItemImportUpdated
+		}).
+		Return(nil)
+
+	report, err := service.ImportItems(rows, 0, 1, false, false)
+
+	require.NoError(t, err)
+	require.True(t, report.Imported)
+	require.Equal(t, 1, report.Created)
+	require.Equal(t, 1, report.Updated)
+	require.Equal(t, 0, report.Failed)
+	require.Equal(t, 9, *report.Rows[0].ItemID)
+	require.Equal(t, 8, *report.Rows[1].ItemID)
+	mockItemRepo.AssertExpectations(t)
+	mockRackRepo.AssertExpectations(t)
+}
+
+// TestItemService_ImportItems_FailedRowStopsImport tests an invalid row keeps every row out unless partial
+func TestItemService_ImportItems_FailedRowStopsImport(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockRackRepo := new(MockRackRepository)
+	service := NewItemService(repository.Repository{ItemRepo: mockItemRepo, CategoryRepo: mockCategoryRepo, RackRepo: mockRackRepo})
+
+	rows := []dto.ItemImportRow{
+		{Line: 2, SKU: "MOU-001", Name: "Mouse", Category: "Accessories", Rack: "A1", Stock: "10", MinimumStock: "2", Price: "150000"},
+		{Line: 3, SKU: "KEY-001", Name: "Keyboard", Category: "Unknown", Rack: "A1", Stock: "four", MinimumStock: "1", Price: "250000"},
+		{Line: 4, SKU: "MOU-001", Name: "Mouse", Category: "Accessories", Rack: "A1", Stock: "10", MinimumStock: "2", Price: "150000"},
+	}
+
+	mockCategoryRepo.On("FindByName", "Accessories").Return(&model.Category{ID: 2}, nil)
+	mockCategoryRepo.On("FindByName", "Unknown").Return(nil, nil)
+	mockRackRepo.On("FindByCode", "A1").Return([]model.Rack{{ID: 3, WarehouseID: 1, Code: "A1"}}, nil)
+	mockItemRepo.On("FindBySKU", "MOU-001").Return((*model.Item)(nil), nil)
+
+	report, err := service.ImportItems(rows, 0, 1, false, false)
+
+	require.NoError(t, err)
+	require.False(t, report.Imported)
+	require.Equal(t, 2, report.Failed)
+	require.Equal(t, 1, report.Skipped)
+	require.Equal(t, model.ItemImportSkipped, report.Rows[0].Status)
+	require.Equal(t, []string{"category Unknown not found", "Stock must be a whole number"}, report.Rows[1].Errors)
+	require.Equal(t, []string{"SKU MOU-001 is already on line 2"}, report.Rows[2].Errors)
+	mockItemRepo.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestItemService_ImportItems_Partial tests a partial import keeps the rows that went through
+func TestItemService_ImportItems_Partial(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockRackRepo := new(MockRackRepository)
+	service := NewItemService(repository.Repository{ItemRepo: mockItemRepo, CategoryRepo: mockCategoryRepo, RackRepo: mockRackRepo})
+
+	rows := []dto.ItemImportRow{
+		{Line: 2, SKU: "MOU-001", Name: "Mouse", Category: "Accessories", Rack: "A1", Stock: "10", MinimumStock: "2", Price: "150000"},
+		{Line: 3, SKU: "KEY-001", Name: "Keyboard", Category: "Accessories", Rack: "B1", Stock: "4", MinimumStock: "1", Price: "250000"},
+		{Line: 4, SKU: "CAB-001", Name: "Cable", Category: "Accessories", Rack: "A1", Stock: "0", MinimumStock: "1", Price: "5000"},
+	}
+
+	mockCategoryRepo.On("FindByName", "Accessories").Return(&model.Category{ID: 2}, nil)
+	mockRackRepo.On("FindByWarehouseAndCode", 1, "A1").Return(&model.Rack{ID: 3, WarehouseID: 1, Code: "A1"}, nil)
+	mockRackRepo.On("FindByWarehouseAndCode", 1, "B1").Return(nil, nil)
+	mockItemRepo.On("FindBySKU", "MOU-001").Return(&model.Item{ID: 8, SKU: "MOU-001", Stock: 20}, nil)
+	mockItemRepo.On("Import", mock.Anything, 1, true, true).
+		Run(func(args mock.Arguments) {
+			imports := args.Get(0).([]model.ItemImport)
+			require.Len(t, imports, 1)
+			imports[0].Status = model.ItemImportFailed
+			imports[0].Err = repository.ErrInsufficientStock
+		}).
+		Return(nil)
+
+	report, err := service.ImportItems(rows, 1, 1, true, true)
+
+	require.NoError(t, err)
+	require.False(t, report.Imported)
+	require.Equal(t, 3, report.Failed)
+	require.Equal(t, []string{"stock can't be lowered below what the item's rack holds"}, report.Rows[0].Errors)
+	require.Equal(t, []string{"rack B1 not found in warehouse 1"}, report.Rows[1].Errors)
+	// Stock is validated like a POST /items request, where it is required
+	require.Equal(t, []string{"Stock is required"}, report.Rows[2].Errors)
+	mockItemRepo.AssertExpectations(t)
+}
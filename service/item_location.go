@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+)
+
+type ItemLocationService interface {
+	GetItemLocations(itemID int) (*[]model.ItemLocation, error)
+	GetRackStock(rackID, page, limit int) (*[]model.ItemLocation, *dto.Pagination, error)
+	GetWarehouseStock(warehouseID, page, limit int) (*[]model.WarehouseStock, *dto.Pagination, error)
+}
+
+type itemLocationService struct {
+	Repo repository.Repository
+}
+
+func NewItemLocationService(repo repository.Repository) ItemLocationService {
+	return &itemLocationService{Repo: repo}
+}
+
+func (s *itemLocationService) GetItemLocations(itemID int) (*[]model.ItemLocation, error) {
+	// Check if item exists
+	item, err := s.Repo.ItemRepo.FindByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, errors.New("item not found")
+	}
+
+	locations, err := s.Repo.ItemLocationRepo.FindByItemID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	return &locations, nil
+}
+
+func (s *itemLocationService) GetRackStock(rackID, page, limit int) (*[]model.ItemLocation, *dto.Pagination, error) {
+	// Check if rack exists
+	rack, err := s.Repo.RackRepo.FindByID(rackID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rack == nil {
+		return nil, nil, errors.New("rack not found")
+	}
+
+	locations, total, err := s.Repo.ItemLocationRepo.FindByRackID(rackID, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return &locations, &pagination, nil
+}
+
+func (s *itemLocationService) GetWarehouseStock(warehouseID, page, limit int) (*[]model.WarehouseStock, *dto.Pagination, error) {
+	// Check if warehouse exists
+	warehouse, err := s.Repo.WarehouseRepo.FindByID(warehouseID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if warehouse == nil {
+		return nil, nil, errors.New("warehouse not found")
+	}
+
+	stocks, total, err := s.Repo.ItemLocationRepo.FindByWarehouseID(warehouseID, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return &stocks, &pagination, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockItemLocationRepository mocks ItemLocationRepository interface
+type MockItemLocationRepository struct {
+	mock.Mock
+}
+
+func (m *MockItemLocationRepository) FindByItemID(itemID int) ([]model.ItemLocation, error) {
+	args := m.Called(itemID)
+	return args.Get(0).([]model.ItemLocation), args.Error(1)
+}
+
+func (m *MockItemLocationRepository) FindByRackID(rackID, page, limit int) ([]model.ItemLocation, int, error) {
+	args := m.Called(rackID, page, limit)
+	return args.Get(0).([]model.ItemLocation), args.Int(1), args.Error(2)
+}
+
+func (m *MockItemLocationRepository) FindByWarehouseID(warehouseID, page, limit int) ([]model.WarehouseStock, int, error) {
+	args := m.Called(warehouseID, page, limit)
+	return args.Get(0).([]model.WarehouseStock), args.Int(1), args.Error(2)
+}
+
+// TestItemLocationService_GetItemLocations_Success tests listing racks holding an item
+func TestItemLocationService_GetItemLocations_Success(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockLocationRepo := new(MockItemLocationRepository)
+	repo := repository.Repository{ItemRepo: mockItemRepo, ItemLocationRepo: mockLocationRepo}
+	service := NewItemLocationService(repo)
+
+	locations := []model.ItemLocation{
+		{ItemID: 1, RackID: 1, Quantity: 8},
+		{ItemID: 1, RackID: 3, Quantity: 2},
+	}
+
+	mockItemRepo.On("FindByID", 1).Return(&model.Item{ID: 1, Stock: 10}, nil)
+	mockLocationRepo.On("FindByItemID", 1).Return(locations, nil)
+
+	result, err := service.GetItemLocations(1)
+
+	require.NoError(t, err)
+	require.Len(t, *result, 2)
+	mockItemRepo.AssertExpectations(t)
+	mockLocationRepo.AssertExpectations(t)
+}
+
+// TestItemLocationService_GetWarehouseStock_NotFound tests unknown warehouse
+func TestItemLocationService_GetWarehouseStock_NotFound(t *testing.T) {
+	mockWarehouseRepo := new(MockWarehouseRepository)
+	repo := repository.Repository{WarehouseRepo: mockWarehouseRepo}
+	service := NewItemLocationService(repo)
+
+	mockWarehouseRepo.On("FindByID", 99).Return((*model.Warehouse)(nil), nil)
+
+	result, pagination, err := service.GetWarehouseStock(99, 1, 10)
+
+	require.Error(t, err)
+	require.Equal(t, "warehouse not found", err.Error())
+	require.Nil(t, result)
+	require.Nil(t, pagination)
+}
+
+// TestItemLocationService_GetWarehouseStock_Success tests per-warehouse totals
+func TestItemLocationService_GetWarehouseStock_Success(t *testing.T) {
+	mockWarehouseRepo := new(MockWarehouseRepository)
+	mockLocationRepo := new(MockItemLocationRepository)
+	repo := repository.Repository{WarehouseRepo: mockWarehouseRepo, ItemLocationRepo: mockLocationRepo}
+	service := NewItemLocationService(repo)
+
+	stocks := []model.WarehouseStock{{WarehouseID: 1, ItemID: 1, Quantity: 12, RackCount: 2}}
+
+	mockWarehouseRepo.On("FindByID", 1).Return(&model.Warehouse{ID: 1}, nil)
+	mockLocationRepo.On("FindByWarehouseID", 1, 1, 10).Return(stocks, 1, nil)
+
+	result, pagination, err := service.GetWarehouseStock(1, 1, 10)
+
+	require.NoError(t, err)
+	require.Equal(t, 12, (*result)[0].Quantity)
+	require.Equal(t, 1, pagination.TotalRecords)
+}
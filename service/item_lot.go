@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+	"time"
+)
+
+type ItemLotService interface {
+	GetItemLots(itemID int) (*[]model.ItemLot, error)
+	GetExpiringLots(withinDays, page, limit int) (*[]model.ItemLot, *dto.Pagination, error)
+}
+
+type itemLotService struct {
+	Repo repository.Repository
+}
+
+func NewItemLotService(repo repository.Repository) ItemLotService {
+	return &itemLotService{Repo: repo}
+}
+
+func (s *itemLotService) GetItemLots(itemID int) (*[]model.ItemLot, error) {
+	// Check if item exists
+	item, err := s.Repo.ItemRepo.FindByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, errors.New("item not found")
+	}
+	if !item.TrackLots {
+		return nil, errors.New("item does not track lots")
+	}
+
+	lots, err := s.Repo.ItemLotRepo.FindByItemID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	return &lots, nil
+}
+
+// GetExpiringLots lists lots in stock that expire within the given number of
+// days from today, lots that already expired come first
+func (s *itemLotService) GetExpiringLots(withinDays, page, limit int) (*[]model.ItemLot, *dto.Pagination, error) {
+	if withinDays < 0 {
+		return nil, nil, errors.New("within must not be negative")
+	}
+
+	year, month, day := time.Now().Date()
+	before := time.Date(year, month, day+withinDays, 0, 0, 0, 0, time.UTC)
+
+	lots, total, err := s.Repo.ItemLotRepo.FindExpiring(before, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return &lots, &pagination, nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockItemLotRepository mocks ItemLotRepository interface
+type MockItemLotRepository struct {
+	mock.Mock
+}
+
+func (m *MockItemLotRepository) FindByItemID(itemID int) ([]model.ItemLot, error) {
+	args := m.Called(itemID)
+	return args.Get(0).([]model.ItemLot), args.Error(1)
+}
+
+func (m *MockItemLotRepository) FindByLotNumber(itemID int, lotNumber string) (*model.ItemLot, error) {
+	args := m.Called(itemID, lotNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ItemLot), args.Error(1)
+}
+
+func (m *MockItemLotRepository) FindExpiring(before time.Time, page, limit int) ([]model.ItemLot, int, error) {
+	args := m.Called(before, page, limit)
+	return args.Get(0).([]model.ItemLot), args.Int(1), args.Error(2)
+}
+
+func TestItemLotService_GetExpiringLots(t *testing.T) {
+	mockLotRepo := new(MockItemLotRepository)
+	service := NewItemLotService(repository.Repository{ItemLotRepo: mockLotRepo})
+
+	year, month, day := time.Now().Date()
+	before := time.Date(year, month, day+30, 0, 0, 0, 0, time.UTC)
+	mockLotRepo.On("FindExpiring", before, 1, 10).Return([]model.ItemLot{{ID: 1, LotNumber: "LOT-A", Quantity: 4}}, 11, nil)
+
+	lots, pagination, err := service.GetExpiringLots(30, 1, 10)
+
+	require.NoError(t, err)
+	require.Len(t, *lots, 1)
+	require.Equal(t, 2, pagination.TotalPages)
+	mockLotRepo.AssertExpectations(t)
+}
+
+func TestItemLotService_GetItemLots_NotTracked(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	service := NewItemLotService(repository.Repository{ItemRepo: mockItemRepo, ItemLotRepo: new(MockItemLotRepository)})
+
+	mockItemRepo.On("FindByID", 3).Return(&model.Item{ID: 3}, nil)
+
+	_, err := service.GetItemLots(3)
+
+	require.Error(t, err)
+	require.Equal(t, "item does not track lots", err.Error())
+}
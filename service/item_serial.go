@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+)
+
+type ItemSerialService interface {
+	GetSerial(serialNumber string) (*model.ItemSerial, []model.SerialEvent, error)
+	GetItemSerials(itemID int, status string, page, limit int) (*[]model.ItemSerial, *dto.Pagination, error)
+}
+
+type itemSerialService struct {
+	Repo repository.Repository
+}
+
+func NewItemSerialService(repo repository.Repository) ItemSerialService {
+	return &itemSerialService{Repo: repo}
+}
+
+// GetSerial returns a serial with its lifecycle: where it was received, moved,
+// sold and returned
+func (s *itemSerialService) GetSerial(serialNumber string) (*model.ItemSerial, []model.SerialEvent, error) {
+	serial, err := s.Repo.ItemSerialRepo.FindBySerialNumber(serialNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	if serial == nil {
+		return nil, nil, errors.New("serial number not found")
+	}
+
+	events, err := s.Repo.ItemSerialRepo.FindEvents(serial.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return serial, events, nil
+}
+
+func (s *itemSerialService) GetItemSerials(itemID int, status string, page, limit int) (*[]model.ItemSerial, *dto.Pagination, error) {
+	switch status {
+	case "", model.SerialStatusInStock, model.SerialStatusInTransit, model.SerialStatusSold,
+		model.SerialStatusDamaged, model.SerialStatusRemoved:
+	default:
+		return nil, nil, errors.New("invalid serial status")
+	}
+
+	// Check if item exists
+	item, err := s.Repo.ItemRepo.FindByID(itemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item == nil {
+		return nil, nil, errors.New("item not found")
+	}
+	if !item.Serialized {
+		return nil, nil, errors.New("item is not serialized")
+	}
+
+	serials, total, err := s.Repo.ItemSerialRepo.FindByItemID(itemID, status, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return &serials, &pagination, nil
+}
+
+// checkSerials checks the serial numbers sent for quantity units of an item.
+// Serialized items need exactly one distinct serial per unit, other items none.
+func checkSerials(item *model.Item, serialNumbers []string, quantity int) ([]string, error) {
+	if !item.Serialized {
+		if len(serialNumbers) > 0 {
+			return nil, errors.New("item is not serialized: " + item.Name)
+		}
+		return nil, nil
+	}
+	if len(serialNumbers) != quantity {
+		return nil, errors.New("serial_numbers must list one serial per unit for serialized item: " + item.Name)
+	}
+
+	seen := make(map[string]bool)
+	for _, serialNumber := range serialNumbers {
+		if serialNumber == "" {
+			return nil, errors.New("serial number must not be empty")
+		}
+		if seen[serialNumber] {
+			return nil, errors.New("serial number listed twice: " + serialNumber)
+		}
+		seen[serialNumber] = true
+	}
+	return serialNumbers, nil
+}
+
+// serialError turns the repository serial errors into messages for the client
+func serialError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrSerialNotInStock):
+		return errors.New("serial numbers are not in stock in the rack")
+	case errors.Is(err, repository.ErrSerialConflict):
+		return errors.New("serial numbers are already in stock or belong to another item")
+	case errors.Is(err, repository.ErrSerialNotSold):
+		return errors.New("serial numbers were not sold")
+	}
+	return err
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockItemSerialRepository mocks ItemSerialRepository interface
+type MockItemSerialRepository struct {
+	mock.Mock
+}
+
+func (m *MockItemSerialRepository) FindBySerialNumber(serialNumber string) (*model.ItemSerial, error) {
+	args := m.Called(serialNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ItemSerial), args.Error(1)
+}
+
+func (m *MockItemSerialRepository) FindBySerialNumbers(itemID int, serialNumbers []string) ([]model.ItemSerial, error) {
+	args := m.Called(itemID, serialNumbers)
+	return args.Get(0).([]model.ItemSerial), args.Error(1)
+}
+
+func (m *MockItemSerialRepository) FindByItemID(itemID int, status string, page, limit int) ([]model.ItemSerial, int, error) {
+	args := m.Called(itemID, status, page, limit)
+	return args.Get(0).([]model.ItemSerial), args.Int(1), args.Error(2)
+}
+
+func (m *MockItemSerialRepository) FindEvents(serialID int) ([]model.SerialEvent, error) {
+	args := m.Called(serialID)
+	return args.Get(0).([]model.SerialEvent), args.Error(1)
+}
+
+func TestItemSerialService_GetSerial(t *testing.T) {
+	mockSerialRepo := new(MockItemSerialRepository)
+	service := NewItemSerialService(repository.Repository{ItemSerialRepo: mockSerialRepo})
+
+	mockSerialRepo.On("FindBySerialNumber", "SN-1").Return(&model.ItemSerial{ID: 4, SerialNumber: "SN-1", Status: model.SerialStatusSold}, nil)
+	mockSerialRepo.On("FindEvents", 4).Return([]model.SerialEvent{
+		{ID: 1, SerialID: 4, EventType: model.MovementTypeReceipt},
+		{ID: 2, SerialID: 4, EventType: model.MovementTypeSale},
+	}, nil)
+	mockSerialRepo.On("FindBySerialNumber", "SN-X").Return(nil, nil)
+
+	serial, events, err := service.GetSerial("SN-1")
+	require.NoError(t, err)
+	require.Equal(t, model.SerialStatusSold, serial.Status)
+	require.Len(t, events, 2)
+
+	_, _, err = service.GetSerial("SN-X")
+	require.Error(t, err)
+	require.Equal(t, "serial number not found", err.Error())
+}
+
+func TestCheckSerials(t *testing.T) {
+	plain := &model.Item{ID: 1, Name: "Cable"}
+	serialized := &model.Item{ID: 2, Name: "Laptop", Serialized: true}
+
+	_, err := checkSerials(plain, []string{"SN-1"}, 1)
+	require.EqualError(t, err, "item is not serialized: Cable")
+
+	_, err = checkSerials(serialized, nil, 1)
+	require.EqualError(t, err, "serial_numbers must list one serial per unit for serialized item: Laptop")
+
+	_, err = checkSerials(serialized, []string{"SN-1", "SN-1"}, 2)
+	require.EqualError(t, err, "serial number listed twice: SN-1")
+
+	serials, err := checkSerials(serialized, []string{"SN-1", "SN-2"}, 2)
+	require.NoError(t, err)
+	require.Len(t, serials, 2)
+}
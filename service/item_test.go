@@ -2,7 +2,9 @@ package service
 
 import (
 	"errors"
+	"project-app-inventory/dto"
 	"project-app-inventory/model"
+	"project-app-inventory/money"
 	"project-app-inventory/repository"
 	"testing"
 
@@ -15,8 +17,8 @@ type MockItemRepository struct {
 	mock.Mock
 }
 
-func (m *MockItemRepository) Create(item *model.Item) error {
-	args := m.Called(item)
+func (m *MockItemRepository) Create(item *model.Item, userID int) error {
+	args := m.Called(item, userID)
 	return args.Error(0)
 }
 
@@ -41,11 +43,36 @@ func (m *MockItemRepository) FindAll(page, limit int) ([]model.Item, int, error)
 	return args.Get(0).([]model.Item), args.Int(1), args.Error(2)
 }
 
-func (m *MockItemRepository) FindLowStock(page, limit int) ([]model.Item, int, error) {
-	args := m.Called(page, limit)
+func (m *MockItemRepository) FindAfter(afterID, limit int) ([]model.Item, error) {
+	args := m.Called(afterID, limit)
+	return args.Get(0).([]model.Item), args.Error(1)
+}
+
+func (m *MockItemRepository) FindLowStock(page, limit int, useAvailable bool) ([]model.Item, int, error) {
+	args := m.Called(page, limit, useAvailable)
 	return args.Get(0).([]model.Item), args.Int(1), args.Error(2)
 }
 
+func (m *MockItemRepository) UpdateABCClasses(items []model.ABCItem) error {
+	args := m.Called(items)
+	return args.Error(0)
+}
+
+func (m *MockItemRepository) FindReorderSuggestions(params model.ReorderParams, itemIDs []int, page, limit int) ([]model.ReorderSuggestion, int, error) {
+	args := m.Called(params, itemIDs, page, limit)
+	return args.Get(0).([]model.ReorderSuggestion), args.Int(1), args.Error(2)
+}
+
+func (m *MockItemRepository) UpdateMinimumStocks(suggestions []model.ReorderSuggestion) error {
+	args := m.Called(suggestions)
+	return args.Error(0)
+}
+
+func (m *MockItemRepository) Import(rows []model.ItemImport, userID int, partial, dryRun bool) error {
+	args := m.Called(rows, userID, partial, dryRun)
+	return args.Error(0)
+}
+
 func (m *MockItemRepository) Update(id int, item *model.Item) error {
 	args := m.Called(id, item)
 	return args.Error(0)
@@ -67,18 +94,42 @@ func TestItemService_Create_Success(t *testing.T) {
 		Name:       "Test Item",
 		CategoryID: 1,
 		Stock:      100,
-		Price:      10000,
+		Price:      money.FromUnits(10000),
 	}
 
 	mockItemRepo.On("FindBySKU", item.SKU).Return((*model.Item)(nil), nil)
-	mockItemRepo.On("Create", item).Return(nil)
+	mockItemRepo.On("Create", item, 1).Return(nil)
 
-	err := service.Create(item)
+	err := service.Create(item, 1)
 
 	require.NoError(t, err)
 	mockItemRepo.AssertExpectations(t)
 }
 
+// TestItemService_Create_LotTrackedWithStock tests lot tracked items can't get opening stock without a lot
+func TestItemService_Create_LotTrackedWithStock(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	service := NewItemService(repository.Repository{ItemRepo: mockItemRepo})
+
+	err := service.Create(&model.Item{SKU: "MILK-1L", Stock: 10, TrackLots: true}, 1)
+
+	require.Error(t, err)
+	require.Equal(t, "lot tracked items must start with zero stock", err.Error())
+	mockItemRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestItemService_Create_SerializedWithStock tests serialized items can't get opening stock without serials
+func TestItemService_Create_SerializedWithStock(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	service := NewItemService(repository.Repository{ItemRepo: mockItemRepo})
+
+	err := service.Create(&model.Item{SKU: "LAP-001", Stock: 2, Serialized: true}, 1)
+
+	require.Error(t, err)
+	require.Equal(t, "serialized items must start with zero stock", err.Error())
+	mockItemRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
 // TestItemService_Create_SKUExists tests creation with existing SKU
 func TestItemService_Create_SKUExists(t *testing.T) {
 	mockItemRepo := new(MockItemRepository)
@@ -90,7 +141,7 @@ func TestItemService_Create_SKUExists(t *testing.T) {
 
 	mockItemRepo.On("FindBySKU", item.SKU).Return(existingItem, nil)
 
-	err := service.Create(item)
+	err := service.Create(item, 1)
 
 	require.Error(t, err)
 	require.Equal(t, "SKU already exists", err.Error())
@@ -107,7 +158,7 @@ func TestItemService_Create_CheckError(t *testing.T) {
 
 	mockItemRepo.On("FindBySKU", item.SKU).Return((*model.Item)(nil), errors.New("db error"))
 
-	err := service.Create(item)
+	err := service.Create(item, 1)
 
 	require.Error(t, err)
 	require.Equal(t, "failed to check SKU", err.Error())
@@ -165,9 +216,9 @@ func TestItemService_GetLowStockItems_Success(t *testing.T) {
 		{ID: 1, Name: "Low Stock Item", Stock: 3},
 	}
 
-	mockItemRepo.On("FindLowStock", 1, 10).Return(items, 1, nil)
+	mockItemRepo.On("FindLowStock", 1, 10, true).Return(items, 1, nil)
 
-	result, pagination, err := service.GetLowStockItems(1, 10)
+	result, pagination, err := service.GetLowStockItems(1, 10, true)
 
 	require.NoError(t, err)
 	require.NotNil(t, result)
@@ -222,13 +273,13 @@ func TestItemService_Update_Success(t *testing.T) {
 		Name:       "Old Name",
 		CategoryID: 1,
 		Stock:      50,
-		Price:      10000,
+		Price:      money.FromUnits(10000),
 	}
 
 	updateData := &model.Item{
 		Name:  "New Name",
 		Stock: 100,
-		Price: 15000,
+		Price: money.FromUnits(15000),
 	}
 
 	mockItemRepo.On("FindByID", 1).Return(existingItem, nil)
@@ -238,6 +289,7 @@ func TestItemService_Update_Success(t *testing.T) {
 
 	require.NoError(t, err)
 	require.Equal(t, "SKU001", updateData.SKU) // Should keep existing SKU
+	require.Equal(t, 50, updateData.Stock)     // Stock can't be overwritten by update
 	mockItemRepo.AssertExpectations(t)
 }
 
@@ -289,3 +341,73 @@ func TestItemService_Delete_NotFound(t *testing.T) {
 	require.Equal(t, "item not found", err.Error())
 	mockItemRepo.AssertExpectations(t)
 }
+
+// TestItemService_GetReorderSuggestions_Defaults tests the default days are used and the daily average is worked out
+func TestItemService_GetReorderSuggestions_Defaults(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	service := NewItemService(repository.Repository{ItemRepo: mockItemRepo})
+
+	params := model.ReorderParams{SalesDays: 90, SafetyDays: 7, CoverDays: 30, LeadTimeDays: 7}
+	mockItemRepo.On("FindReorderSuggestions", params, []int(nil), 1, 10).
+		Return([]model.ReorderSuggestion{{ItemID: 1, UnitsSold: 200, ReorderPoint: 32}}, 1, nil)
+
+	suggestions, pagination, err := service.GetReorderSuggestions(dto.ReorderParamsRequest{}, 1, 10)
+
+	require.NoError(t, err)
+	require.Equal(t, 2.22, (*suggestions)[0].AverageDailySales)
+	require.Equal(t, 1, pagination.TotalRecords)
+	mockItemRepo.AssertExpectations(t)
+}
+
+// TestItemService_GetReorderSuggestions_ZeroDays tests a 0 given for a param is kept rather than defaulted
+func TestItemService_GetReorderSuggestions_ZeroDays(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	service := NewItemService(repository.Repository{ItemRepo: mockItemRepo})
+
+	params := model.ReorderParams{SalesDays: 90, SafetyDays: 0, CoverDays: 30, LeadTimeDays: 7}
+	mockItemRepo.On("FindReorderSuggestions", params, []int(nil), 1, 10).Return([]model.ReorderSuggestion(nil), 0, nil)
+
+	safetyDays := 0
+	_, _, err := service.GetReorderSuggestions(dto.ReorderParamsRequest{SafetyDays: &safetyDays}, 1, 10)
+	require.NoError(t, err)
+	mockItemRepo.AssertExpectations(t)
+
+	salesDays := 0
+	_, _, err = service.GetReorderSuggestions(dto.ReorderParamsRequest{SalesDays: &salesDays}, 1, 10)
+	require.EqualError(t, err, "days must be at least 1")
+}
+
+// TestItemService_AcceptReorderSuggestions tests the suggested reorder points become the minimum stock
+func TestItemService_AcceptReorderSuggestions(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	service := NewItemService(repository.Repository{ItemRepo: mockItemRepo})
+
+	params := model.ReorderParams{SalesDays: 30, SafetyDays: 7, CoverDays: 30, LeadTimeDays: 14}
+	suggestions := []model.ReorderSuggestion{{ItemID: 4, MinimumStock: 5, UnitsSold: 60, ReorderPoint: 42}}
+	mockItemRepo.On("FindReorderSuggestions", params, []int{4, 9}, 1, 2).Return(suggestions, 1, nil)
+	mockItemRepo.On("UpdateMinimumStocks", mock.MatchedBy(func(accepted []model.ReorderSuggestion) bool {
+		return len(accepted) == 1 && accepted[0].ItemID == 4 && accepted[0].ReorderPoint == 42
+	})).Return(nil)
+
+	salesDays, leadTimeDays := 30, 14
+	accepted, err := service.AcceptReorderSuggestions(dto.ReorderParamsRequest{SalesDays: &salesDays, LeadTimeDays: &leadTimeDays}, []int{4, 9})
+
+	require.NoError(t, err)
+	require.Len(t, accepted, 1)
+	require.Equal(t, 2.0, accepted[0].AverageDailySales)
+	mockItemRepo.AssertExpectations(t)
+}
+
+// TestItemService_AcceptReorderSuggestions_NothingToChange tests items already at their suggestion are not updated
+func TestItemService_AcceptReorderSuggestions_NothingToChange(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	service := NewItemService(repository.Repository{ItemRepo: mockItemRepo})
+
+	mockItemRepo.On("FindReorderSuggestions", mock.Anything, []int{4}, 1, 1).Return([]model.ReorderSuggestion(nil), 0, nil)
+
+	accepted, err := service.AcceptReorderSuggestions(dto.ReorderParamsRequest{}, []int{4})
+
+	require.NoError(t, err)
+	require.Empty(t, accepted)
+	mockItemRepo.AssertNotCalled(t, "UpdateMinimumStocks", mock.Anything)
+}
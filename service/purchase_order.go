@@ -0,0 +1,282 @@
+package service
+
+import (
+	"errors"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+	"strconv"
+)
+
+type PurchaseOrderService interface {
+	Create(userID int, req dto.PurchaseOrderRequest) (*model.PurchaseOrder, []model.PurchaseOrderItem, error)
+	CreateFromLowStock(userID int, req dto.LowStockPurchaseOrderRequest) (*model.PurchaseOrder, []model.PurchaseOrderItem, error)
+	GetAllPurchaseOrders(status string, supplierID, page, limit int) (*[]model.PurchaseOrder, *dto.Pagination, error)
+	GetPurchaseOrderByID(id int) (*model.PurchaseOrder, []model.PurchaseOrderItem, error)
+	Update(id int, req dto.PurchaseOrderRequest) (*model.PurchaseOrder, []model.PurchaseOrderItem, error)
+	Approve(id int, userID int) error
+	Cancel(id int) error
+}
+
+type purchaseOrderService struct {
+	Repo repository.Repository
+}
+
+func NewPurchaseOrderService(repo repository.Repository) PurchaseOrderService {
+	return &purchaseOrderService{Repo: repo}
+}
+
+// lowStockPageSize is how many low stock items are fetched per page when
+// raising a purchase order for every item below its minimum
+const lowStockPageSize = 100
+
+func (s *purchaseOrderService) Create(userID int, req dto.PurchaseOrderRequest) (*model.PurchaseOrder, []model.PurchaseOrderItem, error) {
+	if err := s.checkSupplierExists(req.SupplierID); err != nil {
+		return nil, nil, err
+	}
+
+	items, totalAmount, err := s.buildOrderItems(req.Items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	order := &model.PurchaseOrder{
+		SupplierID:  req.SupplierID,
+		TotalAmount: totalAmount,
+		CreatedBy:   userID,
+	}
+	if req.Note != "" {
+		note := req.Note
+		order.Note = &note
+	}
+
+	err = s.Repo.PurchaseOrderRepo.Create(order, items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return order, items, nil
+}
+
+// CreateFromLowStock raises a draft purchase order that tops every selected
+// low stock item back up to its minimum stock. Unit costs start at zero and
+// must be filled in through Update before the order can be approved.
+func (s *purchaseOrderService) CreateFromLowStock(userID int, req dto.LowStockPurchaseOrderRequest) (*model.PurchaseOrder, []model.PurchaseOrderItem, error) {
+	if err := s.checkSupplierExists(req.SupplierID); err != nil {
+		return nil, nil, err
+	}
+
+	var lowStock []model.Item
+	if len(req.ItemIDs) > 0 {
+		seen := make(map[int]bool)
+		for _, itemID := range req.ItemIDs {
+			if seen[itemID] {
+				continue
+			}
+			seen[itemID] = true
+
+			item, err := s.Repo.ItemRepo.FindByID(itemID)
+			if err != nil {
+				return nil, nil, err
+			}
+			if item == nil {
+				return nil, nil, errors.New("item not found: " + strconv.Itoa(itemID))
+			}
+			if item.Stock >= item.MinimumStock {
+				return nil, nil, errors.New("item is not low on stock: " + strconv.Itoa(itemID))
+			}
+			lowStock = append(lowStock, *item)
+		}
+	} else {
+		for page := 1; ; page++ {
+			items, total, err := s.Repo.ItemRepo.FindLowStock(page, lowStockPageSize, false)
+			if err != nil {
+				return nil, nil, err
+			}
+			lowStock = append(lowStock, items...)
+			if len(items) == 0 || len(lowStock) >= total {
+				break
+			}
+		}
+	}
+
+	if len(lowStock) == 0 {
+		return nil, nil, errors.New("no low stock items to order")
+	}
+
+	var lines []dto.PurchaseOrderItemRequest
+	for _, item := range lowStock {
+		lines = append(lines, dto.PurchaseOrderItemRequest{
+			ItemID:   item.ID,
+			Quantity: item.MinimumStock - item.Stock,
+		})
+	}
+
+	return s.Create(userID, dto.PurchaseOrderRequest{
+		SupplierID: req.SupplierID,
+		Note:       req.Note,
+		Items:      lines,
+	})
+}
+
+func (s *purchaseOrderService) GetAllPurchaseOrders(status string, supplierID, page, limit int) (*[]model.PurchaseOrder, *dto.Pagination, error) {
+	switch status {
+	case "", model.PurchaseOrderStatusDraft, model.PurchaseOrderStatusApproved,
+		model.PurchaseOrderStatusPartiallyReceived, model.PurchaseOrderStatusReceived, model.PurchaseOrderStatusCancelled:
+	default:
+		return nil, nil, errors.New("invalid purchase order status")
+	}
+
+	orders, total, err := s.Repo.PurchaseOrderRepo.FindAll(status, supplierID, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return &orders, &pagination, nil
+}
+
+func (s *purchaseOrderService) GetPurchaseOrderByID(id int) (*model.PurchaseOrder, []model.PurchaseOrderItem, error) {
+	order, err := s.findOrder(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, err := s.Repo.PurchaseOrderRepo.FindOrderItems(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return order, items, nil
+}
+
+func (s *purchaseOrderService) Update(id int, req dto.PurchaseOrderRequest) (*model.PurchaseOrder, []model.PurchaseOrderItem, error) {
+	order, err := s.findOrder(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if order.Status != model.PurchaseOrderStatusDraft {
+		return nil, nil, errors.New("only draft purchase orders can be updated")
+	}
+
+	if err := s.checkSupplierExists(req.SupplierID); err != nil {
+		return nil, nil, err
+	}
+
+	items, totalAmount, err := s.buildOrderItems(req.Items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	order.SupplierID = req.SupplierID
+	order.TotalAmount = totalAmount
+	order.Note = nil
+	if req.Note != "" {
+		note := req.Note
+		order.Note = &note
+	}
+
+	err = s.Repo.PurchaseOrderRepo.Update(id, order, items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return order, items, nil
+}
+
+func (s *purchaseOrderService) Approve(id int, userID int) error {
+	order, err := s.findOrder(id)
+	if err != nil {
+		return err
+	}
+	if order.Status != model.PurchaseOrderStatusDraft {
+		return errors.New("only draft purchase orders can be approved")
+	}
+
+	// Orders raised from the low stock list start without costs
+	items, err := s.Repo.PurchaseOrderRepo.FindOrderItems(id)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if item.UnitCost <= 0 {
+			return errors.New("unit cost must be set for item: " + strconv.Itoa(item.ItemID))
+		}
+	}
+
+	return s.Repo.PurchaseOrderRepo.Approve(id, userID)
+}
+
+func (s *purchaseOrderService) Cancel(id int) error {
+	// Check if purchase order exists
+	if _, err := s.findOrder(id); err != nil {
+		return err
+	}
+
+	return s.Repo.PurchaseOrderRepo.Cancel(id)
+}
+
+// buildOrderItems validates the requested lines and computes their subtotals
+func (s *purchaseOrderService) buildOrderItems(lines []dto.PurchaseOrderItemRequest) ([]model.PurchaseOrderItem, money.Amount, error) {
+	if len(lines) == 0 {
+		return nil, 0, errors.New("purchase order must have at least one item")
+	}
+
+	var items []model.PurchaseOrderItem
+	var totalAmount money.Amount
+	seen := make(map[int]bool)
+	for _, line := range lines {
+		if seen[line.ItemID] {
+			return nil, 0, errors.New("duplicate item in purchase order: " + strconv.Itoa(line.ItemID))
+		}
+		seen[line.ItemID] = true
+
+		item, err := s.Repo.ItemRepo.FindByID(line.ItemID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if item == nil {
+			return nil, 0, errors.New("item not found: " + strconv.Itoa(line.ItemID))
+		}
+
+		subtotal := line.UnitCost.Mul(line.Quantity)
+		items = append(items, model.PurchaseOrderItem{
+			ItemID:          line.ItemID,
+			QuantityOrdered: line.Quantity,
+			UnitCost:        line.UnitCost,
+			Subtotal:        subtotal,
+		})
+		totalAmount += subtotal
+	}
+
+	return items, totalAmount, nil
+}
+
+func (s *purchaseOrderService) findOrder(id int) (*model.PurchaseOrder, error) {
+	order, err := s.Repo.PurchaseOrderRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.New("purchase order not found")
+	}
+	return order, nil
+}
+
+func (s *purchaseOrderService) checkSupplierExists(id int) error {
+	supplier, err := s.Repo.SupplierRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if supplier == nil {
+		return errors.New("supplier not found")
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"project-app-inventory/repository"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPurchaseOrderRepository mocks PurchaseOrderRepository interface
+type MockPurchaseOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockPurchaseOrderRepository) Create(order *model.PurchaseOrder, items []model.PurchaseOrderItem) error {
+	args := m.Called(order, items)
+	return args.Error(0)
+}
+
+func (m *MockPurchaseOrderRepository) FindByID(id int) (*model.PurchaseOrder, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PurchaseOrder), args.Error(1)
+}
+
+func (m *MockPurchaseOrderRepository) FindOrderItems(orderID int) ([]model.PurchaseOrderItem, error) {
+	args := m.Called(orderID)
+	return args.Get(0).([]model.PurchaseOrderItem), args.Error(1)
+}
+
+func (m *MockPurchaseOrderRepository) FindAll(status string, supplierID, page, limit int) ([]model.PurchaseOrder, int, error) {
+	args := m.Called(status, supplierID, page, limit)
+	return args.Get(0).([]model.PurchaseOrder), args.Int(1), args.Error(2)
+}
+
+func (m *MockPurchaseOrderRepository) Update(id int, order *model.PurchaseOrder, items []model.PurchaseOrderItem) error {
+	args := m.Called(id, order, items)
+	return args.Error(0)
+}
+
+func (m *MockPurchaseOrderRepository) Approve(id int, userID int) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *MockPurchaseOrderRepository) Cancel(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// TestPurchaseOrderService_Create_Success tests totals computed from the lines
+func TestPurchaseOrderService_Create_Success(t *testing.T) {
+	mockSupplierRepo := new(MockSupplierRepository)
+	mockItemRepo := new(MockItemRepository)
+	mockOrderRepo := new(MockPurchaseOrderRepository)
+	repo := repository.Repository{
+		SupplierRepo:      mockSupplierRepo,
+		ItemRepo:          mockItemRepo,
+		PurchaseOrderRepo: mockOrderRepo,
+	}
+	service := NewPurchaseOrderService(repo)
+
+	req := dto.PurchaseOrderRequest{
+		SupplierID: 1,
+		Items: []dto.PurchaseOrderItemRequest{
+			{ItemID: 3, Quantity: 10, UnitCost: money.FromUnits(500000)},
+			{ItemID: 5, Quantity: 2, UnitCost: money.FromUnits(2000000)},
+		},
+	}
+
+	mockSupplierRepo.On("FindByID", 1).Return(&model.Supplier{ID: 1}, nil)
+	mockItemRepo.On("FindByID", 3).Return(&model.Item{ID: 3}, nil)
+	mockItemRepo.On("FindByID", 5).Return(&model.Item{ID: 5}, nil)
+	mockOrderRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	order, items, err := service.Create(2, req)
+
+	require.NoError(t, err)
+	require.Equal(t, money.FromUnits(9000000), order.TotalAmount)
+	require.Equal(t, money.FromUnits(5000000), items[0].Subtotal)
+	mockOrderRepo.AssertExpectations(t)
+}
+
+// TestPurchaseOrderService_Create_DuplicateItem tests rejecting the same item twice
+func TestPurchaseOrderService_Create_DuplicateItem(t *testing.T) {
+	mockSupplierRepo := new(MockSupplierRepository)
+	mockItemRepo := new(MockItemRepository)
+	mockOrderRepo := new(MockPurchaseOrderRepository)
+	repo := repository.Repository{
+		SupplierRepo:      mockSupplierRepo,
+		ItemRepo:          mockItemRepo,
+		PurchaseOrderRepo: mockOrderRepo,
+	}
+	service := NewPurchaseOrderService(repo)
+
+	req := dto.PurchaseOrderRequest{
+		SupplierID: 1,
+		Items: []dto.PurchaseOrderItemRequest{
+			{ItemID: 3, Quantity: 1, UnitCost: money.FromUnits(10)},
+			{ItemID: 3, Quantity: 1, UnitCost: money.FromUnits(10)},
+		},
+	}
+
+	mockSupplierRepo.On("FindByID", 1).Return(&model.Supplier{ID: 1}, nil)
+	mockItemRepo.On("FindByID", 3).Return(&model.Item{ID: 3}, nil)
+
+	_, _, err := service.Create(2, req)
+
+	require.Error(t, err)
+	mockOrderRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestPurchaseOrderService_CreateFromLowStock_AllItems tests ordering every low stock item
+func TestPurchaseOrderService_CreateFromLowStock_AllItems(t *testing.T) {
+	mockSupplierRepo := new(MockSupplierRepository)
+	mockItemRepo := new(MockItemRepository)
+	mockOrderRepo := new(MockPurchaseOrderRepository)
+	repo := repository.Repository{
+		SupplierRepo:      mockSupplierRepo,
+		ItemRepo:          mockItemRepo,
+		PurchaseOrderRepo: mockOrderRepo,
+	}
+	service := NewPurchaseOrderService(repo)
+
+	lowStock := []model.Item{
+		{ID: 3, Stock: 3, MinimumStock: 5},
+		{ID: 8, Stock: 4, MinimumStock: 10},
+	}
+
+	mockSupplierRepo.On("FindByID", 1).Return(&model.Supplier{ID: 1}, nil)
+	mockItemRepo.On("FindLowStock", 1, lowStockPageSize, false).Return(lowStock, 2, nil)
+	mockItemRepo.On("FindByID", 3).Return(&lowStock[0], nil)
+	mockItemRepo.On("FindByID", 8).Return(&lowStock[1], nil)
+	mockOrderRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	order, items, err := service.CreateFromLowStock(2, dto.LowStockPurchaseOrderRequest{SupplierID: 1})
+
+	require.NoError(t, err)
+	require.Equal(t, 2, order.CreatedBy)
+	require.Len(t, items, 2)
+	require.Equal(t, 2, items[0].QuantityOrdered)
+	require.Equal(t, 6, items[1].QuantityOrdered)
+}
+
+// TestPurchaseOrderService_CreateFromLowStock_NotLow tests picking an item that is not low on stock
+func TestPurchaseOrderService_CreateFromLowStock_NotLow(t *testing.T) {
+	mockSupplierRepo := new(MockSupplierRepository)
+	mockItemRepo := new(MockItemRepository)
+	repo := repository.Repository{
+		SupplierRepo: mockSupplierRepo,
+		ItemRepo:     mockItemRepo,
+	}
+	service := NewPurchaseOrderService(repo)
+
+	mockSupplierRepo.On("FindByID", 1).Return(&model.Supplier{ID: 1}, nil)
+	mockItemRepo.On("FindByID", 1).Return(&model.Item{ID: 1, Stock: 50, MinimumStock: 10}, nil)
+
+	_, _, err := service.CreateFromLowStock(2, dto.LowStockPurchaseOrderRequest{SupplierID: 1, ItemIDs: []int{1}})
+
+	require.Error(t, err)
+	require.Equal(t, "item is not low on stock: 1", err.Error())
+}
+
+// TestPurchaseOrderService_Approve_MissingCost tests approval blocked by lines without unit cost
+func TestPurchaseOrderService_Approve_MissingCost(t *testing.T) {
+	mockOrderRepo := new(MockPurchaseOrderRepository)
+	service := NewPurchaseOrderService(repository.Repository{PurchaseOrderRepo: mockOrderRepo})
+
+	mockOrderRepo.On("FindByID", 7).Return(&model.PurchaseOrder{ID: 7, Status: model.PurchaseOrderStatusDraft}, nil)
+	mockOrderRepo.On("FindOrderItems", 7).Return([]model.PurchaseOrderItem{{ItemID: 3, QuantityOrdered: 2}}, nil)
+
+	err := service.Approve(7, 2)
+
+	require.Error(t, err)
+	require.Equal(t, "unit cost must be set for item: 3", err.Error())
+	mockOrderRepo.AssertNotCalled(t, "Approve", 7, 2)
+}
@@ -12,6 +12,7 @@ type RackService interface {
 	Create(rack *model.Rack) error
 	GetAllRacks(page, limit int) (*[]model.Rack, *dto.Pagination, error)
 	GetRacksByWarehouse(warehouseID, page, limit int) (*[]model.Rack, *dto.Pagination, error)
+	GetRacksAfter(warehouseID, afterID, limit int) ([]model.Rack, error)
 	GetRackByID(id int) (*model.Rack, error)
 	Update(id int, data *model.Rack) error
 	Delete(id int) error
@@ -68,6 +69,12 @@ func (s *rackService) GetRacksByWarehouse(warehouseID, page, limit int) (*[]mode
 	return &racks, &pagination, nil
 }
 
+// GetRacksAfter reads the racks of the warehouse, or of all when warehouseID
+// is 0, after afterID in id order, a page of an export
+func (s *rackService) GetRacksAfter(warehouseID, afterID, limit int) ([]model.Rack, error) {
+	return s.Repo.RackRepo.FindAfter(warehouseID, afterID, limit)
+}
+
 func (s *rackService) GetRackByID(id int) (*model.Rack, error) {
 	rack, err := s.Repo.RackRepo.FindByID(id)
 	if err != nil {
@@ -36,11 +36,21 @@ func (m *MockRackRepository) FindByWarehouseAndCode(warehouseID int, code string
 	return args.Get(0).(*model.Rack), args.Error(1)
 }
 
+func (m *MockRackRepository) FindByCode(code string) ([]model.Rack, error) {
+	args := m.Called(code)
+	return args.Get(0).([]model.Rack), args.Error(1)
+}
+
 func (m *MockRackRepository) FindAll(page, limit int) ([]model.Rack, int, error) {
 	args := m.Called(page, limit)
 	return args.Get(0).([]model.Rack), args.Int(1), args.Error(2)
 }
 
+func (m *MockRackRepository) FindAfter(warehouseID, afterID, limit int) ([]model.Rack, error) {
+	args := m.Called(warehouseID, afterID, limit)
+	return args.Get(0).([]model.Rack), args.Error(1)
+}
+
 func (m *MockRackRepository) FindByWarehouseID(warehouseID, page, limit int) ([]model.Rack, int, error) {
 	args := m.Called(warehouseID, page, limit)
 	return args.Get(0).([]model.Rack), args.Int(1), args.Error(2)
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"errors"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+	"strconv"
+	"time"
+)
+
+type ReceiptService interface {
+	Create(userID int, req dto.ReceiptRequest) (*model.Receipt, []model.ReceiptItem, error)
+	GetAllReceipts(status string, supplierID, page, limit int) (*[]model.Receipt, *dto.Pagination, error)
+	GetReceiptByID(id int) (*model.Receipt, []model.ReceiptItem, error)
+	Void(id int, userID int) error
+}
+
+type receiptService struct {
+	Repo repository.Repository
+}
+
+func NewReceiptService(repo repository.Repository) ReceiptService {
+	return &receiptService{Repo: repo}
+}
+
+func (s *receiptService) Create(userID int, req dto.ReceiptRequest) (*model.Receipt, []model.ReceiptItem, error) {
+	if len(req.Items) == 0 {
+		return nil, nil, errors.New("receipt must have at least one item")
+	}
+
+	// Check if supplier exists
+	supplier, err := s.Repo.SupplierRepo.FindByID(req.SupplierID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if supplier == nil {
+		return nil, nil, errors.New("supplier not found")
+	}
+
+	// A delivery note is booked once per supplier
+	existingReceipt, err := s.Repo.ReceiptRepo.FindByDeliveryNote(req.SupplierID, req.DeliveryNoteNumber)
+	if err != nil {
+		return nil, nil, errors.New("failed to check delivery note number")
+	}
+	if existingReceipt != nil {
+		return nil, nil, errors.New("delivery note number already received for this supplier")
+	}
+
+	// Lines received against a purchase order must be on that order
+	var orderItems map[int]bool
+	if req.PurchaseOrderID != 0 {
+		orderItems, err = s.openOrderItems(req.PurchaseOrderID, req.SupplierID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var items []model.ReceiptItem
+	var totalAmount money.Amount
+	lotExpiries := make(map[string]*time.Time)
+	received := make(map[string]bool)
+	for _, line := range req.Items {
+		item, err := s.Repo.ItemRepo.FindByID(line.ItemID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if item == nil {
+			return nil, nil, errors.New("item not found: " + strconv.Itoa(line.ItemID))
+		}
+		if orderItems != nil && !orderItems[line.ItemID] {
+			return nil, nil, errors.New("item is not on the purchase order: " + strconv.Itoa(line.ItemID))
+		}
+
+		rack, err := s.Repo.RackRepo.FindByID(line.RackID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if rack == nil {
+			return nil, nil, errors.New("rack not found: " + strconv.Itoa(line.RackID))
+		}
+
+		lotNumber, expiryDate, err := s.receivedLot(item, line, lotExpiries)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		serialNumbers, err := checkSerials(item, line.SerialNumbers, line.Quantity)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, serialNumber := range serialNumbers {
+			if received[serialNumber] {
+				return nil, nil, errors.New("serial number listed twice: " + serialNumber)
+			}
+			received[serialNumber] = true
+		}
+
+		subtotal := line.UnitCost.Mul(line.Quantity)
+		items = append(items, model.ReceiptItem{
+			ItemID:        line.ItemID,
+			RackID:        line.RackID,
+			Quantity:      line.Quantity,
+			UnitCost:      line.UnitCost,
+			Subtotal:      subtotal,
+			LotNumber:     lotNumber,
+			ExpiryDate:    expiryDate,
+			SerialNumbers: serialNumbers,
+		})
+		totalAmount += subtotal
+	}
+
+	receipt := &model.Receipt{
+		SupplierID:         req.SupplierID,
+		DeliveryNoteNumber: req.DeliveryNoteNumber,
+		TotalAmount:        totalAmount,
+		ReceivedBy:         userID,
+	}
+	if req.PurchaseOrderID != 0 {
+		orderID := req.PurchaseOrderID
+		receipt.PurchaseOrderID = &orderID
+	}
+	if req.Note != "" {
+		note := req.Note
+		receipt.Note = &note
+	}
+
+	err = s.Repo.ReceiptRepo.Create(receipt, items)
+	if errors.Is(err, repository.ErrOverReceipt) {
+		return nil, nil, errors.New("received quantity exceeds the outstanding purchase order quantity")
+	}
+	if err != nil {
+		return nil, nil, serialError(err)
+	}
+
+	return receipt, items, nil
+}
+
+// receivedLot checks the lot of a receipt line. Lot tracked items need a lot
+// number; a lot received before, or earlier on this receipt, keeps its expiry
+// date. seen holds the expiry of the lots already on this receipt.
+func (s *receiptService) receivedLot(item *model.Item, line dto.ReceiptItemRequest, seen map[string]*time.Time) (*string, *time.Time, error) {
+	if !item.TrackLots {
+		if line.LotNumber != "" || line.ExpiryDate != "" {
+			return nil, nil, errors.New("item does not track lots: " + item.Name)
+		}
+		return nil, nil, nil
+	}
+	if line.LotNumber == "" {
+		return nil, nil, errors.New("lot_number is required for lot tracked item: " + item.Name)
+	}
+
+	var expiryDate *time.Time
+	if line.ExpiryDate != "" {
+		date, err := time.Parse("2006-01-02", line.ExpiryDate)
+		if err != nil {
+			return nil, nil, errors.New("invalid expiry_date, use YYYY-MM-DD")
+		}
+		expiryDate = &date
+	}
+
+	key := strconv.Itoa(item.ID) + "/" + line.LotNumber
+	expected, ok := seen[key]
+	if !ok {
+		lot, err := s.Repo.ItemLotRepo.FindByLotNumber(item.ID, line.LotNumber)
+		if err != nil {
+			return nil, nil, err
+		}
+		if lot != nil {
+			expected, ok = lot.ExpiryDate, true
+		}
+	}
+	if ok && !sameDate(expected, expiryDate) {
+		return nil, nil, errors.New("lot " + line.LotNumber + " was received with another expiry date")
+	}
+	seen[key] = expiryDate
+
+	lotNumber := line.LotNumber
+	return &lotNumber, expiryDate, nil
+}
+
+func sameDate(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+// openOrderItems checks the purchase order can be received from the supplier
+// and returns the items ordered on it
+func (s *receiptService) openOrderItems(orderID, supplierID int) (map[int]bool, error) {
+	order, err := s.Repo.PurchaseOrderRepo.FindByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, errors.New("purchase order not found")
+	}
+	if order.SupplierID != supplierID {
+		return nil, errors.New("purchase order belongs to another supplier")
+	}
+	if order.Status != model.PurchaseOrderStatusApproved && order.Status != model.PurchaseOrderStatusPartiallyReceived {
+		return nil, errors.New("purchase order is not open for receiving")
+	}
+
+	lines, err := s.Repo.PurchaseOrderRepo.FindOrderItems(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	orderItems := make(map[int]bool)
+	for _, line := range lines {
+		orderItems[line.ItemID] = true
+	}
+	return orderItems, nil
+}
+
+func (s *receiptService) GetAllReceipts(status string, supplierID, page, limit int) (*[]model.Receipt, *dto.Pagination, error) {
+	switch status {
+	case "", model.ReceiptStatusReceived, model.ReceiptStatusVoided:
+	default:
+		return nil, nil, errors.New("invalid receipt status")
+	}
+
+	receipts, total, err := s.Repo.ReceiptRepo.FindAll(status, supplierID, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return &receipts, &pagination, nil
+}
+
+func (s *receiptService) GetReceiptByID(id int) (*model.Receipt, []model.ReceiptItem, error) {
+	receipt, err := s.Repo.ReceiptRepo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if receipt == nil {
+		return nil, nil, errors.New("receipt not found")
+	}
+
+	items, err := s.Repo.ReceiptRepo.FindReceiptItems(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return receipt, items, nil
+}
+
+func (s *receiptService) Void(id int, userID int) error {
+	// Check if receipt exists
+	receipt, err := s.Repo.ReceiptRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if receipt == nil {
+		return errors.New("receipt not found")
+	}
+	if receipt.Status == model.ReceiptStatusVoided {
+		return errors.New("receipt is already voided")
+	}
+
+	err = s.Repo.ReceiptRepo.Void(id, userID)
+	if errors.Is(err, repository.ErrInsufficientStock) {
+		return errors.New("not enough stock left in the rack to void receipt")
+	}
+	if errors.Is(err, repository.ErrInsufficientLotStock) {
+		return errors.New("not enough stock left in the lot to void receipt")
+	}
+	if errors.Is(err, repository.ErrSerialNotInStock) {
+		return errors.New("received serial numbers are no longer in stock in the rack")
+	}
+	return err
+}
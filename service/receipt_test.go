@@ -0,0 +1,294 @@
+package service
+
+import (
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"project-app-inventory/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReceiptRepository mocks ReceiptRepository interface
+type MockReceiptRepository struct {
+	mock.Mock
+}
+
+func (m *MockReceiptRepository) Create(receipt *model.Receipt, items []model.ReceiptItem) error {
+	args := m.Called(receipt, items)
+	return args.Error(0)
+}
+
+func (m *MockReceiptRepository) FindByID(id int) (*model.Receipt, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Receipt), args.Error(1)
+}
+
+func (m *MockReceiptRepository) FindByDeliveryNote(supplierID int, deliveryNoteNumber string) (*model.Receipt, error) {
+	args := m.Called(supplierID, deliveryNoteNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Receipt), args.Error(1)
+}
+
+func (m *MockReceiptRepository) FindReceiptItems(receiptID int) ([]model.ReceiptItem, error) {
+	args := m.Called(receiptID)
+	return args.Get(0).([]model.ReceiptItem), args.Error(1)
+}
+
+func (m *MockReceiptRepository) FindAll(status string, supplierID, page, limit int) ([]model.Receipt, int, error) {
+	args := m.Called(status, supplierID, page, limit)
+	return args.Get(0).([]model.Receipt), args.Int(1), args.Error(2)
+}
+
+func (m *MockReceiptRepository) Void(id int, userID int) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+// TestReceiptService_Create_Success tests booking a receipt without purchase order
+func TestReceiptService_Create_Success(t *testing.T) {
+	mockSupplierRepo := new(MockSupplierRepository)
+	mockItemRepo := new(MockItemRepository)
+	mockRackRepo := new(MockRackRepository)
+	mockReceiptRepo := new(MockReceiptRepository)
+	repo := repository.Repository{
+		SupplierRepo: mockSupplierRepo,
+		ItemRepo:     mockItemRepo,
+		RackRepo:     mockRackRepo,
+		ReceiptRepo:  mockReceiptRepo,
+	}
+	service := NewReceiptService(repo)
+
+	req := dto.ReceiptRequest{
+		SupplierID:         1,
+		DeliveryNoteNumber: "SJ-001",
+		Items:              []dto.ReceiptItemRequest{{ItemID: 3, RackID: 1, Quantity: 10, UnitCost: money.FromUnits(50000)}},
+	}
+
+	mockSupplierRepo.On("FindByID", 1).Return(&model.Supplier{ID: 1}, nil)
+	mockReceiptRepo.On("FindByDeliveryNote", 1, "SJ-001").Return(nil, nil)
+	mockItemRepo.On("FindByID", 3).Return(&model.Item{ID: 3}, nil)
+	mockRackRepo.On("FindByID", 1).Return(&model.Rack{ID: 1}, nil)
+	mockReceiptRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	receipt, items, err := service.Create(2, req)
+
+	require.NoError(t, err)
+	require.Equal(t, money.FromUnits(500000), receipt.TotalAmount)
+	require.Nil(t, receipt.PurchaseOrderID)
+	require.Len(t, items, 1)
+	mockReceiptRepo.AssertExpectations(t)
+}
+
+// TestReceiptService_Create_Lots tests lot tracked lines need a lot and keep the expiry of a known lot
+func TestReceiptService_Create_Lots(t *testing.T) {
+	mockSupplierRepo := new(MockSupplierRepository)
+	mockItemRepo := new(MockItemRepository)
+	mockRackRepo := new(MockRackRepository)
+	mockReceiptRepo := new(MockReceiptRepository)
+	mockLotRepo := new(MockItemLotRepository)
+	repo := repository.Repository{
+		SupplierRepo: mockSupplierRepo,
+		ItemRepo:     mockItemRepo,
+		RackRepo:     mockRackRepo,
+		ReceiptRepo:  mockReceiptRepo,
+		ItemLotRepo:  mockLotRepo,
+	}
+	service := NewReceiptService(repo)
+
+	expiry := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	mockSupplierRepo.On("FindByID", 1).Return(&model.Supplier{ID: 1}, nil)
+	mockReceiptRepo.On("FindByDeliveryNote", 1, "SJ-001").Return(nil, nil)
+	mockItemRepo.On("FindByID", 3).Return(&model.Item{ID: 3, Name: "Milk", TrackLots: true}, nil)
+	mockRackRepo.On("FindByID", 1).Return(&model.Rack{ID: 1}, nil)
+	mockLotRepo.On("FindByLotNumber", 3, "LOT-A").Return(&model.ItemLot{ItemID: 3, LotNumber: "LOT-A", ExpiryDate: &expiry}, nil)
+	mockLotRepo.On("FindByLotNumber", 3, "LOT-B").Return(nil, nil)
+	mockReceiptRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	_, _, err := service.Create(2, dto.ReceiptRequest{
+		SupplierID:         1,
+		DeliveryNoteNumber: "SJ-001",
+		Items:              []dto.ReceiptItemRequest{{ItemID: 3, RackID: 1, Quantity: 10}},
+	})
+	require.Error(t, err)
+	require.Equal(t, "lot_number is required for lot tracked item: Milk", err.Error())
+
+	_, _, err = service.Create(2, dto.ReceiptRequest{
+		SupplierID:         1,
+		DeliveryNoteNumber: "SJ-001",
+		Items:              []dto.ReceiptItemRequest{{ItemID: 3, RackID: 1, Quantity: 10, LotNumber: "LOT-A", ExpiryDate: "2026-12-31"}},
+	})
+	require.Error(t, err)
+	require.Equal(t, "lot LOT-A was received with another expiry date", err.Error())
+
+	_, items, err := service.Create(2, dto.ReceiptRequest{
+		SupplierID:         1,
+		DeliveryNoteNumber: "SJ-001",
+		Items: []dto.ReceiptItemRequest{
+			{ItemID: 3, RackID: 1, Quantity: 10, LotNumber: "LOT-A", ExpiryDate: "2026-12-01"},
+			{ItemID: 3, RackID: 1, Quantity: 5, LotNumber: "LOT-B", ExpiryDate: "2027-01-15"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, "LOT-B", *items[1].LotNumber)
+	require.Equal(t, "2027-01-15", items[1].ExpiryDate.Format("2006-01-02"))
+}
+
+// TestReceiptService_Create_Serials tests serialized lines need one distinct serial per unit
+func TestReceiptService_Create_Serials(t *testing.T) {
+	mockSupplierRepo := new(MockSupplierRepository)
+	mockItemRepo := new(MockItemRepository)
+	mockRackRepo := new(MockRackRepository)
+	mockReceiptRepo := new(MockReceiptRepository)
+	repo := repository.Repository{
+		SupplierRepo: mockSupplierRepo,
+		ItemRepo:     mockItemRepo,
+		RackRepo:     mockRackRepo,
+		ReceiptRepo:  mockReceiptRepo,
+	}
+	service := NewReceiptService(repo)
+
+	mockSupplierRepo.On("FindByID", 1).Return(&model.Supplier{ID: 1}, nil)
+	mockReceiptRepo.On("FindByDeliveryNote", 1, "SJ-001").Return(nil, nil)
+	mockItemRepo.On("FindByID", 3).Return(&model.Item{ID: 3, Name: "Laptop", Serialized: true}, nil)
+	mockRackRepo.On("FindByID", 1).Return(&model.Rack{ID: 1}, nil)
+	mockReceiptRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	_, _, err := service.Create(2, dto.ReceiptRequest{
+		SupplierID:         1,
+		DeliveryNoteNumber: "SJ-001",
+		Items:              []dto.ReceiptItemRequest{{ItemID: 3, RackID: 1, Quantity: 2, SerialNumbers: []string{"SN-1"}}},
+	})
+	require.Error(t, err)
+	require.Equal(t, "serial_numbers must list one serial per unit for serialized item: Laptop", err.Error())
+
+	_, _, err = service.Create(2, dto.ReceiptRequest{
+		SupplierID:         1,
+		DeliveryNoteNumber: "SJ-001",
+		Items: []dto.ReceiptItemRequest{
+			{ItemID: 3, RackID: 1, Quantity: 1, SerialNumbers: []string{"SN-1"}},
+			{ItemID: 3, RackID: 1, Quantity: 1, SerialNumbers: []string{"SN-1"}},
+		},
+	})
+	require.Error(t, err)
+	require.Equal(t, "serial number listed twice: SN-1", err.Error())
+
+	_, items, err := service.Create(2, dto.ReceiptRequest{
+		SupplierID:         1,
+		DeliveryNoteNumber: "SJ-001",
+		Items:              []dto.ReceiptItemRequest{{ItemID: 3, RackID: 1, Quantity: 2, SerialNumbers: []string{"SN-1", "SN-2"}}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"SN-1", "SN-2"}, items[0].SerialNumbers)
+}
+
+// TestReceiptService_Create_DuplicateDeliveryNote tests booking the same delivery twice
+func TestReceiptService_Create_DuplicateDeliveryNote(t *testing.T) {
+	mockSupplierRepo := new(MockSupplierRepository)
+	mockReceiptRepo := new(MockReceiptRepository)
+	repo := repository.Repository{
+		SupplierRepo: mockSupplierRepo,
+		ReceiptRepo:  mockReceiptRepo,
+	}
+	service := NewReceiptService(repo)
+
+	req := dto.ReceiptRequest{
+		SupplierID:         1,
+		DeliveryNoteNumber: "SJ-001",
+		Items:              []dto.ReceiptItemRequest{{ItemID: 3, RackID: 1, Quantity: 10}},
+	}
+
+	mockSupplierRepo.On("FindByID", 1).Return(&model.Supplier{ID: 1}, nil)
+	mockReceiptRepo.On("FindByDeliveryNote", 1, "SJ-001").Return(&model.Receipt{ID: 9}, nil)
+
+	_, _, err := service.Create(2, req)
+
+	require.Error(t, err)
+	require.Equal(t, "delivery note number already received for this supplier", err.Error())
+	mockReceiptRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestReceiptService_Create_ItemNotOnOrder tests receiving an item the purchase order doesn't contain
+func TestReceiptService_Create_ItemNotOnOrder(t *testing.T) {
+	mockSupplierRepo := new(MockSupplierRepository)
+	mockItemRepo := new(MockItemRepository)
+	mockOrderRepo := new(MockPurchaseOrderRepository)
+	mockReceiptRepo := new(MockReceiptRepository)
+	repo := repository.Repository{
+		SupplierRepo:      mockSupplierRepo,
+		ItemRepo:          mockItemRepo,
+		PurchaseOrderRepo: mockOrderRepo,
+		ReceiptRepo:       mockReceiptRepo,
+	}
+	service := NewReceiptService(repo)
+
+	req := dto.ReceiptRequest{
+		SupplierID:         1,
+		PurchaseOrderID:    4,
+		DeliveryNoteNumber: "SJ-003",
+		Items:              []dto.ReceiptItemRequest{{ItemID: 5, RackID: 1, Quantity: 1}},
+	}
+
+	mockSupplierRepo.On("FindByID", 1).Return(&model.Supplier{ID: 1}, nil)
+	mockReceiptRepo.On("FindByDeliveryNote", 1, "SJ-003").Return(nil, nil)
+	mockOrderRepo.On("FindByID", 4).Return(&model.PurchaseOrder{ID: 4, SupplierID: 1, Status: model.PurchaseOrderStatusApproved}, nil)
+	mockOrderRepo.On("FindOrderItems", 4).Return([]model.PurchaseOrderItem{{ItemID: 3, QuantityOrdered: 10}}, nil)
+	mockItemRepo.On("FindByID", 5).Return(&model.Item{ID: 5}, nil)
+
+	_, _, err := service.Create(2, req)
+
+	require.Error(t, err)
+	require.Equal(t, "item is not on the purchase order: 5", err.Error())
+}
+
+// TestReceiptService_Create_OrderNotApproved tests receiving against a draft purchase order
+func TestReceiptService_Create_OrderNotApproved(t *testing.T) {
+	mockSupplierRepo := new(MockSupplierRepository)
+	mockOrderRepo := new(MockPurchaseOrderRepository)
+	mockReceiptRepo := new(MockReceiptRepository)
+	repo := repository.Repository{
+		SupplierRepo:      mockSupplierRepo,
+		PurchaseOrderRepo: mockOrderRepo,
+		ReceiptRepo:       mockReceiptRepo,
+	}
+	service := NewReceiptService(repo)
+
+	req := dto.ReceiptRequest{
+		SupplierID:         1,
+		PurchaseOrderID:    4,
+		DeliveryNoteNumber: "SJ-004",
+		Items:              []dto.ReceiptItemRequest{{ItemID: 3, RackID: 1, Quantity: 1}},
+	}
+
+	mockSupplierRepo.On("FindByID", 1).Return(&model.Supplier{ID: 1}, nil)
+	mockReceiptRepo.On("FindByDeliveryNote", 1, "SJ-004").Return(nil, nil)
+	mockOrderRepo.On("FindByID", 4).Return(&model.PurchaseOrder{ID: 4, SupplierID: 1, Status: model.PurchaseOrderStatusDraft}, nil)
+
+	_, _, err := service.Create(2, req)
+
+	require.Error(t, err)
+	require.Equal(t, "purchase order is not open for receiving", err.Error())
+}
+
+// TestReceiptService_Void_StockAlreadyUsed tests the friendly error when stock has left the rack
+func TestReceiptService_Void_StockAlreadyUsed(t *testing.T) {
+	mockReceiptRepo := new(MockReceiptRepository)
+	service := NewReceiptService(repository.Repository{ReceiptRepo: mockReceiptRepo})
+
+	mockReceiptRepo.On("FindByID", 9).Return(&model.Receipt{ID: 9, Status: model.ReceiptStatusReceived}, nil)
+	mockReceiptRepo.On("Void", 9, 2).Return(repository.ErrInsufficientStock)
+
+	err := service.Void(9, 2)
+
+	require.Error(t, err)
+	require.Equal(t, "not enough stock left in the rack to void receipt", err.Error())
+}
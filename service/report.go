@@ -1,14 +1,39 @@
 package service
 
 import (
+	"errors"
 	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
 	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+	"time"
 )
 
 type ReportService interface {
 	GetSummary() (*dto.ReportSummaryResponse, error)
+	GetGrossMargin(groupBy string, from, to *time.Time, page, limit int) ([]model.GrossMargin, *dto.Pagination, error)
+	GetInventoryValuation(asOf *time.Time) (*dto.InventoryValuationResponse, error)
+	GetSalesByPeriod(interval, groupBy string, location *time.Location, from, to time.Time) (*dto.SalesReportResponse, error)
+	GetTopSellers(filter model.ItemMovementFilter, page, limit int) ([]model.ItemMovement, *dto.Pagination, error)
+	GetSlowMovers(filter model.ItemMovementFilter, page, limit int) ([]model.ItemMovement, *dto.Pagination, error)
+	GetDeadStock(days int, filter model.ItemMovementFilter, page, limit int) ([]model.ItemMovement, *dto.Pagination, error)
+	GetABCClassification(settings model.ABCSettings) (*dto.ABCReportResponse, error)
+	ApplyABCClassification(settings model.ABCSettings) (*dto.ABCReportResponse, error)
 }
 
+// itemMovementWindowDays is the sales window of the top sellers and slow
+// movers reports when no dates are given
+const itemMovementWindowDays = 30
+
+// Defaults of the ABC classification: the last 90 days, A up to 80% of the
+// value and B up to 95%
+const (
+	abcPeriodDays = 90
+	abcAThreshold = money.Rate(8000)
+	abcBThreshold = money.Rate(9500)
+)
+
 type reportService struct {
 	Repo *repository.Repository
 }
@@ -42,13 +67,26 @@ func (s *reportService) GetSummary() (*dto.ReportSummaryResponse, error) {
 	}
 	report.TotalSales = totalSales
 
-	// Total revenue
+	// Total revenue, already net of returned goods
 	totalRevenue, err := s.Repo.ReportRepo.GetTotalRevenue()
 	if err != nil {
 		return nil, err
 	}
 	report.TotalRevenue = totalRevenue
 
+	// Refunds paid out for returned goods, tax included
+	totalRefunds, err := s.Repo.ReportRepo.GetTotalRefunds()
+	if err != nil {
+		return nil, err
+	}
+	report.TotalRefunds = totalRefunds
+
+	// Gross sales, discounts and tax behind the revenue
+	report.GrossSales, report.TotalDiscounts, report.TotalTax, err = s.Repo.ReportRepo.GetSalesBreakdown()
+	if err != nil {
+		return nil, err
+	}
+
 	// Active users
 	activeUsers, err := s.Repo.ReportRepo.GetActiveUsers()
 	if err != nil {
@@ -72,3 +110,394 @@ func (s *reportService) GetSummary() (*dto.ReportSummaryResponse, error) {
 
 	return &report, nil
 }
+
+// GetGrossMargin reports revenue against cost of goods sold per sale, item or
+// category, per sale when groupBy is empty
+func (s *reportService) GetGrossMargin(groupBy string, from, to *time.Time, page, limit int) ([]model.GrossMargin, *dto.Pagination, error) {
+	switch groupBy {
+	case "":
+		groupBy = model.GrossMarginBySale
+	case model.GrossMarginBySale, model.GrossMarginByItem, model.GrossMarginByCategory:
+	default:
+		return nil, nil, errors.New("group_by must be one of sale, item or category")
+	}
+
+	if from != nil && to != nil && from.After(*to) {
+		return nil, nil, errors.New("from date must be before to date")
+	}
+
+	margins, total, err := s.Repo.ReportRepo.GetGrossMargin(groupBy, from, to, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range margins {
+		margins[i].GrossMargin = margins[i].Revenue - margins[i].Cost
+		if margins[i].Revenue != 0 {
+			margins[i].MarginPercent = money.Rate(margins[i].GrossMargin.Share(int64(money.MaxRate), int64(margins[i].Revenue)))
+		}
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return margins, &pagination, nil
+}
+
+// GetInventoryValuation values the stock at the end of the asOf day, or the
+// current stock when asOf is nil
+func (s *reportService) GetInventoryValuation(asOf *time.Time) (*dto.InventoryValuationResponse, error) {
+	var until *time.Time
+	if asOf != nil {
+		if asOf.After(time.Now()) {
+			return nil, errors.New("as_of date must not be in the future")
+		}
+		endOfDay := asOf.AddDate(0, 0, 1)
+		until = &endOfDay
+	}
+
+	lines, err := s.Repo.ReportRepo.GetInventoryValuation(until)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.InventoryValuationResponse{Lines: []dto.InventoryValuationLineResponse{}}
+	if asOf != nil {
+		date := asOf.Format("2006-01-02")
+		response.AsOf = &date
+	}
+	for _, line := range lines {
+		response.TotalQuantity += line.Quantity
+		response.TotalValue += line.Value
+		response.Lines = append(response.Lines, dto.InventoryValuationLineResponse{
+			WarehouseID:   line.WarehouseID,
+			WarehouseName: line.WarehouseName,
+			RackID:        line.RackID,
+			RackCode:      line.RackCode,
+			CategoryID:    line.CategoryID,
+			CategoryName:  line.CategoryName,
+			Quantity:      line.Quantity,
+			Value:         line.Value,
+		})
+	}
+
+	return &response, nil
+}
+
+// GetSalesByPeriod reports revenue, number of sales and units sold per day,
+// week or month from the from day through the to day, both midnight in
+// location. Ungrouped reports list every bucket of the range, including the
+// ones without sales.
+func (s *reportService) GetSalesByPeriod(interval, groupBy string, location *time.Location, from, to time.Time) (*dto.SalesReportResponse, error) {
+	switch interval {
+	case "":
+		interval = model.SalesIntervalDay
+	case model.SalesIntervalDay, model.SalesIntervalWeek, model.SalesIntervalMonth:
+	default:
+		return nil, errors.New("interval must be one of day, week or month")
+	}
+
+	switch groupBy {
+	case "", model.SalesGroupByCategory, model.SalesGroupByItem, model.SalesGroupByWarehouse, model.SalesGroupByCashier:
+	default:
+		return nil, errors.New("group_by must be one of category, item, warehouse or cashier")
+	}
+
+	if from.After(to) {
+		return nil, errors.New("from date must be before to date")
+	}
+
+	periods, err := s.Repo.ReportRepo.GetSalesByPeriod(interval, groupBy, location.String(), from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	if groupBy == "" {
+		periods = fillSalesPeriods(periods, interval, from, to)
+	}
+
+	response := dto.SalesReportResponse{
+		From:     from.Format("2006-01-02"),
+		To:       to.Format("2006-01-02"),
+		Interval: interval,
+		GroupBy:  groupBy,
+		Timezone: location.String(),
+		Periods:  []dto.SalesPeriodResponse{},
+	}
+	for _, period := range periods {
+		response.TotalRevenue += period.Revenue
+		response.TotalUnits += period.UnitsSold
+		response.Periods = append(response.Periods, dto.SalesPeriodResponse{
+			Period:     period.Period.Format("2006-01-02"),
+			GroupID:    period.GroupID,
+			GroupName:  period.GroupName,
+			Revenue:    period.Revenue,
+			SalesCount: period.SalesCount,
+			UnitsSold:  period.UnitsSold,
+		})
+	}
+
+	return &response, nil
+}
+
+// fillSalesPeriods returns one period per bucket from the from day through the
+// to day, taking the ones with sales from periods
+func fillSalesPeriods(periods []model.SalesPeriod, interval string, from, to time.Time) []model.SalesPeriod {
+	byDay := make(map[string]model.SalesPeriod, len(periods))
+	for _, period := range periods {
+		byDay[period.Period.Format("2006-01-02")] = period
+	}
+
+	var filled []model.SalesPeriod
+	for start := periodStart(interval, from); !start.After(to); start = nextPeriod(interval, start) {
+		period, ok := byDay[start.Format("2006-01-02")]
+		if !ok {
+			period = model.SalesPeriod{Period: start}
+		}
+		filled = append(filled, period)
+	}
+	return filled
+}
+
+// periodStart returns the first day of the bucket day falls in, weeks start on
+// Monday like date_trunc
+func periodStart(interval string, day time.Time) time.Time {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	switch interval {
+	case model.SalesIntervalWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case model.SalesIntervalMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
+
+func nextPeriod(interval string, start time.Time) time.Time {
+	switch interval {
+	case model.SalesIntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case model.SalesIntervalMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// GetTopSellers lists the items that sold the most units, or the most revenue
+// when filter.SortBy is revenue, over the last 30 days unless a window is given
+func (s *reportService) GetTopSellers(filter model.ItemMovementFilter, page, limit int) ([]model.ItemMovement, *dto.Pagination, error) {
+	switch filter.SortBy {
+	case "":
+		filter.SortBy = model.TopSellersByUnits
+	case model.TopSellersByUnits, model.TopSellersByRevenue:
+	default:
+		return nil, nil, errors.New("sort_by must be units or revenue")
+	}
+
+	filter, err := itemMovementWindow(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.getItemMovement(model.ItemMovementTopSellers, filter, page, limit)
+}
+
+// GetSlowMovers lists the items in stock that sold least of what they had over
+// the last 30 days unless a window is given
+func (s *reportService) GetSlowMovers(filter model.ItemMovementFilter, page, limit int) ([]model.ItemMovement, *dto.Pagination, error) {
+	filter, err := itemMovementWindow(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.getItemMovement(model.ItemMovementSlowMovers, filter, page, limit)
+}
+
+// GetDeadStock lists the items in stock that have not sold in the last days
+func (s *reportService) GetDeadStock(days int, filter model.ItemMovementFilter, page, limit int) ([]model.ItemMovement, *dto.Pagination, error) {
+	if days < 1 {
+		return nil, nil, errors.New("days must be at least 1")
+	}
+
+	now := time.Now()
+	filter.From = now.AddDate(0, 0, -days)
+	filter.To = now
+
+	items, pagination, err := s.getItemMovement(model.ItemMovementDeadStock, filter, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range items {
+		if items[i].LastSoldAt != nil {
+			idle := int(now.Sub(*items[i].LastSoldAt).Hours() / 24)
+			items[i].DaysSinceLastSale = &idle
+		}
+	}
+	return items, pagination, nil
+}
+
+func (s *reportService) getItemMovement(report string, filter model.ItemMovementFilter, page, limit int) ([]model.ItemMovement, *dto.Pagination, error) {
+	items, total, err := s.Repo.ReportRepo.GetItemMovement(report, filter, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range items {
+		whole := int64(items[i].UnitsSold + items[i].Stock)
+		items[i].SellThrough = money.Rate(money.Amount(money.MaxRate).Share(int64(items[i].UnitsSold), whole))
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return items, &pagination, nil
+}
+
+// itemMovementWindow fills in the sales window that was left out: up to the end
+// of today, and 30 days back from its end
+func itemMovementWindow(filter model.ItemMovementFilter) (model.ItemMovementFilter, error) {
+	if filter.To.IsZero() {
+		year, month, day := time.Now().UTC().Date()
+		filter.To = time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+	}
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, 0, -itemMovementWindowDays)
+	}
+	if !filter.From.Before(filter.To) {
+		return filter, errors.New("from date must be before to date")
+	}
+	return filter, nil
+}
+
+// GetABCClassification ranks the items by value over the period and bands them
+// into classes: A until the cumulative share reaches the A threshold, B until
+// it reaches the B threshold, C for the rest and for items that did not sell
+func (s *reportService) GetABCClassification(settings model.ABCSettings) (*dto.ABCReportResponse, error) {
+	settings, items, err := s.classifyItems(settings)
+	if err != nil {
+		return nil, err
+	}
+	return abcReport(settings, items, false), nil
+}
+
+// ApplyABCClassification classifies the items like GetABCClassification and
+// stores the classes on them
+func (s *reportService) ApplyABCClassification(settings model.ABCSettings) (*dto.ABCReportResponse, error) {
+	settings, items, err := s.classifyItems(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.Repo.ItemRepo.UpdateABCClasses(items)
+	if err != nil {
+		return nil, err
+	}
+	return abcReport(settings, items, true), nil
+}
+
+func (s *reportService) classifyItems(settings model.ABCSettings) (model.ABCSettings, []model.ABCItem, error) {
+	switch settings.Basis {
+	case "":
+		settings.Basis = model.ABCBasisRevenue
+	case model.ABCBasisRevenue, model.ABCBasisConsumption:
+	default:
+		return settings, nil, errors.New("basis must be revenue or consumption")
+	}
+
+	if settings.AThreshold == 0 {
+		settings.AThreshold = abcAThreshold
+	}
+	if settings.BThreshold == 0 {
+		settings.BThreshold = abcBThreshold
+	}
+	if settings.AThreshold < 0 || settings.AThreshold >= settings.BThreshold || settings.BThreshold > money.MaxRate {
+		return settings, nil, errors.New("thresholds must satisfy 0 < a_threshold < b_threshold <= 100")
+	}
+
+	if settings.To.IsZero() {
+		year, month, day := time.Now().UTC().Date()
+		settings.To = time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+	}
+	if settings.From.IsZero() {
+		settings.From = settings.To.AddDate(0, 0, -abcPeriodDays)
+	}
+	if !settings.From.Before(settings.To) {
+		return settings, nil, errors.New("from date must be before to date")
+	}
+
+	items, err := s.Repo.ReportRepo.GetABCValues(settings.Basis, settings.From, settings.To)
+	if err != nil {
+		return settings, nil, err
+	}
+
+	var total, running money.Amount
+	for _, item := range items {
+		total += item.Value
+	}
+
+	var reached money.Rate // cumulative share of the items ranked above
+	for i := range items {
+		running += items[i].Value
+		items[i].Share = money.Rate(money.Amount(money.MaxRate).Share(int64(items[i].Value), int64(total)))
+		items[i].CumulativeShare = money.Rate(money.Amount(money.MaxRate).Share(int64(running), int64(total)))
+
+		switch {
+		case items[i].Value <= 0:
+			items[i].Class = model.ABCClassC
+		case reached < settings.AThreshold:
+			items[i].Class = model.ABCClassA
+		case reached < settings.BThreshold:
+			items[i].Class = model.ABCClassB
+		default:
+			items[i].Class = model.ABCClassC
+		}
+		reached = items[i].CumulativeShare
+	}
+
+	return settings, items, nil
+}
+
+func abcReport(settings model.ABCSettings, items []model.ABCItem, applied bool) *dto.ABCReportResponse {
+	response := dto.ABCReportResponse{
+		From:       settings.From.Format("2006-01-02"),
+		To:         settings.To.AddDate(0, 0, -1).Format("2006-01-02"),
+		Basis:      settings.Basis,
+		AThreshold: settings.AThreshold,
+		BThreshold: settings.BThreshold,
+		Applied:    applied,
+		Classes: []dto.ABCClassSummary{
+			{Class: model.ABCClassA}, {Class: model.ABCClassB}, {Class: model.ABCClassC},
+		},
+		Items: []dto.ABCItemResponse{},
+	}
+
+	for _, item := range items {
+		response.TotalValue += item.Value
+
+		summary := &response.Classes[item.Class[0]-'A']
+		summary.ItemCount++
+		summary.Value += item.Value
+
+		response.Items = append(response.Items, dto.ABCItemResponse{
+			ItemID:          item.ItemID,
+			SKU:             item.SKU,
+			Name:            item.Name,
+			Value:           item.Value,
+			Share:           item.Share,
+			CumulativeShare: item.CumulativeShare,
+			Class:           item.Class,
+		})
+	}
+
+	for i := range response.Classes {
+		response.Classes[i].Share = money.Rate(money.Amount(money.MaxRate).Share(int64(response.Classes[i].Value), int64(response.TotalValue)))
+	}
+
+	return &response
+}
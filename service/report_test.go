@@ -2,8 +2,11 @@ package service
 
 import (
 	"errors"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
 	"project-app-inventory/repository"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -29,9 +32,19 @@ func (m *MockReportRepository) GetTotalSales() (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
-func (m *MockReportRepository) GetTotalRevenue() (float64, error) {
+func (m *MockReportRepository) GetTotalRevenue() (money.Amount, error) {
 	args := m.Called()
-	return args.Get(0).(float64), args.Error(1)
+	return args.Get(0).(money.Amount), args.Error(1)
+}
+
+func (m *MockReportRepository) GetTotalRefunds() (money.Amount, error) {
+	args := m.Called()
+	return args.Get(0).(money.Amount), args.Error(1)
+}
+
+func (m *MockReportRepository) GetSalesBreakdown() (money.Amount, money.Amount, money.Amount, error) {
+	args := m.Called()
+	return args.Get(0).(money.Amount), args.Get(1).(money.Amount), args.Get(2).(money.Amount), args.Error(3)
 }
 
 func (m *MockReportRepository) GetActiveUsers() (int, error) {
@@ -49,6 +62,46 @@ func (m *MockReportRepository) GetTotalWarehouses() (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockReportRepository) GetInventoryValuation(asOf *time.Time) ([]model.InventoryValuation, error) {
+	args := m.Called(asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.InventoryValuation), args.Error(1)
+}
+
+func (m *MockReportRepository) GetSalesByPeriod(interval, groupBy, timezone string, from, to time.Time) ([]model.SalesPeriod, error) {
+	args := m.Called(interval, groupBy, timezone, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.SalesPeriod), args.Error(1)
+}
+
+func (m *MockReportRepository) GetItemMovement(report string, filter model.ItemMovementFilter, page, limit int) ([]model.ItemMovement, int, error) {
+	args := m.Called(report, filter, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]model.ItemMovement), args.Int(1), args.Error(2)
+}
+
+func (m *MockReportRepository) GetABCValues(basis string, from, to time.Time) ([]model.ABCItem, error) {
+	args := m.Called(basis, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.ABCItem), args.Error(1)
+}
+
+func (m *MockReportRepository) GetGrossMargin(groupBy string, from, to *time.Time, page, limit int) ([]model.GrossMargin, int, error) {
+	args := m.Called(groupBy, from, to, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]model.GrossMargin), args.Int(1), args.Error(2)
+}
+
 // TestReportService_GetSummary_Success tests getting report summary successfully
 func TestReportService_GetSummary_Success(t *testing.T) {
 	mockReportRepo := new(MockReportRepository)
@@ -58,7 +111,9 @@ func TestReportService_GetSummary_Success(t *testing.T) {
 	mockReportRepo.On("GetTotalItems").Return(100, nil)
 	mockReportRepo.On("GetLowStockItems").Return(5, nil)
 	mockReportRepo.On("GetTotalSales").Return(50, nil)
-	mockReportRepo.On("GetTotalRevenue").Return(1000000.0, nil)
+	mockReportRepo.On("GetTotalRevenue").Return(money.FromUnits(850000), nil)
+	mockReportRepo.On("GetTotalRefunds").Return(money.FromUnits(150000), nil)
+	mockReportRepo.On("GetSalesBreakdown").Return(money.FromUnits(1100000), money.FromUnits(200000), money.FromUnits(100000), nil)
 	mockReportRepo.On("GetActiveUsers").Return(25, nil)
 	mockReportRepo.On("GetTotalCategories").Return(10, nil)
 	mockReportRepo.On("GetTotalWarehouses").Return(3, nil)
@@ -70,7 +125,11 @@ func TestReportService_GetSummary_Success(t *testing.T) {
 	require.Equal(t, 100, result.TotalItems)
 	require.Equal(t, 5, result.LowStockItems)
 	require.Equal(t, 50, result.TotalSales)
-	require.Equal(t, 1000000.0, result.TotalRevenue)
+	require.Equal(t, money.FromUnits(850000), result.TotalRevenue) // net of returns in the repository
+	require.Equal(t, money.FromUnits(150000), result.TotalRefunds)
+	require.Equal(t, money.FromUnits(1100000), result.GrossSales)
+	require.Equal(t, money.FromUnits(200000), result.TotalDiscounts)
+	require.Equal(t, money.FromUnits(100000), result.TotalTax)
 	require.Equal(t, 25, result.ActiveUsers)
 	require.Equal(t, 10, result.TotalCategories)
 	require.Equal(t, 3, result.TotalWarehouses)
@@ -134,7 +193,46 @@ func TestReportService_GetSummary_TotalRevenueError(t *testing.T) {
 	mockReportRepo.On("GetTotalItems").Return(100, nil)
 	mockReportRepo.On("GetLowStockItems").Return(5, nil)
 	mockReportRepo.On("GetTotalSales").Return(50, nil)
-	mockReportRepo.On("GetTotalRevenue").Return(0.0, errors.New("db error"))
+	mockReportRepo.On("GetTotalRevenue").Return(money.Amount(0), errors.New("db error"))
+
+	result, err := service.GetSummary()
+
+	require.Error(t, err)
+	require.Nil(t, result)
+	mockReportRepo.AssertExpectations(t)
+}
+
+// TestReportService_GetSummary_TotalRefundsError tests error on GetTotalRefunds
+func TestReportService_GetSummary_TotalRefundsError(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo}
+	service := NewReportService(&repo)
+
+	mockReportRepo.On("GetTotalItems").Return(100, nil)
+	mockReportRepo.On("GetLowStockItems").Return(5, nil)
+	mockReportRepo.On("GetTotalSales").Return(50, nil)
+	mockReportRepo.On("GetTotalRevenue").Return(money.FromUnits(1000000), nil)
+	mockReportRepo.On("GetTotalRefunds").Return(money.Amount(0), errors.New("db error"))
+
+	result, err := service.GetSummary()
+
+	require.Error(t, err)
+	require.Nil(t, result)
+	mockReportRepo.AssertExpectations(t)
+}
+
+// TestReportService_GetSummary_SalesBreakdownError tests error on GetSalesBreakdown
+func TestReportService_GetSummary_SalesBreakdownError(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo}
+	service := NewReportService(&repo)
+
+	mockReportRepo.On("GetTotalItems").Return(100, nil)
+	mockReportRepo.On("GetLowStockItems").Return(5, nil)
+	mockReportRepo.On("GetTotalSales").Return(50, nil)
+	mockReportRepo.On("GetTotalRevenue").Return(money.FromUnits(1000000), nil)
+	mockReportRepo.On("GetTotalRefunds").Return(money.Amount(0), nil)
+	mockReportRepo.On("GetSalesBreakdown").Return(money.Amount(0), money.Amount(0), money.Amount(0), errors.New("db error"))
 
 	result, err := service.GetSummary()
 
@@ -152,7 +250,9 @@ func TestReportService_GetSummary_ActiveUsersError(t *testing.T) {
 	mockReportRepo.On("GetTotalItems").Return(100, nil)
 	mockReportRepo.On("GetLowStockItems").Return(5, nil)
 	mockReportRepo.On("GetTotalSales").Return(50, nil)
-	mockReportRepo.On("GetTotalRevenue").Return(1000000.0, nil)
+	mockReportRepo.On("GetTotalRevenue").Return(money.FromUnits(1000000), nil)
+	mockReportRepo.On("GetTotalRefunds").Return(money.Amount(0), nil)
+	mockReportRepo.On("GetSalesBreakdown").Return(money.FromUnits(1100000), money.FromUnits(200000), money.FromUnits(100000), nil)
 	mockReportRepo.On("GetActiveUsers").Return(0, errors.New("db error"))
 
 	result, err := service.GetSummary()
@@ -171,7 +271,9 @@ func TestReportService_GetSummary_TotalCategoriesError(t *testing.T) {
 	mockReportRepo.On("GetTotalItems").Return(100, nil)
 	mockReportRepo.On("GetLowStockItems").Return(5, nil)
 	mockReportRepo.On("GetTotalSales").Return(50, nil)
-	mockReportRepo.On("GetTotalRevenue").Return(1000000.0, nil)
+	mockReportRepo.On("GetTotalRevenue").Return(money.FromUnits(1000000), nil)
+	mockReportRepo.On("GetTotalRefunds").Return(money.Amount(0), nil)
+	mockReportRepo.On("GetSalesBreakdown").Return(money.FromUnits(1100000), money.FromUnits(200000), money.FromUnits(100000), nil)
 	mockReportRepo.On("GetActiveUsers").Return(25, nil)
 	mockReportRepo.On("GetTotalCategories").Return(0, errors.New("db error"))
 
@@ -191,7 +293,9 @@ func TestReportService_GetSummary_TotalWarehousesError(t *testing.T) {
 	mockReportRepo.On("GetTotalItems").Return(100, nil)
 	mockReportRepo.On("GetLowStockItems").Return(5, nil)
 	mockReportRepo.On("GetTotalSales").Return(50, nil)
-	mockReportRepo.On("GetTotalRevenue").Return(1000000.0, nil)
+	mockReportRepo.On("GetTotalRevenue").Return(money.FromUnits(1000000), nil)
+	mockReportRepo.On("GetTotalRefunds").Return(money.Amount(0), nil)
+	mockReportRepo.On("GetSalesBreakdown").Return(money.FromUnits(1100000), money.FromUnits(200000), money.FromUnits(100000), nil)
 	mockReportRepo.On("GetActiveUsers").Return(25, nil)
 	mockReportRepo.On("GetTotalCategories").Return(10, nil)
 	mockReportRepo.On("GetTotalWarehouses").Return(0, errors.New("db error"))
@@ -202,3 +306,266 @@ func TestReportService_GetSummary_TotalWarehousesError(t *testing.T) {
 	require.Nil(t, result)
 	mockReportRepo.AssertExpectations(t)
 }
+
+// TestReportService_GetGrossMargin_Success tests margins are worked out from revenue and cost
+func TestReportService_GetGrossMargin_Success(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo}
+	service := NewReportService(&repo)
+
+	mockReportRepo.On("GetGrossMargin", model.GrossMarginByCategory, (*time.Time)(nil), (*time.Time)(nil), 1, 10).
+		Return([]model.GrossMargin{
+			{ID: 1, Name: "Elektronik", Quantity: 7, Revenue: money.FromUnits(700000), Cost: money.FromUnits(480000)},
+			{ID: 2, Name: "Aksesoris", Quantity: 1, Revenue: money.FromUnits(30000), Cost: money.FromUnits(35000)},
+		}, 2, nil)
+
+	margins, pagination, err := service.GetGrossMargin(model.GrossMarginByCategory, nil, nil, 1, 10)
+
+	require.NoError(t, err)
+	require.Len(t, margins, 2)
+	require.Equal(t, money.FromUnits(220000), margins[0].GrossMargin)
+	require.Equal(t, money.Rate(3143), margins[0].MarginPercent) // 31.43%
+	require.Equal(t, money.FromUnits(-5000), margins[1].GrossMargin)
+	require.Equal(t, money.Rate(-1667), margins[1].MarginPercent)
+	require.Equal(t, 2, pagination.TotalRecords)
+	mockReportRepo.AssertExpectations(t)
+}
+
+// TestReportService_GetGrossMargin_InvalidGroupBy tests an unknown grouping is rejected
+func TestReportService_GetGrossMargin_InvalidGroupBy(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo}
+	service := NewReportService(&repo)
+
+	margins, pagination, err := service.GetGrossMargin("supplier", nil, nil, 1, 10)
+
+	require.Error(t, err)
+	require.Nil(t, margins)
+	require.Nil(t, pagination)
+	mockReportRepo.AssertNotCalled(t, "GetGrossMargin")
+}
+
+// TestReportService_GetInventoryValuation_AsOf tests the stock is valued at the end of the as_of day
+func TestReportService_GetInventoryValuation_AsOf(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo}
+	service := NewReportService(&repo)
+
+	asOf := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	endOfDay := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	warehouseID, rackA, rackB := 1, 1, 2
+	warehouse, codeA, codeB := "Gudang Utama", "A-01", "A-02"
+	mockReportRepo.On("GetInventoryValuation", &endOfDay).Return([]model.InventoryValuation{
+		{WarehouseID: &warehouseID, WarehouseName: &warehouse, RackID: &rackA, RackCode: &codeA, CategoryID: 1, CategoryName: "Elektronik", Quantity: 10, Value: money.FromUnits(500000)},
+		{WarehouseID: &warehouseID, WarehouseName: &warehouse, RackID: &rackB, RackCode: &codeB, CategoryID: 1, CategoryName: "Elektronik", Quantity: 4, Value: money.FromUnits(120000)},
+		{CategoryID: 1, CategoryName: "Elektronik", Quantity: 2, Value: money.FromUnits(60000)}, // in transit
+	}, nil)
+
+	result, err := service.GetInventoryValuation(&asOf)
+
+	require.NoError(t, err)
+	require.Equal(t, "2025-01-31", *result.AsOf)
+	require.Equal(t, 16, result.TotalQuantity)
+	require.Equal(t, money.FromUnits(680000), result.TotalValue)
+	require.Len(t, result.Lines, 3)
+	require.Nil(t, result.Lines[2].RackID)
+	mockReportRepo.AssertExpectations(t)
+}
+
+// TestReportService_GetInventoryValuation_FutureDate tests a future as_of date is rejected
+func TestReportService_GetInventoryValuation_FutureDate(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo}
+	service := NewReportService(&repo)
+
+	asOf := time.Now().AddDate(0, 0, 2)
+	result, err := service.GetInventoryValuation(&asOf)
+
+	require.Error(t, err)
+	require.Nil(t, result)
+	mockReportRepo.AssertNotCalled(t, "GetInventoryValuation", mock.Anything)
+}
+
+// TestReportService_GetSalesByPeriod_FillsWeeks tests weeks without sales are listed with zeros
+func TestReportService_GetSalesByPeriod_FillsWeeks(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo}
+	service := NewReportService(&repo)
+
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	from := time.Date(2025, 3, 5, 0, 0, 0, 0, jakarta) // Wednesday
+	to := time.Date(2025, 3, 18, 0, 0, 0, 0, jakarta)
+
+	mockReportRepo.On("GetSalesByPeriod", model.SalesIntervalWeek, "", "WIB", from, to.AddDate(0, 0, 1)).
+		Return([]model.SalesPeriod{
+			{Period: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), Revenue: money.FromUnits(250000), SalesCount: 3, UnitsSold: 5},
+		}, nil)
+
+	result, err := service.GetSalesByPeriod(model.SalesIntervalWeek, "", jakarta, from, to)
+
+	require.NoError(t, err)
+	require.Len(t, result.Periods, 3)
+	require.Equal(t, "2025-03-03", result.Periods[0].Period) // the Monday of the from week
+	require.Equal(t, 0, result.Periods[0].SalesCount)
+	require.Equal(t, "2025-03-10", result.Periods[1].Period)
+	require.Equal(t, 3, result.Periods[1].SalesCount)
+	require.Equal(t, "2025-03-17", result.Periods[2].Period)
+	require.Equal(t, money.FromUnits(250000), result.TotalRevenue)
+	require.Equal(t, 5, result.TotalUnits)
+	mockReportRepo.AssertExpectations(t)
+}
+
+// TestReportService_GetSalesByPeriod_InvalidInterval tests an unknown interval is rejected
+func TestReportService_GetSalesByPeriod_InvalidInterval(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo}
+	service := NewReportService(&repo)
+
+	day := time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)
+	result, err := service.GetSalesByPeriod("year", "", time.UTC, day, day)
+
+	require.Error(t, err)
+	require.Nil(t, result)
+	mockReportRepo.AssertNotCalled(t, "GetSalesByPeriod", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReportService_GetTopSellers_DefaultWindow tests the last 30 days are used and sell-through is worked out
+func TestReportService_GetTopSellers_DefaultWindow(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo}
+	service := NewReportService(&repo)
+
+	categoryID := 3
+	mockReportRepo.On("GetItemMovement", model.ItemMovementTopSellers, mock.MatchedBy(func(filter model.ItemMovementFilter) bool {
+		return filter.SortBy == model.TopSellersByUnits && *filter.CategoryID == 3 &&
+			filter.To.AddDate(0, 0, -30).Equal(filter.From) && filter.To.After(time.Now())
+	}), 1, 10).Return([]model.ItemMovement{
+		{ID: 7, Name: "Kopi", Stock: 30, UnitsSold: 10, Revenue: money.FromUnits(150000)},
+	}, 11, nil)
+
+	items, pagination, err := service.GetTopSellers(model.ItemMovementFilter{CategoryID: &categoryID}, 1, 10)
+
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, money.Rate(2500), items[0].SellThrough) // 10 of 40 units
+	require.Equal(t, 2, pagination.TotalPages)
+	require.Equal(t, 11, pagination.TotalRecords)
+	mockReportRepo.AssertExpectations(t)
+}
+
+// TestReportService_GetTopSellers_InvalidSort tests an unknown sort_by is rejected
+func TestReportService_GetTopSellers_InvalidSort(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo}
+	service := NewReportService(&repo)
+
+	items, pagination, err := service.GetTopSellers(model.ItemMovementFilter{SortBy: "margin"}, 1, 10)
+
+	require.Error(t, err)
+	require.Nil(t, items)
+	require.Nil(t, pagination)
+	mockReportRepo.AssertNotCalled(t, "GetItemMovement", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReportService_GetDeadStock tests the idle days are counted from the last sale
+func TestReportService_GetDeadStock(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo}
+	service := NewReportService(&repo)
+
+	lastSold := time.Now().Add(-120 * 24 * time.Hour)
+	mockReportRepo.On("GetItemMovement", model.ItemMovementDeadStock, mock.MatchedBy(func(filter model.ItemMovementFilter) bool {
+		return filter.To.AddDate(0, 0, -90).Equal(filter.From)
+	}), 1, 10).Return([]model.ItemMovement{
+		{ID: 1, Name: "Teh", Stock: 12},
+		{ID: 2, Name: "Gula", Stock: 5, LastSoldAt: &lastSold},
+	}, 2, nil)
+
+	items, _, err := service.GetDeadStock(90, model.ItemMovementFilter{}, 1, 10)
+
+	require.NoError(t, err)
+	require.Nil(t, items[0].DaysSinceLastSale) // never sold
+	require.Equal(t, 120, *items[1].DaysSinceLastSale)
+	require.Equal(t, money.Rate(0), items[1].SellThrough)
+	mockReportRepo.AssertExpectations(t)
+}
+
+func abcValues() []model.ABCItem {
+	return []model.ABCItem{
+		{ItemID: 1, Name: "Beras", Value: money.FromUnits(700000)},
+		{ItemID: 2, Name: "Minyak", Value: money.FromUnits(150000)},
+		{ItemID: 3, Name: "Gula", Value: money.FromUnits(100000)},
+		{ItemID: 4, Name: "Garam", Value: money.FromUnits(50000)},
+		{ItemID: 5, Name: "Kecap", Value: 0},
+	}
+}
+
+// TestReportService_GetABCClassification tests items are banded by the cumulative share of the items above them
+func TestReportService_GetABCClassification(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo}
+	service := NewReportService(&repo)
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	mockReportRepo.On("GetABCValues", model.ABCBasisRevenue, from, to).Return(abcValues(), nil)
+
+	report, err := service.GetABCClassification(model.ABCSettings{From: from, To: to})
+
+	require.NoError(t, err)
+	require.Equal(t, money.Rate(8000), report.AThreshold)
+	require.Equal(t, money.Rate(9500), report.BThreshold)
+	require.Equal(t, "2025-03-31", report.To)
+	require.Equal(t, money.FromUnits(1000000), report.TotalValue)
+
+	var classes []string
+	for _, item := range report.Items {
+		classes = append(classes, item.Class)
+	}
+	// 70% opens at 0%, 85% at 70%, 95% at 85%, 100% at 95%, no sales
+	require.Equal(t, []string{"A", "A", "B", "C", "C"}, classes)
+	require.Equal(t, money.Rate(8500), report.Items[1].CumulativeShare)
+
+	require.Equal(t, 2, report.Classes[0].ItemCount)
+	require.Equal(t, money.Rate(8500), report.Classes[0].Share)
+	require.Equal(t, 2, report.Classes[2].ItemCount)
+	require.False(t, report.Applied)
+	mockReportRepo.AssertExpectations(t)
+}
+
+// TestReportService_ApplyABCClassification tests the classes are stored on the items
+func TestReportService_ApplyABCClassification(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	mockItemRepo := new(MockItemRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo, ItemRepo: mockItemRepo}
+	service := NewReportService(&repo)
+
+	mockReportRepo.On("GetABCValues", model.ABCBasisConsumption, mock.Anything, mock.Anything).Return(abcValues(), nil)
+	mockItemRepo.On("UpdateABCClasses", mock.MatchedBy(func(items []model.ABCItem) bool {
+		return len(items) == 5 && items[0].Class == "A" && items[2].Class == "A" && items[3].Class == "B"
+	})).Return(nil)
+
+	report, err := service.ApplyABCClassification(model.ABCSettings{
+		Basis:      model.ABCBasisConsumption,
+		AThreshold: money.Rate(9000),
+		BThreshold: money.Rate(9800),
+	})
+
+	require.NoError(t, err)
+	require.True(t, report.Applied)
+	mockReportRepo.AssertExpectations(t)
+	mockItemRepo.AssertExpectations(t)
+}
+
+// TestReportService_GetABCClassification_InvalidThresholds tests the A band must close before the B band
+func TestReportService_GetABCClassification_InvalidThresholds(t *testing.T) {
+	mockReportRepo := new(MockReportRepository)
+	repo := repository.Repository{ReportRepo: mockReportRepo}
+	service := NewReportService(&repo)
+
+	report, err := service.GetABCClassification(model.ABCSettings{AThreshold: money.Rate(9500), BThreshold: money.Rate(8000)})
+
+	require.Error(t, err)
+	require.Nil(t, report)
+	mockReportRepo.AssertNotCalled(t, "GetABCValues", mock.Anything, mock.Anything, mock.Anything)
+}
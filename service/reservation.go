@@ -0,0 +1,187 @@
+package service
+
+import (
+	"errors"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+	"strconv"
+	"time"
+)
+
+type ReservationService interface {
+	Create(userID int, req dto.ReservationRequest) (*model.Reservation, []model.ReservationItem, error)
+	GetAllReservations(status string, page, limit int) (*[]model.Reservation, *dto.Pagination, error)
+	GetReservationByID(id int) (*model.Reservation, []model.ReservationItem, error)
+	Extend(id int, hours int) (*model.Reservation, error)
+	Release(id int) error
+	Convert(id int, userID int) (*model.Sale, error)
+}
+
+type reservationService struct {
+	Repo  repository.Repository
+	Sales SaleService
+}
+
+func NewReservationService(repo repository.Repository) ReservationService {
+	return &reservationService{Repo: repo, Sales: NewSaleService(repo)}
+}
+
+func (s *reservationService) Create(userID int, req dto.ReservationRequest) (*model.Reservation, []model.ReservationItem, error) {
+	if len(req.Items) == 0 {
+		return nil, nil, errors.New("reservation must have at least one item")
+	}
+
+	reservation := &model.Reservation{
+		ExpiresAt: time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour),
+		CreatedBy: userID,
+	}
+	if req.CustomerID != 0 {
+		customer, err := s.Repo.CustomerRepo.FindByID(req.CustomerID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if customer == nil {
+			return nil, nil, errors.New("customer not found")
+		}
+		reservation.CustomerID = &customer.ID
+	}
+	if req.Note != "" {
+		note := req.Note
+		reservation.Note = &note
+	}
+
+	// Merge duplicate lines so the stock check sees the full quantity per item
+	quantities := make(map[int]int)
+	var items []model.ReservationItem
+	for _, line := range req.Items {
+		if _, ok := quantities[line.ItemID]; !ok {
+			items = append(items, model.ReservationItem{ItemID: line.ItemID})
+		}
+		quantities[line.ItemID] += line.Quantity
+	}
+
+	for i := range items {
+		items[i].Quantity = quantities[items[i].ItemID]
+
+		item, err := s.Repo.ItemRepo.FindByID(items[i].ItemID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if item == nil {
+			return nil, nil, errors.New("item not found: " + strconv.Itoa(items[i].ItemID))
+		}
+		if item.Available < items[i].Quantity {
+			return nil, nil, errors.New(repository.ErrInsufficientAvailable.Error() + ": " + item.Name)
+		}
+	}
+
+	// The repository checks again with the items locked
+	err := s.Repo.ReservationRepo.Create(reservation, items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reservation, items, nil
+}
+
+func (s *reservationService) GetAllReservations(status string, page, limit int) (*[]model.Reservation, *dto.Pagination, error) {
+	switch status {
+	case "", model.ReservationStatusActive, model.ReservationStatusReleased, model.ReservationStatusConverted, model.ReservationStatusExpired:
+	default:
+		return nil, nil, errors.New("invalid reservation status")
+	}
+
+	reservations, total, err := s.Repo.ReservationRepo.FindAll(status, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return &reservations, &pagination, nil
+}
+
+func (s *reservationService) GetReservationByID(id int) (*model.Reservation, []model.ReservationItem, error) {
+	reservation, err := s.Repo.ReservationRepo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if reservation == nil {
+		return nil, nil, errors.New("reservation not found")
+	}
+
+	items, err := s.Repo.ReservationRepo.FindReservationItems(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reservation, items, nil
+}
+
+// Extend pushes the expiry back by hours. An expired reservation can't be
+// extended, its stock may already be promised or sold again.
+func (s *reservationService) Extend(id int, hours int) (*model.Reservation, error) {
+	reservation, err := s.activeReservation(id)
+	if err != nil {
+		return nil, err
+	}
+
+	reservation.ExpiresAt = reservation.ExpiresAt.Add(time.Duration(hours) * time.Hour)
+	err = s.Repo.ReservationRepo.Extend(id, reservation.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+func (s *reservationService) Release(id int) error {
+	reservation, err := s.Repo.ReservationRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if reservation == nil {
+		return errors.New("reservation not found")
+	}
+
+	return s.Repo.ReservationRepo.Release(id)
+}
+
+// Convert sells exactly the reserved quantities to the reservation's customer
+func (s *reservationService) Convert(id int, userID int) (*model.Sale, error) {
+	if _, err := s.activeReservation(id); err != nil {
+		return nil, err
+	}
+
+	items, err := s.Repo.ReservationRepo.FindReservationItems(id)
+	if err != nil {
+		return nil, err
+	}
+
+	req := dto.SaleRequest{ReservationID: id}
+	for _, item := range items {
+		req.Items = append(req.Items, dto.SaleItemRequest{ItemID: item.ItemID, Quantity: item.Quantity})
+	}
+
+	return s.Sales.Create(userID, req)
+}
+
+func (s *reservationService) activeReservation(id int) (*model.Reservation, error) {
+	reservation, err := s.Repo.ReservationRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if reservation == nil {
+		return nil, errors.New("reservation not found")
+	}
+	if reservation.Status != model.ReservationStatusActive {
+		return nil, errors.New("reservation is " + reservation.Status)
+	}
+	return reservation, nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReservationRepository mocks ReservationRepository interface
+type MockReservationRepository struct {
+	mock.Mock
+}
+
+func (m *MockReservationRepository) Create(reservation *model.Reservation, items []model.ReservationItem) error {
+	args := m.Called(reservation, items)
+	return args.Error(0)
+}
+
+func (m *MockReservationRepository) FindByID(id int) (*model.Reservation, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Reservation), args.Error(1)
+}
+
+func (m *MockReservationRepository) FindReservationItems(reservationID int) ([]model.ReservationItem, error) {
+	args := m.Called(reservationID)
+	return args.Get(0).([]model.ReservationItem), args.Error(1)
+}
+
+func (m *MockReservationRepository) FindAll(status string, page, limit int) ([]model.Reservation, int, error) {
+	args := m.Called(status, page, limit)
+	return args.Get(0).([]model.Reservation), args.Int(1), args.Error(2)
+}
+
+func (m *MockReservationRepository) Extend(id int, expiresAt time.Time) error {
+	args := m.Called(id, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockReservationRepository) Release(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// TestReservationService_Create_MergesLines tests duplicate lines are checked against available stock together
+func TestReservationService_Create_MergesLines(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockReservationRepo := new(MockReservationRepository)
+	service := NewReservationService(repository.Repository{ItemRepo: mockItemRepo, ReservationRepo: mockReservationRepo})
+
+	mockItemRepo.On("FindByID", 1).Return(&model.Item{ID: 1, Name: "Mouse", Stock: 5, Reserved: 2, Available: 3}, nil)
+
+	_, _, err := service.Create(2, dto.ReservationRequest{
+		ExpiresInHours: 24,
+		Items: []dto.ReservationItemRequest{
+			{ItemID: 1, Quantity: 2},
+			{ItemID: 1, Quantity: 2},
+		},
+	})
+
+	require.Error(t, err)
+	require.Equal(t, repository.ErrInsufficientAvailable.Error()+": Mouse", err.Error())
+	mockReservationRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestReservationService_Create(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockReservationRepo := new(MockReservationRepository)
+	service := NewReservationService(repository.Repository{ItemRepo: mockItemRepo, ReservationRepo: mockReservationRepo})
+
+	mockItemRepo.On("FindByID", 1).Return(&model.Item{ID: 1, Name: "Mouse", Stock: 5, Available: 5}, nil)
+	mockReservationRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	before := time.Now()
+	reservation, items, err := service.Create(2, dto.ReservationRequest{
+		ExpiresInHours: 48,
+		Items:          []dto.ReservationItemRequest{{ItemID: 1, Quantity: 2}, {ItemID: 1, Quantity: 1}},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, 2, reservation.CreatedBy)
+	require.True(t, reservation.ExpiresAt.After(before.Add(47*time.Hour)))
+	require.Equal(t, []model.ReservationItem{{ItemID: 1, Quantity: 3}}, items)
+}
+
+// TestReservationService_Extend_Expired tests an expired reservation can't be extended
+func TestReservationService_Extend_Expired(t *testing.T) {
+	mockReservationRepo := new(MockReservationRepository)
+	service := NewReservationService(repository.Repository{ReservationRepo: mockReservationRepo})
+
+	mockReservationRepo.On("FindByID", 9).Return(&model.Reservation{ID: 9, Status: model.ReservationStatusExpired}, nil)
+
+	_, err := service.Extend(9, 24)
+
+	require.Error(t, err)
+	require.Equal(t, "reservation is expired", err.Error())
+	mockReservationRepo.AssertNotCalled(t, "Extend", mock.Anything, mock.Anything)
+}
+
+func TestReservationService_GetAllReservations_InvalidStatus(t *testing.T) {
+	service := NewReservationService(repository.Repository{ReservationRepo: new(MockReservationRepository)})
+
+	_, _, err := service.GetAllReservations("pending", 1, 10)
+
+	require.Error(t, err)
+	require.Equal(t, "invalid reservation status", err.Error())
+}
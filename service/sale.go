@@ -4,16 +4,21 @@ import (
 	"errors"
 	"project-app-inventory/dto"
 	"project-app-inventory/model"
+	"project-app-inventory/money"
 	"project-app-inventory/repository"
 	"project-app-inventory/utils"
+	"slices"
+	"strconv"
+	"time"
 )
 
 type SaleService interface {
-	Create(userID int, items []dto.SaleItemRequest) (*model.Sale, error)
+	Create(userID int, req dto.SaleRequest) (*model.Sale, error)
 	GetAllSales(page, limit int) (*[]model.Sale, *dto.Pagination, error)
+	GetSalesAfter(afterID, limit int) ([]model.Sale, error)
 	GetSaleByID(id int) (*model.Sale, []model.SaleItem, error)
-	Update(id int, items []dto.SaleItemRequest) error
-	Delete(id int) error
+	Update(id int, userID int, req dto.SaleRequest) error
+	Delete(id int, userID int) error
 }
 
 type saleService struct {
@@ -24,50 +29,55 @@ func NewSaleService(repo repository.Repository) SaleService {
 	return &saleService{Repo: repo}
 }
 
-func (s *saleService) Create(userID int, items []dto.SaleItemRequest) (*model.Sale, error) {
-	if len(items) == 0 {
+func (s *saleService) Create(userID int, req dto.SaleRequest) (*model.Sale, error) {
+	if len(req.Items) == 0 {
 		return nil, errors.New("sale must have at least one item")
 	}
 
-	// Prepare sale items and calculate total
-	var saleItems []model.SaleItem
-	var totalAmount float64
-
-	for _, item := range items {
-		// Get item details
-		itemData, err := s.Repo.ItemRepo.FindByID(item.ItemID)
-		if err != nil {
-			return nil, err
-		}
-		if itemData == nil {
-			return nil, errors.New("item not found")
-		}
+	reservation, held, err := s.saleReservation(req.ReservationID)
+	if err != nil {
+		return nil, err
+	}
 
-		// Check stock availability
-		if itemData.Stock < item.Quantity {
-			return nil, errors.New("insufficient stock for item: " + itemData.Name)
+	// A sale from a reservation defaults to the reservation's customer
+	customerRequest := req.CustomerID
+	if reservation != nil && reservation.CustomerID != nil {
+		if customerRequest != 0 && customerRequest != *reservation.CustomerID {
+			return nil, errors.New("customer does not match the reservation")
 		}
+		customerRequest = *reservation.CustomerID
+	}
 
-		subtotal := itemData.Price * float64(item.Quantity)
-		totalAmount += subtotal
+	customerID, err := s.saleCustomer(customerRequest)
+	if err != nil {
+		return nil, err
+	}
 
-		saleItems = append(saleItems, model.SaleItem{
-			ItemID:      item.ItemID,
-			Quantity:    item.Quantity,
-			PriceAtSale: itemData.Price,
-			Subtotal:    subtotal,
-		})
+	// Prepare sale items per rack location
+	saleItems, err := s.buildSaleItems(req.Items, nil, held)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create sale
 	sale := &model.Sale{
-		UserID:      userID,
-		TotalAmount: totalAmount,
+		UserID:     userID,
+		CustomerID: customerID,
+	}
+	if reservation != nil {
+		sale.ReservationID = &reservation.ID
+	}
+	if err := priceSale(sale, saleItems, req.DiscountPercent, req.DiscountAmount); err != nil {
+		return nil, err
 	}
 
-	err := s.Repo.SaleRepo.Create(sale, saleItems)
+	err = s.Repo.SaleRepo.Create(sale, saleItems)
+	if errors.Is(err, repository.ErrInsufficientAvailable) {
+		// A reservation came in after the lines were checked
+		return nil, errors.New(err.Error() + ", the rest is reserved")
+	}
 	if err != nil {
-		return nil, err
+		return nil, serialError(err)
 	}
 
 	return sale, nil
@@ -88,6 +98,11 @@ func (s *saleService) GetAllSales(page, limit int) (*[]model.Sale, *dto.Paginati
 	return &sales, &pagination, nil
 }
 
+// GetSalesAfter reads the sales after afterID in id order, a page of an export
+func (s *saleService) GetSalesAfter(afterID, limit int) ([]model.Sale, error) {
+	return s.Repo.SaleRepo.FindAfter(afterID, limit)
+}
+
 func (s *saleService) GetSaleByID(id int) (*model.Sale, []model.SaleItem, error) {
 	sale, err := s.Repo.SaleRepo.FindByID(id)
 	if err != nil {
@@ -105,10 +120,13 @@ func (s *saleService) GetSaleByID(id int) (*model.Sale, []model.SaleItem, error)
 	return sale, items, nil
 }
 
-func (s *saleService) Update(id int, items []dto.SaleItemRequest) error {
-	if len(items) == 0 {
+func (s *saleService) Update(id int, userID int, req dto.SaleRequest) error {
+	if len(req.Items) == 0 {
 		return errors.New("sale must have at least one item")
 	}
+	if req.ReservationID != 0 {
+		return errors.New("reservation can only be used when creating a sale")
+	}
 
 	// Check if sale exists
 	existingSale, err := s.Repo.SaleRepo.FindByID(id)
@@ -118,47 +136,53 @@ func (s *saleService) Update(id int, items []dto.SaleItemRequest) error {
 	if existingSale == nil {
 		return errors.New("sale not found")
 	}
+	if err := s.checkNoReturns(id); err != nil {
+		return err
+	}
 
-	// Prepare sale items and calculate total
-	var saleItems []model.SaleItem
-	var totalAmount float64
+	// Stock of the current lines is returned before the new lines are drawn
+	oldItems, err := s.Repo.SaleRepo.FindSaleItems(id)
+	if err != nil {
+		return err
+	}
 
-	for _, item := range items {
-		// Get item details
-		itemData, err := s.Repo.ItemRepo.FindByID(item.ItemID)
+	// Without customer_id the sale keeps its current customer
+	customerID := existingSale.CustomerID
+	if req.CustomerID != 0 {
+		customerID, err = s.saleCustomer(req.CustomerID)
 		if err != nil {
 			return err
 		}
-		if itemData == nil {
-			return errors.New("item not found")
-		}
-
-		subtotal := itemData.Price * float64(item.Quantity)
-		totalAmount += subtotal
+	}
 
-		saleItems = append(saleItems, model.SaleItem{
-			ItemID:      item.ItemID,
-			Quantity:    item.Quantity,
-			PriceAtSale: itemData.Price,
-			Subtotal:    subtotal,
-		})
+	// Prepare sale items per rack location
+	saleItems, err := s.buildSaleItems(req.Items, oldItems, nil)
+	if err != nil {
+		return err
 	}
 
 	// Update sale
 	sale := &model.Sale{
-		ID:          id,
-		TotalAmount: totalAmount,
+		ID:         id,
+		CustomerID: customerID,
+	}
+	if err := priceSale(sale, saleItems, req.DiscountPercent, req.DiscountAmount); err != nil {
+		return err
 	}
 
-	err = s.Repo.SaleRepo.Update(id, sale, saleItems)
+	err = s.Repo.SaleRepo.Update(id, userID, sale, saleItems)
+	if errors.Is(err, repository.ErrInsufficientAvailable) {
+		// A reservation came in after the lines were checked
+		return errors.New(err.Error() + ", the rest is reserved")
+	}
 	if err != nil {
-		return err
+		return serialError(err)
 	}
 
 	return nil
 }
 
-func (s *saleService) Delete(id int) error {
+func (s *saleService) Delete(id int, userID int) error {
 	// Check if sale exists
 	existingSale, err := s.Repo.SaleRepo.FindByID(id)
 	if err != nil {
@@ -167,6 +191,482 @@ func (s *saleService) Delete(id int) error {
 	if existingSale == nil {
 		return errors.New("sale not found")
 	}
+	if err := s.checkNoReturns(id); err != nil {
+		return err
+	}
+
+	return s.Repo.SaleRepo.Delete(id, userID)
+}
 
-	return s.Repo.SaleRepo.Delete(id)
+// checkNoReturns refuses changes to a sale once goods were returned against it,
+// editing or voiding it would restock the returned lines a second time
+func (s *saleService) checkNoReturns(saleID int) error {
+	returns, err := s.Repo.SaleReturnRepo.FindBySaleID(saleID)
+	if err != nil {
+		return err
+	}
+	if len(returns) > 0 {
+		return errors.New("sale has returns and cannot be changed")
+	}
+	return nil
+}
+
+// saleReservation loads the reservation a sale is made from, zero means none.
+// Its lines are returned so the stock they hold counts as available to the sale.
+func (s *saleService) saleReservation(reservationID int) (*model.Reservation, []model.ReservationItem, error) {
+	if reservationID == 0 {
+		return nil, nil, nil
+	}
+
+	reservation, err := s.Repo.ReservationRepo.FindByID(reservationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if reservation == nil {
+		return nil, nil, errors.New("reservation not found")
+	}
+	if reservation.Status != model.ReservationStatusActive {
+		return nil, nil, errors.New("reservation is " + reservation.Status)
+	}
+
+	items, err := s.Repo.ReservationRepo.FindReservationItems(reservationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reservation, items, nil
+}
+
+// saleCustomer checks the customer of a sale, zero means a walk-in sale without customer
+func (s *saleService) saleCustomer(customerID int) (*int, error) {
+	if customerID == 0 {
+		return nil, nil
+	}
+
+	customer, err := s.Repo.CustomerRepo.FindByID(customerID)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, errors.New("customer not found")
+	}
+	return &customer.ID, nil
+}
+
+// buildSaleItems prices the requested items and decides which rack each unit is
+// drawn from. An explicit rack_id must cover the whole quantity; otherwise the
+// item's home rack is used first, then the fullest racks, splitting the request
+// into one sale line per rack. Lot tracked items are sold from their lots,
+// unexpired ones first-expiry-first-out over all racks or within the explicit
+// rack, one line per lot and rack. Serialized items are sold by serial, one
+// line per rack the serials sit in. Quantities in released are treated as available
+// again (the lines of a sale being edited). Stock held by reservations can't be
+// sold, except what held (the sale's own reservation) keeps for this sale.
+// Lines carry their gross amount, line discount and tax rate, priceSale
+// completes the breakdown.
+func (s *saleService) buildSaleItems(items []dto.SaleItemRequest, released []model.SaleItem, held []model.ReservationItem) ([]model.SaleItem, error) {
+	var saleItems []model.SaleItem
+
+	// Remaining quantity per item and rack, shared by all lines of the request
+	available := make(map[int][]model.ItemLocation)
+	lots := make(map[int][]model.ItemLot)
+	taxRates := make(map[int]money.Rate)
+	requested := make(map[int]int)
+	sold := make(map[string]bool)
+
+	// Lots expiring today can still be sold
+	year, month, day := time.Now().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	for _, item := range items {
+		// Get item details
+		itemData, err := s.Repo.ItemRepo.FindByID(item.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		if itemData == nil {
+			return nil, errors.New("item not found")
+		}
+
+		// An open stocktake may freeze the item in the racks it counts, it is
+		// picked from its other racks
+		frozenRacks, err := s.Repo.StocktakeRepo.FindFrozenRacks(item.ItemID)
+		if err != nil {
+			return nil, err
+		}
+		frozen := make(map[int]bool, len(frozenRacks))
+		for _, rackID := range frozenRacks {
+			frozen[rackID] = true
+		}
+		if frozen[item.RackID] {
+			return nil, errors.New("item is frozen by an open stocktake in rack " + strconv.Itoa(item.RackID) + ": " + itemData.Name)
+		}
+
+		taxRate, err := s.taxRate(itemData, taxRates)
+		if err != nil {
+			return nil, err
+		}
+
+		gross := itemData.Price.Mul(item.Quantity)
+		discount, err := discountOf(gross, item.DiscountPercent, item.DiscountAmount)
+		if err != nil {
+			return nil, errors.New(err.Error() + ": " + itemData.Name)
+		}
+
+		requested[item.ItemID] += item.Quantity
+		if err := checkReserved(itemData, requested[item.ItemID], released, held); err != nil {
+			return nil, err
+		}
+
+		serialNumbers, err := checkSerials(itemData, item.SerialNumbers, item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		var lines []model.SaleItem
+		if itemData.Serialized {
+			serials, err := s.Repo.ItemSerialRepo.FindBySerialNumbers(item.ItemID, serialNumbers)
+			if err != nil {
+				return nil, err
+			}
+			lines, err = pickSerials(serialNumbers, serials, item.RackID, released, sold)
+			if err != nil {
+				return nil, err
+			}
+			for _, line := range lines {
+				if frozen[line.RackID] {
+					return nil, errors.New("item is frozen by an open stocktake in rack " + strconv.Itoa(line.RackID) + ": " + itemData.Name)
+				}
+			}
+		} else if itemData.TrackLots {
+			itemLots, ok := lots[item.ItemID]
+			if !ok {
+				itemLots, err = s.Repo.ItemLotRepo.FindByItemID(item.ItemID)
+				if err != nil {
+					return nil, err
+				}
+				itemLots = releaseLots(itemData, itemLots, released)
+				itemLots = slices.DeleteFunc(itemLots, func(lot model.ItemLot) bool { return frozen[lot.RackID] })
+			}
+
+			lines, err = pickLots(itemLots, item.RackID, item.Quantity, today)
+			if err != nil {
+				return nil, errors.New(err.Error() + ": " + itemData.Name)
+			}
+			lots[item.ItemID] = itemLots
+		} else {
+			locations, ok := available[item.ItemID]
+			if !ok {
+				locations, err = s.Repo.ItemLocationRepo.FindByItemID(item.ItemID)
+				if err != nil {
+					return nil, err
+				}
+				locations = releaseLocations(itemData, locations, released)
+				locations = slices.DeleteFunc(locations, func(location model.ItemLocation) bool { return frozen[location.RackID] })
+			}
+
+			picks, err := pickLocations(locations, item.RackID, item.Quantity)
+			if err != nil {
+				return nil, errors.New(err.Error() + ": " + itemData.Name)
+			}
+			available[item.ItemID] = locations
+
+			lines = make([]model.SaleItem, len(picks))
+			for i, pick := range picks {
+				lines[i] = model.SaleItem{RackID: pick.RackID, Quantity: pick.Quantity}
+			}
+		}
+
+		// A line split over racks and lots shares its discount by quantity
+		weights := make([]int64, len(lines))
+		for i, line := range lines {
+			weights[i] = int64(line.Quantity)
+		}
+		discounts := discount.Allocate(weights)
+
+		for i, line := range lines {
+			saleItems = append(saleItems, model.SaleItem{
+				ItemID:         item.ItemID,
+				RackID:         line.RackID,
+				Quantity:       line.Quantity,
+				PriceAtSale:    itemData.Price,
+				GrossAmount:    itemData.Price.Mul(line.Quantity),
+				DiscountAmount: discounts[i],
+				TaxRate:        taxRate,
+				LotNumber:      line.LotNumber,
+				SerialNumbers:  line.SerialNumbers,
+			})
+		}
+	}
+
+	return saleItems, nil
+}
+
+// checkReserved refuses to sell stock that reservations of other orders hold
+func checkReserved(item *model.Item, quantity int, released []model.SaleItem, held []model.ReservationItem) error {
+	reserved := item.Reserved
+	for _, line := range held {
+		if line.ItemID == item.ID {
+			reserved -= line.Quantity
+		}
+	}
+	if reserved <= 0 {
+		return nil
+	}
+
+	available := item.Stock - item.InTransit - reserved
+	for _, line := range released {
+		if line.ItemID == item.ID {
+			available += line.Quantity
+		}
+	}
+	if quantity > available {
+		return errors.New(repository.ErrInsufficientAvailable.Error() + ", the rest is reserved: " + item.Name)
+	}
+	return nil
+}
+
+// taxRate returns the item's own rate or else the rate of its category
+func (s *saleService) taxRate(item *model.Item, categoryRates map[int]money.Rate) (money.Rate, error) {
+	if item.TaxRate != nil {
+		return *item.TaxRate, nil
+	}
+	if rate, ok := categoryRates[item.CategoryID]; ok {
+		return rate, nil
+	}
+
+	category, err := s.Repo.CategoryRepo.FindByID(item.CategoryID)
+	if err != nil {
+		return 0, err
+	}
+	if category == nil {
+		return 0, errors.New("category not found")
+	}
+
+	var rate money.Rate
+	if category.TaxRate != nil {
+		rate = *category.TaxRate
+	}
+	categoryRates[item.CategoryID] = rate
+	return rate, nil
+}
+
+// priceSale spreads the sale discount over the lines by their amount after line
+// discounts, then taxes every line on its discounted amount and totals the sale
+func priceSale(sale *model.Sale, lines []model.SaleItem, discountPercent money.Rate, discountAmount money.Amount) error {
+	weights := make([]int64, len(lines))
+	var base money.Amount
+	for i, line := range lines {
+		weights[i] = int64(line.GrossAmount - line.DiscountAmount)
+		base += line.GrossAmount - line.DiscountAmount
+	}
+
+	saleDiscount, err := discountOf(base, discountPercent, discountAmount)
+	if err != nil {
+		return errors.New(err.Error() + ": sale")
+	}
+	shares := saleDiscount.Allocate(weights)
+
+	sale.GrossAmount, sale.DiscountAmount, sale.TaxAmount, sale.TotalAmount = 0, 0, 0, 0
+	for i := range lines {
+		lines[i].DiscountAmount += shares[i]
+		taxable := lines[i].GrossAmount - lines[i].DiscountAmount
+		lines[i].TaxAmount = taxable.Percent(lines[i].TaxRate)
+		lines[i].Subtotal = taxable + lines[i].TaxAmount
+
+		sale.GrossAmount += lines[i].GrossAmount
+		sale.DiscountAmount += lines[i].DiscountAmount
+		sale.TaxAmount += lines[i].TaxAmount
+		sale.TotalAmount += lines[i].Subtotal
+	}
+
+	return nil
+}
+
+// discountOf returns the discount taken off base, given either as a percentage
+// or as a fixed amount that can't exceed base
+func discountOf(base money.Amount, percent money.Rate, amount money.Amount) (money.Amount, error) {
+	if percent != 0 && amount != 0 {
+		return 0, errors.New("discount must be either a percent or an amount")
+	}
+	if percent < 0 || percent > money.MaxRate {
+		return 0, errors.New("discount percent must be between 0 and 100")
+	}
+	if amount < 0 {
+		return 0, errors.New("discount amount cannot be negative")
+	}
+	if amount > base {
+		return 0, errors.New("discount amount exceeds the price")
+	}
+
+	if percent != 0 {
+		return base.Percent(percent), nil
+	}
+	return amount, nil
+}
+
+// releaseLocations adds the quantities of released sale lines of the item back
+// onto its locations, creating the location when the rack is currently empty
+func releaseLocations(item *model.Item, locations []model.ItemLocation, released []model.SaleItem) []model.ItemLocation {
+	for _, line := range released {
+		if line.ItemID != item.ID {
+			continue
+		}
+		found := false
+		for i := range locations {
+			if locations[i].RackID == line.RackID {
+				locations[i].Quantity += line.Quantity
+				found = true
+				break
+			}
+		}
+		if !found {
+			locations = append(locations, model.ItemLocation{ItemID: item.ID, RackID: line.RackID, Quantity: line.Quantity})
+		}
+	}
+	return locations
+}
+
+// pickLocations draws quantity from locations (already in picking order) and
+// decrements them in place so later lines for the same item see what is left
+func pickLocations(locations []model.ItemLocation, rackID, quantity int) ([]model.ItemLocation, error) {
+	if rackID != 0 {
+		for i := range locations {
+			if locations[i].RackID == rackID {
+				if locations[i].Quantity < quantity {
+					return nil, errors.New("insufficient stock in selected rack for item")
+				}
+				locations[i].Quantity -= quantity
+				return []model.ItemLocation{{RackID: rackID, Quantity: quantity}}, nil
+			}
+		}
+		return nil, errors.New("item is not stored in selected rack")
+	}
+
+	var picks []model.ItemLocation
+	remaining := quantity
+	for i := range locations {
+		if remaining == 0 {
+			break
+		}
+		if locations[i].Quantity == 0 {
+			continue
+		}
+		take := min(locations[i].Quantity, remaining)
+		picks = append(picks, model.ItemLocation{RackID: locations[i].RackID, Quantity: take})
+		remaining -= take
+	}
+	if remaining > 0 {
+		return nil, errors.New("insufficient stock for item")
+	}
+
+	for _, pick := range picks {
+		for i := range locations {
+			if locations[i].RackID == pick.RackID {
+				locations[i].Quantity -= pick.Quantity
+			}
+		}
+	}
+	return picks, nil
+}
+
+// releaseLots adds the quantities of released sale lines of the item back onto
+// the lots and racks they were taken from
+func releaseLots(item *model.Item, lots []model.ItemLot, released []model.SaleItem) []model.ItemLot {
+	for _, line := range released {
+		if line.ItemID != item.ID || line.LotNumber == nil {
+			continue
+		}
+		found := false
+		for i := range lots {
+			if lots[i].LotNumber == *line.LotNumber && lots[i].RackID == line.RackID {
+				lots[i].Quantity += line.Quantity
+				found = true
+				break
+			}
+		}
+		if !found {
+			lots = append(lots, model.ItemLot{ItemID: item.ID, RackID: line.RackID, LotNumber: *line.LotNumber, Quantity: line.Quantity})
+		}
+	}
+	return lots
+}
+
+// pickLots draws quantity from lots (already in FEFO order), only from rackID
+// when one is given, skipping lots expired before today. It returns a line per
+// lot and rack drawn from and decrements the lots in place like pickLocations.
+func pickLots(lots []model.ItemLot, rackID, quantity int, today time.Time) ([]model.SaleItem, error) {
+	var lines []model.SaleItem
+	var picked []int
+	remaining := quantity
+	for i := range lots {
+		if remaining == 0 {
+			break
+		}
+		if lots[i].Quantity == 0 || lots[i].Expired(today) || (rackID != 0 && lots[i].RackID != rackID) {
+			continue
+		}
+		take := min(lots[i].Quantity, remaining)
+		lotNumber := lots[i].LotNumber
+		lines = append(lines, model.SaleItem{RackID: lots[i].RackID, Quantity: take, LotNumber: &lotNumber})
+		picked = append(picked, i)
+		remaining -= take
+	}
+	if remaining > 0 {
+		if rackID != 0 {
+			return nil, errors.New("insufficient unexpired lot stock in selected rack for item")
+		}
+		return nil, errors.New("insufficient unexpired lot stock for item")
+	}
+
+	for j, i := range picked {
+		lots[i].Quantity -= lines[j].Quantity
+	}
+	return lines, nil
+}
+
+// pickSerials groups the requested serials into one line per rack they sit in.
+// A serial must be in stock, or sold on a released line, and in rackID when one
+// is given; sold holds the serials already taken by earlier lines of the request.
+func pickSerials(serialNumbers []string, serials []model.ItemSerial, rackID int, released []model.SaleItem, sold map[string]bool) ([]model.SaleItem, error) {
+	releasedSerials := make(map[string]bool)
+	for _, line := range released {
+		for _, serialNumber := range line.SerialNumbers {
+			releasedSerials[serialNumber] = true
+		}
+	}
+
+	racks := make(map[string]int)
+	for _, serial := range serials {
+		if serial.Status == model.SerialStatusInStock ||
+			(serial.Status == model.SerialStatusSold && releasedSerials[serial.SerialNumber]) {
+			racks[serial.SerialNumber] = serial.RackID
+		}
+	}
+
+	var lines []model.SaleItem
+	for _, serialNumber := range serialNumbers {
+		rack, ok := racks[serialNumber]
+		if !ok || sold[serialNumber] {
+			return nil, errors.New("serial number is not in stock: " + serialNumber)
+		}
+		if rackID != 0 && rack != rackID {
+			return nil, errors.New("serial number " + serialNumber + " is not in rack " + strconv.Itoa(rackID))
+		}
+		sold[serialNumber] = true
+
+		line := -1
+		for i := range lines {
+			if lines[i].RackID == rack {
+				line = i
+				break
+			}
+		}
+		if line < 0 {
+			lines = append(lines, model.SaleItem{RackID: rack})
+			line = len(lines) - 1
+		}
+		lines[line].Quantity++
+		lines[line].SerialNumbers = append(lines[line].SerialNumbers, serialNumber)
+	}
+	return lines, nil
 }
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"project-app-inventory/repository"
+	"strconv"
+)
+
+type SaleReturnService interface {
+	Create(saleID, userID int, req dto.SaleReturnRequest) (*model.SaleReturn, []model.SaleReturnItem, error)
+	GetSaleReturns(saleID int) ([]model.SaleReturn, []model.SaleReturnItem, error)
+}
+
+type saleReturnService struct {
+	Repo repository.Repository
+}
+
+func NewSaleReturnService(repo repository.Repository) SaleReturnService {
+	return &saleReturnService{Repo: repo}
+}
+
+// Create returns sale lines, refunding them at the price they were sold for
+func (s *saleReturnService) Create(saleID, userID int, req dto.SaleReturnRequest) (*model.SaleReturn, []model.SaleReturnItem, error) {
+	if len(req.Items) == 0 {
+		return nil, nil, errors.New("return must have at least one item")
+	}
+
+	// Check if sale exists
+	sale, err := s.Repo.SaleRepo.FindByID(saleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sale == nil {
+		return nil, nil, errors.New("sale not found")
+	}
+
+	saleItems, err := s.Repo.SaleRepo.FindSaleItems(saleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines := make(map[int]model.SaleItem)
+	for _, line := range saleItems {
+		lines[line.ID] = line
+	}
+
+	// Quantity still returnable per sale line after earlier returns
+	returnedItems, err := s.Repo.SaleReturnRepo.FindReturnItemsBySaleID(saleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	remaining := make(map[int]int)
+	for _, line := range saleItems {
+		remaining[line.ID] = line.Quantity
+	}
+	returnedSerials := make(map[string]bool)
+	for _, returned := range returnedItems {
+		remaining[returned.SaleItemID] -= returned.Quantity
+		for _, serialNumber := range returned.SerialNumbers {
+			returnedSerials[serialNumber] = true
+		}
+	}
+
+	var items []model.SaleReturnItem
+	var refundAmount money.Amount
+	for _, reqItem := range req.Items {
+		line, ok := lines[reqItem.SaleItemID]
+		if !ok {
+			return nil, nil, errors.New("sale item not found: " + strconv.Itoa(reqItem.SaleItemID))
+		}
+		if reqItem.Quantity > remaining[line.ID] {
+			return nil, nil, errors.New(repository.ErrOverReturn.Error() + ": sale item " + strconv.Itoa(line.ID))
+		}
+		returnedBefore := line.Quantity - remaining[line.ID]
+		remaining[line.ID] -= reqItem.Quantity
+
+		serialNumbers, err := returnedSerialNumbers(line, reqItem, returnedSerials)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rackID := line.RackID
+		if reqItem.RackID != 0 && reqItem.Condition == model.ReturnConditionRestock {
+			rack, err := s.Repo.RackRepo.FindByID(reqItem.RackID)
+			if err != nil {
+				return nil, nil, err
+			}
+			if rack == nil {
+				return nil, nil, errors.New("rack not found: " + strconv.Itoa(reqItem.RackID))
+			}
+			rackID = rack.ID
+		}
+
+		// Units are refunded at their share of the net line, discounts and tax
+		// included; taking the difference of running shares means a line returned
+		// in several parts refunds exactly its subtotal
+		returnedAfter := int64(returnedBefore + reqItem.Quantity)
+		refund := line.Subtotal.Share(returnedAfter, int64(line.Quantity)) -
+			line.Subtotal.Share(int64(returnedBefore), int64(line.Quantity))
+		refundAmount += refund
+
+		items = append(items, model.SaleReturnItem{
+			SaleItemID:    line.ID,
+			ItemID:        line.ItemID,
+			RackID:        rackID,
+			Quantity:      reqItem.Quantity,
+			Condition:     reqItem.Condition,
+			PriceAtSale:   line.PriceAtSale,
+			RefundAmount:  refund,
+			SerialNumbers: serialNumbers,
+		})
+	}
+
+	saleReturn := &model.SaleReturn{
+		SaleID:       saleID,
+		RefundAmount: refundAmount,
+		CreatedBy:    userID,
+	}
+	if req.Note != "" {
+		saleReturn.Note = &req.Note
+	}
+
+	err = s.Repo.SaleReturnRepo.Create(saleReturn, items)
+	if err != nil {
+		return nil, nil, serialError(err)
+	}
+
+	return saleReturn, items, nil
+}
+
+// returnedSerialNumbers checks the serials returned from a sale line. Units of a
+// serialized line are returned by serial, each sold on the line and not returned
+// yet; returned collects the serials returned so far.
+func returnedSerialNumbers(line model.SaleItem, reqItem dto.SaleReturnItemRequest, returned map[string]bool) ([]string, error) {
+	if len(line.SerialNumbers) == 0 {
+		if len(reqItem.SerialNumbers) > 0 {
+			return nil, errors.New("sale item has no serial numbers: " + strconv.Itoa(line.ID))
+		}
+		return nil, nil
+	}
+	if len(reqItem.SerialNumbers) != reqItem.Quantity {
+		return nil, errors.New("serial_numbers must list one serial per returned unit: sale item " + strconv.Itoa(line.ID))
+	}
+
+	onLine := make(map[string]bool)
+	for _, serialNumber := range line.SerialNumbers {
+		onLine[serialNumber] = true
+	}
+	for _, serialNumber := range reqItem.SerialNumbers {
+		if !onLine[serialNumber] {
+			return nil, errors.New("serial number was not sold on sale item " + strconv.Itoa(line.ID) + ": " + serialNumber)
+		}
+		if returned[serialNumber] {
+			return nil, errors.New("serial number already returned: " + serialNumber)
+		}
+		returned[serialNumber] = true
+	}
+	return reqItem.SerialNumbers, nil
+}
+
+func (s *saleReturnService) GetSaleReturns(saleID int) ([]model.SaleReturn, []model.SaleReturnItem, error) {
+	// Check if sale exists
+	sale, err := s.Repo.SaleRepo.FindByID(saleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sale == nil {
+		return nil, nil, errors.New("sale not found")
+	}
+
+	returns, err := s.Repo.SaleReturnRepo.FindBySaleID(saleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, err := s.Repo.SaleReturnRepo.FindReturnItemsBySaleID(saleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return returns, items, nil
+}
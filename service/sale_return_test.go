@@ -0,0 +1,163 @@
+package service
+
+import (
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"project-app-inventory/repository"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSaleReturnRepository mocks SaleReturnRepository interface
+type MockSaleReturnRepository struct {
+	mock.Mock
+}
+
+func (m *MockSaleReturnRepository) Create(saleReturn *model.SaleReturn, items []model.SaleReturnItem) error {
+	args := m.Called(saleReturn, items)
+	return args.Error(0)
+}
+
+func (m *MockSaleReturnRepository) FindBySaleID(saleID int) ([]model.SaleReturn, error) {
+	args := m.Called(saleID)
+	return args.Get(0).([]model.SaleReturn), args.Error(1)
+}
+
+func (m *MockSaleReturnRepository) FindReturnItemsBySaleID(saleID int) ([]model.SaleReturnItem, error) {
+	args := m.Called(saleID)
+	return args.Get(0).([]model.SaleReturnItem), args.Error(1)
+}
+
+// TestSaleReturnService_Create_RefundFromPriceAtSale tests the refund uses the price the line was sold for
+func TestSaleReturnService_Create_RefundFromNetSubtotal(t *testing.T) {
+	mockSaleRepo := new(MockSaleRepository)
+	mockReturnRepo := new(MockSaleReturnRepository)
+	repo := repository.Repository{
+		SaleRepo:       mockSaleRepo,
+		SaleReturnRepo: mockReturnRepo,
+	}
+	service := NewSaleReturnService(repo)
+
+	// Line 7 sold 3 units for a net 100000 after discount and tax
+	mockSaleRepo.On("FindByID", 5).Return(&model.Sale{ID: 5}, nil)
+	mockSaleRepo.On("FindSaleItems", 5).Return([]model.SaleItem{
+		{ID: 7, SaleID: 5, ItemID: 3, RackID: 1, Quantity: 3, PriceAtSale: money.FromUnits(40000), Subtotal: money.FromUnits(100000)},
+		{ID: 8, SaleID: 5, ItemID: 4, RackID: 2, Quantity: 1, PriceAtSale: money.FromUnits(50000), Subtotal: money.FromUnits(55500)},
+	}, nil)
+	mockReturnRepo.On("FindReturnItemsBySaleID", 5).Return([]model.SaleReturnItem{}, nil)
+	mockReturnRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	req := dto.SaleReturnRequest{Items: []dto.SaleReturnItemRequest{
+		{SaleItemID: 7, Quantity: 2, Condition: model.ReturnConditionRestock},
+		{SaleItemID: 8, Quantity: 1, Condition: model.ReturnConditionDamaged},
+	}}
+	saleReturn, items, err := service.Create(5, 2, req)
+
+	require.NoError(t, err)
+	require.Equal(t, money.Amount(6666667+5550000), saleReturn.RefundAmount)
+	require.Equal(t, 2, saleReturn.CreatedBy)
+	require.Len(t, items, 2)
+	require.Equal(t, money.Amount(6666667), items[0].RefundAmount)
+	require.Equal(t, 1, items[0].RackID)
+	require.Equal(t, model.ReturnConditionDamaged, items[1].Condition)
+}
+
+func TestSaleReturnService_Create_LastUnitRefundsRest(t *testing.T) {
+	mockSaleRepo := new(MockSaleRepository)
+	mockReturnRepo := new(MockSaleReturnRepository)
+	repo := repository.Repository{
+		SaleRepo:       mockSaleRepo,
+		SaleReturnRepo: mockReturnRepo,
+	}
+	service := NewSaleReturnService(repo)
+
+	mockSaleRepo.On("FindByID", 5).Return(&model.Sale{ID: 5}, nil)
+	mockSaleRepo.On("FindSaleItems", 5).Return([]model.SaleItem{
+		{ID: 7, SaleID: 5, ItemID: 3, RackID: 1, Quantity: 3, PriceAtSale: money.FromUnits(40000), Subtotal: money.FromUnits(100000)},
+	}, nil)
+	mockReturnRepo.On("FindReturnItemsBySaleID", 5).Return([]model.SaleReturnItem{
+		{SaleItemID: 7, Quantity: 2, RefundAmount: money.Amount(6666667)},
+	}, nil)
+	mockReturnRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	req := dto.SaleReturnRequest{Items: []dto.SaleReturnItemRequest{
+		{SaleItemID: 7, Quantity: 1, Condition: model.ReturnConditionRestock},
+	}}
+	saleReturn, _, err := service.Create(5, 2, req)
+
+	require.NoError(t, err)
+	require.Equal(t, money.Amount(3333333), saleReturn.RefundAmount)
+}
+
+// TestSaleReturnService_Create_ExceedsEarlierReturns tests returning more than is left after earlier returns
+func TestSaleReturnService_Create_ExceedsEarlierReturns(t *testing.T) {
+	mockSaleRepo := new(MockSaleRepository)
+	mockReturnRepo := new(MockSaleReturnRepository)
+	repo := repository.Repository{
+		SaleRepo:       mockSaleRepo,
+		SaleReturnRepo: mockReturnRepo,
+	}
+	service := NewSaleReturnService(repo)
+
+	mockSaleRepo.On("FindByID", 5).Return(&model.Sale{ID: 5}, nil)
+	mockSaleRepo.On("FindSaleItems", 5).Return([]model.SaleItem{
+		{ID: 7, SaleID: 5, ItemID: 3, RackID: 1, Quantity: 3, PriceAtSale: money.FromUnits(100000)},
+	}, nil)
+	mockReturnRepo.On("FindReturnItemsBySaleID", 5).Return([]model.SaleReturnItem{
+		{SaleItemID: 7, Quantity: 2},
+	}, nil)
+
+	req := dto.SaleReturnRequest{Items: []dto.SaleReturnItemRequest{
+		{SaleItemID: 7, Quantity: 2, Condition: model.ReturnConditionRestock},
+	}}
+	_, _, err := service.Create(5, 2, req)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), repository.ErrOverReturn.Error())
+	mockReturnRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestSaleReturnService_Create_LineOfOtherSale tests returning a line that doesn't belong to the sale
+func TestSaleReturnService_Create_LineOfOtherSale(t *testing.T) {
+	mockSaleRepo := new(MockSaleRepository)
+	mockReturnRepo := new(MockSaleReturnRepository)
+	repo := repository.Repository{
+		SaleRepo:       mockSaleRepo,
+		SaleReturnRepo: mockReturnRepo,
+	}
+	service := NewSaleReturnService(repo)
+
+	mockSaleRepo.On("FindByID", 5).Return(&model.Sale{ID: 5}, nil)
+	mockSaleRepo.On("FindSaleItems", 5).Return([]model.SaleItem{
+		{ID: 7, SaleID: 5, ItemID: 3, RackID: 1, Quantity: 3, PriceAtSale: money.FromUnits(100000)},
+	}, nil)
+	mockReturnRepo.On("FindReturnItemsBySaleID", 5).Return([]model.SaleReturnItem{}, nil)
+
+	req := dto.SaleReturnRequest{Items: []dto.SaleReturnItemRequest{
+		{SaleItemID: 99, Quantity: 1, Condition: model.ReturnConditionRestock},
+	}}
+	_, _, err := service.Create(5, 2, req)
+
+	require.Error(t, err)
+	require.Equal(t, "sale item not found: 99", err.Error())
+}
+
+// TestSaleService_Delete_HasReturns tests that a sale with returns can't be voided
+func TestSaleService_Delete_HasReturns(t *testing.T) {
+	mockSaleRepo := new(MockSaleRepository)
+	mockReturnRepo := new(MockSaleReturnRepository)
+	repo := repository.Repository{SaleRepo: mockSaleRepo, SaleReturnRepo: mockReturnRepo}
+	service := NewSaleService(repo)
+
+	mockSaleRepo.On("FindByID", 5).Return(&model.Sale{ID: 5}, nil)
+	mockReturnRepo.On("FindBySaleID", 5).Return([]model.SaleReturn{{ID: 11, SaleID: 5}}, nil)
+
+	err := service.Delete(5, 1)
+
+	require.Error(t, err)
+	require.Equal(t, "sale has returns and cannot be changed", err.Error())
+	mockSaleRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"project-app-inventory/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPickLocations_HomeRackFirst tests splitting a quantity over racks in picking order
+func TestPickLocations_HomeRackFirst(t *testing.T) {
+	locations := []model.ItemLocation{
+		{RackID: 1, Quantity: 3}, // home rack
+		{RackID: 4, Quantity: 10},
+	}
+
+	picks, err := pickLocations(locations, 0, 5)
+
+	require.NoError(t, err)
+	require.Len(t, picks, 2)
+	require.Equal(t, model.ItemLocation{RackID: 1, Quantity: 3}, picks[0])
+	require.Equal(t, model.ItemLocation{RackID: 4, Quantity: 2}, picks[1])
+	require.Equal(t, 0, locations[0].Quantity)
+	require.Equal(t, 8, locations[1].Quantity)
+}
+
+// TestPickLocations_ExplicitRack tests drawing from a selected rack only
+func TestPickLocations_ExplicitRack(t *testing.T) {
+	locations := []model.ItemLocation{
+		{RackID: 1, Quantity: 3},
+		{RackID: 4, Quantity: 10},
+	}
+
+	picks, err := pickLocations(locations, 4, 5)
+	require.NoError(t, err)
+	require.Equal(t, []model.ItemLocation{{RackID: 4, Quantity: 5}}, picks)
+
+	_, err = pickLocations(locations, 1, 5)
+	require.Error(t, err)
+
+	_, err = pickLocations(locations, 9, 1)
+	require.Error(t, err)
+}
+
+// TestPickLocations_Insufficient tests a request larger than all racks together
+func TestPickLocations_Insufficient(t *testing.T) {
+	locations := []model.ItemLocation{{RackID: 1, Quantity: 3}}
+
+	picks, err := pickLocations(locations, 0, 4)
+
+	require.Error(t, err)
+	require.Nil(t, picks)
+	require.Equal(t, 3, locations[0].Quantity) // nothing consumed on failure
+}
+
+// TestPickLots_FEFO tests lots are drawn earliest expiry first over all racks and expired lots are skipped
+func TestPickLots_FEFO(t *testing.T) {
+	today := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	expired := today.AddDate(0, 0, -1)
+	soon := today
+	later := today.AddDate(0, 1, 0)
+	lots := []model.ItemLot{
+		{RackID: 1, LotNumber: "OLD", ExpiryDate: &expired, Quantity: 5},
+		{RackID: 2, LotNumber: "SOON", ExpiryDate: &soon, Quantity: 2},
+		{RackID: 1, LotNumber: "LATER", ExpiryDate: &later, Quantity: 4},
+		{RackID: 1, LotNumber: "NONE", Quantity: 10},
+	}
+
+	lines, err := pickLots(lots, 0, 3, today)
+
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	require.Equal(t, 2, lines[0].RackID)
+	require.Equal(t, 2, lines[0].Quantity)
+	require.Equal(t, "SOON", *lines[0].LotNumber)
+	require.Equal(t, 1, lines[1].RackID)
+	require.Equal(t, 1, lines[1].Quantity)
+	require.Equal(t, "LATER", *lines[1].LotNumber)
+	require.Equal(t, 5, lots[0].Quantity)
+	require.Equal(t, 3, lots[2].Quantity)
+
+	_, err = pickLots(lots, 0, 14, today)
+	require.Error(t, err)
+	require.Equal(t, 3, lots[2].Quantity) // nothing consumed on failure
+}
+
+// TestPickLots_InRack tests an explicit rack only draws the lots that rack holds
+func TestPickLots_InRack(t *testing.T) {
+	today := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	soon := today.AddDate(0, 0, 3)
+	lots := []model.ItemLot{
+		{RackID: 1, LotNumber: "A", ExpiryDate: &soon, Quantity: 4},
+		{RackID: 2, LotNumber: "A", ExpiryDate: &soon, Quantity: 1},
+		{RackID: 2, LotNumber: "B", Quantity: 5},
+	}
+
+	lines, err := pickLots(lots, 2, 3, today)
+
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	require.Equal(t, "A", *lines[0].LotNumber)
+	require.Equal(t, 1, lines[0].Quantity)
+	require.Equal(t, "B", *lines[1].LotNumber)
+	require.Equal(t, 2, lines[1].Quantity)
+	for _, line := range lines {
+		require.Equal(t, 2, line.RackID)
+	}
+	require.Equal(t, 4, lots[0].Quantity) // rack 1 untouched
+
+	_, err = pickLots(lots, 1, 5, today)
+	require.Error(t, err)
+}
+
+// TestSaleService_BuildSaleItems_ReleasedLines tests that lines of an edited sale count as available
+func TestSaleService_BuildSaleItems_ReleasedLines(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockLocationRepo := new(MockItemLocationRepository)
+	mockStocktakeRepo := new(MockStocktakeRepository)
+	repo := repository.Repository{ItemRepo: mockItemRepo, ItemLocationRepo: mockLocationRepo, StocktakeRepo: mockStocktakeRepo}
+	service := &saleService{Repo: repo}
+
+	noTax := money.Rate(0)
+	item := &model.Item{ID: 1, Name: "Mouse", RackID: 1, Price: money.FromUnits(1000), TaxRate: &noTax}
+	released := []model.SaleItem{{ItemID: 1, RackID: 2, Quantity: 2}}
+
+	mockItemRepo.On("FindByID", 1).Return(item, nil)
+	mockLocationRepo.On("FindByItemID", 1).Return([]model.ItemLocation{{ItemID: 1, RackID: 1, Quantity: 1}}, nil)
+	mockStocktakeRepo.On("FindFrozenRacks", 1).Return([]int(nil), nil)
+
+	saleItems, err := service.buildSaleItems([]dto.SaleItemRequest{{ItemID: 1, Quantity: 3}}, released, nil)
+
+	require.NoError(t, err)
+	require.Len(t, saleItems, 2)
+	require.Equal(t, 1, saleItems[0].RackID)
+	require.Equal(t, 2, saleItems[1].RackID)
+	require.Equal(t, money.FromUnits(1000), saleItems[0].GrossAmount)
+	require.Equal(t, money.FromUnits(2000), saleItems[1].GrossAmount)
+}
+
+// TestCheckReserved tests that stock held for other orders can't be sold, but a sale's own reservation can
+func TestCheckReserved(t *testing.T) {
+	item := &model.Item{ID: 1, Name: "Mouse", Stock: 5, Reserved: 3}
+
+	err := checkReserved(item, 3, nil, nil)
+	require.Error(t, err)
+	require.Equal(t, repository.ErrInsufficientAvailable.Error()+", the rest is reserved: Mouse", err.Error())
+
+	require.NoError(t, checkReserved(item, 2, nil, nil))
+	require.NoError(t, checkReserved(item, 5, nil, []model.ReservationItem{{ItemID: 1, Quantity: 3}}))
+	require.NoError(t, checkReserved(item, 3, []model.SaleItem{{ItemID: 1, Quantity: 1}}, nil))
+}
+
+// TestSaleService_Create_DiscountsAndTax tests line and sale discounts with taxes from the item or its category
+func TestSaleService_Create_DiscountsAndTax(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockLocationRepo := new(MockItemLocationRepository)
+	mockCategoryRepo := new(MockCategoryRepository)
+	mockSaleRepo := new(MockSaleRepository)
+	mockStocktakeRepo := new(MockStocktakeRepository)
+	repo := repository.Repository{
+		ItemRepo:         mockItemRepo,
+		ItemLocationRepo: mockLocationRepo,
+		CategoryRepo:     mockCategoryRepo,
+		SaleRepo:         mockSaleRepo,
+		StocktakeRepo:    mockStocktakeRepo,
+	}
+	service := NewSaleService(repo)
+
+	categoryRate := money.Rate(1100)
+	noTax := money.Rate(0)
+	keyboard := &model.Item{ID: 1, Name: "Keyboard", CategoryID: 1, RackID: 1, Price: money.FromUnits(100000)}
+	mouse := &model.Item{ID: 2, Name: "Mouse", CategoryID: 1, RackID: 1, Price: money.FromUnits(50000), TaxRate: &noTax}
+
+	mockItemRepo.On("FindByID", 1).Return(keyboard, nil)
+	mockItemRepo.On("FindByID", 2).Return(mouse, nil)
+	mockCategoryRepo.On("FindByID", 1).Return(&model.Category{ID: 1, TaxRate: &categoryRate}, nil).Once()
+	mockLocationRepo.On("FindByItemID", 1).Return([]model.ItemLocation{{ItemID: 1, RackID: 1, Quantity: 5}}, nil)
+	mockLocationRepo.On("FindByItemID", 2).Return([]model.ItemLocation{{ItemID: 2, RackID: 1, Quantity: 5}}, nil)
+	mockStocktakeRepo.On("FindFrozenRacks", mock.Anything).Return([]int(nil), nil)
+	mockSaleRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	sale, err := service.Create(1, dto.SaleRequest{
+		Items: []dto.SaleItemRequest{
+			{ItemID: 1, Quantity: 2, DiscountPercent: money.Rate(1000)},
+			{ItemID: 2, Quantity: 1},
+		},
+		DiscountAmount: money.FromUnits(25000),
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, money.FromUnits(250000), sale.GrossAmount)
+	require.Equal(t, money.FromUnits(45000), sale.DiscountAmount)
+	require.Equal(t, money.Amount(1764783), sale.TaxAmount)
+	require.Equal(t, sale.GrossAmount-sale.DiscountAmount+sale.TaxAmount, sale.TotalAmount)
+
+	lines := mockSaleRepo.Calls[0].Arguments.Get(1).([]model.SaleItem)
+	require.Len(t, lines, 2)
+	require.Equal(t, money.Rate(1100), lines[0].TaxRate)
+	require.Equal(t, money.Amount(3956522), lines[0].DiscountAmount)
+	require.Equal(t, money.Amount(17808261), lines[0].Subtotal)
+	require.Equal(t, money.Rate(0), lines[1].TaxRate)
+	require.Equal(t, money.Amount(4456522), lines[1].Subtotal)
+	mockCategoryRepo.AssertExpectations(t)
+}
+
+// TestDiscountOf tests the accepted discount forms
+func TestDiscountOf(t *testing.T) {
+	base := money.FromUnits(1000)
+
+	discount, err := discountOf(base, money.Rate(2500), 0)
+	require.NoError(t, err)
+	require.Equal(t, money.FromUnits(250), discount)
+
+	discount, err = discountOf(base, 0, money.FromUnits(100))
+	require.NoError(t, err)
+	require.Equal(t, money.FromUnits(100), discount)
+
+	_, err = discountOf(base, money.Rate(1000), money.FromUnits(100))
+	require.EqualError(t, err, "discount must be either a percent or an amount")
+
+	_, err = discountOf(base, 0, money.FromUnits(1001))
+	require.EqualError(t, err, "discount amount exceeds the price")
+
+	_, err = discountOf(base, money.Rate(10001), 0)
+	require.Error(t, err)
+}
+
+// TestPickSerials tests serials are grouped per rack and must be in stock or released
+func TestPickSerials(t *testing.T) {
+	serials := []model.ItemSerial{
+		{SerialNumber: "SN-1", RackID: 1, Status: model.SerialStatusInStock},
+		{SerialNumber: "SN-2", RackID: 2, Status: model.SerialStatusInStock},
+		{SerialNumber: "SN-3", RackID: 1, Status: model.SerialStatusInStock},
+		{SerialNumber: "SN-4", RackID: 2, Status: model.SerialStatusSold},
+	}
+
+	lines, err := pickSerials([]string{"SN-1", "SN-2", "SN-3"}, serials, 0, nil, map[string]bool{})
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	require.Equal(t, model.SaleItem{RackID: 1, Quantity: 2, SerialNumbers: []string{"SN-1", "SN-3"}}, lines[0])
+	require.Equal(t, model.SaleItem{RackID: 2, Quantity: 1, SerialNumbers: []string{"SN-2"}}, lines[1])
+
+	_, err = pickSerials([]string{"SN-2"}, serials, 1, nil, map[string]bool{})
+	require.EqualError(t, err, "serial number SN-2 is not in rack 1")
+
+	_, err = pickSerials([]string{"SN-4"}, serials, 0, nil, map[string]bool{})
+	require.EqualError(t, err, "serial number is not in stock: SN-4")
+
+	_, err = pickSerials([]string{"SN-1"}, serials, 0, nil, map[string]bool{"SN-1": true})
+	require.EqualError(t, err, "serial number is not in stock: SN-1")
+
+	// Editing the sale that sold SN-4 may sell it again
+	released := []model.SaleItem{{ItemID: 5, RackID: 2, Quantity: 1, SerialNumbers: []string{"SN-4"}}}
+	lines, err = pickSerials([]string{"SN-4"}, serials, 0, released, map[string]bool{})
+	require.NoError(t, err)
+	require.Equal(t, 2, lines[0].RackID)
+}
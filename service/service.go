@@ -3,31 +3,59 @@ package service
 import "project-app-inventory/repository"
 
 type Service struct {
-	AssignmentService AssignmentService
-	SubmissionService SubmissionService
-	UserService       UserService
-	AuthService       AuthService
-	PermissionService PermissionIface
-	ItemService       ItemService
-	CategoryService   CategoryService
-	RackService       RackService
-	WarehouseService  WarehouseService
-	SaleService       SaleService
-	ReportService     ReportService
+	AssignmentService     AssignmentService
+	SubmissionService     SubmissionService
+	UserService           UserService
+	AuthService           AuthService
+	PermissionService     PermissionIface
+	ItemService           ItemService
+	CategoryService       CategoryService
+	RackService           RackService
+	WarehouseService      WarehouseService
+	SaleService           SaleService
+	ReportService         ReportService
+	StockMovementService  StockMovementService
+	ItemLocationService   ItemLocationService
+	ItemLotService        ItemLotService
+	ItemSerialService     ItemSerialService
+	TransferService       TransferService
+	SupplierService       SupplierService
+	PurchaseOrderService  PurchaseOrderService
+	ReceiptService        ReceiptService
+	CustomerService       CustomerService
+	SaleReturnService     SaleReturnService
+	IdempotencyKeyService IdempotencyKeyService
+	ReservationService    ReservationService
+	StocktakeService      StocktakeService
+	ForecastService       ForecastService
 }
 
 func NewService(repo repository.Repository) Service {
 	return Service{
-		AssignmentService: NewAssignmentService(repo),
-		SubmissionService: NewSubmissionService(repo),
-		UserService:       NewUserService(repo),
-		AuthService:       NewAuthService(repo),
-		PermissionService: NewPermissionService(repo),
-		ItemService:       NewItemService(repo),
-		CategoryService:   NewCategoryService(repo),
-		RackService:       NewRackService(repo),
-		WarehouseService:  NewWarehouseService(repo),
-		SaleService:       NewSaleService(repo),
-		ReportService:     NewReportService(&repo),
+		AssignmentService:     NewAssignmentService(repo),
+		SubmissionService:     NewSubmissionService(repo),
+		UserService:           NewUserService(repo),
+		AuthService:           NewAuthService(repo),
+		PermissionService:     NewPermissionService(repo),
+		ItemService:           NewItemService(repo),
+		CategoryService:       NewCategoryService(repo),
+		RackService:           NewRackService(repo),
+		WarehouseService:      NewWarehouseService(repo),
+		SaleService:           NewSaleService(repo),
+		ReportService:         NewReportService(&repo),
+		StockMovementService:  NewStockMovementService(repo),
+		ItemLocationService:   NewItemLocationService(repo),
+		ItemLotService:        NewItemLotService(repo),
+		ItemSerialService:     NewItemSerialService(repo),
+		TransferService:       NewTransferService(repo),
+		SupplierService:       NewSupplierService(repo),
+		PurchaseOrderService:  NewPurchaseOrderService(repo),
+		ReceiptService:        NewReceiptService(repo),
+		CustomerService:       NewCustomerService(repo),
+		SaleReturnService:     NewSaleReturnService(repo),
+		IdempotencyKeyService: NewIdempotencyKeyService(repo),
+		ReservationService:    NewReservationService(repo),
+		StocktakeService:      NewStocktakeService(repo),
+		ForecastService:       NewForecastService(repo),
 	}
 }
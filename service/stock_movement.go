@@ -0,0 +1,164 @@
+package service
+
+import (
+	"errors"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+	"time"
+)
+
+type StockMovementService interface {
+	GetItemMovements(itemID int, from, to *time.Time, page, limit int) (*[]model.StockMovement, *dto.Pagination, error)
+	AdjustStock(itemID, userID int, req dto.StockAdjustmentRequest) (*model.StockMovement, error)
+}
+
+type stockMovementService struct {
+	Repo repository.Repository
+}
+
+func NewStockMovementService(repo repository.Repository) StockMovementService {
+	return &stockMovementService{Repo: repo}
+}
+
+func (s *stockMovementService) GetItemMovements(itemID int, from, to *time.Time, page, limit int) (*[]model.StockMovement, *dto.Pagination, error) {
+	// Check if item exists
+	item, err := s.Repo.ItemRepo.FindByID(itemID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if item == nil {
+		return nil, nil, errors.New("item not found")
+	}
+
+	if from != nil && to != nil && from.After(*to) {
+		return nil, nil, errors.New("from date must be before to date")
+	}
+
+	movements, total, err := s.Repo.StockMovementRepo.FindByItemID(itemID, from, to, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return &movements, &pagination, nil
+}
+
+func (s *stockMovementService) AdjustStock(itemID, userID int, req dto.StockAdjustmentRequest) (*model.StockMovement, error) {
+	if req.Quantity == 0 {
+		return nil, errors.New("adjustment quantity must not be zero")
+	}
+
+	// Direction must match the reason, only a correction may go either way
+	switch req.ReasonCode {
+	case model.AdjustmentReasonDamage, model.AdjustmentReasonLoss, model.AdjustmentReasonWriteOff:
+		if req.Quantity > 0 {
+			return nil, errors.New(req.ReasonCode + " adjustment must have a negative quantity")
+		}
+	case model.AdjustmentReasonFound:
+		if req.Quantity < 0 {
+			return nil, errors.New("found adjustment must have a positive quantity")
+		}
+	case model.AdjustmentReasonCorrection:
+	default:
+		return nil, errors.New("invalid reason code")
+	}
+
+	// Check if item exists
+	item, err := s.Repo.ItemRepo.FindByID(itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, errors.New("item not found")
+	}
+
+	rackID := req.RackID
+	if rackID == 0 {
+		rackID = item.RackID
+	} else {
+		rack, err := s.Repo.RackRepo.FindByID(rackID)
+		if err != nil {
+			return nil, err
+		}
+		if rack == nil {
+			return nil, errors.New("rack not found")
+		}
+	}
+
+	// Lot tracked stock is adjusted on one of its existing lots
+	lotNumber, err := s.adjustedLot(item, req.LotNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	// Serialized stock is adjusted unit by unit
+	quantity := req.Quantity
+	if quantity < 0 {
+		quantity = -quantity
+	}
+	serialNumbers, err := checkSerials(item, req.SerialNumbers, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	reasonCode := req.ReasonCode
+	referenceType := model.ReferenceTypeItem
+	movement := &model.StockMovement{
+		ItemID:        itemID,
+		UserID:        userID,
+		RackID:        rackID,
+		MovementType:  model.MovementTypeAdjustment,
+		Quantity:      req.Quantity,
+		ReasonCode:    &reasonCode,
+		ReferenceType: &referenceType,
+		ReferenceID:   &itemID,
+		LotNumber:     lotNumber,
+		SerialNumbers: serialNumbers,
+	}
+	if req.Note != "" {
+		note := req.Note
+		movement.Reason = &note
+	}
+
+	err = s.Repo.StockMovementRepo.Record(movement)
+	if errors.Is(err, repository.ErrInsufficientStock) {
+		return nil, errors.New("adjustment would drive stock below zero")
+	}
+	if errors.Is(err, repository.ErrInsufficientLotStock) {
+		return nil, errors.New("adjustment would drive the lot below zero in the rack")
+	}
+	if err != nil {
+		return nil, serialError(err)
+	}
+
+	return movement, nil
+}
+
+// adjustedLot checks the lot an adjustment applies to, only lot tracked items have one
+func (s *stockMovementService) adjustedLot(item *model.Item, lotNumber string) (*string, error) {
+	if !item.TrackLots {
+		if lotNumber != "" {
+			return nil, errors.New("item does not track lots")
+		}
+		return nil, nil
+	}
+	if lotNumber == "" {
+		return nil, errors.New("lot_number is required for lot tracked items")
+	}
+
+	lot, err := s.Repo.ItemLotRepo.FindByLotNumber(item.ID, lotNumber)
+	if err != nil {
+		return nil, err
+	}
+	if lot == nil {
+		return nil, errors.New("lot not found: " + lotNumber)
+	}
+	return &lot.LotNumber, nil
+}
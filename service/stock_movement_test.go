@@ -0,0 +1,155 @@
+package service
+
+import (
+	"errors"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockStockMovementRepository mocks StockMovementRepository interface
+type MockStockMovementRepository struct {
+	mock.Mock
+}
+
+func (m *MockStockMovementRepository) Record(movement *model.StockMovement) error {
+	args := m.Called(movement)
+	return args.Error(0)
+}
+
+func (m *MockStockMovementRepository) FindByItemID(itemID int, from, to *time.Time, page, limit int) ([]model.StockMovement, int, error) {
+	args := m.Called(itemID, from, to, page, limit)
+	return args.Get(0).([]model.StockMovement), args.Int(1), args.Error(2)
+}
+
+// TestStockMovementService_GetItemMovements_Success tests listing the ledger of an item
+func TestStockMovementService_GetItemMovements_Success(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockMovementRepo := new(MockStockMovementRepository)
+	repo := repository.Repository{ItemRepo: mockItemRepo, StockMovementRepo: mockMovementRepo}
+	service := NewStockMovementService(repo)
+
+	movements := []model.StockMovement{
+		{ID: 1, ItemID: 1, MovementType: model.MovementTypeOpening, Quantity: 10, BalanceAfter: 10},
+		{ID: 2, ItemID: 1, MovementType: model.MovementTypeSale, Quantity: -3, BalanceAfter: 7},
+	}
+
+	mockItemRepo.On("FindByID", 1).Return(&model.Item{ID: 1}, nil)
+	mockMovementRepo.On("FindByItemID", 1, (*time.Time)(nil), (*time.Time)(nil), 1, 10).Return(movements, 2, nil)
+
+	result, pagination, err := service.GetItemMovements(1, nil, nil, 1, 10)
+
+	require.NoError(t, err)
+	require.Len(t, *result, 2)
+	require.Equal(t, 7, (*result)[1].BalanceAfter)
+	require.Equal(t, 2, pagination.TotalRecords)
+	mockItemRepo.AssertExpectations(t)
+	mockMovementRepo.AssertExpectations(t)
+}
+
+// TestStockMovementService_GetItemMovements_ItemNotFound tests listing for unknown item
+func TestStockMovementService_GetItemMovements_ItemNotFound(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	repo := repository.Repository{ItemRepo: mockItemRepo}
+	service := NewStockMovementService(repo)
+
+	mockItemRepo.On("FindByID", 999).Return((*model.Item)(nil), nil)
+
+	result, pagination, err := service.GetItemMovements(999, nil, nil, 1, 10)
+
+	require.Error(t, err)
+	require.Equal(t, "item not found", err.Error())
+	require.Nil(t, result)
+	require.Nil(t, pagination)
+	mockItemRepo.AssertExpectations(t)
+}
+
+// TestStockMovementService_GetItemMovements_InvalidRange tests from after to
+func TestStockMovementService_GetItemMovements_InvalidRange(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	repo := repository.Repository{ItemRepo: mockItemRepo}
+	service := NewStockMovementService(repo)
+
+	from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mockItemRepo.On("FindByID", 1).Return(&model.Item{ID: 1}, nil)
+
+	_, _, err := service.GetItemMovements(1, &from, &to, 1, 10)
+
+	require.Error(t, err)
+	mockItemRepo.AssertExpectations(t)
+}
+
+// TestStockMovementService_GetItemMovements_Error tests repository failure
+func TestStockMovementService_GetItemMovements_Error(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockMovementRepo := new(MockStockMovementRepository)
+	repo := repository.Repository{ItemRepo: mockItemRepo, StockMovementRepo: mockMovementRepo}
+	service := NewStockMovementService(repo)
+
+	mockItemRepo.On("FindByID", 1).Return(&model.Item{ID: 1}, nil)
+	mockMovementRepo.On("FindByItemID", 1, (*time.Time)(nil), (*time.Time)(nil), 1, 10).
+		Return([]model.StockMovement{}, 0, errors.New("db error"))
+
+	result, _, err := service.GetItemMovements(1, nil, nil, 1, 10)
+
+	require.Error(t, err)
+	require.Nil(t, result)
+}
+
+// TestStockMovementService_AdjustStock_Success tests a damage write-down
+func TestStockMovementService_AdjustStock_Success(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockMovementRepo := new(MockStockMovementRepository)
+	repo := repository.Repository{ItemRepo: mockItemRepo, StockMovementRepo: mockMovementRepo}
+	service := NewStockMovementService(repo)
+
+	req := dto.StockAdjustmentRequest{ReasonCode: "damage", Quantity: -2, Note: "dropped"}
+
+	mockItemRepo.On("FindByID", 1).Return(&model.Item{ID: 1, Stock: 10}, nil)
+	mockMovementRepo.On("Record", mock.MatchedBy(func(m *model.StockMovement) bool {
+		return m.ItemID == 1 && m.UserID == 3 && m.Quantity == -2 &&
+			*m.ReasonCode == "damage" && *m.Reason == "dropped"
+	})).Return(nil)
+
+	movement, err := service.AdjustStock(1, 3, req)
+
+	require.NoError(t, err)
+	require.Equal(t, model.MovementTypeAdjustment, movement.MovementType)
+	mockItemRepo.AssertExpectations(t)
+	mockMovementRepo.AssertExpectations(t)
+}
+
+// TestStockMovementService_AdjustStock_WrongDirection tests reason/sign mismatch
+func TestStockMovementService_AdjustStock_WrongDirection(t *testing.T) {
+	service := NewStockMovementService(repository.Repository{})
+
+	_, err := service.AdjustStock(1, 3, dto.StockAdjustmentRequest{ReasonCode: "loss", Quantity: 5})
+	require.Error(t, err)
+
+	_, err = service.AdjustStock(1, 3, dto.StockAdjustmentRequest{ReasonCode: "found", Quantity: -5})
+	require.Error(t, err)
+}
+
+// TestStockMovementService_AdjustStock_BelowZero tests rejecting negative results
+func TestStockMovementService_AdjustStock_BelowZero(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockMovementRepo := new(MockStockMovementRepository)
+	repo := repository.Repository{ItemRepo: mockItemRepo, StockMovementRepo: mockMovementRepo}
+	service := NewStockMovementService(repo)
+
+	mockItemRepo.On("FindByID", 1).Return(&model.Item{ID: 1, Stock: 1}, nil)
+	mockMovementRepo.On("Record", mock.Anything).Return(repository.ErrInsufficientStock)
+
+	movement, err := service.AdjustStock(1, 3, dto.StockAdjustmentRequest{ReasonCode: "correction", Quantity: -5})
+
+	require.Error(t, err)
+	require.Nil(t, movement)
+	require.Equal(t, "adjustment would drive stock below zero", err.Error())
+}
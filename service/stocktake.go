@@ -0,0 +1,260 @@
+package service
+
+import (
+	"errors"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+	"strconv"
+)
+
+type StocktakeService interface {
+	Create(userID int, req dto.StocktakeRequest) (*model.Stocktake, []model.StocktakeItem, error)
+	GetAllStocktakes(status string, page, limit int) (*[]model.Stocktake, *dto.Pagination, error)
+	GetStocktakeByID(id int) (*model.Stocktake, []model.StocktakeItem, error)
+	SubmitCounts(id, userID int, req dto.StocktakeCountsRequest) error
+	Approve(id, userID int, req dto.StocktakeApproveRequest) error
+	Cancel(id int) error
+}
+
+type stocktakeService struct {
+	Repo repository.Repository
+}
+
+func NewStocktakeService(repo repository.Repository) StocktakeService {
+	return &stocktakeService{Repo: repo}
+}
+
+// Create opens a count of a warehouse or of a set of racks, racks given with a
+// warehouse must belong to it
+func (s *stocktakeService) Create(userID int, req dto.StocktakeRequest) (*model.Stocktake, []model.StocktakeItem, error) {
+	if req.WarehouseID == 0 && len(req.RackIDs) == 0 {
+		return nil, nil, errors.New("warehouse_id or rack_ids is required")
+	}
+
+	stocktake := &model.Stocktake{
+		FreezeSales: req.FreezeSales,
+		CreatedBy:   userID,
+	}
+	seenClasses := make(map[string]bool)
+	for _, class := range req.ABCClasses {
+		if !seenClasses[class] {
+			seenClasses[class] = true
+			stocktake.ABCClasses = append(stocktake.ABCClasses, class)
+		}
+	}
+	if req.WarehouseID != 0 {
+		warehouse, err := s.Repo.WarehouseRepo.FindByID(req.WarehouseID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if warehouse == nil {
+			return nil, nil, errors.New("warehouse not found")
+		}
+		stocktake.WarehouseID = &warehouse.ID
+	}
+
+	seen := make(map[int]bool)
+	for _, rackID := range req.RackIDs {
+		if seen[rackID] {
+			continue
+		}
+		seen[rackID] = true
+
+		rack, err := s.Repo.RackRepo.FindByID(rackID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if rack == nil {
+			return nil, nil, errors.New("rack not found: " + strconv.Itoa(rackID))
+		}
+		if req.WarehouseID != 0 && rack.WarehouseID != req.WarehouseID {
+			return nil, nil, errors.New("rack does not belong to the warehouse: " + strconv.Itoa(rackID))
+		}
+		stocktake.RackIDs = append(stocktake.RackIDs, rack.ID)
+	}
+	if req.Note != "" {
+		note := req.Note
+		stocktake.Note = &note
+	}
+
+	err := s.Repo.StocktakeRepo.Create(stocktake)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, err := s.Repo.StocktakeRepo.FindItems(stocktake.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return stocktake, items, nil
+}
+
+func (s *stocktakeService) GetAllStocktakes(status string, page, limit int) (*[]model.Stocktake, *dto.Pagination, error) {
+	switch status {
+	case "", model.StocktakeStatusOpen, model.StocktakeStatusApproved, model.StocktakeStatusCancelled:
+	default:
+		return nil, nil, errors.New("invalid stocktake status")
+	}
+
+	stocktakes, total, err := s.Repo.StocktakeRepo.FindAll(status, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return &stocktakes, &pagination, nil
+}
+
+func (s *stocktakeService) GetStocktakeByID(id int) (*model.Stocktake, []model.StocktakeItem, error) {
+	stocktake, err := s.Repo.StocktakeRepo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stocktake == nil {
+		return nil, nil, errors.New("stocktake not found")
+	}
+
+	items, err := s.Repo.StocktakeRepo.FindItems(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return stocktake, items, nil
+}
+
+// SubmitCounts records counted quantities. Items may be counted in any rack of
+// the stocktake, also items that had no stock there when the count opened.
+func (s *stocktakeService) SubmitCounts(id, userID int, req dto.StocktakeCountsRequest) error {
+	if len(req.Counts) == 0 {
+		return errors.New("counts must have at least one line")
+	}
+
+	stocktake, err := s.Repo.StocktakeRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if stocktake == nil {
+		return errors.New("stocktake not found")
+	}
+	if stocktake.Status != model.StocktakeStatusOpen {
+		return errors.New("stocktake is not open for counting")
+	}
+
+	inScope := make(map[int]bool)
+	for _, rackID := range stocktake.RackIDs {
+		inScope[rackID] = true
+	}
+
+	seen := make(map[[2]int]bool)
+	var counts []model.StocktakeItem
+	for _, line := range req.Counts {
+		if line.CountedQuantity == nil || *line.CountedQuantity < 0 {
+			return errors.New("counted_quantity must not be negative")
+		}
+		if !inScope[line.RackID] {
+			return errors.New("rack is not counted by this stocktake: " + strconv.Itoa(line.RackID))
+		}
+		key := [2]int{line.ItemID, line.RackID}
+		if seen[key] {
+			return errors.New("item counted twice in rack: " + strconv.Itoa(line.ItemID))
+		}
+		seen[key] = true
+
+		item, err := s.Repo.ItemRepo.FindByID(line.ItemID)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return errors.New("item not found: " + strconv.Itoa(line.ItemID))
+		}
+
+		counts = append(counts, model.StocktakeItem{
+			ItemID:          line.ItemID,
+			RackID:          line.RackID,
+			CountedQuantity: line.CountedQuantity,
+		})
+	}
+
+	return s.Repo.StocktakeRepo.Count(id, userID, counts)
+}
+
+// Approve posts the variances once every line is counted. Lot tracked and
+// serialized stock is corrected per lot or serial through adjustments, so their
+// lines must match or be excluded from the approval.
+func (s *stocktakeService) Approve(id, userID int, req dto.StocktakeApproveRequest) error {
+	stocktake, err := s.Repo.StocktakeRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if stocktake == nil {
+		return errors.New("stocktake not found")
+	}
+	if stocktake.Status != model.StocktakeStatusOpen {
+		return errors.New("only open stocktakes can be approved")
+	}
+
+	items, err := s.Repo.StocktakeRepo.FindItems(id)
+	if err != nil {
+		return err
+	}
+
+	lines := make(map[int]bool, len(items))
+	for _, line := range items {
+		lines[line.ID] = true
+	}
+	excluded := make(map[int]bool)
+	for _, lineID := range req.ExcludeItemIDs {
+		if !lines[lineID] {
+			return errors.New("stocktake line not found: " + strconv.Itoa(lineID))
+		}
+		excluded[lineID] = true
+	}
+
+	for _, line := range items {
+		if excluded[line.ID] {
+			continue
+		}
+		variance := line.Variance()
+		if variance == nil {
+			return errors.New(repository.ErrStocktakeUncounted.Error() + ": " + line.SKU + " in rack " + strconv.Itoa(line.RackID))
+		}
+		if *variance == 0 {
+			continue
+		}
+
+		item, err := s.Repo.ItemRepo.FindByID(line.ItemID)
+		if err != nil {
+			return err
+		}
+		if item != nil && (item.TrackLots || item.Serialized) {
+			return errors.New("variance of lot tracked or serialized item must be adjusted per lot or serial, exclude line " +
+				strconv.Itoa(line.ID) + ": " + line.SKU)
+		}
+	}
+
+	err = s.Repo.StocktakeRepo.Approve(id, userID, req.ExcludeItemIDs)
+	if errors.Is(err, repository.ErrInsufficientStock) {
+		return errors.New("variance would drive rack stock below zero, stock moved after the count")
+	}
+	return err
+}
+
+func (s *stocktakeService) Cancel(id int) error {
+	stocktake, err := s.Repo.StocktakeRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if stocktake == nil {
+		return errors.New("stocktake not found")
+	}
+
+	return s.Repo.StocktakeRepo.Cancel(id)
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/money"
+	"project-app-inventory/repository"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockStocktakeRepository mocks StocktakeRepository interface
+type MockStocktakeRepository struct {
+	mock.Mock
+}
+
+func (m *MockStocktakeRepository) Create(stocktake *model.Stocktake) error {
+	args := m.Called(stocktake)
+	return args.Error(0)
+}
+
+func (m *MockStocktakeRepository) FindByID(id int) (*model.Stocktake, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Stocktake), args.Error(1)
+}
+
+func (m *MockStocktakeRepository) FindAll(status string, page, limit int) ([]model.Stocktake, int, error) {
+	args := m.Called(status, page, limit)
+	return args.Get(0).([]model.Stocktake), args.Int(1), args.Error(2)
+}
+
+func (m *MockStocktakeRepository) FindItems(stocktakeID int) ([]model.StocktakeItem, error) {
+	args := m.Called(stocktakeID)
+	return args.Get(0).([]model.StocktakeItem), args.Error(1)
+}
+
+func (m *MockStocktakeRepository) Count(id, userID int, counts []model.StocktakeItem) error {
+	args := m.Called(id, userID, counts)
+	return args.Error(0)
+}
+
+func (m *MockStocktakeRepository) Approve(id, userID int, excludeIDs []int) error {
+	args := m.Called(id, userID, excludeIDs)
+	return args.Error(0)
+}
+
+func (m *MockStocktakeRepository) Cancel(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockStocktakeRepository) FindFrozenRacks(itemID int) ([]int, error) {
+	args := m.Called(itemID)
+	return args.Get(0).([]int), args.Error(1)
+}
+
+// TestStocktakeService_Create_RackOutsideWarehouse tests racks given with a warehouse must belong to it
+func TestStocktakeService_Create_RackOutsideWarehouse(t *testing.T) {
+	mockWarehouseRepo := new(MockWarehouseRepository)
+	mockRackRepo := new(MockRackRepository)
+	mockStocktakeRepo := new(MockStocktakeRepository)
+	service := NewStocktakeService(repository.Repository{
+		WarehouseRepo: mockWarehouseRepo,
+		RackRepo:      mockRackRepo,
+		StocktakeRepo: mockStocktakeRepo,
+	})
+
+	mockWarehouseRepo.On("FindByID", 1).Return(&model.Warehouse{ID: 1}, nil)
+	mockRackRepo.On("FindByID", 4).Return(&model.Rack{ID: 4, WarehouseID: 2}, nil)
+
+	_, _, err := service.Create(1, dto.StocktakeRequest{WarehouseID: 1, RackIDs: []int{4}})
+
+	require.Error(t, err)
+	require.Equal(t, "rack does not belong to the warehouse: 4", err.Error())
+	mockStocktakeRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestStocktakeService_SubmitCounts_RackNotCounted tests counts are limited to the racks of the stocktake
+func TestStocktakeService_SubmitCounts_RackNotCounted(t *testing.T) {
+	mockStocktakeRepo := new(MockStocktakeRepository)
+	service := NewStocktakeService(repository.Repository{StocktakeRepo: mockStocktakeRepo})
+
+	mockStocktakeRepo.On("FindByID", 5).Return(&model.Stocktake{ID: 5, RackIDs: []int{3}, Status: model.StocktakeStatusOpen}, nil)
+
+	counted := 4
+	err := service.SubmitCounts(5, 2, dto.StocktakeCountsRequest{
+		Counts: []dto.StocktakeCountRequest{{ItemID: 7, RackID: 9, CountedQuantity: &counted}},
+	})
+
+	require.Error(t, err)
+	require.Equal(t, "rack is not counted by this stocktake: 9", err.Error())
+	mockStocktakeRepo.AssertNotCalled(t, "Count", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestStocktakeService_Approve tests approval needs every line counted and only checks items with a variance
+func TestStocktakeService_Approve(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockStocktakeRepo := new(MockStocktakeRepository)
+	service := NewStocktakeService(repository.Repository{ItemRepo: mockItemRepo, StocktakeRepo: mockStocktakeRepo})
+
+	eight, ten := 8, 10
+	mockItemRepo.On("FindByID", 7).Return(&model.Item{ID: 7}, nil)
+	mockStocktakeRepo.On("FindByID", 5).Return(&model.Stocktake{ID: 5, Status: model.StocktakeStatusOpen}, nil)
+	mockStocktakeRepo.On("FindItems", 5).Return([]model.StocktakeItem{
+		{ID: 1, ItemID: 7, SKU: "MOU-001", RackID: 3, ExpectedQuantity: 10, CountedQuantity: &eight},
+		{ID: 2, ItemID: 8, SKU: "KEY-001", RackID: 3, ExpectedQuantity: 10},
+	}, nil).Once()
+
+	err := service.Approve(5, 1, dto.StocktakeApproveRequest{})
+	require.Error(t, err)
+	require.Equal(t, "stocktake has uncounted lines: KEY-001 in rack 3", err.Error())
+
+	// Two keyboards sold after the snapshot are not a variance
+	mockStocktakeRepo.On("FindItems", 5).Return([]model.StocktakeItem{
+		{ID: 1, ItemID: 7, SKU: "MOU-001", RackID: 3, ExpectedQuantity: 10, CountedQuantity: &eight},
+		{ID: 2, ItemID: 8, SKU: "KEY-001", RackID: 3, ExpectedQuantity: 12, MovedQuantity: -2, CountedQuantity: &ten},
+	}, nil)
+	mockStocktakeRepo.On("Approve", 5, 1, []int(nil)).Return(nil)
+
+	err = service.Approve(5, 1, dto.StocktakeApproveRequest{})
+	require.NoError(t, err)
+	mockStocktakeRepo.AssertCalled(t, "Approve", 5, 1, []int(nil))
+	mockItemRepo.AssertNotCalled(t, "FindByID", 8)
+}
+
+// TestStocktakeService_Approve_LotTrackedLine tests a lot tracked line with a variance must be excluded
+func TestStocktakeService_Approve_LotTrackedLine(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockStocktakeRepo := new(MockStocktakeRepository)
+	service := NewStocktakeService(repository.Repository{ItemRepo: mockItemRepo, StocktakeRepo: mockStocktakeRepo})
+
+	eight, four := 8, 4
+	mockItemRepo.On("FindByID", 7).Return(&model.Item{ID: 7, TrackLots: true}, nil)
+	mockStocktakeRepo.On("FindByID", 5).Return(&model.Stocktake{ID: 5, Status: model.StocktakeStatusOpen}, nil)
+	mockStocktakeRepo.On("FindItems", 5).Return([]model.StocktakeItem{
+		{ID: 1, ItemID: 7, SKU: "MED-001", RackID: 3, ExpectedQuantity: 10, CountedQuantity: &eight},
+		{ID: 2, ItemID: 8, SKU: "KEY-001", RackID: 3, ExpectedQuantity: 4, CountedQuantity: &four},
+	}, nil)
+
+	err := service.Approve(5, 1, dto.StocktakeApproveRequest{})
+	require.Error(t, err)
+	require.Equal(t, "variance of lot tracked or serialized item must be adjusted per lot or serial, exclude line 1: MED-001", err.Error())
+
+	err = service.Approve(5, 1, dto.StocktakeApproveRequest{ExcludeItemIDs: []int{9}})
+	require.Error(t, err)
+	require.Equal(t, "stocktake line not found: 9", err.Error())
+
+	mockStocktakeRepo.On("Approve", 5, 1, []int{1}).Return(nil)
+
+	err = service.Approve(5, 1, dto.StocktakeApproveRequest{ExcludeItemIDs: []int{1}})
+	require.NoError(t, err)
+	mockStocktakeRepo.AssertCalled(t, "Approve", 5, 1, []int{1})
+}
+
+// TestSaleService_Create_FrozenRack tests items are not sold from racks frozen by an open stocktake
+func TestSaleService_Create_FrozenRack(t *testing.T) {
+	mockItemRepo := new(MockItemRepository)
+	mockLocationRepo := new(MockItemLocationRepository)
+	mockStocktakeRepo := new(MockStocktakeRepository)
+	mockSaleRepo := new(MockSaleRepository)
+	service := NewSaleService(repository.Repository{
+		ItemRepo:         mockItemRepo,
+		ItemLocationRepo: mockLocationRepo,
+		StocktakeRepo:    mockStocktakeRepo,
+		SaleRepo:         mockSaleRepo,
+	})
+
+	noTax := money.Rate(0)
+	mockItemRepo.On("FindByID", 1).Return(&model.Item{ID: 1, Name: "Mouse", RackID: 1, TaxRate: &noTax}, nil)
+	mockLocationRepo.On("FindByItemID", 1).Return([]model.ItemLocation{
+		{ItemID: 1, RackID: 1, Quantity: 5},
+		{ItemID: 1, RackID: 2, Quantity: 2},
+	}, nil)
+	mockStocktakeRepo.On("FindFrozenRacks", 1).Return([]int{1}, nil)
+	mockSaleRepo.On("Create", mock.Anything, mock.MatchedBy(func(items []model.SaleItem) bool {
+		return len(items) == 1 && items[0].RackID == 2 && items[0].Quantity == 2
+	})).Return(nil)
+
+	_, err := service.Create(1, dto.SaleRequest{Items: []dto.SaleItemRequest{{ItemID: 1, RackID: 1, Quantity: 1}}})
+	require.Error(t, err)
+	require.Equal(t, "item is frozen by an open stocktake in rack 1: Mouse", err.Error())
+
+	_, err = service.Create(1, dto.SaleRequest{Items: []dto.SaleItemRequest{{ItemID: 1, Quantity: 3}}})
+	require.Error(t, err)
+	require.Equal(t, "insufficient stock for item: Mouse", err.Error())
+
+	_, err = service.Create(1, dto.SaleRequest{Items: []dto.SaleItemRequest{{ItemID: 1, Quantity: 2}}})
+	require.NoError(t, err)
+	mockSaleRepo.AssertNumberOfCalls(t, "Create", 1)
+}
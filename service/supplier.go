@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+)
+
+type SupplierService interface {
+	Create(supplier *model.Supplier) error
+	GetAllSuppliers(page, limit int) (*[]model.Supplier, *dto.Pagination, error)
+	GetSupplierByID(id int) (*model.Supplier, error)
+	Update(id int, data *model.Supplier) error
+	Delete(id int) error
+}
+
+type supplierService struct {
+	Repo repository.Repository
+}
+
+func NewSupplierService(repo repository.Repository) SupplierService {
+	return &supplierService{Repo: repo}
+}
+
+func (s *supplierService) Create(supplier *model.Supplier) error {
+	// Check if name already exists
+	existingSupplier, err := s.Repo.SupplierRepo.FindByName(supplier.Name)
+	if err != nil {
+		return errors.New("failed to check supplier name")
+	}
+	if existingSupplier != nil {
+		return errors.New("supplier name already exists")
+	}
+
+	return s.Repo.SupplierRepo.Create(supplier)
+}
+
+func (s *supplierService) GetAllSuppliers(page, limit int) (*[]model.Supplier, *dto.Pagination, error) {
+	suppliers, total, err := s.Repo.SupplierRepo.FindAll(page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return &suppliers, &pagination, nil
+}
+
+func (s *supplierService) GetSupplierByID(id int) (*model.Supplier, error) {
+	supplier, err := s.Repo.SupplierRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, errors.New("supplier not found")
+	}
+	return supplier, nil
+}
+
+func (s *supplierService) Update(id int, data *model.Supplier) error {
+	// Check if supplier exists
+	existingSupplier, err := s.Repo.SupplierRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if existingSupplier == nil {
+		return errors.New("supplier not found")
+	}
+
+	// Empty fields keep their existing values
+	if data.Name == "" {
+		data.Name = existingSupplier.Name
+	}
+	if data.ContactName == nil {
+		data.ContactName = existingSupplier.ContactName
+	}
+	if data.Phone == nil {
+		data.Phone = existingSupplier.Phone
+	}
+	if data.Email == nil {
+		data.Email = existingSupplier.Email
+	}
+	if data.Address == nil {
+		data.Address = existingSupplier.Address
+	}
+	if data.LeadTimeDays == nil {
+		data.LeadTimeDays = existingSupplier.LeadTimeDays
+	}
+
+	// Check if name is being changed and if new name already exists
+	if data.Name != existingSupplier.Name {
+		nameExists, err := s.Repo.SupplierRepo.FindByName(data.Name)
+		if err != nil {
+			return errors.New("failed to check supplier name")
+		}
+		if nameExists != nil {
+			return errors.New("supplier name already exists")
+		}
+	}
+
+	return s.Repo.SupplierRepo.Update(id, data)
+}
+
+func (s *supplierService) Delete(id int) error {
+	// Check if supplier exists
+	existingSupplier, err := s.Repo.SupplierRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if existingSupplier == nil {
+		return errors.New("supplier not found")
+	}
+
+	// Purchase orders keep a reference to their supplier
+	_, total, err := s.Repo.PurchaseOrderRepo.FindAll("", id, 1, 1)
+	if err != nil {
+		return err
+	}
+	if total > 0 {
+		return errors.New("supplier has purchase orders and cannot be deleted")
+	}
+
+	return s.Repo.SupplierRepo.Delete(id)
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSupplierRepository mocks SupplierRepository interface
+type MockSupplierRepository struct {
+	mock.Mock
+}
+
+func (m *MockSupplierRepository) Create(supplier *model.Supplier) error {
+	args := m.Called(supplier)
+	return args.Error(0)
+}
+
+func (m *MockSupplierRepository) FindByID(id int) (*model.Supplier, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Supplier), args.Error(1)
+}
+
+func (m *MockSupplierRepository) FindByName(name string) (*model.Supplier, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Supplier), args.Error(1)
+}
+
+func (m *MockSupplierRepository) FindAll(page, limit int) ([]model.Supplier, int, error) {
+	args := m.Called(page, limit)
+	return args.Get(0).([]model.Supplier), args.Int(1), args.Error(2)
+}
+
+func (m *MockSupplierRepository) Update(id int, supplier *model.Supplier) error {
+	args := m.Called(id, supplier)
+	return args.Error(0)
+}
+
+func (m *MockSupplierRepository) Delete(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// TestSupplierService_Create_NameExists tests creation with existing name
+func TestSupplierService_Create_NameExists(t *testing.T) {
+	mockSupplierRepo := new(MockSupplierRepository)
+	repo := repository.Repository{SupplierRepo: mockSupplierRepo}
+	service := NewSupplierService(repo)
+
+	supplier := &model.Supplier{Name: "PT Sinar Elektronik"}
+
+	mockSupplierRepo.On("FindByName", supplier.Name).Return(&model.Supplier{ID: 1, Name: supplier.Name}, nil)
+
+	err := service.Create(supplier)
+
+	require.Error(t, err)
+	require.Equal(t, "supplier name already exists", err.Error())
+	mockSupplierRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+// TestSupplierService_Update_KeepsEmptyFields tests partial update keeps existing values
+func TestSupplierService_Update_KeepsEmptyFields(t *testing.T) {
+	mockSupplierRepo := new(MockSupplierRepository)
+	repo := repository.Repository{SupplierRepo: mockSupplierRepo}
+	service := NewSupplierService(repo)
+
+	phone := "021-5550101"
+	existing := &model.Supplier{ID: 1, Name: "PT Sinar Elektronik", Phone: &phone}
+	data := &model.Supplier{}
+
+	mockSupplierRepo.On("FindByID", 1).Return(existing, nil)
+	mockSupplierRepo.On("Update", 1, data).Return(nil)
+
+	err := service.Update(1, data)
+
+	require.NoError(t, err)
+	require.Equal(t, "PT Sinar Elektronik", data.Name)
+	require.Equal(t, &phone, data.Phone)
+	mockSupplierRepo.AssertExpectations(t)
+}
+
+// TestSupplierService_Delete_HasPurchaseOrders tests deleting a supplier still referenced by orders
+func TestSupplierService_Delete_HasPurchaseOrders(t *testing.T) {
+	mockSupplierRepo := new(MockSupplierRepository)
+	mockOrderRepo := new(MockPurchaseOrderRepository)
+	repo := repository.Repository{SupplierRepo: mockSupplierRepo, PurchaseOrderRepo: mockOrderRepo}
+	service := NewSupplierService(repo)
+
+	mockSupplierRepo.On("FindByID", 1).Return(&model.Supplier{ID: 1}, nil)
+	mockOrderRepo.On("FindAll", "", 1, 1, 1).Return([]model.PurchaseOrder{{ID: 4}}, 1, nil)
+
+	err := service.Delete(1)
+
+	require.Error(t, err)
+	require.Equal(t, "supplier has purchase orders and cannot be deleted", err.Error())
+	mockSupplierRepo.AssertNotCalled(t, "Delete", 1)
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"errors"
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"project-app-inventory/utils"
+	"strconv"
+)
+
+type TransferService interface {
+	Create(userID int, req dto.TransferRequest) (*model.Transfer, []model.TransferItem, error)
+	GetAllTransfers(status string, page, limit int) (*[]model.Transfer, *dto.Pagination, error)
+	GetTransferByID(id int) (*model.Transfer, []model.TransferItem, error)
+	Dispatch(id int, userID int) error
+	Receive(id int, userID int) error
+	Cancel(id int) error
+}
+
+type transferService struct {
+	Repo repository.Repository
+}
+
+func NewTransferService(repo repository.Repository) TransferService {
+	return &transferService{Repo: repo}
+}
+
+func (s *transferService) Create(userID int, req dto.TransferRequest) (*model.Transfer, []model.TransferItem, error) {
+	if len(req.Items) == 0 {
+		return nil, nil, errors.New("transfer must have at least one item")
+	}
+	if req.SourceRackID == req.DestinationRackID {
+		return nil, nil, errors.New("source and destination rack must be different")
+	}
+
+	// Check if both racks exist
+	for _, rackID := range []int{req.SourceRackID, req.DestinationRackID} {
+		rack, err := s.Repo.RackRepo.FindByID(rackID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if rack == nil {
+			return nil, nil, errors.New("rack not found: " + strconv.Itoa(rackID))
+		}
+	}
+
+	// Merge duplicate lines so the stock check sees the full quantity per item
+	quantities := make(map[int]int)
+	serials := make(map[int][]string)
+	var items []model.TransferItem
+	for _, line := range req.Items {
+		if _, ok := quantities[line.ItemID]; !ok {
+			items = append(items, model.TransferItem{ItemID: line.ItemID})
+		}
+		quantities[line.ItemID] += line.Quantity
+		serials[line.ItemID] = append(serials[line.ItemID], line.SerialNumbers...)
+	}
+
+	for i := range items {
+		items[i].Quantity = quantities[items[i].ItemID]
+
+		item, available, err := s.sourceQuantity(items[i].ItemID, req.SourceRackID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if available < items[i].Quantity {
+			return nil, nil, errors.New("insufficient stock in source rack for item: " + strconv.Itoa(items[i].ItemID))
+		}
+
+		// Serialized units must be moved by serial, from the source rack
+		items[i].SerialNumbers, err = checkSerials(item, serials[items[i].ItemID], items[i].Quantity)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := s.checkSerialsInRack(item, items[i].SerialNumbers, req.SourceRackID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	transfer := &model.Transfer{
+		SourceRackID:      req.SourceRackID,
+		DestinationRackID: req.DestinationRackID,
+		CreatedBy:         userID,
+	}
+	if req.Note != "" {
+		note := req.Note
+		transfer.Note = &note
+	}
+
+	// Lots of lot tracked items are picked at dispatch, when the stock moves
+	err := s.Repo.TransferRepo.Create(transfer, items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return transfer, items, nil
+}
+
+// sourceQuantity returns the item and how many of its units sit in the rack
+func (s *transferService) sourceQuantity(itemID, rackID int) (*model.Item, int, error) {
+	item, err := s.Repo.ItemRepo.FindByID(itemID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if item == nil {
+		return nil, 0, errors.New("item not found: " + strconv.Itoa(itemID))
+	}
+
+	locations, err := s.Repo.ItemLocationRepo.FindByItemID(itemID)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, location := range locations {
+		if location.RackID == rackID {
+			return item, location.Quantity, nil
+		}
+	}
+	return item, 0, nil
+}
+
+// checkSerialsInRack checks every serial is in stock in the rack
+func (s *transferService) checkSerialsInRack(item *model.Item, serialNumbers []string, rackID int) error {
+	if len(serialNumbers) == 0 {
+		return nil
+	}
+
+	serials, err := s.Repo.ItemSerialRepo.FindBySerialNumbers(item.ID, serialNumbers)
+	if err != nil {
+		return err
+	}
+	inRack := make(map[string]bool)
+	for _, serial := range serials {
+		inRack[serial.SerialNumber] = serial.Status == model.SerialStatusInStock && serial.RackID == rackID
+	}
+	for _, serialNumber := range serialNumbers {
+		if !inRack[serialNumber] {
+			return errors.New("serial number is not in stock in the source rack: " + serialNumber)
+		}
+	}
+	return nil
+}
+
+func (s *transferService) GetAllTransfers(status string, page, limit int) (*[]model.Transfer, *dto.Pagination, error) {
+	switch status {
+	case "", model.TransferStatusDraft, model.TransferStatusInTransit, model.TransferStatusReceived, model.TransferStatusCancelled:
+	default:
+		return nil, nil, errors.New("invalid transfer status")
+	}
+
+	transfers, total, err := s.Repo.TransferRepo.FindAll(status, page, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pagination := dto.Pagination{
+		CurrentPage:  page,
+		Limit:        limit,
+		TotalPages:   utils.TotalPage(limit, int64(total)),
+		TotalRecords: total,
+	}
+	return &transfers, &pagination, nil
+}
+
+func (s *transferService) GetTransferByID(id int) (*model.Transfer, []model.TransferItem, error) {
+	transfer, err := s.Repo.TransferRepo.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if transfer == nil {
+		return nil, nil, errors.New("transfer not found")
+	}
+
+	items, err := s.Repo.TransferRepo.FindTransferItems(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return transfer, items, nil
+}
+
+func (s *transferService) Dispatch(id int, userID int) error {
+	// Check if transfer exists
+	if err := s.checkTransferExists(id); err != nil {
+		return err
+	}
+
+	err := s.Repo.TransferRepo.Dispatch(id, userID)
+	switch {
+	case errors.Is(err, repository.ErrInsufficientStock):
+		return errors.New("transfer would drive source rack stock negative")
+	case errors.Is(err, repository.ErrInsufficientLotStock):
+		return errors.New("insufficient lot stock in source rack")
+	case errors.Is(err, repository.ErrInsufficientAvailable):
+		return errors.New(err.Error() + ", the rest is reserved")
+	}
+	return serialError(err)
+}
+
+func (s *transferService) Receive(id int, userID int) error {
+	// Check if transfer exists
+	if err := s.checkTransferExists(id); err != nil {
+		return err
+	}
+
+	err := s.Repo.TransferRepo.Receive(id, userID)
+	return serialError(err)
+}
+
+func (s *transferService) Cancel(id int) error {
+	// Check if transfer exists
+	if err := s.checkTransferExists(id); err != nil {
+		return err
+	}
+
+	return s.Repo.TransferRepo.Cancel(id)
+}
+
+func (s *transferService) checkTransferExists(id int) error {
+	transfer, err := s.Repo.TransferRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if transfer == nil {
+		return errors.New("transfer not found")
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"project-app-inventory/dto"
+	"project-app-inventory/model"
+	"project-app-inventory/repository"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTransferRepository mocks TransferRepository interface
+type MockTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransferRepository) Create(transfer *model.Transfer, items []model.TransferItem) error {
+	args := m.Called(transfer, items)
+	return args.Error(0)
+}
+
+func (m *MockTransferRepository) FindByID(id int) (*model.Transfer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Transfer), args.Error(1)
+}
+
+func (m *MockTransferRepository) FindTransferItems(transferID int) ([]model.TransferItem, error) {
+	args := m.Called(transferID)
+	return args.Get(0).([]model.TransferItem), args.Error(1)
+}
+
+func (m *MockTransferRepository) FindAll(status string, page, limit int) ([]model.Transfer, int, error) {
+	args := m.Called(status, page, limit)
+	return args.Get(0).([]model.Transfer), args.Int(1), args.Error(2)
+}
+
+func (m *MockTransferRepository) Dispatch(id int, userID int) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *MockTransferRepository) Receive(id int, userID int) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *MockTransferRepository) Cancel(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// TestTransferService_Create_Success tests creating a draft transfer with merged lines
+func TestTransferService_Create_Success(t *testing.T) {
+	mockRackRepo := new(MockRackRepository)
+	mockItemRepo := new(MockItemRepository)
+	mockLocationRepo := new(MockItemLocationRepository)
+	mockTransferRepo := new(MockTransferRepository)
+	repo := repository.Repository{
+		RackRepo:         mockRackRepo,
+		ItemRepo:         mockItemRepo,
+		ItemLocationRepo: mockLocationRepo,
+		TransferRepo:     mockTransferRepo,
+	}
+	service := NewTransferService(repo)
+
+	req := dto.TransferRequest{
+		SourceRackID:      1,
+		DestinationRackID: 2,
+		Items: []dto.TransferItemRequest{
+			{ItemID: 5, Quantity: 2},
+			{ItemID: 5, Quantity: 3},
+		},
+	}
+
+	mockRackRepo.On("FindByID", 1).Return(&model.Rack{ID: 1}, nil)
+	mockRackRepo.On("FindByID", 2).Return(&model.Rack{ID: 2}, nil)
+	mockItemRepo.On("FindByID", 5).Return(&model.Item{ID: 5}, nil)
+	mockLocationRepo.On("FindByItemID", 5).Return([]model.ItemLocation{{ItemID: 5, RackID: 1, Quantity: 5}}, nil)
+	mockTransferRepo.On("Create", mock.Anything, []model.TransferItem{{ItemID: 5, Quantity: 5}}).Return(nil)
+
+	transfer, items, err := service.Create(9, req)
+
+	require.NoError(t, err)
+	require.Equal(t, 9, transfer.CreatedBy)
+	require.Len(t, items, 1)
+	mockTransferRepo.AssertExpectations(t)
+}
+
+// TestTransferService_Create_InsufficientStock tests a transfer that would drive the source rack negative
+func TestTransferService_Create_InsufficientStock(t *testing.T) {
+	mockRackRepo := new(MockRackRepository)
+	mockItemRepo := new(MockItemRepository)
+	mockLocationRepo := new(MockItemLocationRepository)
+	mockTransferRepo := new(MockTransferRepository)
+	repo := repository.Repository{
+		RackRepo:         mockRackRepo,
+		ItemRepo:         mockItemRepo,
+		ItemLocationRepo: mockLocationRepo,
+		TransferRepo:     mockTransferRepo,
+	}
+	service := NewTransferService(repo)
+
+	req := dto.TransferRequest{
+		SourceRackID:      1,
+		DestinationRackID: 2,
+		Items:             []dto.TransferItemRequest{{ItemID: 5, Quantity: 6}},
+	}
+
+	mockRackRepo.On("FindByID", 1).Return(&model.Rack{ID: 1}, nil)
+	mockRackRepo.On("FindByID", 2).Return(&model.Rack{ID: 2}, nil)
+	mockItemRepo.On("FindByID", 5).Return(&model.Item{ID: 5}, nil)
+	mockLocationRepo.On("FindByItemID", 5).Return([]model.ItemLocation{{ItemID: 5, RackID: 1, Quantity: 5}}, nil)
+
+	transfer, _, err := service.Create(9, req)
+
+	require.Error(t, err)
+	require.Nil(t, transfer)
+	mockTransferRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+// TestTransferService_Create_LotTracked tests a lot tracked line is kept whole, its lots are picked at dispatch
+func TestTransferService_Create_LotTracked(t *testing.T) {
+	mockRackRepo := new(MockRackRepository)
+	mockItemRepo := new(MockItemRepository)
+	mockLocationRepo := new(MockItemLocationRepository)
+	mockTransferRepo := new(MockTransferRepository)
+	repo := repository.Repository{
+		RackRepo:         mockRackRepo,
+		ItemRepo:         mockItemRepo,
+		ItemLocationRepo: mockLocationRepo,
+		TransferRepo:     mockTransferRepo,
+	}
+	service := NewTransferService(repo)
+
+	req := dto.TransferRequest{
+		SourceRackID:      1,
+		DestinationRackID: 2,
+		Items:             []dto.TransferItemRequest{{ItemID: 5, Quantity: 4}},
+	}
+
+	mockRackRepo.On("FindByID", 1).Return(&model.Rack{ID: 1}, nil)
+	mockRackRepo.On("FindByID", 2).Return(&model.Rack{ID: 2}, nil)
+	mockItemRepo.On("FindByID", 5).Return(&model.Item{ID: 5, TrackLots: true}, nil)
+	mockLocationRepo.On("FindByItemID", 5).Return([]model.ItemLocation{{ItemID: 5, RackID: 1, Quantity: 5}}, nil)
+	mockTransferRepo.On("Create", mock.Anything, []model.TransferItem{{ItemID: 5, Quantity: 4}}).Return(nil)
+
+	_, items, err := service.Create(9, req)
+
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Nil(t, items[0].LotNumber)
+	mockTransferRepo.AssertExpectations(t)
+}
+
+// TestTransferService_Create_SameRack tests rejecting a transfer to the same rack
+func TestTransferService_Create_SameRack(t *testing.T) {
+	service := NewTransferService(repository.Repository{})
+
+	req := dto.TransferRequest{
+		SourceRackID:      1,
+		DestinationRackID: 1,
+		Items:             []dto.TransferItemRequest{{ItemID: 5, Quantity: 1}},
+	}
+
+	_, _, err := service.Create(9, req)
+
+	require.Error(t, err)
+}
+
+// TestTransferService_Dispatch_NegativeStock tests the friendly error on dispatch
+func TestTransferService_Dispatch_NegativeStock(t *testing.T) {
+	mockTransferRepo := new(MockTransferRepository)
+	service := NewTransferService(repository.Repository{TransferRepo: mockTransferRepo})
+
+	mockTransferRepo.On("FindByID", 1).Return(&model.Transfer{ID: 1, Status: model.TransferStatusDraft}, nil)
+	mockTransferRepo.On("Dispatch", 1, 9).Return(repository.ErrInsufficientStock)
+
+	err := service.Dispatch(1, 9)
+
+	require.Error(t, err)
+	require.Equal(t, "transfer would drive source rack stock negative", err.Error())
+}
+
+// TestTransferService_Dispatch_Reserved tests a dispatch that would take reserved units
+func TestTransferService_Dispatch_Reserved(t *testing.T) {
+	mockTransferRepo := new(MockTransferRepository)
+	service := NewTransferService(repository.Repository{TransferRepo: mockTransferRepo})
+
+	mockTransferRepo.On("FindByID", 1).Return(&model.Transfer{ID: 1, Status: model.TransferStatusDraft}, nil)
+	mockTransferRepo.On("Dispatch", 1, 9).Return(repository.ErrInsufficientAvailable)
+
+	err := service.Dispatch(1, 9)
+
+	require.Error(t, err)
+	require.Equal(t, repository.ErrInsufficientAvailable.Error()+", the rest is reserved", err.Error())
+}
@@ -11,6 +11,7 @@ import (
 type UserService interface {
 	Create(user *model.User) error
 	GetAllUsers(page, limit int) (*[]model.User, *dto.Pagination, error)
+	GetUsersAfter(afterID, limit int) ([]model.User, error)
 	GetUserByID(id int) (model.User, error)
 	GetUserByIDDetailed(id int) (*model.User, error)
 	Update(id int, data *model.User) error
@@ -53,6 +54,11 @@ func (s *userService) GetAllUsers(page, limit int) (*[]model.User, *dto.Paginati
 	return &users, &pagination, nil
 }
 
+// GetUsersAfter reads the users after afterID in id order, a page of an export
+func (s *userService) GetUsersAfter(afterID, limit int) ([]model.User, error) {
+	return s.Repo.UserRepo.FindAfter(afterID, limit)
+}
+
 func (s *userService) GetUserByID(id int) (model.User, error) {
 	return s.Repo.UserRepo.GetUserByID(id)
 }
@@ -46,6 +46,11 @@ func (m *MockUserRepository) FindAll(page, limit int) ([]model.User, int, error)
 	return args.Get(0).([]model.User), args.Int(1), args.Error(2)
 }
 
+func (m *MockUserRepository) FindAfter(afterID, limit int) ([]model.User, error) {
+	args := m.Called(afterID, limit)
+	return args.Get(0).([]model.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Update(id int, user *model.User) error {
 	args := m.Called(id, user)
 	return args.Error(0)
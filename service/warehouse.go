@@ -11,6 +11,7 @@ import (
 type WarehouseService interface {
 	Create(warehouse *model.Warehouse) error
 	GetAllWarehouses(page, limit int) (*[]model.Warehouse, *dto.Pagination, error)
+	GetWarehousesAfter(afterID, limit int) ([]model.Warehouse, error)
 	GetWarehouseByID(id int) (*model.Warehouse, error)
 	Update(id int, data *model.Warehouse) error
 	Delete(id int) error
@@ -52,6 +53,11 @@ func (s *warehouseService) GetAllWarehouses(page, limit int) (*[]model.Warehouse
 	return &warehouses, &pagination, nil
 }
 
+// GetWarehousesAfter reads the warehouses after afterID in id order, a page of an export
+func (s *warehouseService) GetWarehousesAfter(afterID, limit int) ([]model.Warehouse, error) {
+	return s.Repo.WarehouseRepo.FindAfter(afterID, limit)
+}
+
 func (s *warehouseService) GetWarehouseByID(id int) (*model.Warehouse, error) {
 	warehouse, err := s.Repo.WarehouseRepo.FindByID(id)
 	if err != nil {
@@ -41,6 +41,11 @@ func (m *MockWarehouseRepository) FindAll(page, limit int) ([]model.Warehouse, i
 	return args.Get(0).([]model.Warehouse), args.Int(1), args.Error(2)
 }
 
+func (m *MockWarehouseRepository) FindAfter(afterID, limit int) ([]model.Warehouse, error) {
+	args := m.Called(afterID, limit)
+	return args.Get(0).([]model.Warehouse), args.Error(1)
+}
+
 func (m *MockWarehouseRepository) Update(id int, warehouse *model.Warehouse) error {
 	args := m.Called(id, warehouse)
 	return args.Error(0)
@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/pflag"
@@ -16,6 +17,25 @@ type Configuration struct {
 	Limit       int
 	PathLogging string
 	DB          DatabaseCofig
+
+	// IdempotencyTTL is how long a response stored for an Idempotency-Key is replayed
+	IdempotencyTTL time.Duration
+
+	// CostingMethod is how the cost of goods sold of a sale line is taken,
+	// "average" for the weighted average cost or "fifo" for the oldest cost layers
+	CostingMethod string
+}
+
+// defaultIdempotencyTTL applies when IDEMPOTENCY_TTL is empty or invalid
+const defaultIdempotencyTTL = 24 * time.Hour
+
+// costingMethod returns method when it is a known costing method and the
+// weighted average otherwise
+func costingMethod(method string) string {
+	if method == "fifo" {
+		return method
+	}
+	return "average"
 }
 
 type DatabaseCofig struct {
@@ -33,6 +53,11 @@ func ReadConfigurationGodotENv() (Configuration, error) {
 		return Configuration{}, errors.New("Error loading .env file")
 	}
 
+	idempotencyTTL, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_TTL"))
+	if err != nil || idempotencyTTL <= 0 {
+		idempotencyTTL = defaultIdempotencyTTL
+	}
+
 	return Configuration{
 		AppName:     os.Getenv("APP_NAME"),
 		Port:        os.Getenv("PORT"),
@@ -46,6 +71,8 @@ func ReadConfigurationGodotENv() (Configuration, error) {
 			Host:     os.Getenv("DATABASE_HOST"),
 			Port:     os.Getenv("DATABASE_PORT"),
 		},
+		IdempotencyTTL: idempotencyTTL,
+		CostingMethod:  costingMethod(os.Getenv("COSTING_METHOD")),
 	}, nil
 
 }
@@ -68,6 +95,11 @@ func ReadConfiguration() (Configuration, error) {
 	pflag.Parse()
 	viper.BindPFlags(pflag.CommandLine)
 
+	idempotencyTTL := viper.GetDuration("IDEMPOTENCY_TTL")
+	if idempotencyTTL <= 0 {
+		idempotencyTTL = defaultIdempotencyTTL
+	}
+
 	return Configuration{
 		AppName:     viper.GetString("APP_NAME"),
 		Port:        viper.GetString("PORT"),
@@ -82,6 +114,8 @@ func ReadConfiguration() (Configuration, error) {
 			Port:     viper.GetString("DATABASE_PORT"),
 			MaxConn:  viper.GetInt32("DATABASE_MAX_CONN"),
 		},
+		IdempotencyTTL: idempotencyTTL,
+		CostingMethod:  costingMethod(viper.GetString("COSTING_METHOD")),
 	}, nil
 
 }
@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WantsCSV reports whether the client asked for CSV, with ?format=csv or an
+// Accept header of text/csv
+func WantsCSV(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return strings.EqualFold(format, "csv")
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/csv")
+}
+
+// CSVWriter streams rows to the response as a CSV attachment. The columns are
+// the json fields of the row struct, named like in the JSON responses.
+type CSVWriter struct {
+	w        http.ResponseWriter
+	csv      *csv.Writer
+	filename string
+	columns  []csvColumn
+}
+
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+func NewCSVWriter(w http.ResponseWriter, filename string) *CSVWriter {
+	return &CSVWriter{w: w, csv: csv.NewWriter(w), filename: filename}
+}
+
+// Write writes a slice of structs, or of pointers to structs, as rows. The
+// first call sends the headers and the header row, even when rows is empty.
+func (c *CSVWriter) Write(rows any) error {
+	slice := reflect.Indirect(reflect.ValueOf(rows))
+	if slice.Kind() != reflect.Slice {
+		return fmt.Errorf("csv rows must be a slice, got %s", slice.Kind())
+	}
+
+	if c.columns == nil {
+		elem := slice.Type().Elem()
+		if elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+		c.columns = csvColumns(elem, nil)
+
+		c.w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		c.w.Header().Set("Content-Disposition", `attachment; filename="`+c.filename+`"`)
+		c.w.WriteHeader(http.StatusOK)
+
+		header := make([]string, len(c.columns))
+		for i, column := range c.columns {
+			header[i] = column.name
+		}
+		if err := c.csv.Write(header); err != nil {
+			return err
+		}
+	}
+
+	record := make([]string, len(c.columns))
+	for i := 0; i < slice.Len(); i++ {
+		row := reflect.Indirect(slice.Index(i))
+		for j, column := range c.columns {
+			record[j] = csvValue(row.FieldByIndex(column.index))
+		}
+		if err := c.csv.Write(record); err != nil {
+			return err
+		}
+	}
+
+	// Send what is written so far, the rest may still be read from the database
+	c.csv.Flush()
+	if flusher, ok := c.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return c.csv.Error()
+}
+
+// csvColumns lists the fields the json encoding of t has, in order. Fields of
+// embedded structs are taken in like json does.
+func csvColumns(t reflect.Type, index []int) []csvColumn {
+	var columns []csvColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			columns = append(columns, csvColumns(field.Type, fieldIndex)...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, csvColumn{name: name, index: fieldIndex})
+	}
+	return columns
+}
+
+// csvValue formats a field the way it reads in JSON, without quotes: money and
+// rates with two decimals, times as RFC 3339, nil as an empty cell and lists or
+// objects as JSON
+func csvValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value.Format(time.RFC3339)
+	case fmt.Stringer:
+		return value.String()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return csvText(v.String())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.IsNil() {
+		return ""
+	}
+	encoded, err := json.Marshal(v.Interface())
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// csvText keeps text a spreadsheet would run as a formula, like a name of
+// "=HYPERLINK(...)", from being run by quoting it with a leading '
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
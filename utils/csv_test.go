@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"project-app-inventory/money"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type csvTestBase struct {
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type csvTestRow struct {
+	csvTestBase
+	Name    string       `json:"name,omitempty"`
+	Price   money.Amount `json:"price"`
+	TaxRate *money.Rate  `json:"tax_rate"`
+	Note    *string      `json:"note"`
+	Tags    []string     `json:"tags"`
+	Active  bool         `json:"active"`
+	Secret  string       `json:"-"`
+	hidden  string
+}
+
+// TestCSVColumns tests embedded fields come first and skipped fields are left out
+func TestCSVColumns(t *testing.T) {
+	columns := csvColumns(reflect.TypeOf(csvTestRow{}), nil)
+
+	var names []string
+	for _, column := range columns {
+		names = append(names, column.name)
+	}
+	require.Equal(t, []string{"id", "created_at", "name", "price", "tax_rate", "note", "tags", "active"}, names)
+	require.Equal(t, []int{0, 1}, columns[1].index)
+}
+
+// TestCSVValue tests fields are written as they read in JSON
+func TestCSVValue(t *testing.T) {
+	rate := money.Rate(1100)
+	note := "fragile"
+	var noNote *string
+
+	cases := []struct {
+		value any
+		want  string
+	}{
+		{money.FromUnits(12), "12.00"},
+		{money.Amount(-150), "-1.50"},
+		{&rate, "11.00"},
+		{(*money.Rate)(nil), ""},
+		{&note, "fragile"},
+		{noNote, ""},
+		{time.Date(2024, 1, 31, 14, 5, 0, 0, time.UTC), "2024-01-31T14:05:00Z"},
+		{-3, "-3"},
+		{true, "true"},
+		{[]string{"a", "b"}, `["a","b"]`},
+		{[]string(nil), ""},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"-1+1", "'-1+1"},
+		{"Mouse", "Mouse"},
+	}
+	for _, c := range cases {
+		value := reflect.ValueOf(&c.value).Elem().Elem()
+		require.Equal(t, c.want, csvValue(value), "%#v", c.value)
+	}
+}
+
+// TestCSVWriter_Write tests the header row is written once across several writes
+func TestCSVWriter_Write(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writer := NewCSVWriter(recorder, "rows.csv")
+
+	created := time.Date(2024, 1, 31, 14, 5, 0, 0, time.UTC)
+	require.NoError(t, writer.Write([]csvTestRow{{csvTestBase: csvTestBase{ID: 1, CreatedAt: created}, Name: "Mouse", Price: money.FromUnits(5)}}))
+	require.NoError(t, writer.Write(&[]*csvTestRow{{csvTestBase: csvTestBase{ID: 2, CreatedAt: created}, Name: "+Cable", Active: true}}))
+
+	require.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="rows.csv"`, recorder.Header().Get("Content-Disposition"))
+	require.Equal(t, "id,created_at,name,price,tax_rate,note,tags,active\n"+
+		"1,2024-01-31T14:05:00Z,Mouse,5.00,,,,false\n"+
+		"2,2024-01-31T14:05:00Z,'+Cable,0.00,,,,true\n", recorder.Body.String())
+}
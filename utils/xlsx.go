@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"project-app-inventory/money"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// WantsXLSX reports whether the client asked for an Excel file, with
+// ?format=xlsx or an Accept header of the xlsx content type
+func WantsXLSX(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return strings.EqualFold(format, "xlsx")
+	}
+	return strings.Contains(r.Header.Get("Accept"), xlsxContentType)
+}
+
+// XLSXSheet is one worksheet: a bold header row, the rows and an optional bold
+// totals row. Cells are typed by their Go value: ints and floats are numbers,
+// money.Amount a number with two decimals, money.Rate a percentage, time.Time
+// a date, nil an empty cell and anything else text.
+type XLSXSheet struct {
+	Name   string
+	Header []string
+	Rows   [][]any
+	Totals []any
+}
+
+// ResponseXLSX sends the sheet as an .xlsx attachment
+func ResponseXLSX(w http.ResponseWriter, filename string, sheet XLSXSheet) {
+	var buf bytes.Buffer
+	if err := writeXLSX(&buf, sheet); err != nil {
+		ResponseBadRequest(w, http.StatusInternalServerError, "failed to write xlsx: "+err.Error(), nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", xlsxContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// writeXLSX writes the smallest workbook Excel and LibreOffice open without
+// repairing: one sheet, inline strings and the styles the cells need
+func writeXLSX(buf *bytes.Buffer, sheet XLSXSheet) error {
+	archive := zip.NewWriter(buf)
+
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, xmlEscape(xlsxSheetName(sheet.Name)))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", xlsxWorksheet(sheet)},
+	}
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write([]byte(file.content)); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// Cell formats, in the order of the cellXfs of xlsxStyles. Every format is
+// there twice, the bold one right after the regular one.
+const (
+	xlsxGeneral = iota * 2
+	xlsxMoney
+	xlsxDate
+	xlsxPercent
+)
+
+// xlsxEpoch is day zero of the 1900 date system as Excel counts it
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+func xlsxWorksheet(sheet XLSXSheet) string {
+	var rows strings.Builder
+	rowNum := 0
+	writeRow := func(cells []any, bold bool) {
+		rowNum++
+		fmt.Fprintf(&rows, `<row r="%d">`, rowNum)
+		for i, value := range cells {
+			rows.WriteString(xlsxCell(xlsxCellRef(i, rowNum), value, bold))
+		}
+		rows.WriteString(`</row>`)
+	}
+
+	header := make([]any, len(sheet.Header))
+	for i, name := range sheet.Header {
+		header[i] = name
+	}
+	writeRow(header, true)
+	for _, row := range sheet.Rows {
+		writeRow(row, false)
+	}
+	if sheet.Totals != nil {
+		writeRow(sheet.Totals, true)
+	}
+
+	return xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<sheetData>` + rows.String() + `</sheetData></worksheet>`
+}
+
+func xlsxCell(ref string, value any, bold bool) string {
+	style := func(format int) int {
+		if bold {
+			return format + 1
+		}
+		return format
+	}
+	number := func(format int, v string) string {
+		return fmt.Sprintf(`<c r="%s" s="%d"><v>%s</v></c>`, ref, style(format), v)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case *int:
+		if v == nil {
+			return ""
+		}
+		return xlsxCell(ref, *v, bold)
+	case *string:
+		if v == nil {
+			return ""
+		}
+		return xlsxCell(ref, *v, bold)
+	case int:
+		return number(xlsxGeneral, strconv.Itoa(v))
+	case int64:
+		return number(xlsxGeneral, strconv.FormatInt(v, 10))
+	case float64:
+		return number(xlsxGeneral, strconv.FormatFloat(v, 'f', -1, 64))
+	case money.Amount:
+		return number(xlsxMoney, v.String())
+	case money.Rate:
+		return number(xlsxPercent, strconv.FormatFloat(float64(v)/float64(money.MaxRate), 'f', -1, 64))
+	case time.Time:
+		// The date as it reads in its own location, Excel dates have no zone
+		year, month, day := v.Date()
+		days := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Sub(xlsxEpoch).Hours() / 24
+		return number(xlsxDate, strconv.Itoa(int(days)))
+	case string:
+		return fmt.Sprintf(`<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style(xlsxGeneral), xmlEscape(v))
+	default:
+		return xlsxCell(ref, fmt.Sprint(v), bold)
+	}
+}
+
+// xlsxCellRef names the cell of the zero based column in the row, A1 style
+func xlsxCellRef(column, row int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+// xlsxSheetName drops what Excel does not allow in a sheet name
+func xlsxSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" {
+		name = "Sheet1"
+	}
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	return name
+}
+
+func xmlEscape(s string) string {
+	var buf strings.Builder
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles holds a regular and a bold font, and per cell format a regular
+// and a bold style: general, #,##0.00 (built in 4), yyyy-mm-dd and 0.00%
+// (built in 10)
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="8">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="4" fontId="1" fillId="0" borderId="0" xfId="0" applyNumberFormat="1" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="164" fontId="1" fillId="0" borderId="0" xfId="0" applyNumberFormat="1" applyFont="1"/>` +
+	`<xf numFmtId="10" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="10" fontId="1" fillId="0" borderId="0" xfId="0" applyNumberFormat="1" applyFont="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"project-app-inventory/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestWriteXLSX tests the workbook unzips and the sheet holds typed, styled cells
+func TestWriteXLSX(t *testing.T) {
+	sheet := XLSXSheet{
+		Name:   "Sales 2024-01-01 to 2024-01-31",
+		Header: []string{"period", "name", "revenue", "count", "tax_rate"},
+		Rows: [][]any{
+			{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "Mouse & Pad", money.FromUnits(1500), 3, money.Rate(1100)},
+		},
+		Totals: []any{"Total", nil, money.FromUnits(1500), 3, nil},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeXLSX(&buf, sheet))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		f, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(f)
+		require.NoError(t, err)
+		f.Close()
+		files[file.Name] = string(content)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		require.Contains(t, files, name)
+	}
+	require.Contains(t, files["xl/workbook.xml"], `<sheet name="Sales 2024-01-01 to 2024-01-31"`)
+
+	worksheet := files["xl/worksheets/sheet1.xml"]
+	// Header, bold text
+	require.Contains(t, worksheet, `<row r="1"><c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">period</t></is></c>`)
+	require.Contains(t, worksheet, `<c r="E1" s="1" t="inlineStr"><is><t xml:space="preserve">tax_rate</t></is></c></row>`)
+	// Data: a date serial, escaped text, money with two decimals, a number and a percentage
+	require.Contains(t, worksheet, `<row r="2"><c r="A2" s="4"><v>45292</v></c>`+
+		`<c r="B2" s="0" t="inlineStr"><is><t xml:space="preserve">Mouse &amp; Pad</t></is></c>`+
+		`<c r="C2" s="2"><v>1500.00</v></c>`+
+		`<c r="D2" s="0"><v>3</v></c>`+
+		`<c r="E2" s="6"><v>0.11</v></c></row>`)
+	// Totals, bold and with the empty cells left out
+	require.Contains(t, worksheet, `<row r="3"><c r="A3" s="1" t="inlineStr"><is><t xml:space="preserve">Total</t></is></c>`+
+		`<c r="C3" s="3"><v>1500.00</v></c>`+
+		`<c r="D3" s="1"><v>3</v></c></row>`)
+}
+
+// TestXLSXCellRef tests columns past Z roll over to two letters
+func TestXLSXCellRef(t *testing.T) {
+	require.Equal(t, "A1", xlsxCellRef(0, 1))
+	require.Equal(t, "Z2", xlsxCellRef(25, 2))
+	require.Equal(t, "AA1", xlsxCellRef(26, 1))
+	require.Equal(t, "AZ3", xlsxCellRef(51, 3))
+	require.Equal(t, "BA3", xlsxCellRef(52, 3))
+}
+
+// TestXLSXSheetName tests invalid characters are dropped and long names cut
+func TestXLSXSheetName(t *testing.T) {
+	require.Equal(t, "Sales 20240101", xlsxSheetName("Sales 2024/01/01"))
+	require.Equal(t, "Sheet1", xlsxSheetName("[]"))
+	require.Len(t, xlsxSheetName("Inventory valuation 2024-01-31 12:00"), 31)
+}